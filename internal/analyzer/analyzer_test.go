@@ -21,7 +21,7 @@ func TestScanProjectValidProject(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	analysis, err := analyzer.ScanProject(tmpDir)
+	analysis, err := analyzer.ScanProject(tmpDir, nil)
 	if err != nil {
 		t.Fatalf("ScanProject() failed: %v", err)
 	}
@@ -32,7 +32,7 @@ func TestScanProjectValidProject(t *testing.T) {
 }
 
 func TestScanProjectInvalidPath(t *testing.T) {
-	_, err := analyzer.ScanProject("/nonexistent/path")
+	_, err := analyzer.ScanProject("/nonexistent/path", nil)
 	if err == nil {
 		t.Error("ScanProject() should fail for non-existent path")
 	}
@@ -64,7 +64,7 @@ service UserService {
 		t.Fatal(err)
 	}
 
-	analysis, err := analyzer.ScanProject(tmpDir)
+	analysis, err := analyzer.ScanProject(tmpDir, nil)
 	if err != nil {
 		t.Fatalf("ScanProject() failed: %v", err)
 	}
@@ -95,7 +95,7 @@ func TestScanProjectWithConfigFiles(t *testing.T) {
 		}
 	}
 
-	analysis, err := analyzer.ScanProject(tmpDir)
+	analysis, err := analyzer.ScanProject(tmpDir, nil)
 	if err != nil {
 		t.Fatalf("ScanProject() failed: %v", err)
 	}
@@ -127,7 +127,7 @@ require (
 		t.Fatal(err)
 	}
 
-	analysis, err := analyzer.ScanProject(tmpDir)
+	analysis, err := analyzer.ScanProject(tmpDir, nil)
 	if err != nil {
 		t.Fatalf("ScanProject() failed: %v", err)
 	}
@@ -140,3 +140,222 @@ require (
 		t.Error("go-zero version should be detected")
 	}
 }
+
+func TestParseAPISpecificationWithImports(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	typesContent := `syntax = "v1"
+
+type User {
+	Id int64 ` + "`json:\"id\"`" + `
+}
+`
+	apiContent := `syntax = "v1"
+
+import "types.api"
+
+@server (
+	group:  user
+	prefix: /api/v1
+	jwt:    Auth
+)
+service user-api {
+	@doc "Get a user"
+	@handler GetUser
+	get /users/:id returns (User)
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "types.api"), []byte(typesContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	apiFile := filepath.Join(tmpDir, "user.api")
+	if err := os.WriteFile(apiFile, []byte(apiContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := analyzer.ParseAPISpecification(apiFile, nil)
+	if err != nil {
+		t.Fatalf("ParseAPISpecification() failed: %v", err)
+	}
+
+	if spec.ServiceName != "user" {
+		t.Errorf("ServiceName = %q, want %q", spec.ServiceName, "user")
+	}
+	if len(spec.Types) != 1 || spec.Types[0] != "User" {
+		t.Errorf("Types = %v, want [User]", spec.Types)
+	}
+	if len(spec.Endpoints) != 1 {
+		t.Fatalf("Expected 1 endpoint, got %d", len(spec.Endpoints))
+	}
+
+	ep := spec.Endpoints[0]
+	if ep.Method != "GET" || ep.Path != "/api/v1/users/:id" || ep.Handler != "GetUser" {
+		t.Errorf("Unexpected endpoint: %+v", ep)
+	}
+	if ep.Group != "user" || ep.JWT != "Auth" || ep.Response != "User" || ep.Doc != "Get a user" {
+		t.Errorf("Unexpected endpoint attributes: %+v", ep)
+	}
+}
+
+// chdir changes the working directory for the rest of the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestScanProjectRelativePathWithAPIImports(t *testing.T) {
+	tmpDir := t.TempDir()
+	apiDir := filepath.Join(tmpDir, "project", "api")
+	if err := os.MkdirAll(apiDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"types.api": "syntax = \"v1\"\n\ntype User {\n\tId int64 `json:\"id\"`\n}\n",
+		"user.api":  "syntax = \"v1\"\n\nimport \"types.api\"\n\nservice user-api {\n\t@handler GetUser\n\tget /users/:id returns (User)\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(apiDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Discovered files are relative here, while imports are parsed as absolute paths
+	chdir(t, tmpDir)
+	analysis, err := analyzer.ScanProject("project", nil)
+	if err != nil {
+		t.Fatalf("ScanProject() failed: %v", err)
+	}
+	if analysis.Summary.APIServices != 1 {
+		t.Fatalf("Expected 1 API service, got %d: %+v", analysis.Summary.APIServices, analysis.Services)
+	}
+	if analysis.Services[0].Name != "user" {
+		t.Errorf("Unexpected service: %+v", analysis.Services[0])
+	}
+}
+
+func TestScanProjectWithMultiFileProto(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	commonDir := filepath.Join(tmpDir, "common")
+	rpcDir := filepath.Join(tmpDir, "rpc", "user")
+	for _, dir := range []string{commonDir, rpcDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commonContent := `syntax = "proto3";
+
+package common;
+
+option go_package = "./common";
+
+message Empty {}
+`
+	userContent := `syntax = "proto3";
+
+package user;
+
+import "common/types.proto";
+
+option go_package = "./user";
+
+message GetUserReq {
+  int64 id = 1;
+}
+
+message GetUserResp {
+  string name = 1;
+  map<string, string> labels = 2;
+}
+
+service User {
+  rpc GetUser(GetUserReq) returns (GetUserResp) {
+    option deprecated = false;
+  }
+  rpc Watch(common.Empty) returns (stream GetUserResp);
+}
+
+service Admin {
+  rpc Ping(common.Empty) returns (common.Empty);
+}
+`
+	if err := os.WriteFile(filepath.Join(commonDir, "types.proto"), []byte(commonContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(rpcDir, "user.proto"), []byte(userContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := analyzer.ScanProject(tmpDir, nil)
+	if err != nil {
+		t.Fatalf("ScanProject() failed: %v", err)
+	}
+
+	// common/types.proto has no service and is imported, so only user.proto counts
+	if analysis.Summary.RPCServices != 2 {
+		t.Fatalf("Expected 2 RPC services, got %d: %+v", analysis.Summary.RPCServices, analysis.Services)
+	}
+	if analysis.Summary.TotalRPCMethods != 3 {
+		t.Errorf("Expected 3 RPC methods, got %d", analysis.Summary.TotalRPCMethods)
+	}
+
+	user := analysis.Services[0]
+	if user.Name != "User" || len(user.RPCMethods) != 2 {
+		t.Fatalf("Unexpected first service: %+v", user)
+	}
+	if user.RPCMethods[0].Stream || !user.RPCMethods[1].Stream {
+		t.Errorf("Unexpected stream flags: %+v", user.RPCMethods)
+	}
+
+	spec, err := analyzer.ParseProtoSpecification(filepath.Join(rpcDir, "user.proto"), []string{rpcDir, tmpDir}, nil)
+	if err != nil {
+		t.Fatalf("ParseProtoSpecification() failed: %v", err)
+	}
+	if spec.Package != "user" || spec.GoPackage != "./user" {
+		t.Errorf("Package = %q, GoPackage = %q", spec.Package, spec.GoPackage)
+	}
+	if len(spec.Messages) != 3 {
+		t.Errorf("Expected 3 messages including the import, got %d", len(spec.Messages))
+	}
+	if spec.Methods[1].Stream != "response" {
+		t.Errorf("Watch Stream = %q, want %q", spec.Methods[1].Stream, "response")
+	}
+	if spec.Methods[0].Options["deprecated"] != "false" {
+		t.Errorf("GetUser options = %v", spec.Methods[0].Options)
+	}
+}
+
+func TestScanProjectRelativePathWithProtoImports(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"project/common/health.proto": "syntax = \"proto3\";\n\npackage common;\n\noption go_package = \"./common\";\n\nmessage Empty {}\n\nservice Health {\n  rpc Check(Empty) returns (Empty);\n}\n",
+		"project/rpc/user.proto":      "syntax = \"proto3\";\n\npackage user;\n\nimport \"common/health.proto\";\n\noption go_package = \"./user\";\n\nservice User {\n  rpc Ping(common.Empty) returns (common.Empty);\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The imported file defines a service of its own but belongs to user.proto
+	chdir(t, tmpDir)
+	analysis, err := analyzer.ScanProject("project", nil)
+	if err != nil {
+		t.Fatalf("ScanProject() failed: %v", err)
+	}
+	if analysis.Summary.RPCServices != 1 || analysis.Services[0].Name != "User" {
+		t.Fatalf("Expected only the User service, got %+v", analysis.Services)
+	}
+}
@@ -2,9 +2,10 @@ package analyzer
 
 import (
 	"fmt"
-	"os"
-	"regexp"
+	"path"
 	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
 )
 
 type APISpecification struct {
@@ -12,78 +13,70 @@ type APISpecification struct {
 	ServiceName string
 	Endpoints   []Endpoint
 	Types       []string
+	AST         *apispec.Spec // full syntax tree, including imported files
 }
 
 type Endpoint struct {
 	Method   string
-	Path     string
+	Path     string // full path including the @server prefix
 	Handler  string
 	Request  string
 	Response string
+	Group    string
+	Prefix   string
+	JWT      string
+	Doc      string
+	File     string
 }
 
-func ParseAPISpecification(apiFile string) (*APISpecification, error) {
-	content, err := os.ReadFile(apiFile)
+// ParseAPISpecification parses an .api file and every file it imports, passing each
+// import to checkImport, when set, before it is read
+func ParseAPISpecification(apiFile string, checkImport func(path string) error) (*APISpecification, error) {
+	ast, err := apispec.Load(apiFile, checkImport)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read API file: %w", err)
+		return nil, err
 	}
-	spec := &APISpecification{FilePath: apiFile}
-	fileContent := string(content)
-	spec.ServiceName = extractServiceName(fileContent)
+
+	spec := &APISpecification{FilePath: apiFile, AST: ast}
+	spec.ServiceName = strings.TrimSuffix(ast.ServiceName(), "-api")
 	if spec.ServiceName == "" {
 		return nil, fmt.Errorf("no service name found")
 	}
-	spec.Endpoints = extractEndpoints(fileContent)
-	spec.Types = extractTypes(fileContent)
-	return spec, nil
-}
-
-func extractServiceName(content string) string {
-	serviceRegex := regexp.MustCompile(`service\s+([a-zA-Z0-9_-]+)\s*{`)
-	matches := serviceRegex.FindStringSubmatch(content)
-	if len(matches) > 1 {
-		return strings.TrimSuffix(matches[1], "-api")
-	}
-	return ""
-}
 
-func extractEndpoints(content string) []Endpoint {
-	var endpoints []Endpoint
-	serviceRegex := regexp.MustCompile(`service\s+[a-zA-Z0-9_-]+\s*{([^}]+)}`)
-	serviceMatches := serviceRegex.FindStringSubmatch(content)
-	if len(serviceMatches) < 2 {
-		return endpoints
-	}
-	serviceBlock := serviceMatches[1]
-	endpointRegex := regexp.MustCompile(`@handler\s+(\w+)\s+(\w+)\s+(/[^\s(]*)\s*(?:\(([^)]+)\))?\s*(?:returns\s*\(([^)]+)\))?`)
-	matches := endpointRegex.FindAllStringSubmatch(serviceBlock, -1)
-	for _, match := range matches {
-		if len(match) > 3 {
-			endpoint := Endpoint{
-				Handler: match[1],
-				Method:  strings.ToUpper(match[2]),
-				Path:    match[3],
+	for _, file := range ast.Files {
+		for _, svc := range file.Services {
+			for _, route := range svc.Routes {
+				spec.Endpoints = append(spec.Endpoints, Endpoint{
+					Method:   strings.ToUpper(route.Method),
+					Path:     joinRoutePath(svc.Prefix(), route.Path),
+					Handler:  route.Handler,
+					Request:  route.Request.String(),
+					Response: route.Response.String(),
+					Group:    svc.Group(),
+					Prefix:   svc.Prefix(),
+					JWT:      svc.JWT(),
+					Doc:      route.Summary(),
+					File:     file.Path,
+				})
 			}
-			if len(match) > 4 {
-				endpoint.Request = strings.TrimSpace(match[4])
-			}
-			if len(match) > 5 {
-				endpoint.Response = strings.TrimSpace(match[5])
-			}
-			endpoints = append(endpoints, endpoint)
 		}
 	}
-	return endpoints
+
+	for _, t := range ast.Types() {
+		spec.Types = append(spec.Types, t.Name)
+	}
+
+	return spec, nil
 }
 
-func extractTypes(content string) []string {
-	var types []string
-	typeRegex := regexp.MustCompile(`type\s+(\w+)\s+(?:struct\s*)?{`)
-	matches := typeRegex.FindAllStringSubmatch(content, -1)
-	for _, match := range matches {
-		if len(match) > 1 {
-			types = append(types, match[1])
-		}
+// joinRoutePath prepends a @server prefix to a route path the way goctl does
+func joinRoutePath(prefix, routePath string) string {
+	if prefix == "" {
+		return routePath
+	}
+	joined := path.Join("/", prefix, routePath)
+	if strings.HasSuffix(routePath, "/") && !strings.HasSuffix(joined, "/") {
+		joined += "/"
 	}
-	return types
+	return joined
 }
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/protospec"
 )
 
 // ProjectAnalysis represents a comprehensive analysis of a go-zero project
@@ -32,6 +34,7 @@ type EndpointInfo struct {
 	Method  string
 	Path    string
 	Handler string
+	Group   string
 }
 
 // RPCMethodInfo represents an RPC method
@@ -67,8 +70,9 @@ type ProjectSummary struct {
 	GoZeroVersion     string
 }
 
-// ScanProject analyzes a go-zero project directory
-func ScanProject(projectPath string) (*ProjectAnalysis, error) {
+// ScanProject analyzes a go-zero project directory; files imported by its specs are
+// passed to checkImport, when set, before they are read
+func ScanProject(projectPath string, checkImport func(path string) error) (*ProjectAnalysis, error) {
 	if !filepath.IsAbs(projectPath) {
 		var err error
 		projectPath, err = filepath.Abs(projectPath)
@@ -95,7 +99,25 @@ func ScanProject(projectPath string) (*ProjectAnalysis, error) {
 	// Discover API services
 	apiFiles, err := discoverAPIFiles(projectPath)
 	if err == nil {
+		// Parse every spec first so files imported by another spec are not counted twice
+		specs := make(map[string]*APISpecification)
+		imported := make(map[string]bool)
 		for _, apiFile := range apiFiles {
+			if spec, err := ParseAPISpecification(apiFile, checkImport); err == nil {
+				specs[apiFile] = spec
+				if len(spec.AST.Files) > 1 {
+					for _, file := range spec.AST.Files[1:] {
+						imported[absFile(file.Path)] = true
+					}
+				}
+			}
+		}
+
+		for _, apiFile := range apiFiles {
+			if imported[absFile(apiFile)] {
+				continue
+			}
+
 			service := ServiceInfo{
 				Type:      "api",
 				Path:      filepath.Dir(apiFile),
@@ -103,14 +125,15 @@ func ScanProject(projectPath string) (*ProjectAnalysis, error) {
 				Endpoints: []EndpointInfo{},
 			}
 
-			// Parse API spec to extract endpoints
-			if spec, err := ParseAPISpecification(apiFile); err == nil {
+			// Use the parsed spec to extract endpoints
+			if spec, ok := specs[apiFile]; ok {
 				service.Name = spec.ServiceName
 				for _, endpoint := range spec.Endpoints {
 					service.Endpoints = append(service.Endpoints, EndpointInfo{
 						Method:  endpoint.Method,
 						Path:    endpoint.Path,
 						Handler: endpoint.Handler,
+						Group:   endpoint.Group,
 					})
 				}
 			}
@@ -124,31 +147,66 @@ func ScanProject(projectPath string) (*ProjectAnalysis, error) {
 	// Discover RPC services
 	protoFiles, err := discoverProtoFiles(projectPath)
 	if err == nil {
+		// Imports are resolved like goctl rpc protoc -I <dir> -I <project root>
+		specs := make(map[string]*RPCService)
+		imported := make(map[string]bool)
 		for _, protoFile := range protoFiles {
-			service := ServiceInfo{
-				Type:       "rpc",
-				Path:       filepath.Dir(protoFile),
-				SpecFile:   protoFile,
-				RPCMethods: []RPCMethodInfo{},
+			if spec, err := ParseProtoSpecification(protoFile, []string{filepath.Dir(protoFile), projectPath}, checkImport); err == nil {
+				specs[protoFile] = spec
+				if len(spec.AST.Files) > 1 {
+					for _, file := range spec.AST.Files[1:] {
+						imported[absFile(file.Path)] = true
+					}
+				}
 			}
+		}
 
-			// Parse proto spec to extract methods
-			if spec, err := ParseProtoSpecification(protoFile); err == nil {
-				service.Name = spec.ServiceName
+		for _, protoFile := range protoFiles {
+			if imported[absFile(protoFile)] {
+				continue
+			}
+
+			spec, ok := specs[protoFile]
+			if !ok {
+				// Message-only files are shared definitions, not services
+				if file, err := protospec.ParseFile(protoFile); err == nil && len(file.Services) == 0 {
+					continue
+				}
+				analysis.Services = append(analysis.Services, ServiceInfo{
+					Type:       "rpc",
+					Path:       filepath.Dir(protoFile),
+					SpecFile:   protoFile,
+					RPCMethods: []RPCMethodInfo{},
+				})
+				analysis.Summary.RPCServices++
+				continue
+			}
+
+			// A proto file may declare several services (goctl rpc protoc --multiple)
+			for _, name := range spec.Services {
+				service := ServiceInfo{
+					Name:       name,
+					Type:       "rpc",
+					Path:       filepath.Dir(protoFile),
+					SpecFile:   protoFile,
+					RPCMethods: []RPCMethodInfo{},
+				}
 				for _, method := range spec.Methods {
-					isStream := strings.Contains(method.Stream, "stream")
+					if method.Service != name {
+						continue
+					}
 					service.RPCMethods = append(service.RPCMethods, RPCMethodInfo{
 						Name:     method.Name,
 						Request:  method.Request,
 						Response: method.Response,
-						Stream:   isStream,
+						Stream:   method.Stream != "",
 					})
 				}
-			}
 
-			analysis.Services = append(analysis.Services, service)
-			analysis.Summary.RPCServices++
-			analysis.Summary.TotalRPCMethods += len(service.RPCMethods)
+				analysis.Services = append(analysis.Services, service)
+				analysis.Summary.RPCServices++
+				analysis.Summary.TotalRPCMethods += len(service.RPCMethods)
+			}
 		}
 	}
 
@@ -172,6 +230,15 @@ func ScanProject(projectPath string) (*ProjectAnalysis, error) {
 	return analysis, nil
 }
 
+// absFile returns path as an absolute path, so discovered files and the absolute paths
+// of parsed imports compare equal when the project path is relative
+func absFile(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
 // discoverAPIFiles finds all .api files in the project
 func discoverAPIFiles(projectPath string) ([]string, error) {
 	var apiFiles []string
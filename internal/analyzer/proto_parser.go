@@ -2,107 +2,130 @@ package analyzer
 
 import (
 	"fmt"
-	"os"
-	"regexp"
-	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/protospec"
 )
 
 type RPCService struct {
 	FilePath    string
-	ServiceName string
+	ServiceName string // first service in the file
+	Package     string
+	GoPackage   string
+	Services    []string
 	Methods     []RPCMethod
-	Messages    []string
+	Messages    []RPCMessage
+	Enums       []string
+	Imports     []string
+	AST         *protospec.Spec // full syntax tree, including imported files
 }
 
 type RPCMethod struct {
-	Name     string
-	Request  string
-	Response string
-	Stream   string
+	Service         string
+	Name            string
+	Request         string
+	Response        string
+	Stream          string // "", "request", "response" or "bidirectional"
+	ClientStreaming bool
+	ServerStreaming bool
+	Options         map[string]string
 }
 
-func ParseProtoSpecification(protoFile string) (*RPCService, error) {
-	content, err := os.ReadFile(protoFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read proto file: %w", err)
-	}
+type RPCMessage struct {
+	Name   string // package-qualified, including parent messages
+	File   string
+	Fields []RPCField
+}
 
-	service := &RPCService{
-		FilePath: protoFile,
-	}
+type RPCField struct {
+	Name    string
+	Type    string // as written, e.g. "repeated string" or "map<string, int64>"
+	Number  int
+	Oneof   string
+	Comment string
+}
 
-	fileContent := string(content)
+// ParseProtoSpecification parses a .proto file and every file it imports
+// Imports are resolved against includePaths like goctl rpc protoc -I; by default
+// the directory of protoFile is used. Each import is passed to checkImport, when set,
+// before it is read
+func ParseProtoSpecification(protoFile string, includePaths []string, checkImport func(path string) error) (*RPCService, error) {
+	ast, err := protospec.Load(protoFile, includePaths, checkImport)
+	if err != nil {
+		return nil, err
+	}
 
-	service.ServiceName = extractRPCServiceName(fileContent)
-	if service.ServiceName == "" {
+	root := ast.Root
+	if len(root.Services) == 0 {
 		return nil, fmt.Errorf("no service name found")
 	}
 
-	service.Methods = extractRPCMethods(fileContent)
-	service.Messages = extractMessages(fileContent)
-
-	return service, nil
-}
-
-func extractRPCServiceName(content string) string {
-	serviceRegex := regexp.MustCompile(`service\s+([A-Z][a-zA-Z0-9_]*)\s*{`)
-	matches := serviceRegex.FindStringSubmatch(content)
-	if len(matches) > 1 {
-		return matches[1]
+	service := &RPCService{
+		FilePath:    protoFile,
+		ServiceName: root.Services[0].Name,
+		Package:     root.Package,
+		GoPackage:   root.GoPackage(),
+		AST:         ast,
 	}
-	return ""
-}
 
-func extractRPCMethods(content string) []RPCMethod {
-	var methods []RPCMethod
-
-	serviceRegex := regexp.MustCompile(`service\s+[A-Z][a-zA-Z0-9_]*\s*{([^}]+)}`)
-	serviceMatches := serviceRegex.FindStringSubmatch(content)
-	if len(serviceMatches) < 2 {
-		return methods
+	for _, imp := range root.Imports {
+		service.Imports = append(service.Imports, imp.Path)
 	}
 
-	serviceBlock := serviceMatches[1]
-	methodRegex := regexp.MustCompile(`rpc\s+([A-Z][a-zA-Z0-9_]*)\s*\(([^)]+)\)\s*returns\s*\(([^)]+)\)`)
-	matches := methodRegex.FindAllStringSubmatch(serviceBlock, -1)
-
-	for _, match := range matches {
-		if len(match) > 3 {
+	for _, svc := range root.Services {
+		service.Services = append(service.Services, svc.Name)
+		for _, m := range svc.Methods {
 			method := RPCMethod{
-				Name:     match[1],
-				Request:  strings.TrimSpace(match[2]),
-				Response: strings.TrimSpace(match[3]),
-			}
-
-			if strings.Contains(match[2], "stream") {
-				method.Stream = "request"
+				Service:         svc.Name,
+				Name:            m.Name,
+				Request:         m.Request,
+				Response:        m.Response,
+				Stream:          streamKind(m),
+				ClientStreaming: m.ClientStreaming,
+				ServerStreaming: m.ServerStreaming,
 			}
-			if strings.Contains(match[3], "stream") {
-				if method.Stream == "request" {
-					method.Stream = "bidirectional"
-				} else {
-					method.Stream = "response"
+			if len(m.Options) > 0 {
+				method.Options = make(map[string]string)
+				for _, opt := range m.Options {
+					method.Options[opt.Name] = opt.Value
 				}
 			}
-
-			methods = append(methods, method)
+			service.Methods = append(service.Methods, method)
 		}
 	}
 
-	return methods
-}
+	for _, msg := range ast.Messages() {
+		message := RPCMessage{Name: msg.FullName}
+		if f := ast.FileOf(msg); f != nil {
+			message.File = f.Path
+		}
+		for _, field := range msg.Fields {
+			message.Fields = append(message.Fields, RPCField{
+				Name:    field.Name,
+				Type:    field.TypeString(),
+				Number:  field.Number,
+				Oneof:   field.Oneof,
+				Comment: field.Comment,
+			})
+		}
+		service.Messages = append(service.Messages, message)
+	}
 
-func extractMessages(content string) []string {
-	var messages []string
+	for _, enum := range ast.Enums() {
+		service.Enums = append(service.Enums, enum.FullName)
+	}
 
-	messageRegex := regexp.MustCompile(`message\s+([A-Z][a-zA-Z0-9_]*)\s*{`)
-	matches := messageRegex.FindAllStringSubmatch(content, -1)
+	return service, nil
+}
 
-	for _, match := range matches {
-		if len(match) > 1 {
-			messages = append(messages, match[1])
-		}
+// streamKind describes which side of a method streams
+func streamKind(m *protospec.Method) string {
+	switch {
+	case m.ClientStreaming && m.ServerStreaming:
+		return "bidirectional"
+	case m.ClientStreaming:
+		return "request"
+	case m.ServerStreaming:
+		return "response"
 	}
-
-	return messages
+	return ""
 }
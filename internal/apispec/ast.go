@@ -0,0 +1,402 @@
+package apispec
+
+import (
+	"strings"
+)
+
+// File is the syntax tree of a single .api file
+type File struct {
+	Path          string         `json:"path,omitempty"`
+	Syntax        string         `json:"syntax,omitempty"`
+	Info          *InfoDecl      `json:"info,omitempty"`
+	Imports       []*ImportDecl  `json:"imports,omitempty"`
+	Types         []*TypeDecl    `json:"types,omitempty"`
+	Services      []*ServiceDecl `json:"services,omitempty"`
+	Doc           []string       `json:"doc,omitempty"` // comments before the syntax declaration
+	SyntaxComment string         `json:"syntax_comment,omitempty"`
+	Trailing      []string       `json:"trailing,omitempty"`    // comments after the last declaration
+	Decls         []Decl         `json:"-"`                     // top-level declarations in source order
+	Groups        []*TypeGroup   `json:"type_groups,omitempty"` // type ( ... ) blocks
+}
+
+// Decl is implemented by every top-level declaration
+type Decl interface {
+	Position() Pos
+}
+
+// Property is a "key: value" entry inside info(), @server() or @doc()
+type Property struct {
+	Pos     Pos      `json:"pos"`
+	Key     string   `json:"key"`
+	Value   string   `json:"value"`
+	Quoted  bool     `json:"quoted,omitempty"` // value was written as a string literal
+	Doc     []string `json:"doc,omitempty"`
+	Comment string   `json:"comment,omitempty"`
+}
+
+// InfoDecl is the info(...) block
+type InfoDecl struct {
+	Pos        Pos         `json:"pos"`
+	Properties []*Property `json:"properties,omitempty"`
+	Doc        []string    `json:"doc,omitempty"`
+}
+
+// Position returns the position of the info keyword
+func (d *InfoDecl) Position() Pos { return d.Pos }
+
+// Get returns the value of an info property, or empty string
+func (d *InfoDecl) Get(key string) string {
+	return getProperty(d.Properties, key)
+}
+
+// ImportDecl is a single imported .api file
+type ImportDecl struct {
+	Pos     Pos      `json:"pos"`
+	Path    string   `json:"path"`
+	Doc     []string `json:"doc,omitempty"`
+	Comment string   `json:"comment,omitempty"`
+	Grouped bool     `json:"grouped,omitempty"` // declared inside import ( ... )
+}
+
+// Position returns the position of the import path
+func (d *ImportDecl) Position() Pos { return d.Pos }
+
+// TypeGroup records a type ( ... ) block so it can be printed back as one
+type TypeGroup struct {
+	Pos    Pos         `json:"pos"`
+	Types  []*TypeDecl `json:"-"`
+	Names  []string    `json:"names"`
+	Doc    []string    `json:"doc,omitempty"`
+	EndPos Pos         `json:"end_pos"` // position of the closing parenthesis
+}
+
+// Position returns the position of the type keyword
+func (g *TypeGroup) Position() Pos { return g.Pos }
+
+// TypeDecl is a named struct type
+type TypeDecl struct {
+	Pos     Pos        `json:"pos"`
+	Name    string     `json:"name"`
+	Struct  bool       `json:"struct,omitempty"` // written with the optional struct keyword
+	Fields  []*Field   `json:"fields,omitempty"`
+	Doc     []string   `json:"doc,omitempty"`
+	Comment string     `json:"comment,omitempty"`
+	EndDoc  []string   `json:"end_doc,omitempty"` // comments before the closing brace
+	EndPos  Pos        `json:"end_pos"`           // position of the closing brace
+	Group   *TypeGroup `json:"-"`
+}
+
+// Position returns the position of the type name
+func (d *TypeDecl) Position() Pos { return d.Pos }
+
+// Field returns the field with the given name, or nil
+func (d *TypeDecl) Field(name string) *Field {
+	for _, f := range d.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Field is a struct field; Name is empty for embedded fields
+type Field struct {
+	Pos     Pos       `json:"pos"`
+	Name    string    `json:"name,omitempty"`
+	Type    *TypeExpr `json:"type"`
+	Tag     string    `json:"tag,omitempty"` // tag without the back quotes
+	Doc     []string  `json:"doc,omitempty"`
+	Comment string    `json:"comment,omitempty"`
+}
+
+// Embedded reports whether the field is an embedded type
+func (f *Field) Embedded() bool {
+	return f.Name == ""
+}
+
+// TagValue returns the value of a tag key such as json, form, path or header
+func (f *Field) TagValue(key string) (string, bool) {
+	return LookupTag(f.Tag, key)
+}
+
+// TypeKind classifies a TypeExpr
+type TypeKind string
+
+const (
+	KindIdent     TypeKind = "ident"
+	KindPointer   TypeKind = "pointer"
+	KindArray     TypeKind = "array"
+	KindMap       TypeKind = "map"
+	KindStruct    TypeKind = "struct"
+	KindInterface TypeKind = "interface"
+)
+
+// TypeExpr is a type expression such as string, *User, []int64, map[string]Item or an inline struct
+type TypeExpr struct {
+	Pos    Pos       `json:"pos"`
+	Kind   TypeKind  `json:"kind"`
+	Name   string    `json:"name,omitempty"`   // ident name
+	Len    string    `json:"len,omitempty"`    // fixed array length
+	Key    *TypeExpr `json:"key,omitempty"`    // map key
+	Elem   *TypeExpr `json:"elem,omitempty"`   // pointer, array and map element
+	Fields []*Field  `json:"fields,omitempty"` // inline struct fields
+	EndDoc []string  `json:"end_doc,omitempty"`
+}
+
+// String returns the Go-like source form of the type; inline structs are abbreviated
+func (t *TypeExpr) String() string {
+	if t == nil {
+		return ""
+	}
+	switch t.Kind {
+	case KindPointer:
+		return "*" + t.Elem.String()
+	case KindArray:
+		return "[" + t.Len + "]" + t.Elem.String()
+	case KindMap:
+		return "map[" + t.Key.String() + "]" + t.Elem.String()
+	case KindStruct:
+		return "struct{...}"
+	case KindInterface:
+		return "interface{}"
+	default:
+		return t.Name
+	}
+}
+
+// BaseName returns the innermost named type, stripping pointers, arrays and maps
+func (t *TypeExpr) BaseName() string {
+	for t != nil {
+		switch t.Kind {
+		case KindPointer, KindArray, KindMap:
+			t = t.Elem
+		case KindIdent:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// Annotation is an @name "value" or @name(key: value ...) annotation
+type Annotation struct {
+	Pos        Pos         `json:"pos"`
+	Name       string      `json:"name"`
+	Value      string      `json:"value,omitempty"`
+	Properties []*Property `json:"properties,omitempty"`
+	Doc        []string    `json:"doc,omitempty"`
+	Comment    string      `json:"comment,omitempty"`
+}
+
+// Get returns the value of an annotation property, or empty string
+func (a *Annotation) Get(key string) string {
+	if a == nil {
+		return ""
+	}
+	return getProperty(a.Properties, key)
+}
+
+// ServiceDecl is one service block, optionally preceded by @server(...)
+// A service may be split into several blocks, each forming a route group
+type ServiceDecl struct {
+	Pos     Pos         `json:"pos"`
+	Name    string      `json:"name"`
+	Server  *Annotation `json:"server,omitempty"`
+	Routes  []*Route    `json:"routes,omitempty"`
+	Doc     []string    `json:"doc,omitempty"`
+	Comment string      `json:"comment,omitempty"`
+	EndDoc  []string    `json:"end_doc,omitempty"`
+	EndPos  Pos         `json:"end_pos"`
+}
+
+// Position returns the position of the service keyword
+func (d *ServiceDecl) Position() Pos {
+	if d.Server != nil {
+		return d.Server.Pos
+	}
+	return d.Pos
+}
+
+// Group returns the @server group attribute
+func (d *ServiceDecl) Group() string { return d.Server.Get("group") }
+
+// Prefix returns the @server prefix attribute
+func (d *ServiceDecl) Prefix() string { return d.Server.Get("prefix") }
+
+// JWT returns the @server jwt attribute
+func (d *ServiceDecl) JWT() string { return d.Server.Get("jwt") }
+
+// Timeout returns the @server timeout attribute
+func (d *ServiceDecl) Timeout() string { return d.Server.Get("timeout") }
+
+// Middleware returns the @server middleware list
+func (d *ServiceDecl) Middleware() []string {
+	return splitList(d.Server.Get("middleware"))
+}
+
+// Route is a single endpoint inside a service block
+type Route struct {
+	Pos          Pos         `json:"pos"`
+	Method       string      `json:"method"`
+	Path         string      `json:"path"`
+	Handler      string      `json:"handler,omitempty"`
+	Request      *TypeExpr   `json:"request,omitempty"`
+	Response     *TypeExpr   `json:"response,omitempty"`
+	AtDoc        *Annotation `json:"at_doc,omitempty"`    // @doc annotation
+	AtServer     *Annotation `json:"at_server,omitempty"` // legacy per-route @server(handler: ...)
+	AtHandlerPos Pos         `json:"-"`
+	Doc          []string    `json:"doc,omitempty"`
+	Comment      string      `json:"comment,omitempty"`
+
+	HandlerComment string `json:"handler_comment,omitempty"` // comment after @handler
+}
+
+// Summary returns the route description from @doc
+func (r *Route) Summary() string {
+	if r.AtDoc == nil {
+		return ""
+	}
+	if r.AtDoc.Value != "" {
+		return r.AtDoc.Value
+	}
+	return r.AtDoc.Get("summary")
+}
+
+// PathParams returns the :name path parameters of the route
+func (r *Route) PathParams() []string {
+	var params []string
+	for _, seg := range strings.Split(r.Path, "/") {
+		if strings.HasPrefix(seg, ":") && len(seg) > 1 {
+			params = append(params, seg[1:])
+		}
+	}
+	return params
+}
+
+// Spec is a root .api file together with every file it imports
+type Spec struct {
+	Root  *File   `json:"root"`
+	Files []*File `json:"files"` // root first, then imports in resolution order
+}
+
+// Types returns the types declared across all files
+func (s *Spec) Types() []*TypeDecl {
+	var types []*TypeDecl
+	for _, f := range s.Files {
+		types = append(types, f.Types...)
+	}
+	return types
+}
+
+// Type returns the type with the given name across all files, or nil
+func (s *Spec) Type(name string) *TypeDecl {
+	for _, t := range s.Types() {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// Services returns the service blocks declared across all files
+func (s *Spec) Services() []*ServiceDecl {
+	var services []*ServiceDecl
+	for _, f := range s.Files {
+		services = append(services, f.Services...)
+	}
+	return services
+}
+
+// ServiceName returns the name shared by the service blocks
+func (s *Spec) ServiceName() string {
+	for _, svc := range s.Services() {
+		return svc.Name
+	}
+	return ""
+}
+
+// FileOf returns the file declaring the given service block, or nil
+func (s *Spec) FileOf(svc *ServiceDecl) *File {
+	for _, f := range s.Files {
+		for _, candidate := range f.Services {
+			if candidate == svc {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// LookupTag returns the value of key in a struct tag such as `json:"name,optional" path:"id"`
+func LookupTag(tag, key string) (string, bool) {
+	tag = strings.Trim(tag, "`")
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " \t")
+		colon := strings.Index(tag, ":\"")
+		if colon <= 0 {
+			return "", false
+		}
+		name := tag[:colon]
+		rest := tag[colon+2:]
+		end := strings.Index(rest, "\"")
+		for end > 0 && rest[end-1] == '\\' {
+			next := strings.Index(rest[end+1:], "\"")
+			if next < 0 {
+				end = -1
+				break
+			}
+			end += next + 1
+		}
+		if end < 0 {
+			return "", false
+		}
+		if name == key {
+			return rest[:end], true
+		}
+		tag = rest[end+1:]
+	}
+	return "", false
+}
+
+func getProperty(props []*Property, key string) string {
+	for _, p := range props {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// CommentText returns the text of comments with the // and /* */ markers removed
+func CommentText(comments ...string) string {
+	var lines []string
+	for _, c := range comments {
+		switch {
+		case strings.HasPrefix(c, "//"):
+			lines = append(lines, strings.TrimSpace(strings.TrimPrefix(c, "//")))
+		case strings.HasPrefix(c, "/*"):
+			body := strings.TrimSuffix(strings.TrimPrefix(c, "/*"), "*/")
+			for _, line := range strings.Split(body, "\n") {
+				line = strings.TrimSpace(line)
+				line = strings.TrimSpace(strings.TrimPrefix(line, "*"))
+				if line != "" {
+					lines = append(lines, line)
+				}
+			}
+		case strings.TrimSpace(c) != "":
+			lines = append(lines, strings.TrimSpace(c))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,412 @@
+package apispec
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NewRoute describes a route to add to a service block
+type NewRoute struct {
+	Method   string
+	Path     string
+	Handler  string
+	Doc      string
+	Request  string
+	Response string
+}
+
+// NewType describes a struct type to add alongside a route
+type NewType struct {
+	Name   string
+	Fields []NewField
+}
+
+// NewField is a field of a NewType; Tag is written without back quotes
+type NewField struct {
+	Name string
+	Type string
+	Tag  string
+}
+
+// Edit is the result of an in-place change to one file of a spec
+type Edit struct {
+	Path     string // file that was changed
+	Source   []byte // complete new content of the file
+	NewBlock bool   // a new @server/service block was appended
+}
+
+// insertion is text to insert at a byte offset
+type insertion struct {
+	offset int
+	text   string
+}
+
+// AddRoute inserts route into the service block whose @server group and prefix match,
+// appending a new block when none does, and adds types to the same file
+// Only the inserted lines change; the rest of the file is kept byte for byte
+func AddRoute(spec *Spec, group, prefix string, route NewRoute, types []NewType) (*Edit, error) {
+	route.Method = strings.ToLower(route.Method)
+	if !httpMethods[route.Method] {
+		return nil, fmt.Errorf("unsupported HTTP method %q", route.Method)
+	}
+	if !strings.HasPrefix(route.Path, "/") {
+		return nil, fmt.Errorf("route path %q must start with /", route.Path)
+	}
+	if route.Handler == "" {
+		return nil, fmt.Errorf("route %s %s has no handler", route.Method, route.Path)
+	}
+	prefix = normalizePrefix(prefix)
+
+	if err := checkRouteConflicts(spec, group, prefix, route); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, t := range spec.Types() {
+		known[t.Name] = true
+	}
+	for _, t := range types {
+		if known[t.Name] {
+			return nil, fmt.Errorf("type %s already exists", t.Name)
+		}
+		known[t.Name] = true
+	}
+	for _, name := range []string{route.Request, route.Response} {
+		base := strings.TrimLeft(name, "*[]")
+		if base != "" && !known[base] && !isBuiltinType(base) {
+			return nil, fmt.Errorf("undefined type %s", base)
+		}
+	}
+
+	svc, err := findServiceBlock(spec, group, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	file := spec.Root
+	if svc != nil {
+		file = spec.FileOf(svc)
+	} else if services := spec.Services(); len(services) > 0 {
+		file = spec.FileOf(services[0])
+	}
+
+	src, err := os.ReadFile(file.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API file: %w", err)
+	}
+	lines := lineStarts(src)
+
+	var inserts []insertion
+	edit := &Edit{Path: file.Path}
+
+	if svc != nil {
+		inserts = append(inserts, routeInsertion(src, lines, svc, route))
+	} else {
+		edit.NewBlock = true
+		inserts = append(inserts, blockInsertion(src, spec.ServiceName(), group, prefix, route))
+	}
+	if len(types) > 0 {
+		inserts = append(inserts, typesInsertion(src, lines, file, types))
+	}
+
+	// Apply from the end so earlier offsets stay valid
+	sort.SliceStable(inserts, func(i, j int) bool { return inserts[i].offset > inserts[j].offset })
+	out := string(src)
+	for _, ins := range inserts {
+		out = out[:ins.offset] + ins.text + out[ins.offset:]
+	}
+	edit.Source = []byte(out)
+
+	if _, err := Parse(file.Path, edit.Source); err != nil {
+		return nil, fmt.Errorf("edited spec is invalid: %w", err)
+	}
+	return edit, nil
+}
+
+// checkRouteConflicts rejects duplicate method+path pairs and duplicate handlers in a group
+func checkRouteConflicts(spec *Spec, group, prefix string, route NewRoute) error {
+	fullPath := joinPath(prefix, route.Path)
+	for _, svc := range spec.Services() {
+		for _, r := range svc.Routes {
+			if r.Method == route.Method && joinPath(normalizePrefix(svc.Prefix()), r.Path) == fullPath {
+				return fmt.Errorf("route %s %s already exists (handler %s)", strings.ToUpper(route.Method), fullPath, r.Handler)
+			}
+			if r.Handler == route.Handler && svc.Group() == group {
+				return fmt.Errorf("handler %s already exists in group %q", route.Handler, group)
+			}
+		}
+	}
+	return nil
+}
+
+// findServiceBlock returns the service block for group and prefix, or nil if a new block is needed
+func findServiceBlock(spec *Spec, group, prefix string) (*ServiceDecl, error) {
+	services := spec.Services()
+	if group == "" && prefix == "" {
+		if len(services) == 1 {
+			return services[0], nil
+		}
+		for _, svc := range services {
+			if svc.Group() == "" && svc.Prefix() == "" {
+				return svc, nil
+			}
+		}
+		if len(services) > 1 {
+			return nil, fmt.Errorf("spec has %d service blocks; specify a group or prefix", len(services))
+		}
+		return nil, nil
+	}
+
+	for _, svc := range services {
+		if group != "" && svc.Group() != group {
+			continue
+		}
+		if prefix != "" && normalizePrefix(svc.Prefix()) != prefix {
+			continue
+		}
+		return svc, nil
+	}
+	return nil, nil
+}
+
+func routeInsertion(src []byte, lines []int, svc *ServiceDecl, route NewRoute) insertion {
+	indent := "\t"
+	if len(svc.Routes) > 0 {
+		indent = leadingSpace(src, lines, routeStartLine(svc.Routes[0]))
+	}
+
+	var b strings.Builder
+	if routesSeparated(svc) {
+		b.WriteString("\n")
+	}
+	writeRoute(&b, indent, route)
+
+	end := offsetOf(src, lines, svc.EndPos)
+	lineStart := lines[svc.EndPos.Line-1]
+	if strings.TrimSpace(string(src[lineStart:end])) == "" {
+		return insertion{offset: lineStart, text: b.String()}
+	}
+	// The closing brace shares its line with other code
+	return insertion{offset: end, text: "\n" + b.String()}
+}
+
+func blockInsertion(src []byte, serviceName, group, prefix string, route NewRoute) insertion {
+	var b strings.Builder
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	var props [][2]string
+	if group != "" {
+		props = append(props, [2]string{"group", group})
+	}
+	if prefix != "" {
+		props = append(props, [2]string{"prefix", prefix})
+	}
+	if len(props) > 0 {
+		width := 0
+		for _, p := range props {
+			if len(p[0]) > width {
+				width = len(p[0])
+			}
+		}
+		b.WriteString("@server (\n")
+		for _, p := range props {
+			fmt.Fprintf(&b, "\t%s:%s %s\n", p[0], strings.Repeat(" ", width-len(p[0])), p[1])
+		}
+		b.WriteString(")\n")
+	}
+	fmt.Fprintf(&b, "service %s {\n", serviceName)
+	writeRoute(&b, "\t", route)
+	b.WriteString("}\n")
+
+	return insertion{offset: len(src), text: b.String()}
+}
+
+func typesInsertion(src []byte, lines []int, file *File, types []NewType) insertion {
+	if len(file.Types) > 0 {
+		last := file.Types[len(file.Types)-1]
+		if g := last.Group; g != nil {
+			// Extend the type ( ... ) block in place
+			indent := leadingSpace(src, lines, last.Pos.Line)
+			var b strings.Builder
+			for _, t := range types {
+				b.WriteString("\n")
+				writeType(&b, indent, t)
+			}
+			end := offsetOf(src, lines, g.EndPos)
+			lineStart := lines[g.EndPos.Line-1]
+			if strings.TrimSpace(string(src[lineStart:end])) == "" {
+				return insertion{offset: lineStart, text: b.String()}
+			}
+			return insertion{offset: end, text: b.String()}
+		}
+
+		var b strings.Builder
+		for _, t := range types {
+			b.WriteString("\n")
+			b.WriteString("type ")
+			writeType(&b, "", t)
+		}
+		return insertion{offset: lineEnd(src, lines, last.EndPos.Line), text: b.String()}
+	}
+
+	// No types yet: put them before the first service block
+	var b strings.Builder
+	for _, t := range types {
+		b.WriteString("type ")
+		writeType(&b, "", t)
+		b.WriteString("\n")
+	}
+	if len(file.Services) == 0 {
+		text := b.String()
+		if len(src) > 0 && src[len(src)-1] != '\n' {
+			text = "\n" + text
+		}
+		return insertion{offset: len(src), text: "\n" + text}
+	}
+	svc := file.Services[0]
+	line := svc.Position().Line
+	for _, doc := range docOf(svc) {
+		line -= strings.Count(doc, "\n") + 1
+	}
+	return insertion{offset: lines[line-1], text: b.String()}
+}
+
+func writeRoute(b *strings.Builder, indent string, route NewRoute) {
+	if route.Doc != "" {
+		fmt.Fprintf(b, "%s@doc %s\n", indent, strconv.Quote(route.Doc))
+	}
+	fmt.Fprintf(b, "%s@handler %s\n", indent, route.Handler)
+	fmt.Fprintf(b, "%s%s %s", indent, route.Method, route.Path)
+	if route.Request != "" {
+		fmt.Fprintf(b, " (%s)", route.Request)
+	}
+	if route.Response != "" {
+		fmt.Fprintf(b, " returns (%s)", route.Response)
+	}
+	b.WriteString("\n")
+}
+
+// writeType writes "Name {...}" with aligned fields; the caller writes any type keyword
+func writeType(b *strings.Builder, indent string, t NewType) {
+	fmt.Fprintf(b, "%s%s {\n", indent, t.Name)
+	nameWidth, typeWidth := 0, 0
+	for _, f := range t.Fields {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(f.Name))
+		typeWidth = max(typeWidth, utf8.RuneCountInString(f.Type))
+	}
+	for _, f := range t.Fields {
+		line := indent + "\t" + padRight(f.Name, nameWidth) + " "
+		if f.Tag != "" {
+			line += padRight(f.Type, typeWidth) + " `" + f.Tag + "`"
+		} else {
+			line += f.Type
+		}
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+}
+
+// routesSeparated reports whether the block puts blank lines between routes
+func routesSeparated(svc *ServiceDecl) bool {
+	for i := 1; i < len(svc.Routes); i++ {
+		if routeStartLine(svc.Routes[i]) > svc.Routes[i-1].Pos.Line+1 {
+			return true
+		}
+	}
+	return false
+}
+
+// routeStartLine returns the first line of a route, including its annotations
+func routeStartLine(r *Route) int {
+	line := r.Pos.Line
+	for _, a := range []*Annotation{r.AtDoc, r.AtServer} {
+		if a != nil && a.Pos.Line < line {
+			line = a.Pos.Line
+		}
+	}
+	if r.AtHandlerPos.Line > 0 && r.AtHandlerPos.Line < line {
+		line = r.AtHandlerPos.Line
+	}
+	return line
+}
+
+func docOf(svc *ServiceDecl) []string {
+	if svc.Server != nil {
+		return svc.Server.Doc
+	}
+	return svc.Doc
+}
+
+// lineStarts returns the byte offset at which each line begins
+func lineStarts(src []byte) []int {
+	starts := []int{0}
+	for i, c := range src {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// offsetOf converts a position to a byte offset
+func offsetOf(src []byte, lines []int, pos Pos) int {
+	off := lines[pos.Line-1]
+	for col := 1; col < pos.Column && off < len(src); col++ {
+		_, size := utf8.DecodeRune(src[off:])
+		off += size
+	}
+	return off
+}
+
+// lineEnd returns the offset just past the newline ending line
+func lineEnd(src []byte, lines []int, line int) int {
+	if line < len(lines) {
+		return lines[line]
+	}
+	return len(src)
+}
+
+func leadingSpace(src []byte, lines []int, line int) string {
+	start := lines[line-1]
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return string(src[start:end])
+}
+
+func normalizePrefix(prefix string) string {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" || prefix == "/" {
+		return ""
+	}
+	return "/" + strings.Trim(prefix, "/")
+}
+
+func joinPath(prefix, routePath string) string {
+	if prefix == "" {
+		return routePath
+	}
+	return prefix + routePath
+}
+
+// isBuiltinType reports whether name is a Go builtin usable as a request or response
+func isBuiltinType(name string) bool {
+	switch name {
+	case "string", "bool", "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64", "byte", "rune":
+		return true
+	}
+	return false
+}
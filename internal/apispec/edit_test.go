@@ -0,0 +1,191 @@
+package apispec_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+)
+
+const editBase = `syntax = "v1"
+
+// User is returned by every endpoint
+type User {
+	Id   int64  ` + "`json:\"id\"`" + `
+	Name string ` + "`json:\"name\"`" + ` // display name
+}
+
+@server (
+	group:  user
+	prefix: /api/v1
+)
+service user-api {
+	@handler GetUser
+	get /users/:id returns (User)
+
+	// keep this comment
+	@handler ListUsers
+	get /users returns ([]User)
+}
+
+@server (
+	group: admin
+)
+service user-api {
+    @handler Ban
+    post /ban
+}
+`
+
+func loadEditSpec(t *testing.T, content string) *apispec.Spec {
+	t.Helper()
+	apiFile := filepath.Join(t.TempDir(), "user.api")
+	if err := os.WriteFile(apiFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := apispec.Load(apiFile, nil)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	return spec
+}
+
+func TestAddRouteToMatchingBlock(t *testing.T) {
+	spec := loadEditSpec(t, editBase)
+
+	edit, err := apispec.AddRoute(spec, "user", "/api/v1", apispec.NewRoute{
+		Method:   "POST",
+		Path:     "/users",
+		Handler:  "CreateUser",
+		Doc:      "Create a user",
+		Request:  "CreateUserReq",
+		Response: "User",
+	}, []apispec.NewType{{
+		Name: "CreateUserReq",
+		Fields: []apispec.NewField{
+			{Name: "Name", Type: "string", Tag: `json:"name"`},
+			{Name: "Tags", Type: "[]string", Tag: `json:"tags,optional"`},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("AddRoute() failed: %v", err)
+	}
+	if edit.NewBlock {
+		t.Error("Expected the route to go into the existing user block")
+	}
+
+	want := strings.Replace(editBase, "\tName string `json:\"name\"` // display name\n}\n",
+		"\tName string `json:\"name\"` // display name\n}\n\ntype CreateUserReq {\n\tName string   `json:\"name\"`\n\tTags []string `json:\"tags,optional\"`\n}\n", 1)
+	want = strings.Replace(want, "\tget /users returns ([]User)\n}\n",
+		"\tget /users returns ([]User)\n\n\t@doc \"Create a user\"\n\t@handler CreateUser\n\tpost /users (CreateUserReq) returns (User)\n}\n", 1)
+
+	if string(edit.Source) != want {
+		t.Errorf("Unexpected edited source:\n%s\nwant:\n%s", edit.Source, want)
+	}
+}
+
+func TestAddRouteKeepsBlockIndentation(t *testing.T) {
+	spec := loadEditSpec(t, editBase)
+
+	edit, err := apispec.AddRoute(spec, "admin", "", apispec.NewRoute{Method: "post", Path: "/unban", Handler: "Unban"}, nil)
+	if err != nil {
+		t.Fatalf("AddRoute() failed: %v", err)
+	}
+	if !strings.HasSuffix(string(edit.Source), "    @handler Ban\n    post /ban\n    @handler Unban\n    post /unban\n}\n") {
+		t.Errorf("Expected route with four-space indentation, got:\n%s", edit.Source)
+	}
+}
+
+func TestAddRouteCreatesBlock(t *testing.T) {
+	spec := loadEditSpec(t, editBase)
+
+	edit, err := apispec.AddRoute(spec, "order", "/api/v2", apispec.NewRoute{Method: "get", Path: "/orders", Handler: "ListOrders"}, nil)
+	if err != nil {
+		t.Fatalf("AddRoute() failed: %v", err)
+	}
+	if !edit.NewBlock {
+		t.Error("Expected a new service block")
+	}
+	wantTail := "\n@server (\n\tgroup:  order\n\tprefix: /api/v2\n)\nservice user-api {\n\t@handler ListOrders\n\tget /orders\n}\n"
+	if !strings.HasPrefix(string(edit.Source), editBase) || !strings.HasSuffix(string(edit.Source), wantTail) {
+		t.Errorf("Unexpected edited source:\n%s", edit.Source)
+	}
+}
+
+func TestAddRouteTypesBeforeFirstService(t *testing.T) {
+	content := "syntax = \"v1\"\n\n// the service\nservice ping-api {\n\t@handler Ping\n\tget /ping\n}\n"
+	spec := loadEditSpec(t, content)
+
+	edit, err := apispec.AddRoute(spec, "", "", apispec.NewRoute{Method: "get", Path: "/echo", Handler: "Echo", Response: "EchoResp"},
+		[]apispec.NewType{{Name: "EchoResp", Fields: []apispec.NewField{{Name: "Msg", Type: "string", Tag: `json:"msg"`}}}})
+	if err != nil {
+		t.Fatalf("AddRoute() failed: %v", err)
+	}
+
+	want := "syntax = \"v1\"\n\ntype EchoResp {\n\tMsg string `json:\"msg\"`\n}\n\n// the service\nservice ping-api {\n\t@handler Ping\n\tget /ping\n\t@handler Echo\n\tget /echo returns (EchoResp)\n}\n"
+	if string(edit.Source) != want {
+		t.Errorf("Unexpected edited source:\n%s\nwant:\n%s", edit.Source, want)
+	}
+}
+
+func TestAddRouteErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   string
+		prefix  string
+		route   apispec.NewRoute
+		types   []apispec.NewType
+		wantErr string
+	}{
+		{
+			name:    "duplicate path",
+			group:   "user",
+			prefix:  "api/v1/",
+			route:   apispec.NewRoute{Method: "get", Path: "/users", Handler: "Other"},
+			wantErr: "route GET /api/v1/users already exists (handler ListUsers)",
+		},
+		{
+			name:    "duplicate handler",
+			group:   "user",
+			prefix:  "/api/v1",
+			route:   apispec.NewRoute{Method: "delete", Path: "/users/:id", Handler: "GetUser"},
+			wantErr: "handler GetUser already exists in group \"user\"",
+		},
+		{
+			name:    "existing type",
+			group:   "user",
+			route:   apispec.NewRoute{Method: "put", Path: "/users", Handler: "Put"},
+			types:   []apispec.NewType{{Name: "User"}},
+			wantErr: "type User already exists",
+		},
+		{
+			name:    "undefined type",
+			group:   "user",
+			route:   apispec.NewRoute{Method: "put", Path: "/users", Handler: "Put", Request: "Missing"},
+			wantErr: "undefined type Missing",
+		},
+		{
+			name:    "ambiguous block",
+			route:   apispec.NewRoute{Method: "put", Path: "/x", Handler: "X"},
+			wantErr: "spec has 2 service blocks; specify a group or prefix",
+		},
+		{
+			name:    "bad method",
+			group:   "user",
+			route:   apispec.NewRoute{Method: "fetch", Path: "/x", Handler: "X"},
+			wantErr: "unsupported HTTP method \"fetch\"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := loadEditSpec(t, editBase)
+			_, err := apispec.AddRoute(spec, tt.group, tt.prefix, tt.route, tt.types)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("AddRoute() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package apispec
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Format prints a parsed file as canonical .api source
+// syntax, info and imports come first and imports are merged into one block;
+// struct fields, tags and property values are aligned, routes are separated by
+// blank lines, the optional struct keyword is dropped and comments are kept
+func Format(file *File) []byte {
+	p := &printer{}
+
+	var sections []func()
+	if file.Syntax != "" || len(file.Doc) > 0 {
+		sections = append(sections, func() {
+			p.comments(0, file.Doc)
+			if file.Syntax != "" {
+				p.line(0, withComment(`syntax = "`+file.Syntax+`"`, file.SyntaxComment))
+			}
+		})
+	}
+	if file.Info != nil {
+		sections = append(sections, func() { p.info(file.Info) })
+	}
+	if len(file.Imports) > 0 {
+		sections = append(sections, func() { p.imports(file.Imports) })
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *TypeGroup:
+			sections = append(sections, func() { p.typeGroup(d) })
+		case *TypeDecl:
+			sections = append(sections, func() { p.typeDecl(0, d, true) })
+		case *ServiceDecl:
+			sections = append(sections, func() { p.service(d) })
+		}
+	}
+	if len(file.Trailing) > 0 {
+		sections = append(sections, func() { p.comments(0, file.Trailing) })
+	}
+
+	for i, section := range sections {
+		if i > 0 {
+			p.b.WriteString("\n")
+		}
+		section()
+	}
+	return []byte(p.b.String())
+}
+
+// FormatSource parses and formats .api source
+func FormatSource(filename string, src []byte) ([]byte, error) {
+	file, err := Parse(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	return Format(file), nil
+}
+
+// printer accumulates formatted source
+type printer struct {
+	b strings.Builder
+}
+
+// line writes text indented by tabs; empty text writes a blank line
+func (p *printer) line(indent int, text string) {
+	if text != "" {
+		p.b.WriteString(strings.Repeat("\t", indent))
+		p.b.WriteString(strings.TrimRight(text, " \t"))
+	}
+	p.b.WriteString("\n")
+}
+
+func (p *printer) comments(indent int, comments []string) {
+	for _, c := range comments {
+		p.line(indent, c)
+	}
+}
+
+func (p *printer) info(info *InfoDecl) {
+	p.comments(0, info.Doc)
+	p.line(0, "info (")
+	p.properties(1, info.Properties)
+	p.line(0, ")")
+}
+
+// properties writes "key: value" lines with the values aligned
+func (p *printer) properties(indent int, props []*Property) {
+	width := 0
+	for _, prop := range props {
+		width = max(width, utf8.RuneCountInString(prop.Key)+1)
+	}
+	for _, prop := range props {
+		p.comments(indent, prop.Doc)
+		value := prop.Value
+		if prop.Quoted {
+			value = quote(value)
+		}
+		p.line(indent, withComment(padRight(prop.Key+":", width)+" "+value, prop.Comment))
+	}
+}
+
+func (p *printer) annotation(indent int, ann *Annotation) {
+	p.comments(indent, ann.Doc)
+	if ann.Properties != nil {
+		p.line(indent, "@"+ann.Name+" (")
+		p.properties(indent+1, ann.Properties)
+		p.line(indent, withComment(")", ann.Comment))
+		return
+	}
+	value := ann.Value
+	if ann.Name == "doc" {
+		value = quote(value)
+	}
+	p.line(indent, withComment("@"+ann.Name+" "+value, ann.Comment))
+}
+
+func (p *printer) imports(imports []*ImportDecl) {
+	if len(imports) == 1 {
+		imp := imports[0]
+		p.comments(0, imp.Doc)
+		p.line(0, withComment("import "+quote(imp.Path), imp.Comment))
+		return
+	}
+	p.line(0, "import (")
+	for _, imp := range imports {
+		p.comments(1, imp.Doc)
+		p.line(1, withComment(quote(imp.Path), imp.Comment))
+	}
+	p.line(0, ")")
+}
+
+func (p *printer) typeGroup(g *TypeGroup) {
+	p.comments(0, g.Doc)
+	p.line(0, "type (")
+	for i, t := range g.Types {
+		if i > 0 && startLine(t.Pos.Line, t.Doc) > g.Types[i-1].EndPos.Line+1 {
+			p.line(0, "")
+		}
+		p.typeDecl(1, t, false)
+	}
+	p.line(0, ")")
+}
+
+func (p *printer) typeDecl(indent int, t *TypeDecl, keyword bool) {
+	p.comments(indent, t.Doc)
+	head := t.Name
+	if keyword {
+		head = "type " + head
+	}
+	if len(t.Fields) == 0 && len(t.EndDoc) == 0 {
+		p.line(indent, withComment(head+" {}", t.Comment))
+		return
+	}
+	p.line(indent, head+" {")
+	p.fields(indent+1, t.Fields, t.EndDoc)
+	p.line(indent, withComment("}", t.Comment))
+}
+
+// fields writes struct fields in sections aligned like gofmt
+// A blank line, an embedded field or a multi-line inline struct ends a section
+func (p *printer) fields(indent int, fields []*Field, endDoc []string) {
+	var section []*Field
+	flush := func() {
+		p.section(indent, section)
+		section = nil
+	}
+
+	for i, f := range fields {
+		if i > 0 && startLine(f.Pos.Line, f.Doc) > fieldEndLine(fields[i-1])+1 {
+			flush()
+			p.line(0, "")
+		}
+		if f.Embedded() {
+			flush()
+			p.comments(indent, f.Doc)
+			text := typeString(f.Type)
+			if f.Tag != "" {
+				text += " `" + f.Tag + "`"
+			}
+			p.line(indent, withComment(text, f.Comment))
+			continue
+		}
+		section = append(section, f)
+		if inlineStruct(f.Type) != nil {
+			flush()
+		}
+	}
+	flush()
+	p.comments(indent, endDoc)
+}
+
+func (p *printer) section(indent int, fields []*Field) {
+	nameWidth, typeWidth, tagWidth := 0, 0, 0
+	for _, f := range fields {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(f.Name))
+		if inlineStruct(f.Type) != nil {
+			continue
+		}
+		if f.Tag != "" || f.Comment != "" {
+			typeWidth = max(typeWidth, utf8.RuneCountInString(typeString(f.Type)))
+		}
+		if f.Tag != "" && f.Comment != "" {
+			tagWidth = max(tagWidth, utf8.RuneCountInString(f.Tag)+2)
+		}
+	}
+
+	for _, f := range fields {
+		p.comments(indent, f.Doc)
+		if st := inlineStruct(f.Type); st != nil {
+			p.line(indent, padRight(f.Name, nameWidth)+" "+typePrefix(f.Type)+"struct {")
+			p.fields(indent+1, st.Fields, st.EndDoc)
+			closing := "}"
+			if f.Tag != "" {
+				closing += " `" + f.Tag + "`"
+			}
+			p.line(indent, withComment(closing, f.Comment))
+			continue
+		}
+
+		text := padRight(f.Name, nameWidth) + " "
+		typ := typeString(f.Type)
+		switch {
+		case f.Tag != "" && f.Comment != "":
+			text += padRight(typ, typeWidth) + " " + padRight("`"+f.Tag+"`", tagWidth) + " " + f.Comment
+		case f.Tag != "":
+			text += padRight(typ, typeWidth) + " `" + f.Tag + "`"
+		case f.Comment != "":
+			text += padRight(typ, typeWidth) + " " + f.Comment
+		default:
+			text += typ
+		}
+		p.line(indent, text)
+	}
+}
+
+func (p *printer) service(svc *ServiceDecl) {
+	if svc.Server != nil {
+		p.annotation(0, svc.Server)
+	}
+	p.comments(0, svc.Doc)
+	p.line(0, withComment("service "+svc.Name+" {", svc.Comment))
+	for i, r := range svc.Routes {
+		if i > 0 {
+			p.line(0, "")
+		}
+		p.route(1, r)
+	}
+	p.comments(1, svc.EndDoc)
+	p.line(0, "}")
+}
+
+func (p *printer) route(indent int, r *Route) {
+	if r.AtDoc != nil {
+		p.annotation(indent, r.AtDoc)
+	}
+	if r.AtServer != nil {
+		p.annotation(indent, r.AtServer)
+	}
+	p.comments(indent, r.Doc)
+	if r.AtServer == nil || r.AtServer.Get("handler") == "" {
+		p.line(indent, withComment("@handler "+r.Handler, r.HandlerComment))
+	}
+
+	text := r.Method + " " + r.Path
+	if r.Request != nil {
+		text += " (" + typeString(r.Request) + ")"
+	}
+	if r.Response != nil {
+		text += " returns (" + typeString(r.Response) + ")"
+	}
+	p.line(indent, withComment(text, r.Comment))
+}
+
+// typeString returns the single-line source of a type; inline structs must be empty
+func typeString(t *TypeExpr) string {
+	switch t.Kind {
+	case KindStruct:
+		return "struct{}"
+	case KindPointer, KindArray, KindMap:
+		return typePrefix(t) + typeString(innermost(t))
+	}
+	return t.String()
+}
+
+// typePrefix returns the pointer, array and map prefix in front of the innermost type
+func typePrefix(t *TypeExpr) string {
+	var b strings.Builder
+	for ; t != nil; t = t.Elem {
+		switch t.Kind {
+		case KindPointer:
+			b.WriteString("*")
+		case KindArray:
+			b.WriteString("[" + t.Len + "]")
+		case KindMap:
+			b.WriteString("map[" + typeString(t.Key) + "]")
+		default:
+			return b.String()
+		}
+	}
+	return b.String()
+}
+
+func innermost(t *TypeExpr) *TypeExpr {
+	for t.Elem != nil && (t.Kind == KindPointer || t.Kind == KindArray || t.Kind == KindMap) {
+		t = t.Elem
+	}
+	return t
+}
+
+// inlineStruct returns the non-empty inline struct a type ends in, or nil
+func inlineStruct(t *TypeExpr) *TypeExpr {
+	t = innermost(t)
+	if t.Kind == KindStruct && (len(t.Fields) > 0 || len(t.EndDoc) > 0) {
+		return t
+	}
+	return nil
+}
+
+// fieldEndLine estimates the last source line of a field, including inline structs
+func fieldEndLine(f *Field) int {
+	st := inlineStruct(f.Type)
+	if st == nil {
+		return f.Pos.Line
+	}
+	end := f.Pos.Line
+	for _, inner := range st.Fields {
+		end = max(end, fieldEndLine(inner))
+	}
+	return end + commentLines(st.EndDoc) + 1
+}
+
+// startLine returns the first line of a node whose doc comments precede line
+func startLine(line int, doc []string) int {
+	return line - commentLines(doc)
+}
+
+func commentLines(comments []string) int {
+	n := 0
+	for _, c := range comments {
+		n += 1 + strings.Count(c, "\n")
+	}
+	return n
+}
+
+func withComment(text, comment string) string {
+	if comment == "" {
+		return text
+	}
+	return text + " " + comment
+}
+
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,118 @@
+package apispec_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+)
+
+func TestFormatGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "format", "*.api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no testdata/format .api files found")
+	}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := apispec.FormatSource(name, src)
+			if err != nil {
+				t.Fatalf("FormatSource(%s) failed: %v", name, err)
+			}
+
+			golden := strings.TrimSuffix(file, ".api") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create): %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("formatted %s does not match %s (run with -update to refresh):\n%s", name, golden, got)
+			}
+		})
+	}
+}
+
+// TestFormatPreservesSpec formats every parser fixture and checks the result
+// is stable and describes the same declarations
+func TestFormatPreservesSpec(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatFiles, _ := filepath.Glob(filepath.Join("testdata", "format", "*.api"))
+	files = append(files, formatFiles...)
+
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			before, err := apispec.Parse(file, src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			formatted := apispec.Format(before)
+
+			after, err := apispec.Parse(file, formatted)
+			if err != nil {
+				t.Fatalf("formatted source does not parse: %v\n%s", err, formatted)
+			}
+			if again := apispec.Format(after); string(again) != string(formatted) {
+				t.Errorf("Format is not idempotent:\n%s\n---\n%s", formatted, again)
+			}
+			if got, want := summarize(after), summarize(before); got != want {
+				t.Errorf("declarations changed:\n got %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+// summarize lists the declarations and comments of a file independent of layout
+func summarize(f *apispec.File) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "syntax=%s info=%v;", f.Syntax, f.Info != nil)
+	for _, imp := range f.Imports {
+		fmt.Fprintf(&b, "import %s %s;", imp.Path, imp.Comment)
+	}
+	var fields func(fields []*apispec.Field)
+	fields = func(list []*apispec.Field) {
+		for _, fd := range list {
+			fmt.Fprintf(&b, " %s %s `%s` %v %s", fd.Name, fd.Type, fd.Tag, fd.Doc, fd.Comment)
+			if fd.Type.Kind == apispec.KindStruct {
+				fields(fd.Type.Fields)
+			}
+		}
+	}
+	for _, typ := range f.Types {
+		fmt.Fprintf(&b, "type %s %v %s {", typ.Name, typ.Doc, typ.Comment)
+		fields(typ.Fields)
+		fmt.Fprintf(&b, " %v};", typ.EndDoc)
+	}
+	for _, svc := range f.Services {
+		fmt.Fprintf(&b, "service %s group=%s prefix=%s jwt=%s %s {", svc.Name, svc.Group(), svc.Prefix(), svc.JWT(), svc.Comment)
+		for _, r := range svc.Routes {
+			fmt.Fprintf(&b, " %s %s %s %s %s %q %s;", r.Method, r.Path, r.Handler, r.Request, r.Response, r.Summary(), r.Comment)
+		}
+		fmt.Fprintf(&b, " %v};", svc.EndDoc)
+	}
+	fmt.Fprintf(&b, "trailing=%v", f.Trailing)
+	return b.String()
+}
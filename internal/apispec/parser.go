@@ -0,0 +1,874 @@
+package apispec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Error is a syntax error with a file position
+type Error struct {
+	Filename string
+	Pos      Pos
+	Msg      string
+}
+
+func (e *Error) Error() string {
+	if e.Filename != "" {
+		return fmt.Sprintf("%s:%d:%d: %s", e.Filename, e.Pos.Line, e.Pos.Column, e.Msg)
+	}
+	return fmt.Sprintf("%d:%d: %s", e.Pos.Line, e.Pos.Column, e.Msg)
+}
+
+// httpMethods lists the route methods accepted by goctl
+var httpMethods = map[string]bool{
+	"get": true, "head": true, "post": true, "put": true, "patch": true,
+	"delete": true, "connect": true, "options": true, "trace": true,
+}
+
+// parser builds a File from tokens
+type parser struct {
+	lex *lexer
+
+	tok      Token // current token
+	prevLine int   // line on which the previous non-comment token ended
+
+	comments []Token // comments read since the last consumed token
+	pending  []Token // comments waiting to become doc of the next node
+}
+
+// ParseFile reads and parses a single .api file
+func ParseFile(path string) (*File, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API file: %w", err)
+	}
+	return Parse(path, src)
+}
+
+// Parse parses .api source; filename is only used for error positions
+func Parse(filename string, src []byte) (*File, error) {
+	p := &parser{lex: newLexer(filename, src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	file := &File{Path: filename}
+	if err := p.parseFile(file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// ParseTypeExpr parses a single type expression, such as []*User or map[string]int, as it
+// may follow a field name on one line. Inline struct types and comments are refused
+func ParseTypeExpr(src string) (*TypeExpr, error) {
+	if strings.ContainsAny(src, "\r\n") {
+		return nil, fmt.Errorf("type %q spans several lines", src)
+	}
+	p := &parser{lex: newLexer("", []byte(src))}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	typ, err := p.parseTypeExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.Kind != EOF {
+		return nil, p.unexpected("end of type")
+	}
+	if len(p.comments) > 0 {
+		return nil, fmt.Errorf("type %q holds a comment", src)
+	}
+	for t := typ; t != nil; t = t.Elem {
+		if t.Kind == KindStruct || (t.Key != nil && t.Key.Kind == KindStruct) {
+			return nil, fmt.Errorf("type %q declares an inline struct; declare a named type instead", src)
+		}
+	}
+	return typ, nil
+}
+
+// Load parses path and, recursively, every file it imports
+// Import paths are resolved relative to the importing file. When checkImport is set,
+// every import is passed to it before it is read, and an error stops the load
+func Load(path string, checkImport func(path string) error) (*Spec, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve API file path: %w", err)
+	}
+
+	spec := &Spec{}
+	seen := make(map[string]bool)
+
+	var load func(path string, from *ImportDecl, fromFile string) error
+	load = func(path string, from *ImportDecl, fromFile string) error {
+		if seen[path] {
+			return nil
+		}
+		seen[path] = true
+
+		if from != nil && checkImport != nil {
+			if err := checkImport(path); err != nil {
+				return &Error{Filename: fromFile, Pos: from.Pos, Msg: fmt.Sprintf("cannot import %q: %v", from.Path, err)}
+			}
+		}
+		file, err := ParseFile(path)
+		if err != nil {
+			if from != nil {
+				if _, ok := err.(*Error); !ok {
+					return &Error{Filename: fromFile, Pos: from.Pos, Msg: fmt.Sprintf("cannot import %q: %v", from.Path, err)}
+				}
+			}
+			return err
+		}
+		spec.Files = append(spec.Files, file)
+
+		for _, imp := range file.Imports {
+			target := imp.Path
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(path), target)
+			}
+			if err := load(filepath.Clean(target), imp, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := load(absPath, nil, ""); err != nil {
+		return nil, err
+	}
+	spec.Root = spec.Files[0]
+
+	if err := checkServiceNames(spec); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// checkServiceNames verifies all service blocks share one name, as goctl requires
+func checkServiceNames(spec *Spec) error {
+	var first *ServiceDecl
+	for _, f := range spec.Files {
+		for _, svc := range f.Services {
+			if first == nil {
+				first = svc
+				continue
+			}
+			if svc.Name != first.Name {
+				return &Error{Filename: f.Path, Pos: svc.Pos, Msg: fmt.Sprintf("service name %q does not match %q; all service blocks must share one name", svc.Name, first.Name)}
+			}
+		}
+	}
+	return nil
+}
+
+// advance moves to the next non-comment token, collecting comments on the way
+func (p *parser) advance() error {
+	if p.tok.End > 0 {
+		p.prevLine = p.endLine(p.tok)
+	}
+	for {
+		tok, err := p.lex.next()
+		if err != nil {
+			return err
+		}
+		if tok.Kind == COMMENT {
+			p.comments = append(p.comments, tok)
+			continue
+		}
+		p.tok = tok
+		return nil
+	}
+}
+
+// endLine returns the line on which tok ends
+func (p *parser) endLine(tok Token) int {
+	return tok.Pos.Line + strings.Count(tok.Text, "\n")
+}
+
+func (p *parser) errorf(pos Pos, format string, args ...interface{}) error {
+	return &Error{Filename: p.lex.filename, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) unexpected(want string) error {
+	if p.tok.Kind == EOF {
+		return p.errorf(p.tok.Pos, "expected %s, found end of file", want)
+	}
+	return p.errorf(p.tok.Pos, "expected %s, found %q", want, p.tok.Text)
+}
+
+func (p *parser) expect(kind TokenKind) (Token, error) {
+	tok := p.tok
+	if tok.Kind != kind {
+		return tok, p.unexpected(kind.String())
+	}
+	return tok, p.advance()
+}
+
+func (p *parser) expectIdent(text string) error {
+	if p.tok.Kind != IDENT || p.tok.Text != text {
+		return p.unexpected(fmt.Sprintf("%q", text))
+	}
+	return p.advance()
+}
+
+// takeDoc returns comments that precede the current token on earlier lines
+// A trailing comment on the previous token's line is not part of the doc
+func (p *parser) takeDoc() []string {
+	var doc []string
+	for _, c := range p.pending {
+		doc = append(doc, c.Text)
+	}
+	p.pending = nil
+	for _, c := range p.comments {
+		doc = append(doc, c.Text)
+	}
+	p.comments = nil
+	return doc
+}
+
+// takeLineComment returns a comment that starts on line, consuming it
+// Remaining comments are kept as doc for the next node
+func (p *parser) takeLineComment(line int) string {
+	comment := ""
+	var rest []Token
+	for _, c := range p.comments {
+		if comment == "" && c.Pos.Line == line {
+			comment = c.Text
+			continue
+		}
+		rest = append(rest, c)
+	}
+	p.comments = nil
+	p.pending = append(p.pending, rest...)
+	return comment
+}
+
+func (p *parser) parseFile(file *File) error {
+	for p.tok.Kind != EOF {
+		if p.tok.Kind != IDENT && p.tok.Kind != AT {
+			return p.unexpected("declaration")
+		}
+
+		if p.tok.Kind == AT {
+			svc, err := p.parseService()
+			if err != nil {
+				return err
+			}
+			file.Services = append(file.Services, svc)
+			file.Decls = append(file.Decls, svc)
+			continue
+		}
+
+		switch p.tok.Text {
+		case "syntax":
+			doc := p.takeDoc()
+			if file.Syntax != "" {
+				return p.errorf(p.tok.Pos, "duplicate syntax declaration")
+			}
+			if err := p.advance(); err != nil {
+				return err
+			}
+			if _, err := p.expect(ASSIGN); err != nil {
+				return err
+			}
+			tok, err := p.expect(STRING)
+			if err != nil {
+				return err
+			}
+			file.Syntax = unquote(tok.Text)
+			file.Doc = doc
+			file.SyntaxComment = p.takeLineComment(tok.Pos.Line)
+		case "info":
+			if file.Info != nil {
+				return p.errorf(p.tok.Pos, "duplicate info declaration")
+			}
+			info, err := p.parseInfo()
+			if err != nil {
+				return err
+			}
+			file.Info = info
+			file.Decls = append(file.Decls, info)
+		case "import":
+			imports, err := p.parseImport()
+			if err != nil {
+				return err
+			}
+			for _, imp := range imports {
+				file.Imports = append(file.Imports, imp)
+				file.Decls = append(file.Decls, imp)
+			}
+		case "type":
+			types, group, err := p.parseTypeDecl()
+			if err != nil {
+				return err
+			}
+			file.Types = append(file.Types, types...)
+			if group != nil {
+				file.Groups = append(file.Groups, group)
+				file.Decls = append(file.Decls, group)
+			} else {
+				for _, t := range types {
+					file.Decls = append(file.Decls, t)
+				}
+			}
+		case "service":
+			svc, err := p.parseService()
+			if err != nil {
+				return err
+			}
+			file.Services = append(file.Services, svc)
+			file.Decls = append(file.Decls, svc)
+		default:
+			return p.errorf(p.tok.Pos, "unexpected %q, expected syntax, info, import, type, @server or service", p.tok.Text)
+		}
+	}
+
+	file.Trailing = p.takeDoc()
+	return nil
+}
+
+// parseProperties parses "key: value" lines up to the closing parenthesis
+func (p *parser) parseProperties() ([]*Property, error) {
+	if _, err := p.expect(LPAREN); err != nil {
+		return nil, err
+	}
+
+	var props []*Property
+	for p.tok.Kind != RPAREN {
+		if p.tok.Kind != IDENT {
+			return nil, p.unexpected("property name or ')'")
+		}
+		prop := &Property{Pos: p.tok.Pos, Key: p.tok.Text, Doc: p.takeDoc()}
+
+		// Values are free-form text up to the end of the line, so read them raw
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.Kind != COLON {
+			return nil, p.unexpected("':'")
+		}
+		p.lex.reset(p.tok)
+		p.lex.advance()
+		value, valuePos, quoted := p.lex.lineValue()
+		if value == "" {
+			return nil, p.errorf(valuePos, "missing value for %q", prop.Key)
+		}
+		prop.Value = value
+		prop.Quoted = quoted
+		if err := p.resync(valuePos.Line); err != nil {
+			return nil, err
+		}
+		prop.Comment = p.takeLineComment(valuePos.Line)
+		props = append(props, prop)
+	}
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return props, nil
+}
+
+// resync resumes tokenizing after a raw read that ended on line
+func (p *parser) resync(line int) error {
+	p.tok = Token{}
+	p.prevLine = line
+	return p.advance()
+}
+
+func (p *parser) parseInfo() (*InfoDecl, error) {
+	info := &InfoDecl{Pos: p.tok.Pos, Doc: p.takeDoc()}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	props, err := p.parseProperties()
+	if err != nil {
+		return nil, err
+	}
+	info.Properties = props
+	return info, nil
+}
+
+func (p *parser) parseImport() ([]*ImportDecl, error) {
+	doc := p.takeDoc()
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.Kind == STRING {
+		imp := &ImportDecl{Pos: p.tok.Pos, Path: unquote(p.tok.Text), Doc: doc}
+		line := p.tok.Pos.Line
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		imp.Comment = p.takeLineComment(line)
+		return []*ImportDecl{imp}, nil
+	}
+
+	if p.tok.Kind != LPAREN {
+		return nil, p.unexpected("import path or '('")
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var imports []*ImportDecl
+	for p.tok.Kind != RPAREN {
+		if p.tok.Kind != STRING {
+			return nil, p.unexpected("import path")
+		}
+		imp := &ImportDecl{Pos: p.tok.Pos, Path: unquote(p.tok.Text), Doc: p.takeDoc(), Grouped: true}
+		if len(imports) == 0 {
+			imp.Doc = append(doc, imp.Doc...)
+		}
+		line := p.tok.Pos.Line
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		imp.Comment = p.takeLineComment(line)
+		imports = append(imports, imp)
+	}
+	if len(imports) == 0 {
+		return nil, p.errorf(p.tok.Pos, "empty import block")
+	}
+	return imports, p.advance()
+}
+
+// parseTypeDecl parses "type Name {...}" or a "type ( ... )" group
+func (p *parser) parseTypeDecl() ([]*TypeDecl, *TypeGroup, error) {
+	typePos := p.tok.Pos
+	doc := p.takeDoc()
+	if err := p.advance(); err != nil {
+		return nil, nil, err
+	}
+
+	if p.tok.Kind != LPAREN {
+		t, err := p.parseTypeSpec(doc)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []*TypeDecl{t}, nil, nil
+	}
+
+	group := &TypeGroup{Pos: typePos, Doc: doc}
+	if err := p.advance(); err != nil {
+		return nil, nil, err
+	}
+	for p.tok.Kind != RPAREN {
+		t, err := p.parseTypeSpec(p.takeDoc())
+		if err != nil {
+			return nil, nil, err
+		}
+		t.Group = group
+		group.Types = append(group.Types, t)
+		group.Names = append(group.Names, t.Name)
+	}
+	group.EndPos = p.tok.Pos
+	if err := p.advance(); err != nil {
+		return nil, nil, err
+	}
+	return group.Types, group, nil
+}
+
+// parseTypeSpec parses "Name [struct] { fields }"
+func (p *parser) parseTypeSpec(doc []string) (*TypeDecl, error) {
+	if p.tok.Kind != IDENT {
+		return nil, p.unexpected("type name")
+	}
+	if strings.Contains(p.tok.Text, "-") || strings.Contains(p.tok.Text, ".") {
+		return nil, p.errorf(p.tok.Pos, "invalid type name %q", p.tok.Text)
+	}
+	t := &TypeDecl{Pos: p.tok.Pos, Name: p.tok.Text, Doc: doc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.Kind == ASSIGN {
+		return nil, p.errorf(p.tok.Pos, "type aliases are not supported")
+	}
+	if p.tok.Kind == IDENT && p.tok.Text == "struct" {
+		t.Struct = true
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	fields, endDoc, endPos, err := p.parseFields()
+	if err != nil {
+		return nil, err
+	}
+	t.Fields = fields
+	t.EndDoc = endDoc
+	t.EndPos = endPos
+	t.Comment = p.takeLineComment(endPos.Line)
+	return t, nil
+}
+
+// parseFields parses "{ field* }" and returns the fields, the comments before '}' and its position
+func (p *parser) parseFields() ([]*Field, []string, Pos, error) {
+	if _, err := p.expect(LBRACE); err != nil {
+		return nil, nil, Pos{}, err
+	}
+
+	var fields []*Field
+	names := make(map[string]bool)
+	for p.tok.Kind != RBRACE {
+		if p.tok.Kind == EOF {
+			return nil, nil, Pos{}, p.unexpected("'}'")
+		}
+		field, err := p.parseField()
+		if err != nil {
+			return nil, nil, Pos{}, err
+		}
+		key := field.Name
+		if key == "" {
+			key = field.Type.BaseName()
+		}
+		if names[key] {
+			return nil, nil, Pos{}, p.errorf(field.Pos, "duplicate field %q", key)
+		}
+		names[key] = true
+		fields = append(fields, field)
+	}
+
+	endPos := p.tok.Pos
+	endDoc := p.takeDoc()
+	p.prevLine = endPos.Line
+	if err := p.advance(); err != nil {
+		return nil, nil, Pos{}, err
+	}
+	return fields, endDoc, endPos, nil
+}
+
+// parseField parses "Name Type `tag`" or an embedded "Type `tag`"
+func (p *parser) parseField() (*Field, error) {
+	field := &Field{Pos: p.tok.Pos, Doc: p.takeDoc()}
+	line := p.tok.Pos.Line
+
+	// A lone identifier (or *Ident) on its line is an embedded type
+	embedded := p.tok.Kind == STAR
+	if p.tok.Kind == IDENT {
+		name := p.tok
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.Pos.Line != line || p.tok.Kind == RAWSTRING || p.tok.Kind == RBRACE {
+			field.Type = &TypeExpr{Pos: name.Pos, Kind: KindIdent, Name: name.Text}
+		} else {
+			if strings.Contains(name.Text, "-") || strings.Contains(name.Text, ".") {
+				return nil, p.errorf(name.Pos, "invalid field name %q", name.Text)
+			}
+			field.Name = name.Text
+		}
+	} else if !embedded {
+		return nil, p.unexpected("field name")
+	}
+
+	if field.Type == nil {
+		typ, err := p.parseTypeExpr()
+		if err != nil {
+			return nil, err
+		}
+		field.Type = typ
+	}
+
+	if p.tok.Kind == RAWSTRING && p.tok.Pos.Line == p.prevLine {
+		field.Tag = strings.Trim(p.tok.Text, "`")
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	field.Comment = p.takeLineComment(p.prevLine)
+	if p.tok.Kind != RBRACE && p.tok.Pos.Line == p.prevLine {
+		return nil, p.errorf(p.tok.Pos, "unexpected %q after field, expected newline", p.tok.Text)
+	}
+	return field, nil
+}
+
+// parseTypeExpr parses a type expression
+func (p *parser) parseTypeExpr() (*TypeExpr, error) {
+	pos := p.tok.Pos
+	switch p.tok.Kind {
+	case STAR:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		elem, err := p.parseTypeExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &TypeExpr{Pos: pos, Kind: KindPointer, Elem: elem}, nil
+	case LBRACK:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		length := ""
+		if p.tok.Kind == NUMBER {
+			length = p.tok.Text
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := p.expect(RBRACK); err != nil {
+			return nil, err
+		}
+		elem, err := p.parseTypeExpr()
+		if err != nil {
+			return nil, err
+		}
+		return &TypeExpr{Pos: pos, Kind: KindArray, Len: length, Elem: elem}, nil
+	case IDENT:
+		name := p.tok.Text
+		switch name {
+		case "map":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(LBRACK); err != nil {
+				return nil, err
+			}
+			key, err := p.parseTypeExpr()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(RBRACK); err != nil {
+				return nil, err
+			}
+			elem, err := p.parseTypeExpr()
+			if err != nil {
+				return nil, err
+			}
+			return &TypeExpr{Pos: pos, Kind: KindMap, Key: key, Elem: elem}, nil
+		case "struct":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			fields, endDoc, _, err := p.parseFields()
+			if err != nil {
+				return nil, err
+			}
+			return &TypeExpr{Pos: pos, Kind: KindStruct, Fields: fields, EndDoc: endDoc}, nil
+		case "interface":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(LBRACE); err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(RBRACE); err != nil {
+				return nil, err
+			}
+			return &TypeExpr{Pos: pos, Kind: KindInterface}, nil
+		}
+		if strings.Contains(name, "-") {
+			return nil, p.errorf(pos, "invalid type name %q", name)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return &TypeExpr{Pos: pos, Kind: KindIdent, Name: name}, nil
+	}
+	return nil, p.unexpected("type")
+}
+
+// parseAnnotation parses @name "value", @name(key: value ...) or @handler Name
+func (p *parser) parseAnnotation() (*Annotation, error) {
+	ann := &Annotation{Pos: p.tok.Pos, Doc: p.takeDoc()}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.Kind != IDENT {
+		return nil, p.unexpected("annotation name")
+	}
+	ann.Name = p.tok.Text
+	line := p.tok.Pos.Line
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.tok.Kind == LPAREN:
+		props, err := p.parseProperties()
+		if err != nil {
+			return nil, err
+		}
+		ann.Properties = props
+	case (p.tok.Kind == STRING || p.tok.Kind == IDENT) && p.tok.Pos.Line == line:
+		ann.Value = p.tok.Text
+		if p.tok.Kind == STRING {
+			ann.Value = unquote(p.tok.Text)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, p.unexpected(fmt.Sprintf("value for @%s", ann.Name))
+	}
+
+	ann.Comment = p.takeLineComment(p.prevLine)
+	return ann, nil
+}
+
+// parseService parses an optional @server(...) followed by a service block
+func (p *parser) parseService() (*ServiceDecl, error) {
+	svc := &ServiceDecl{}
+
+	if p.tok.Kind == AT {
+		ann, err := p.parseAnnotation()
+		if err != nil {
+			return nil, err
+		}
+		if ann.Name != "server" {
+			return nil, p.errorf(ann.Pos, "unexpected @%s outside a service, expected @server", ann.Name)
+		}
+		if ann.Properties == nil {
+			return nil, p.errorf(ann.Pos, "@server requires (key: value) properties")
+		}
+		svc.Server = ann
+		if p.tok.Kind != IDENT || p.tok.Text != "service" {
+			return nil, p.unexpected("service after @server")
+		}
+	}
+
+	svc.Pos = p.tok.Pos
+	svc.Doc = p.takeDoc()
+	if err := p.expectIdent("service"); err != nil {
+		return nil, err
+	}
+	if p.tok.Kind != IDENT {
+		return nil, p.unexpected("service name")
+	}
+	svc.Name = p.tok.Text
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	lbrace, err := p.expect(LBRACE)
+	if err != nil {
+		return nil, err
+	}
+	svc.Comment = p.takeLineComment(lbrace.Pos.Line)
+
+	for p.tok.Kind != RBRACE {
+		if p.tok.Kind == EOF {
+			return nil, p.unexpected("'}'")
+		}
+		route, err := p.parseRoute()
+		if err != nil {
+			return nil, err
+		}
+		svc.Routes = append(svc.Routes, route)
+	}
+
+	svc.EndPos = p.tok.Pos
+	svc.EndDoc = p.takeDoc()
+	p.prevLine = svc.EndPos.Line
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+// parseRoute parses annotations followed by "method /path [(Req)] [returns (Resp)]"
+func (p *parser) parseRoute() (*Route, error) {
+	route := &Route{}
+	doc := p.takeDoc()
+
+	for p.tok.Kind == AT {
+		ann, err := p.parseAnnotation()
+		if err != nil {
+			return nil, err
+		}
+		ann.Doc = append(doc, ann.Doc...)
+		doc = nil
+		switch ann.Name {
+		case "doc":
+			if route.AtDoc != nil {
+				return nil, p.errorf(ann.Pos, "duplicate @doc")
+			}
+			route.AtDoc = ann
+		case "handler":
+			if route.Handler != "" {
+				return nil, p.errorf(ann.Pos, "duplicate @handler")
+			}
+			if ann.Value == "" {
+				return nil, p.errorf(ann.Pos, "@handler requires a name")
+			}
+			route.Handler = ann.Value
+			route.AtHandlerPos = ann.Pos
+			route.HandlerComment = ann.Comment
+			route.Doc = append(route.Doc, ann.Doc...)
+		case "server":
+			route.AtServer = ann
+			if h := ann.Get("handler"); h != "" && route.Handler == "" {
+				route.Handler = h
+				route.AtHandlerPos = ann.Pos
+			}
+		default:
+			return nil, p.errorf(ann.Pos, "unknown annotation @%s in service", ann.Name)
+		}
+	}
+	route.Doc = append(route.Doc, doc...)
+	route.Doc = append(route.Doc, p.takeDoc()...)
+
+	if p.tok.Kind != IDENT || !httpMethods[strings.ToLower(p.tok.Text)] {
+		return nil, p.unexpected("HTTP method")
+	}
+	if route.Handler == "" {
+		return nil, p.errorf(p.tok.Pos, "route %s has no @handler", p.tok.Text)
+	}
+	route.Pos = p.tok.Pos
+	route.Method = strings.ToLower(p.tok.Text)
+	line := p.tok.Pos.Line
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.Kind != PATH {
+		return nil, p.unexpected("route path")
+	}
+	route.Path = p.tok.Text
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.tok.Kind == LPAREN && p.tok.Pos.Line == line {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		req, err := p.parseTypeExpr()
+		if err != nil {
+			return nil, err
+		}
+		route.Request = req
+		if _, err := p.expect(RPAREN); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.tok.Kind == IDENT && p.tok.Text == "returns" && p.tok.Pos.Line == line {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(LPAREN); err != nil {
+			return nil, err
+		}
+		resp, err := p.parseTypeExpr()
+		if err != nil {
+			return nil, err
+		}
+		route.Response = resp
+		if _, err := p.expect(RPAREN); err != nil {
+			return nil, err
+		}
+	}
+
+	route.Comment = p.takeLineComment(p.prevLine)
+	if p.tok.Kind != RBRACE && p.tok.Kind != EOF && p.tok.Pos.Line == p.prevLine {
+		return nil, p.errorf(p.tok.Pos, "unexpected %q after route, expected newline", p.tok.Text)
+	}
+	return route, nil
+}
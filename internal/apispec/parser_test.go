@@ -0,0 +1,274 @@
+package apispec_test
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestParseGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no testdata .api files found")
+	}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ast, err := apispec.Parse(name, src)
+			if err != nil {
+				t.Fatalf("Parse(%s) failed: %v", name, err)
+			}
+
+			got, err := json.MarshalIndent(ast, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := strings.TrimSuffix(file, ".api") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create): %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("AST for %s does not match %s (run with -update to refresh):\n%s", name, golden, got)
+			}
+		})
+	}
+}
+
+func TestLoadResolvesImports(t *testing.T) {
+	spec, err := apispec.Load(filepath.Join("testdata", "full.api"), nil)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if len(spec.Files) != 3 {
+		t.Fatalf("Expected 3 files (root + 2 imports), got %d", len(spec.Files))
+	}
+	if spec.ServiceName() != "user-api" {
+		t.Errorf("ServiceName() = %q, want %q", spec.ServiceName(), "user-api")
+	}
+
+	services := spec.Services()
+	if len(services) != 3 {
+		t.Fatalf("Expected 3 service blocks, got %d", len(services))
+	}
+
+	routes := 0
+	for _, svc := range services {
+		routes += len(svc.Routes)
+	}
+	if routes != 5 {
+		t.Errorf("Expected 5 routes across all files, got %d", routes)
+	}
+
+	if spec.Type("User") == nil || spec.Type("BanReq") == nil {
+		t.Error("Expected types from imported files to be resolvable")
+	}
+
+	user := services[0]
+	if user.Group() != "user" || user.Prefix() != "/api/v1" || user.JWT() != "Auth" || user.Timeout() != "3s" {
+		t.Errorf("Unexpected @server attributes: group=%q prefix=%q jwt=%q timeout=%q",
+			user.Group(), user.Prefix(), user.JWT(), user.Timeout())
+	}
+	if mw := user.Middleware(); len(mw) != 2 || mw[0] != "Log" || mw[1] != "Trace" {
+		t.Errorf("Middleware() = %v, want [Log Trace]", mw)
+	}
+	if got := user.Routes[0].Summary(); got != "Get a user by id" {
+		t.Errorf("Summary() = %q", got)
+	}
+	if got := user.Routes[1].Summary(); got != "List users" {
+		t.Errorf("Summary() = %q", got)
+	}
+	if params := user.Routes[0].PathParams(); len(params) != 1 || params[0] != "id" {
+		t.Errorf("PathParams() = %v, want [id]", params)
+	}
+}
+
+func TestLoadMissingImport(t *testing.T) {
+	dir := t.TempDir()
+	apiFile := filepath.Join(dir, "main.api")
+	content := "syntax = \"v1\"\n\nimport \"missing.api\"\n\nservice a {\n\t@handler A\n\tget /a\n}\n"
+	if err := os.WriteFile(apiFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := apispec.Load(apiFile, nil)
+	if err == nil {
+		t.Fatal("Expected error for missing import")
+	}
+	if !strings.Contains(err.Error(), "main.api:3:8") {
+		t.Errorf("Expected error to point at the import, got: %v", err)
+	}
+}
+
+func TestLoadRefusedImport(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "types.api"), []byte("type Req {\n\tId int64 `json:\"id\"`\n}\n"), 0644)
+	apiFile := filepath.Join(dir, "main.api")
+	os.WriteFile(apiFile, []byte("import \"types.api\"\n\nservice a {\n\t@handler A\n\tget /a\n}\n"), 0644)
+
+	var checked []string
+	_, err := apispec.Load(apiFile, func(path string) error {
+		checked = append(checked, path)
+		return errors.New("not allowed")
+	})
+	if err == nil || !strings.Contains(err.Error(), "main.api:1:8: cannot import \"types.api\": not allowed") {
+		t.Errorf("Expected refused import error, got: %v", err)
+	}
+	if len(checked) != 1 || checked[0] != filepath.Join(dir, "types.api") {
+		t.Errorf("checkImport got %v, want only the import", checked)
+	}
+}
+
+func TestLoadServiceNameMismatch(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "other.api"), []byte("service b {\n\t@handler B\n\tget /b\n}\n"), 0644)
+	apiFile := filepath.Join(dir, "main.api")
+	os.WriteFile(apiFile, []byte("import \"other.api\"\n\nservice a {\n\t@handler A\n\tget /a\n}\n"), 0644)
+
+	_, err := apispec.Load(apiFile, nil)
+	if err == nil || !strings.Contains(err.Error(), "other.api:1:1") {
+		t.Errorf("Expected service name mismatch error at other.api:1:1, got: %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{
+			name:    "missing handler",
+			src:     "service a {\n\tget /a\n}\n",
+			wantErr: "test.api:2:2: route get has no @handler",
+		},
+		{
+			name:    "unknown method",
+			src:     "service a {\n\t@handler A\n\tfetch /a\n}\n",
+			wantErr: "test.api:3:2: expected HTTP method, found \"fetch\"",
+		},
+		{
+			name:    "unterminated service",
+			src:     "service a {\n\t@handler A\n\tget /a\n",
+			wantErr: "test.api:4:1: expected '}', found end of file",
+		},
+		{
+			name:    "missing route path",
+			src:     "service a {\n\t@handler A\n\tget (Req)\n}\n",
+			wantErr: "test.api:3:6: expected route path, found \"(\"",
+		},
+		{
+			name:    "unterminated string",
+			src:     "syntax = \"v1\n",
+			wantErr: "test.api:1:10: string literal not terminated",
+		},
+		{
+			name:    "duplicate field",
+			src:     "type A {\n\tName string\n\tName int\n}\n",
+			wantErr: "test.api:3:2: duplicate field \"Name\"",
+		},
+		{
+			name:    "missing server property value",
+			src:     "@server (\n\tgroup:\n)\nservice a {\n}\n",
+			wantErr: "test.api:2:8: missing value for \"group\"",
+		},
+		{
+			name:    "unknown top-level",
+			src:     "syntax = \"v1\"\n\nrpc Foo\n",
+			wantErr: "test.api:3:1: unexpected \"rpc\", expected syntax, info, import, type, @server or service",
+		},
+		{
+			name:    "annotation outside service",
+			src:     "@handler Foo\nservice a {\n}\n",
+			wantErr: "test.api:1:1: unexpected @handler outside a service, expected @server",
+		},
+		{
+			name:    "type alias",
+			src:     "type A = B\n",
+			wantErr: "test.api:1:8: type aliases are not supported",
+		},
+		{
+			name:    "field garbage",
+			src:     "type A {\n\tName string `json:\"name\"` extra\n}\n",
+			wantErr: "test.api:2:28: unexpected \"extra\" after field, expected newline",
+		},
+		{
+			name:    "unterminated comment",
+			src:     "/* open\nservice a {}\n",
+			wantErr: "test.api:1:1: comment not terminated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := apispec.Parse("test.api", []byte(tt.src))
+			if err == nil {
+				t.Fatalf("Expected error %q, got none", tt.wantErr)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("Parse() error = %q, want %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLookupTag(t *testing.T) {
+	tag := `json:"name,optional" path:"id" form:"q,default=\"x\""`
+
+	tests := []struct {
+		key   string
+		want  string
+		found bool
+	}{
+		{"json", "name,optional", true},
+		{"path", "id", true},
+		{"form", `q,default=\"x\"`, true},
+		{"header", "", false},
+	}
+
+	for _, tt := range tests {
+		got, found := apispec.LookupTag(tag, tt.key)
+		if got != tt.want || found != tt.found {
+			t.Errorf("LookupTag(%q) = %q, %v; want %q, %v", tt.key, got, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestParseTypeExpr(t *testing.T) {
+	for _, src := range []string{"string", "*User", "[]*types.User", "[4]byte", "map[string][]int64", "interface{}"} {
+		if _, err := apispec.ParseTypeExpr(src); err != nil {
+			t.Errorf("ParseTypeExpr(%q) failed: %v", src, err)
+		}
+	}
+	for _, src := range []string{"", "string\n}", "string }", "string `json:\"x\"`", "[]struct{}", "string // note", "map[string]"} {
+		if _, err := apispec.ParseTypeExpr(src); err == nil {
+			t.Errorf("ParseTypeExpr(%q) succeeded", src)
+		}
+	}
+}
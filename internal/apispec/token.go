@@ -0,0 +1,324 @@
+package apispec
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind identifies the lexical class of a token
+type TokenKind int
+
+const (
+	EOF TokenKind = iota
+	IDENT
+	STRING    // "double quoted"
+	RAWSTRING // `back quoted`, used for struct tags
+	NUMBER
+	PATH    // route path such as /users/:id
+	COMMENT // // line or /* block */ comment
+	LPAREN
+	RPAREN
+	LBRACE
+	RBRACE
+	LBRACK
+	RBRACK
+	STAR
+	COMMA
+	COLON
+	ASSIGN
+	AT
+)
+
+var tokenNames = map[TokenKind]string{
+	EOF:       "end of file",
+	IDENT:     "identifier",
+	STRING:    "string",
+	RAWSTRING: "raw string",
+	NUMBER:    "number",
+	PATH:      "path",
+	COMMENT:   "comment",
+	LPAREN:    "'('",
+	RPAREN:    "')'",
+	LBRACE:    "'{'",
+	RBRACE:    "'}'",
+	LBRACK:    "'['",
+	RBRACK:    "']'",
+	STAR:      "'*'",
+	COMMA:     "','",
+	COLON:     "':'",
+	ASSIGN:    "'='",
+	AT:        "'@'",
+}
+
+func (k TokenKind) String() string {
+	if name, ok := tokenNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("token(%d)", int(k))
+}
+
+// Pos is a position in a source file, 1-based
+type Pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Token is a lexical token
+type Token struct {
+	Kind   TokenKind
+	Text   string // raw source text of the token
+	Pos    Pos
+	Offset int // byte offset of the token start
+	End    int // byte offset just past the token
+}
+
+// lexer splits .api source into tokens
+type lexer struct {
+	filename string
+	src      []byte
+	offset   int
+	line     int
+	column   int
+}
+
+func newLexer(filename string, src []byte) *lexer {
+	return &lexer{filename: filename, src: src, line: 1, column: 1}
+}
+
+func (l *lexer) errorf(pos Pos, format string, args ...interface{}) error {
+	return &Error{Filename: l.filename, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) pos() Pos {
+	return Pos{Line: l.line, Column: l.column}
+}
+
+func (l *lexer) peekRune() rune {
+	if l.offset >= len(l.src) {
+		return -1
+	}
+	r, _ := utf8.DecodeRune(l.src[l.offset:])
+	return r
+}
+
+func (l *lexer) peekRuneAt(n int) rune {
+	off := l.offset
+	for i := 0; i < n; i++ {
+		if off >= len(l.src) {
+			return -1
+		}
+		_, size := utf8.DecodeRune(l.src[off:])
+		off += size
+	}
+	if off >= len(l.src) {
+		return -1
+	}
+	r, _ := utf8.DecodeRune(l.src[off:])
+	return r
+}
+
+func (l *lexer) advance() rune {
+	if l.offset >= len(l.src) {
+		return -1
+	}
+	r, size := utf8.DecodeRune(l.src[l.offset:])
+	l.offset += size
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+	return r
+}
+
+// reset moves the lexer back to the start of tok so it can be re-read in another mode
+func (l *lexer) reset(tok Token) {
+	l.offset = tok.Offset
+	l.line = tok.Pos.Line
+	l.column = tok.Pos.Column
+}
+
+func (l *lexer) skipSpace() {
+	for {
+		r := l.peekRune()
+		if r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == '\f' || r == '\uFEFF' {
+			l.advance()
+			continue
+		}
+		return
+	}
+}
+
+// next returns the next token, including comments
+func (l *lexer) next() (Token, error) {
+	l.skipSpace()
+
+	start := l.offset
+	pos := l.pos()
+	tok := Token{Pos: pos, Offset: start}
+
+	r := l.peekRune()
+	switch {
+	case r == -1:
+		tok.Kind = EOF
+	case r == '/' && l.peekRuneAt(1) == '/':
+		for r := l.peekRune(); r != -1 && r != '\n'; r = l.peekRune() {
+			l.advance()
+		}
+		tok.Kind = COMMENT
+	case r == '/' && l.peekRuneAt(1) == '*':
+		l.advance()
+		l.advance()
+		closed := false
+		for r := l.peekRune(); r != -1; r = l.peekRune() {
+			l.advance()
+			if r == '*' && l.peekRune() == '/' {
+				l.advance()
+				closed = true
+				break
+			}
+		}
+		if !closed {
+			return tok, l.errorf(pos, "comment not terminated")
+		}
+		tok.Kind = COMMENT
+	case r == '/':
+		for r := l.peekRune(); r != -1 && !unicode.IsSpace(r) && r != '(' && r != ')' && r != '{' && r != '}'; r = l.peekRune() {
+			if r == '/' && l.peekRuneAt(1) == '/' && l.offset > start {
+				break
+			}
+			l.advance()
+		}
+		tok.Kind = PATH
+	case r == '"':
+		l.advance()
+		for {
+			r := l.advance()
+			if r == -1 || r == '\n' {
+				return tok, l.errorf(pos, "string literal not terminated")
+			}
+			if r == '\\' {
+				l.advance()
+				continue
+			}
+			if r == '"' {
+				break
+			}
+		}
+		tok.Kind = STRING
+	case r == '`':
+		l.advance()
+		for {
+			r := l.advance()
+			if r == -1 {
+				return tok, l.errorf(pos, "raw string literal not terminated")
+			}
+			if r == '`' {
+				break
+			}
+		}
+		tok.Kind = RAWSTRING
+	case isIdentStart(r):
+		for r := l.peekRune(); isIdentPart(r); r = l.peekRune() {
+			l.advance()
+		}
+		tok.Kind = IDENT
+	case unicode.IsDigit(r):
+		for r := l.peekRune(); unicode.IsDigit(r) || unicode.IsLetter(r) || r == '.'; r = l.peekRune() {
+			l.advance()
+		}
+		tok.Kind = NUMBER
+	default:
+		l.advance()
+		switch r {
+		case '(':
+			tok.Kind = LPAREN
+		case ')':
+			tok.Kind = RPAREN
+		case '{':
+			tok.Kind = LBRACE
+		case '}':
+			tok.Kind = RBRACE
+		case '[':
+			tok.Kind = LBRACK
+		case ']':
+			tok.Kind = RBRACK
+		case '*':
+			tok.Kind = STAR
+		case ',':
+			tok.Kind = COMMA
+		case ':':
+			tok.Kind = COLON
+		case '=':
+			tok.Kind = ASSIGN
+		case '@':
+			tok.Kind = AT
+		default:
+			return tok, l.errorf(pos, "unexpected character %q", r)
+		}
+	}
+
+	tok.End = l.offset
+	tok.Text = string(l.src[start:l.offset])
+	return tok, nil
+}
+
+// lineValue reads the raw value of a "key: value" property, starting right after the colon
+// The value ends at a newline, a closing parenthesis or a line comment
+func (l *lexer) lineValue() (string, Pos, bool) {
+	for r := l.peekRune(); r == ' ' || r == '\t'; r = l.peekRune() {
+		l.advance()
+	}
+	pos := l.pos()
+
+	if l.peekRune() == '"' {
+		start := l.offset
+		l.advance()
+		for {
+			r := l.advance()
+			if r == -1 || r == '\n' || r == '"' {
+				break
+			}
+			if r == '\\' {
+				l.advance()
+			}
+		}
+		return unquote(string(l.src[start:l.offset])), pos, true
+	}
+
+	start := l.offset
+	for {
+		r := l.peekRune()
+		if r == -1 || r == '\n' || r == ')' {
+			break
+		}
+		if r == '/' && l.peekRuneAt(1) == '/' {
+			break
+		}
+		l.advance()
+	}
+	return strings.TrimSpace(string(l.src[start:l.offset])), pos, false
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '-' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// unquote strips the quotes of a "double quoted" string, keeping escapes readable
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return strings.ReplaceAll(s, `\"`, `"`)
+}
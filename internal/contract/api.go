@@ -0,0 +1,430 @@
+package contract
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+)
+
+// bindingTags are the struct tags go-zero binds request and response fields with
+var bindingTags = []string{"json", "form", "path", "header"}
+
+// apiRoute is a route together with the settings of its service block
+type apiRoute struct {
+	route *apispec.Route
+	svc   *apispec.ServiceDecl
+	file  string
+}
+
+func (r *apiRoute) key() string {
+	return r.svc.Group() + "/" + r.route.Handler
+}
+
+func (r *apiRoute) fullPath() string {
+	if prefix := r.svc.Prefix(); prefix != "" {
+		return path.Join("/", prefix, r.route.Path)
+	}
+	return r.route.Path
+}
+
+func (r *apiRoute) endpoint() string {
+	return strings.ToUpper(r.route.Method) + " " + r.fullPath()
+}
+
+// apiField is a field after embedded types are expanded
+type apiField struct {
+	field  *apispec.Field
+	owner  string // type declaring the field
+	file   string
+	inline bool // declared in an inline struct
+}
+
+// apiDiff compares two loaded .api specs
+type apiDiff struct {
+	report   *Report
+	old, new *apispec.Spec
+	requests map[string]bool // types reachable from a request in the new spec
+	compared map[[2]string]bool
+}
+
+// DiffAPI compares two versions of an .api specification
+func DiffAPI(old, new *apispec.Spec) *Report {
+	d := &apiDiff{
+		report:   &Report{Format: "api", Old: old.Root.Path, New: new.Root.Path},
+		old:      old,
+		new:      new,
+		requests: make(map[string]bool),
+		compared: make(map[[2]string]bool),
+	}
+	for _, r := range apiRoutes(new) {
+		if r.route.Request != nil {
+			reachable(new, r.route.Request.BaseName(), d.requests)
+		}
+	}
+
+	d.diffRoutes()
+	d.diffTypes()
+	d.report.finish()
+	return d.report
+}
+
+func apiRoutes(spec *apispec.Spec) []*apiRoute {
+	var routes []*apiRoute
+	for _, f := range spec.Files {
+		for _, svc := range f.Services {
+			for _, route := range svc.Routes {
+				routes = append(routes, &apiRoute{route: route, svc: svc, file: f.Path})
+			}
+		}
+	}
+	return routes
+}
+
+func (d *apiDiff) diffRoutes() {
+	oldRoutes, newRoutes := apiRoutes(d.old), apiRoutes(d.new)
+
+	byKey := make(map[string]*apiRoute)
+	byEndpoint := make(map[string]*apiRoute)
+	for _, r := range newRoutes {
+		byKey[r.key()] = r
+		byEndpoint[r.endpoint()] = r
+	}
+	matched := make(map[*apiRoute]bool)
+
+	for _, old := range oldRoutes {
+		current := byKey[old.key()]
+		if current == nil {
+			// A renamed handler keeps the method and path
+			if candidate := byEndpoint[old.endpoint()]; candidate != nil && !matched[candidate] {
+				current = candidate
+				d.report.add(Change{
+					Severity: SeverityInfo, Kind: HandlerRenamed, Location: old.endpoint(),
+					Message: fmt.Sprintf("handler renamed from %s to %s", old.route.Handler, current.route.Handler),
+					Old:     old.route.Handler, New: current.route.Handler,
+					File: current.file, Line: current.route.Pos.Line,
+				})
+			}
+		}
+		if current == nil || matched[current] {
+			d.report.add(Change{
+				Severity: SeverityBreaking, Kind: RouteRemoved, Location: old.endpoint(),
+				Message: fmt.Sprintf("route %s (handler %s) was removed", old.endpoint(), old.route.Handler),
+				File:    old.file, Line: old.route.Pos.Line,
+			})
+			continue
+		}
+		matched[current] = true
+		d.diffRoute(old, current)
+	}
+
+	for _, r := range newRoutes {
+		if !matched[r] {
+			d.report.add(Change{
+				Severity: SeverityInfo, Kind: RouteAdded, Location: r.endpoint(),
+				Message: fmt.Sprintf("route %s (handler %s) was added", r.endpoint(), r.route.Handler),
+				File:    r.file, Line: r.route.Pos.Line,
+			})
+		}
+	}
+}
+
+func (d *apiDiff) diffRoute(old, current *apiRoute) {
+	location := old.endpoint()
+	line := current.route.Pos.Line
+	change := func(severity Severity, kind, message, oldValue, newValue string) {
+		d.report.add(Change{Severity: severity, Kind: kind, Location: location, Message: message, Old: oldValue, New: newValue, File: current.file, Line: line})
+	}
+
+	if !strings.EqualFold(old.route.Method, current.route.Method) {
+		change(SeverityBreaking, MethodChanged, fmt.Sprintf("method changed from %s to %s", strings.ToUpper(old.route.Method), strings.ToUpper(current.route.Method)),
+			strings.ToUpper(old.route.Method), strings.ToUpper(current.route.Method))
+	}
+	if old.fullPath() != current.fullPath() {
+		change(SeverityBreaking, PathChanged, fmt.Sprintf("path changed from %s to %s", old.fullPath(), current.fullPath()), old.fullPath(), current.fullPath())
+	}
+
+	switch oldJWT, newJWT := old.svc.JWT(), current.svc.JWT(); {
+	case oldJWT == "" && newJWT != "":
+		change(SeverityBreaking, JWTChanged, "route now requires JWT authentication", oldJWT, newJWT)
+	case oldJWT != "" && newJWT == "":
+		change(SeverityWarning, JWTChanged, "route no longer requires JWT authentication", oldJWT, newJWT)
+	}
+
+	oldReq, newReq := typeName(old.route.Request), typeName(current.route.Request)
+	if oldReq != newReq {
+		severity := SeverityWarning
+		if oldReq == "" {
+			severity = SeverityBreaking
+		}
+		change(severity, RequestTypeChanged, fmt.Sprintf("request type changed from %s to %s", orNone(oldReq), orNone(newReq)), oldReq, newReq)
+		if old.route.Request != nil && current.route.Request != nil {
+			d.diffTypePair(old.route.Request.BaseName(), current.route.Request.BaseName())
+		}
+	}
+	oldResp, newResp := typeName(old.route.Response), typeName(current.route.Response)
+	if oldResp != newResp {
+		severity := SeverityWarning
+		if newResp == "" || (old.route.Response != nil && current.route.Response != nil && old.route.Response.Kind != current.route.Response.Kind) {
+			severity = SeverityBreaking
+		}
+		change(severity, ResponseTypeChanged, fmt.Sprintf("response type changed from %s to %s", orNone(oldResp), orNone(newResp)), oldResp, newResp)
+		if old.route.Response != nil && current.route.Response != nil {
+			d.diffTypePair(old.route.Response.BaseName(), current.route.Response.BaseName())
+		}
+	}
+}
+
+func (d *apiDiff) diffTypes() {
+	for _, old := range d.old.Types() {
+		if d.new.Type(old.Name) == nil {
+			d.report.add(Change{
+				Severity: SeverityWarning, Kind: TypeRemoved, Location: old.Name,
+				Message: fmt.Sprintf("type %s was removed", old.Name),
+				File:    apiTypeFile(d.old, old), Line: old.Pos.Line,
+			})
+			continue
+		}
+		d.diffTypePair(old.Name, old.Name)
+	}
+}
+
+// diffTypePair compares the fields of an old type with those of a new type, once per pair
+func (d *apiDiff) diffTypePair(oldName, newName string) {
+	pair := [2]string{oldName, newName}
+	if d.compared[pair] || oldName == "" || newName == "" {
+		return
+	}
+	d.compared[pair] = true
+
+	oldType, newType := d.old.Type(oldName), d.new.Type(newName)
+	if oldType == nil || newType == nil {
+		return
+	}
+	d.diffFields(newName, flatten(d.old, oldType), flatten(d.new, newType), d.requests[newName])
+}
+
+func (d *apiDiff) diffFields(location string, oldFields, newFields []apiField, request bool) {
+	byName := make(map[string]apiField)
+	for _, f := range newFields {
+		byName[f.field.Name] = f
+	}
+	seen := make(map[string]bool)
+
+	for _, old := range oldFields {
+		loc := fieldLocation(location, old)
+		current, ok := byName[old.field.Name]
+		if !ok {
+			d.report.add(Change{
+				Severity: SeverityBreaking, Kind: FieldRemoved, Location: loc,
+				Message: fmt.Sprintf("field %s (%s) was removed", old.field.Name, wireNames(old.field)),
+				File:    old.file, Line: old.field.Pos.Line,
+			})
+			continue
+		}
+		seen[old.field.Name] = true
+		d.diffField(loc, old, current, request)
+	}
+
+	for _, f := range newFields {
+		if seen[f.field.Name] {
+			continue
+		}
+		loc := fieldLocation(location, f)
+		switch {
+		case request && isRequired(f.field):
+			d.report.add(Change{
+				Severity: SeverityBreaking, Kind: RequiredFieldAdded, Location: loc,
+				Message: fmt.Sprintf("required request field %s (%s) was added", f.field.Name, wireNames(f.field)),
+				File:    f.file, Line: f.field.Pos.Line,
+			})
+		default:
+			d.report.add(Change{
+				Severity: SeverityInfo, Kind: FieldAdded, Location: loc,
+				Message: fmt.Sprintf("field %s (%s) was added", f.field.Name, wireNames(f.field)),
+				File:    f.file, Line: f.field.Pos.Line,
+			})
+		}
+	}
+}
+
+func (d *apiDiff) diffField(location string, old, current apiField, request bool) {
+	change := func(severity Severity, kind, message, oldValue, newValue string) {
+		d.report.add(Change{Severity: severity, Kind: kind, Location: location, Message: message, Old: oldValue, New: newValue, File: current.file, Line: current.field.Pos.Line})
+	}
+
+	oldType, newType := old.field.Type, current.field.Type
+	if oldType.Kind == apispec.KindStruct && newType.Kind == apispec.KindStruct {
+		d.diffFields(location, inlineFields(old), inlineFields(current), request)
+	} else if oldType.String() != newType.String() {
+		change(SeverityBreaking, FieldRetyped, fmt.Sprintf("type changed from %s to %s", oldType, newType), oldType.String(), newType.String())
+	}
+
+	oldJSON, oldHasJSON := tagName(old.field, "json")
+	newJSON, newHasJSON := tagName(current.field, "json")
+	if oldHasJSON && newHasJSON && oldJSON != newJSON {
+		change(SeverityBreaking, JSONTagChanged, fmt.Sprintf("json name changed from %q to %q", oldJSON, newJSON), oldJSON, newJSON)
+	}
+	for _, key := range bindingTags {
+		if key == "json" && oldHasJSON && newHasJSON {
+			continue
+		}
+		oldName, oldOK := tagName(old.field, key)
+		newName, newOK := tagName(current.field, key)
+		if oldOK != newOK || oldName != newName {
+			change(SeverityBreaking, TagChanged, fmt.Sprintf("binding changed from %s to %s", wireNames(old.field), wireNames(current.field)), wireNames(old.field), wireNames(current.field))
+			break
+		}
+	}
+
+	switch oldRequired, newRequired := isRequired(old.field), isRequired(current.field); {
+	case !oldRequired && newRequired && request:
+		change(SeverityBreaking, FieldNowRequired, "request field is now required", "optional", "required")
+	case !oldRequired && newRequired:
+		change(SeverityInfo, FieldNowRequired, "field is now required", "optional", "required")
+	case oldRequired && !newRequired && !request:
+		change(SeverityWarning, FieldNowOptional, "response field is now optional and may be omitted", "required", "optional")
+	case oldRequired && !newRequired:
+		change(SeverityInfo, FieldNowOptional, "field is now optional", "required", "optional")
+	}
+}
+
+// flatten returns the fields of a type with embedded types expanded
+func flatten(spec *apispec.Spec, typ *apispec.TypeDecl) []apiField {
+	var fields []apiField
+	visiting := make(map[string]bool)
+	var walk func(t *apispec.TypeDecl)
+	walk = func(t *apispec.TypeDecl) {
+		if visiting[t.Name] {
+			return
+		}
+		visiting[t.Name] = true
+		file := apiTypeFile(spec, t)
+		for _, f := range t.Fields {
+			if f.Embedded() {
+				if embedded := spec.Type(f.Type.BaseName()); embedded != nil {
+					walk(embedded)
+					continue
+				}
+			}
+			field := f
+			if field.Embedded() {
+				field = &apispec.Field{Pos: f.Pos, Name: f.Type.BaseName(), Type: f.Type, Tag: f.Tag}
+			}
+			fields = append(fields, apiField{field: field, owner: t.Name, file: file})
+		}
+		visiting[t.Name] = false
+	}
+	walk(typ)
+	return fields
+}
+
+// fieldLocation names a field after the type declaring it, so embedded fields are reported once
+func fieldLocation(location string, f apiField) string {
+	if f.inline {
+		return location + "." + f.field.Name
+	}
+	return f.owner + "." + f.field.Name
+}
+
+func inlineFields(f apiField) []apiField {
+	var fields []apiField
+	for _, field := range f.field.Type.Fields {
+		fields = append(fields, apiField{field: field, owner: f.owner, file: f.file, inline: true})
+	}
+	return fields
+}
+
+// reachable adds name and every type its fields refer to
+func reachable(spec *apispec.Spec, name string, into map[string]bool) {
+	typ := spec.Type(name)
+	if typ == nil || into[name] {
+		return
+	}
+	into[name] = true
+	var walkFields func(fields []*apispec.Field)
+	walkFields = func(fields []*apispec.Field) {
+		for _, f := range fields {
+			if f.Type.Kind == apispec.KindStruct {
+				walkFields(f.Type.Fields)
+				continue
+			}
+			reachable(spec, f.Type.BaseName(), into)
+		}
+	}
+	walkFields(typ.Fields)
+}
+
+func apiTypeFile(spec *apispec.Spec, typ *apispec.TypeDecl) string {
+	for _, f := range spec.Files {
+		for _, t := range f.Types {
+			if t == typ {
+				return f.Path
+			}
+		}
+	}
+	return ""
+}
+
+// tagName returns the name part of a binding tag
+func tagName(f *apispec.Field, key string) (string, bool) {
+	value, ok := f.TagValue(key)
+	if !ok {
+		return "", false
+	}
+	name, _, _ := strings.Cut(value, ",")
+	return name, true
+}
+
+// wireNames describes how a field is bound, such as json:"name" path:"id"
+func wireNames(f *apispec.Field) string {
+	var names []string
+	for _, key := range bindingTags {
+		if name, ok := tagName(f, key); ok {
+			names = append(names, fmt.Sprintf("%s:%q", key, name))
+		}
+	}
+	if len(names) == 0 {
+		return "untagged"
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+// isRequired reports whether go-zero requires the field when binding a request
+func isRequired(f *apispec.Field) bool {
+	if _, ok := tagName(f, "path"); ok {
+		return true
+	}
+	bound := false
+	for _, key := range bindingTags {
+		value, ok := f.TagValue(key)
+		if !ok {
+			continue
+		}
+		bound = true
+		for _, opt := range strings.Split(value, ",")[1:] {
+			opt = strings.TrimSpace(opt)
+			if opt == "optional" || opt == "omitempty" || strings.HasPrefix(opt, "default=") {
+				return false
+			}
+		}
+	}
+	return bound
+}
+
+func typeName(t *apispec.TypeExpr) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
@@ -0,0 +1,163 @@
+// Package contract detects breaking changes between two versions of an .api or .proto contract
+package contract
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity ranks how a change affects existing clients
+type Severity string
+
+const (
+	// SeverityBreaking changes break existing clients
+	SeverityBreaking Severity = "breaking"
+	// SeverityWarning changes may break some clients or generated code
+	SeverityWarning Severity = "warning"
+	// SeverityInfo changes are backward compatible
+	SeverityInfo Severity = "info"
+)
+
+var severityRank = map[Severity]int{SeverityBreaking: 0, SeverityWarning: 1, SeverityInfo: 2}
+
+// ParseSeverity returns the severity named s
+func ParseSeverity(s string) (Severity, error) {
+	severity := Severity(strings.ToLower(s))
+	if _, ok := severityRank[severity]; !ok {
+		return "", fmt.Errorf("unknown severity %q", s)
+	}
+	return severity, nil
+}
+
+// AtLeast reports whether s is as severe as other
+func (s Severity) AtLeast(other Severity) bool {
+	return severityRank[s] <= severityRank[other]
+}
+
+// Change kinds
+const (
+	RouteRemoved         = "route_removed"
+	RouteAdded           = "route_added"
+	MethodChanged        = "method_changed"
+	PathChanged          = "path_changed"
+	HandlerRenamed       = "handler_renamed"
+	RequestTypeChanged   = "request_type_changed"
+	ResponseTypeChanged  = "response_type_changed"
+	JWTChanged           = "jwt_changed"
+	TypeRemoved          = "type_removed"
+	FieldRemoved         = "field_removed"
+	FieldAdded           = "field_added"
+	FieldRetyped         = "field_retyped"
+	FieldRenamed         = "field_renamed"
+	JSONTagChanged       = "json_tag_changed"
+	TagChanged           = "tag_changed"
+	FieldNowRequired     = "field_now_required"
+	FieldNowOptional     = "field_now_optional"
+	RequiredFieldAdded   = "required_field_added"
+	ServiceRemoved       = "service_removed"
+	RPCRemoved           = "rpc_removed"
+	RPCAdded             = "rpc_added"
+	RPCSignatureChanged  = "rpc_signature_changed"
+	StreamingChanged     = "streaming_changed"
+	MessageRemoved       = "message_removed"
+	FieldNumberReused    = "field_number_reused"
+	FieldLabelChanged    = "field_label_changed"
+	OneofChanged         = "oneof_changed"
+	EnumRemoved          = "enum_removed"
+	EnumValueRemoved     = "enum_value_removed"
+	EnumValueRenumbered  = "enum_value_renumbered"
+	EnumValueRenamed     = "enum_value_renamed"
+	PackageChanged       = "package_changed"
+	FieldRemovedReserved = "field_removed_reserved"
+)
+
+// Change is a single difference between the old and new contract
+type Change struct {
+	Severity Severity `json:"severity"`
+	Kind     string   `json:"kind"`
+	Location string   `json:"location"` // route, type.field, service.rpc or message.field
+	Message  string   `json:"message"`
+	Old      string   `json:"old,omitempty"`
+	New      string   `json:"new,omitempty"`
+	File     string   `json:"file,omitempty"` // file of the new declaration, or of the old one when removed
+	Line     int      `json:"line,omitempty"`
+}
+
+// Report is the result of comparing two contracts
+type Report struct {
+	Format   string   `json:"format"` // api or proto
+	Old      string   `json:"old"`
+	New      string   `json:"new"`
+	Changes  []Change `json:"changes"`
+	Breaking int      `json:"breaking"`
+	Warnings int      `json:"warnings"`
+	Info     int      `json:"info"`
+}
+
+// add records a change once; embedded types can surface the same field twice
+func (r *Report) add(c Change) {
+	for _, existing := range r.Changes {
+		if existing.Kind == c.Kind && existing.Location == c.Location && existing.Message == c.Message {
+			return
+		}
+	}
+	r.Changes = append(r.Changes, c)
+}
+
+// finish sorts the changes by severity and location and counts them
+func (r *Report) finish() {
+	sort.SliceStable(r.Changes, func(i, j int) bool {
+		a, b := r.Changes[i], r.Changes[j]
+		if a.Severity != b.Severity {
+			return severityRank[a.Severity] < severityRank[b.Severity]
+		}
+		return a.Location < b.Location
+	})
+	r.Breaking, r.Warnings, r.Info = 0, 0, 0
+	for _, c := range r.Changes {
+		switch c.Severity {
+		case SeverityBreaking:
+			r.Breaking++
+		case SeverityWarning:
+			r.Warnings++
+		default:
+			r.Info++
+		}
+	}
+}
+
+// Exceeds reports whether the report has a change at least as severe as threshold
+func (r *Report) Exceeds(threshold Severity) bool {
+	for _, c := range r.Changes {
+		if c.Severity.AtLeast(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the report as text grouped by severity
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Contract diff (%s): %s -> %s\n", r.Format, r.Old, r.New)
+	fmt.Fprintf(&b, "Breaking: %d, Warnings: %d, Info: %d\n", r.Breaking, r.Warnings, r.Info)
+	if len(r.Changes) == 0 {
+		b.WriteString("\nNo changes\n")
+		return b.String()
+	}
+
+	var current Severity
+	for _, c := range r.Changes {
+		if c.Severity != current {
+			current = c.Severity
+			fmt.Fprintf(&b, "\n%s:\n", strings.ToUpper(string(current)))
+		}
+		fmt.Fprintf(&b, "  [%s] %s: %s", c.Kind, c.Location, c.Message)
+		if c.File != "" && c.Line > 0 {
+			fmt.Fprintf(&b, " (%s:%d)", c.File, c.Line)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
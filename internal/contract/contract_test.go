@@ -0,0 +1,180 @@
+package contract_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+	"github.com/zeromicro/mcp-zero/internal/contract"
+	"github.com/zeromicro/mcp-zero/internal/protospec"
+)
+
+// changeSet indexes a report as "severity kind location" lines
+func changeSet(report *contract.Report) map[string]bool {
+	set := make(map[string]bool)
+	for _, c := range report.Changes {
+		set[string(c.Severity)+" "+c.Kind+" "+c.Location] = true
+	}
+	return set
+}
+
+func checkChanges(t *testing.T, report *contract.Report, want []string) {
+	t.Helper()
+	got := changeSet(report)
+	for _, w := range want {
+		if !got[w] {
+			t.Errorf("missing change %q", w)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d changes, want %d:\n%s", len(got), len(want), report)
+	}
+}
+
+func TestDiffAPI(t *testing.T) {
+	old, err := apispec.Load("testdata/old.api", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := apispec.Load("testdata/new.api", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := contract.DiffAPI(old, current)
+	checkChanges(t, report, []string{
+		"breaking route_removed DELETE /api/users/:id",
+		"breaking method_changed GET /api/users",
+		"breaking path_changed GET /api/users",
+		"breaking jwt_changed POST /api/users",
+		"breaking jwt_changed GET /api/users/:id",
+		"breaking jwt_changed GET /api/users",
+		"breaking jwt_changed GET /api/ping",
+		"breaking field_retyped Base.Id",
+		"breaking json_tag_changed User.Name",
+		"breaking field_removed User.Nickname",
+		"breaking field_now_required CreateUserReq.Email",
+		"breaking required_field_added CreateUserReq.Phone",
+		"warning field_now_optional User.Age",
+		"warning type_removed Legacy",
+		"info field_added CreateUserReq.Bio",
+		"info handler_renamed GET /api/ping",
+		"info route_added GET /api/stats",
+	})
+	if report.Breaking != 12 || !report.Exceeds(contract.SeverityBreaking) {
+		t.Errorf("Breaking = %d, want 12", report.Breaking)
+	}
+	if report.Changes[0].Severity != contract.SeverityBreaking || report.Changes[len(report.Changes)-1].Severity != contract.SeverityInfo {
+		t.Error("changes should be ordered by severity")
+	}
+
+	for _, c := range report.Changes {
+		if c.Kind == contract.JSONTagChanged && (c.Old != "name" || c.New != "full_name" || c.Line != 20) {
+			t.Errorf("unexpected json tag change %+v", c)
+		}
+	}
+}
+
+func TestDiffAPIUnchanged(t *testing.T) {
+	spec, err := apispec.Load("testdata/old.api", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := contract.DiffAPI(spec, spec)
+	if len(report.Changes) != 0 || report.Exceeds(contract.SeverityInfo) {
+		t.Errorf("expected no changes, got:\n%s", report)
+	}
+}
+
+func TestDiffProto(t *testing.T) {
+	old, err := protospec.Load("testdata/old.proto", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := protospec.Load("testdata/new.proto", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := contract.DiffProto(old, current)
+	checkChanges(t, report, []string{
+		"breaking service_removed Admin",
+		"breaking rpc_removed UserService.DeleteUser",
+		"breaking streaming_changed UserService.Watch",
+		"breaking field_retyped GetUserRequest.id",
+		"breaking field_number_reused User.nick_name",
+		"breaking field_label_changed User.tags",
+		"breaking json_tag_changed User.email",
+		"breaking field_removed User.phone",
+		"breaking enum_value_removed Status.STATUS_BANNED",
+		"warning field_renamed User.name",
+		"warning field_removed_reserved User.legacy",
+		"warning enum_value_renamed Status.STATUS_ACTIVE",
+		"info rpc_added UserService.CreateUser",
+		"info field_added User.avatar",
+	})
+}
+
+func TestCheckout(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("api/types/user.api", "syntax = \"v1\"\n\ntype User {\n\tName string `json:\"name\"`\n}\n")
+	write("api/main.api", "syntax = \"v1\"\n\nimport \"types/user.api\"\n\nservice users-api {\n\t@handler GetUser\n\tget /user returns (User)\n}\n")
+	run("init", "-q")
+	run("add", "-A")
+	run("commit", "-q", "-m", "initial")
+
+	write("api/types/user.api", "syntax = \"v1\"\n\ntype User {\n\tName string `json:\"username\"`\n}\n")
+
+	rev, err := contract.Checkout(context.Background(), filepath.Join(dir, "api", "main.api"), "HEAD", nil)
+	if err != nil {
+		t.Fatalf("Checkout() failed: %v", err)
+	}
+	defer rev.Close()
+
+	old, err := apispec.Load(rev.Path, nil)
+	if err != nil {
+		t.Fatalf("failed to load checkout: %v", err)
+	}
+	current, err := apispec.Load(filepath.Join(dir, "api", "main.api"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := contract.DiffAPI(old, current)
+	rev.Relabel(report)
+
+	checkChanges(t, report, []string{"breaking json_tag_changed User.Name"})
+	if report.Old != "HEAD:api/main.api" {
+		t.Errorf("Old = %q, want HEAD:api/main.api", report.Old)
+	}
+	if !strings.HasSuffix(report.Changes[0].File, filepath.Join("api", "types", "user.api")) {
+		t.Errorf("File = %q, want the working tree file", report.Changes[0].File)
+	}
+
+	if _, err := contract.Checkout(context.Background(), filepath.Join(dir, "api", "missing.api"), "HEAD", nil); err == nil {
+		t.Error("expected an error for a file that is not in the revision")
+	}
+}
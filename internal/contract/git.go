@@ -0,0 +1,174 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+	"github.com/zeromicro/mcp-zero/internal/protospec"
+)
+
+// Revision is a contract file and its imports checked out from git into a temporary directory
+type Revision struct {
+	Rev  string
+	Path string // checked out copy of the requested file
+	Dir  string // temporary directory mirroring the repository layout
+	Root string // repository root
+
+	// IncludePaths are the proto include paths mapped into the checkout
+	IncludePaths []string
+}
+
+// Label returns rev:path for a file in the checkout, or the path unchanged
+func (r *Revision) Label(path string) string {
+	rel, err := filepath.Rel(r.Dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return r.Rev + ":" + filepath.ToSlash(rel)
+}
+
+// Relabel rewrites checkout paths in a report to rev:path
+func (r *Revision) Relabel(report *Report) {
+	report.Old = r.Label(report.Old)
+	for i := range report.Changes {
+		report.Changes[i].File = r.Label(report.Changes[i].File)
+	}
+}
+
+// Close removes the checkout
+func (r *Revision) Close() error {
+	return os.RemoveAll(r.Dir)
+}
+
+// Checkout reads path as of rev with git show, together with the files it imports
+// .api imports are resolved relative to the importing file; .proto imports against
+// includePaths inside the repository, or the directory of path when there are none
+func Checkout(ctx context.Context, path, rev string, includePaths []string) (*Revision, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(filepath.Dir(absPath)); err == nil {
+		absPath = filepath.Join(resolved, filepath.Base(absPath))
+	}
+
+	out, err := git(ctx, filepath.Dir(absPath), "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("%s is not inside a git repository: %w", path, err)
+	}
+	root := strings.TrimSpace(string(out))
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(root, absPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("%s is outside the repository %s", path, root)
+	}
+
+	dir, err := os.MkdirTemp("", "mcp-zero-contract-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	r := &Revision{Rev: rev, Path: filepath.Join(dir, rel), Dir: dir, Root: root}
+
+	var includes []string
+	for _, include := range includePaths {
+		abs, err := filepath.Abs(include)
+		if err != nil {
+			continue
+		}
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = resolved
+		}
+		if relInclude, err := filepath.Rel(root, abs); err == nil && !strings.HasPrefix(relInclude, "..") {
+			includes = append(includes, relInclude)
+		}
+	}
+	if len(includes) == 0 {
+		includes = []string{filepath.Dir(rel)}
+	}
+	for _, include := range includes {
+		r.IncludePaths = append(r.IncludePaths, filepath.Join(dir, include))
+	}
+
+	isProto := strings.EqualFold(filepath.Ext(rel), ".proto")
+	seen := make(map[string]bool)
+	var fetch func(rel string, required bool) error
+	fetch = func(rel string, required bool) error {
+		rel = filepath.Clean(rel)
+		if seen[rel] || strings.HasPrefix(rel, "..") {
+			return nil
+		}
+		seen[rel] = true
+
+		src, err := git(ctx, root, "show", rev+":"+filepath.ToSlash(rel))
+		if err != nil {
+			if required {
+				return fmt.Errorf("failed to read %s at %s: %w", filepath.ToSlash(rel), rev, err)
+			}
+			// Missing imports are reported by the parser
+			return nil
+		}
+		target := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, src, 0644); err != nil {
+			return err
+		}
+
+		var imports []string
+		if isProto {
+			if file, err := protospec.Parse(target, src); err == nil {
+				for _, imp := range file.Imports {
+					for _, include := range r.IncludePaths {
+						imports = append(imports, filepath.Join(include, imp.Path))
+					}
+				}
+			}
+		} else if file, err := apispec.Parse(target, src); err == nil {
+			for _, imp := range file.Imports {
+				if !filepath.IsAbs(imp.Path) {
+					imports = append(imports, filepath.Join(filepath.Dir(target), imp.Path))
+				}
+			}
+		}
+		for _, imp := range imports {
+			impRel, err := filepath.Rel(dir, imp)
+			if err != nil {
+				continue
+			}
+			if err := fetch(impRel, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := fetch(rel, true); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s", msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,414 @@
+package contract
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/protospec"
+)
+
+// protoDiff compares two loaded .proto specs
+type protoDiff struct {
+	report   *Report
+	old, new *protospec.Spec
+}
+
+// DiffProto compares two versions of a .proto contract
+// Messages, enums and services are matched by name relative to the root package,
+// fields and enum values by number
+func DiffProto(old, new *protospec.Spec) *Report {
+	d := &protoDiff{
+		report: &Report{Format: "proto", Old: old.Root.Path, New: new.Root.Path},
+		old:    old,
+		new:    new,
+	}
+
+	if old.Root.Package != new.Root.Package {
+		d.report.add(Change{
+			Severity: SeverityBreaking, Kind: PackageChanged, Location: "package",
+			Message: fmt.Sprintf("package changed from %q to %q; every gRPC method path changes", old.Root.Package, new.Root.Package),
+			Old:     old.Root.Package, New: new.Root.Package,
+			File: new.Root.Path, Line: 1,
+		})
+	}
+
+	d.diffServices()
+	d.diffMessages()
+	d.diffEnums()
+	d.report.finish()
+	return d.report
+}
+
+// relative strips the root package from a full name
+func relative(spec *protospec.Spec, fullName string) string {
+	fullName = strings.TrimPrefix(fullName, ".")
+	if spec.Root.Package != "" {
+		return strings.TrimPrefix(fullName, spec.Root.Package+".")
+	}
+	return fullName
+}
+
+// typeRef normalizes a message or enum reference so both versions compare equal
+func typeRef(spec *protospec.Spec, scope, name string) string {
+	if strings.HasPrefix(name, ".") {
+		return relative(spec, name)
+	}
+	// Search from the innermost scope outwards, as protoc does
+	for s := scope; ; {
+		candidate := name
+		if s != "" {
+			candidate = s + "." + name
+		}
+		if spec.Message(candidate) != nil || findEnum(spec, candidate) != nil {
+			return relative(spec, candidate)
+		}
+		if s == "" {
+			break
+		}
+		if i := strings.LastIndex(s, "."); i >= 0 {
+			s = s[:i]
+		} else {
+			s = ""
+		}
+	}
+	return name
+}
+
+func findEnum(spec *protospec.Spec, fullName string) *protospec.Enum {
+	for _, e := range spec.Enums() {
+		if e.FullName == fullName {
+			return e
+		}
+	}
+	return nil
+}
+
+func (d *protoDiff) diffServices() {
+	type serviceRef struct {
+		svc  *protospec.Service
+		file *protospec.File
+	}
+	services := func(spec *protospec.Spec) map[string]serviceRef {
+		byName := make(map[string]serviceRef)
+		for _, f := range spec.Files {
+			for _, svc := range f.Services {
+				byName[svc.Name] = serviceRef{svc, f}
+			}
+		}
+		return byName
+	}
+	oldServices, newServices := services(d.old), services(d.new)
+
+	for _, f := range d.old.Files {
+		for _, oldSvc := range f.Services {
+			current, ok := newServices[oldSvc.Name]
+			if !ok {
+				d.report.add(Change{
+					Severity: SeverityBreaking, Kind: ServiceRemoved, Location: oldSvc.Name,
+					Message: fmt.Sprintf("service %s and its %d rpc methods were removed", oldSvc.Name, len(oldSvc.Methods)),
+					File:    f.Path, Line: oldSvc.Pos.Line,
+				})
+				continue
+			}
+			for _, m := range oldSvc.Methods {
+				location := oldSvc.Name + "." + m.Name
+				newMethod := current.svc.Method(m.Name)
+				if newMethod == nil {
+					d.report.add(Change{
+						Severity: SeverityBreaking, Kind: RPCRemoved, Location: location,
+						Message: fmt.Sprintf("rpc %s was removed", m.Name),
+						File:    f.Path, Line: m.Pos.Line,
+					})
+					continue
+				}
+				d.diffMethod(location, f, m, current.file, newMethod)
+			}
+		}
+	}
+
+	for _, f := range d.new.Files {
+		for _, svc := range f.Services {
+			old, ok := oldServices[svc.Name]
+			for _, m := range svc.Methods {
+				if ok && old.svc.Method(m.Name) != nil {
+					continue
+				}
+				d.report.add(Change{
+					Severity: SeverityInfo, Kind: RPCAdded, Location: svc.Name + "." + m.Name,
+					Message: fmt.Sprintf("rpc %s was added", m.Name),
+					File:    f.Path, Line: m.Pos.Line,
+				})
+			}
+		}
+	}
+}
+
+func (d *protoDiff) diffMethod(location string, oldFile *protospec.File, old *protospec.Method, newFile *protospec.File, current *protospec.Method) {
+	change := func(kind, message, oldValue, newValue string) {
+		d.report.add(Change{Severity: SeverityBreaking, Kind: kind, Location: location, Message: message, Old: oldValue, New: newValue, File: newFile.Path, Line: current.Pos.Line})
+	}
+
+	oldReq, newReq := typeRef(d.old, oldFile.Package, old.Request), typeRef(d.new, newFile.Package, current.Request)
+	if oldReq != newReq {
+		change(RPCSignatureChanged, fmt.Sprintf("request type changed from %s to %s", oldReq, newReq), oldReq, newReq)
+	}
+	oldResp, newResp := typeRef(d.old, oldFile.Package, old.Response), typeRef(d.new, newFile.Package, current.Response)
+	if oldResp != newResp {
+		change(RPCSignatureChanged, fmt.Sprintf("response type changed from %s to %s", oldResp, newResp), oldResp, newResp)
+	}
+	if old.ClientStreaming != current.ClientStreaming || old.ServerStreaming != current.ServerStreaming {
+		change(StreamingChanged, fmt.Sprintf("streaming changed from %s to %s", streamKind(old), streamKind(current)), streamKind(old), streamKind(current))
+	}
+}
+
+func streamKind(m *protospec.Method) string {
+	switch {
+	case m.ClientStreaming && m.ServerStreaming:
+		return "bidirectional streaming"
+	case m.ClientStreaming:
+		return "client streaming"
+	case m.ServerStreaming:
+		return "server streaming"
+	}
+	return "unary"
+}
+
+func (d *protoDiff) diffMessages() {
+	newMessages := make(map[string]*protospec.Message)
+	for _, m := range d.new.Messages() {
+		newMessages[relative(d.new, m.FullName)] = m
+	}
+
+	for _, old := range d.old.Messages() {
+		name := relative(d.old, old.FullName)
+		current, ok := newMessages[name]
+		if !ok {
+			d.report.add(Change{
+				Severity: SeverityWarning, Kind: MessageRemoved, Location: name,
+				Message: fmt.Sprintf("message %s was removed", name),
+				File:    filePath(d.old.FileOf(old)), Line: old.Pos.Line,
+			})
+			continue
+		}
+		d.diffMessage(name, old, current)
+	}
+}
+
+func (d *protoDiff) diffMessage(name string, old, current *protospec.Message) {
+	oldFile, newFile := d.old.FileOf(old), d.new.FileOf(current)
+	byNumber := make(map[int]*protospec.Field)
+	for _, f := range current.Fields {
+		byNumber[f.Number] = f
+	}
+	oldNumbers := make(map[int]bool)
+
+	for _, oldField := range old.Fields {
+		oldNumbers[oldField.Number] = true
+		location := name + "." + oldField.Name
+		newField := byNumber[oldField.Number]
+		if newField == nil {
+			if current.IsReserved(oldField.Number) {
+				d.report.add(Change{
+					Severity: SeverityWarning, Kind: FieldRemovedReserved, Location: location,
+					Message: fmt.Sprintf("field %s = %d was removed and its number reserved", oldField.Name, oldField.Number),
+					File:    filePath(newFile), Line: current.Pos.Line,
+				})
+			} else {
+				d.report.add(Change{
+					Severity: SeverityBreaking, Kind: FieldRemoved, Location: location,
+					Message: fmt.Sprintf("field %s = %d was removed without reserving its number", oldField.Name, oldField.Number),
+					File:    filePath(oldFile), Line: oldField.Pos.Line,
+				})
+			}
+			continue
+		}
+		d.diffProtoField(location, old, oldField, newFile, current, newField)
+	}
+
+	for _, f := range current.Fields {
+		if oldNumbers[f.Number] {
+			continue
+		}
+		location := name + "." + f.Name
+		change := Change{File: filePath(newFile), Line: f.Pos.Line, Location: location}
+		switch {
+		case old.IsReserved(f.Number):
+			change.Severity, change.Kind = SeverityBreaking, FieldNumberReused
+			change.Message = fmt.Sprintf("field %s reuses reserved number %d", f.Name, f.Number)
+		case contains(old.ReservedNames, f.Name):
+			change.Severity, change.Kind = SeverityBreaking, FieldNumberReused
+			change.Message = fmt.Sprintf("field %s reuses a reserved name", f.Name)
+		case f.Label == "required":
+			change.Severity, change.Kind = SeverityBreaking, RequiredFieldAdded
+			change.Message = fmt.Sprintf("required field %s = %d was added", f.Name, f.Number)
+		default:
+			change.Severity, change.Kind = SeverityInfo, FieldAdded
+			change.Message = fmt.Sprintf("field %s = %d was added", f.Name, f.Number)
+		}
+		d.report.add(change)
+	}
+}
+
+func (d *protoDiff) diffProtoField(location string, oldMsg *protospec.Message, old *protospec.Field, newFile *protospec.File, newMsg *protospec.Message, current *protospec.Field) {
+	change := func(severity Severity, kind, message, oldValue, newValue string) {
+		d.report.add(Change{Severity: severity, Kind: kind, Location: location, Message: message, Old: oldValue, New: newValue, File: filePath(newFile), Line: current.Pos.Line})
+	}
+
+	oldType := d.fieldType(d.old, oldMsg, old)
+	newType := d.fieldType(d.new, newMsg, current)
+
+	if old.Name != current.Name {
+		if oldType != newType {
+			change(SeverityBreaking, FieldNumberReused,
+				fmt.Sprintf("field number %d was reused: %s %s is now %s %s", old.Number, oldType, old.Name, newType, current.Name),
+				fmt.Sprintf("%s %s", oldType, old.Name), fmt.Sprintf("%s %s", newType, current.Name))
+			return
+		}
+		change(SeverityWarning, FieldRenamed, fmt.Sprintf("field renamed from %s to %s; JSON clients and generated code break", old.Name, current.Name), old.Name, current.Name)
+	}
+
+	if oldType != newType {
+		change(SeverityBreaking, FieldRetyped, fmt.Sprintf("type changed from %s to %s", oldType, newType), oldType, newType)
+	}
+	if old.IsRepeated() != current.IsRepeated() {
+		change(SeverityBreaking, FieldLabelChanged, fmt.Sprintf("label changed from %s to %s", labelOf(old), labelOf(current)), labelOf(old), labelOf(current))
+	} else if old.Label != "required" && current.Label == "required" {
+		change(SeverityBreaking, FieldNowRequired, "field is now required", labelOf(old), labelOf(current))
+	}
+	if oldJSON, newJSON := jsonName(old), jsonName(current); old.Name == current.Name && oldJSON != newJSON {
+		change(SeverityBreaking, JSONTagChanged, fmt.Sprintf("json name changed from %q to %q", oldJSON, newJSON), oldJSON, newJSON)
+	}
+	if old.Oneof != current.Oneof {
+		change(SeverityWarning, OneofChanged, fmt.Sprintf("oneof changed from %s to %s", orNone(old.Oneof), orNone(current.Oneof)), old.Oneof, current.Oneof)
+	}
+}
+
+// fieldType returns the normalized type of a field, including map keys
+func (d *protoDiff) fieldType(spec *protospec.Spec, msg *protospec.Message, f *protospec.Field) string {
+	value := f.Type
+	if !isScalar(value) {
+		value = typeRef(spec, msg.FullName, value)
+	}
+	if f.IsMap() {
+		return fmt.Sprintf("map<%s, %s>", f.KeyType, value)
+	}
+	return value
+}
+
+func (d *protoDiff) diffEnums() {
+	newEnums := make(map[string]*protospec.Enum)
+	for _, e := range d.new.Enums() {
+		newEnums[relative(d.new, e.FullName)] = e
+	}
+
+	for _, old := range d.old.Enums() {
+		name := relative(d.old, old.FullName)
+		current, ok := newEnums[name]
+		if !ok {
+			d.report.add(Change{
+				Severity: SeverityWarning, Kind: EnumRemoved, Location: name,
+				Message: fmt.Sprintf("enum %s was removed", name),
+				File:    enumFile(d.old, old), Line: old.Pos.Line,
+			})
+			continue
+		}
+
+		file := enumFile(d.new, current)
+		byNumber := make(map[int]*protospec.EnumValue)
+		byName := make(map[string]*protospec.EnumValue)
+		for _, v := range current.Values {
+			byNumber[v.Number] = v
+			byName[v.Name] = v
+		}
+		for _, v := range old.Values {
+			location := name + "." + v.Name
+			switch moved, same := byName[v.Name], byNumber[v.Number]; {
+			case moved != nil && moved.Number != v.Number:
+				d.report.add(Change{
+					Severity: SeverityBreaking, Kind: EnumValueRenumbered, Location: location,
+					Message: fmt.Sprintf("value %s changed from %d to %d", v.Name, v.Number, moved.Number),
+					Old:     fmt.Sprint(v.Number), New: fmt.Sprint(moved.Number),
+					File: file, Line: moved.Pos.Line,
+				})
+			case moved == nil && same != nil:
+				d.report.add(Change{
+					Severity: SeverityWarning, Kind: EnumValueRenamed, Location: location,
+					Message: fmt.Sprintf("value %d renamed from %s to %s; JSON clients break", v.Number, v.Name, same.Name),
+					Old:     v.Name, New: same.Name,
+					File: file, Line: same.Pos.Line,
+				})
+			case moved == nil:
+				d.report.add(Change{
+					Severity: SeverityBreaking, Kind: EnumValueRemoved, Location: location,
+					Message: fmt.Sprintf("value %s = %d was removed", v.Name, v.Number),
+					File:    enumFile(d.old, old), Line: v.Pos.Line,
+				})
+			}
+		}
+	}
+}
+
+func enumFile(spec *protospec.Spec, enum *protospec.Enum) string {
+	for _, f := range spec.Files {
+		for _, e := range f.Enums {
+			if e == enum {
+				return f.Path
+			}
+		}
+	}
+	for _, m := range spec.Messages() {
+		for _, e := range m.Enums {
+			if e == enum {
+				return filePath(spec.FileOf(m))
+			}
+		}
+	}
+	return ""
+}
+
+var scalarTypes = map[string]bool{
+	"double": true, "float": true, "int32": true, "int64": true, "uint32": true, "uint64": true,
+	"sint32": true, "sint64": true, "fixed32": true, "fixed64": true, "sfixed32": true, "sfixed64": true,
+	"bool": true, "string": true, "bytes": true,
+}
+
+func isScalar(typ string) bool {
+	return scalarTypes[typ]
+}
+
+func labelOf(f *protospec.Field) string {
+	if f.Label == "" {
+		return "singular"
+	}
+	return f.Label
+}
+
+// jsonName returns the proto3 JSON name of a field
+func jsonName(f *protospec.Field) string {
+	if name := strings.Trim(f.Option("json_name"), `"`); name != "" {
+		return name
+	}
+	parts := strings.Split(f.Name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func filePath(f *protospec.File) string {
+	if f == nil {
+		return ""
+	}
+	return f.Path
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
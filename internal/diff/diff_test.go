@@ -0,0 +1,46 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/diff"
+)
+
+func TestUnified(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+	b := "one\n2\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n"
+
+	got := diff.Unified("a.api", "b.api", []byte(a), []byte(b), 2)
+	want := `--- a.api
++++ b.api
+@@ -1,4 +1,4 @@
+ one
+-two
++2
+ three
+ four
+@@ -9,2 +9,3 @@
+ nine
+ ten
++eleven
+`
+	if got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := diff.Unified("a", "b", []byte(a), []byte(a), 3); got != "" {
+		t.Errorf("expected no diff for equal input, got %q", got)
+	}
+}
+
+func TestUnifiedEdges(t *testing.T) {
+	got := diff.Unified("a", "b", nil, []byte("new\n"), 3)
+	if want := "--- a\n+++ b\n@@ -0,0 +1 @@\n+new\n"; got != want {
+		t.Errorf("created file diff = %q, want %q", got, want)
+	}
+
+	got = diff.Unified("a", "b", []byte("x\n"), []byte("x"), 3)
+	if want := "--- a\n+++ b\n@@ -1 +1 @@\n-x\n+x\n\\ No newline at end of file\n"; got != want {
+		t.Errorf("missing newline diff = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,416 @@
+// Package doctor checks the toolchain mcp-zero generates and builds code with
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+	"github.com/zeromicro/mcp-zero/internal/goctl"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn" // generation works, but may be slow or fail in some cases
+	StatusFail Status = "fail" // some tools will fail until it is fixed
+)
+
+// Check is one row of the doctor report
+type Check struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"` // command or action that resolves a warning or failure
+}
+
+// Report is the outcome of every check
+type Report struct {
+	Status   Status  `json:"status"` // the worst status of any check
+	Checks   []Check `json:"checks"`
+	Passed   int     `json:"passed"`
+	Warnings int     `json:"warnings"`
+	Failures int     `json:"failures"`
+	Goctl    *Goctl  `json:"goctl,omitempty"` // nil when goctl was not found
+}
+
+// Goctl is the compatibility of the installed goctl release with mcp-zero
+type Goctl struct {
+	Path           string         `json:"goctl_path"`
+	Version        string         `json:"goctl_version"`
+	Status         string         `json:"status"` // ok, unsupported, untested or unknown
+	MinimumVersion string         `json:"minimum_version"`
+	Features       []GoctlFeature `json:"features"`
+	Hints          []string       `json:"hints"`
+}
+
+// GoctlFeature is a gated goctl feature and whether the installed release has it
+type GoctlFeature struct {
+	Name      string `json:"name"`
+	Since     string `json:"since"`
+	Supported bool   `json:"supported"`
+}
+
+// Options configure a doctor run
+type Options struct {
+	Workspace string             // directory generated code is written to
+	Deps      fixer.Dependencies // how modules are resolved
+}
+
+// MinimumGoVersion is the oldest Go release mcp-zero supports
+const MinimumGoVersion = "1.19"
+
+// protocPlugins are the plugins goctl rpc protoc runs, with the command that installs each
+var protocPlugins = []struct{ name, install string }{
+	{"protoc-gen-go", "go install google.golang.org/protobuf/cmd/protoc-gen-go@latest"},
+	{"protoc-gen-go-grpc", "go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@latest"},
+}
+
+// goEnv holds the go env values the checks need
+type goEnv struct {
+	GOVERSION  string
+	GOPATH     string
+	GOMODCACHE string
+	GOPROXY    string
+}
+
+// Run checks the toolchain in order: go, goctl, protoc and its plugins, the module cache,
+// GOPROXY, the workspace and go-zero in the module cache
+func Run(ctx context.Context, opts Options) *Report {
+	var checks []Check
+	env, goCheck := checkGo(ctx)
+	compat, goctlCheck := CheckGoctl()
+	checks = append(checks, goCheck, goctlCheck)
+	checks = append(checks, checkTool("protoc", "goctl env check --install --verbose --force  (or download it from https://github.com/protocolbuffers/protobuf/releases)"))
+	for _, plugin := range protocPlugins {
+		checks = append(checks, checkTool(plugin.name, plugin.install))
+	}
+	if env != nil {
+		checks = append(checks, checkModuleCache(env), checkGoproxy(env, opts.Deps))
+	}
+	checks = append(checks, checkWorkspace(opts.Workspace))
+	if env != nil {
+		checks = append(checks, checkGoZero(ctx, opts.Deps))
+	}
+
+	report := &Report{Status: StatusPass, Checks: checks, Goctl: compat}
+	for _, check := range checks {
+		switch check.Status {
+		case StatusPass:
+			report.Passed++
+		case StatusWarn:
+			report.Warnings++
+			if report.Status == StatusPass {
+				report.Status = StatusWarn
+			}
+		case StatusFail:
+			report.Failures++
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+// checkGo finds go and reads its environment; env is nil when go cannot run
+func checkGo(ctx context.Context) (*goEnv, Check) {
+	check := Check{Name: "go"}
+	path, err := exec.LookPath("go")
+	if err != nil {
+		check.Status, check.Detail, check.Fix = StatusFail, "go not found in PATH", "Install Go from https://go.dev/dl/ and add its bin directory to PATH"
+		return nil, check
+	}
+	result := goctl.Run(ctx, "", 0, "go", "env", "-json", "GOVERSION", "GOPATH", "GOMODCACHE", "GOPROXY")
+	var env goEnv
+	if result.Error != nil || json.Unmarshal([]byte(result.Stdout), &env) != nil {
+		check.Status, check.Detail, check.Fix = StatusFail, fmt.Sprintf("%s env failed: %v %s", path, result.Error, strings.TrimSpace(result.Stderr)), "Reinstall Go from https://go.dev/dl/"
+		return nil, check
+	}
+
+	check.Detail = fmt.Sprintf("%s at %s", env.GOVERSION, path)
+	if goOlderThan(env.GOVERSION, MinimumGoVersion) {
+		check.Status, check.Fix = StatusFail, "Install Go "+MinimumGoVersion+" or later from https://go.dev/dl/"
+		check.Detail += ", older than " + MinimumGoVersion
+		return &env, check
+	}
+	check.Status = StatusPass
+	return &env, check
+}
+
+var goVersionPattern = regexp.MustCompile(`^(?:go)?(\d+)\.(\d+)`)
+
+// goOlderThan reports whether a GOVERSION such as go1.22.3 is older than min such as 1.19;
+// development builds are never older
+func goOlderThan(version, min string) bool {
+	v, m := goVersionPattern.FindStringSubmatch(version), goVersionPattern.FindStringSubmatch(min)
+	if v == nil || m == nil {
+		return false
+	}
+	major, _ := strconv.Atoi(v[1])
+	minor, _ := strconv.Atoi(v[2])
+	minMajor, _ := strconv.Atoi(m[1])
+	minMinor, _ := strconv.Atoi(m[2])
+	return major < minMajor || (major == minMajor && minor < minMinor)
+}
+
+// CheckGoctl finds goctl like the generation tools do and checks its release and the
+// gated features it has; compat is nil when goctl is not found
+func CheckGoctl() (compat *Goctl, check Check) {
+	const install = "go install github.com/zeromicro/go-zero/tools/goctl@latest"
+	check = Check{Name: "goctl"}
+	path, err := goctl.DiscoverGoctl()
+	if err != nil {
+		check.Status, check.Detail, check.Fix = StatusFail, "goctl not found", install
+		return nil, check
+	}
+	version, err := goctl.DetectVersion(path)
+	compat = &Goctl{Path: path, Version: version.String(), Status: "ok", MinimumVersion: goctl.MinimumVersion.String(), Hints: []string{}}
+	check.Detail = fmt.Sprintf("%s at %s", version, path)
+	switch {
+	case err != nil:
+		compat.Status = "unknown"
+		compat.Hints = append(compat.Hints, fmt.Sprintf("Could not detect the goctl version (%v); features are not gated, so generation may fail with unsupported flags. Check that %s --version runs", err, path))
+		check.Status, check.Fix = StatusWarn, install
+		check.Detail += fmt.Sprintf(": %v", err)
+	case !version.AtLeast(goctl.MinimumVersion):
+		compat.Status = "unsupported"
+		compat.Hints = append(compat.Hints, fmt.Sprintf("goctl %s is older than %s, the oldest release mcp-zero supports; generation tools will refuse to run. %s", version, goctl.MinimumVersion, goctl.UpgradeHint))
+		check.Status, check.Fix = StatusFail, install
+		check.Detail += fmt.Sprintf(", older than %s", goctl.MinimumVersion)
+	case goctl.Untested(version):
+		compat.Status = "untested"
+		compat.Hints = append(compat.Hints, fmt.Sprintf("goctl %s is newer than the releases mcp-zero is tested with (%d.%d and older); generated layouts may differ", version, goctl.NewestTested.Major, goctl.NewestTested.Minor))
+		check.Status = StatusWarn
+		check.Detail += fmt.Sprintf(", newer than the releases mcp-zero is tested with (%d.%d)", goctl.NewestTested.Major, goctl.NewestTested.Minor)
+	default:
+		check.Status = StatusPass
+	}
+
+	// A supported release may still lack features added later
+	var missing []string
+	for _, feature := range goctl.Features {
+		supported := version.Supports(feature)
+		compat.Features = append(compat.Features, GoctlFeature{Name: feature.Name, Since: feature.Since.String(), Supported: supported})
+		if !supported && compat.Status == "ok" {
+			missing = append(missing, fmt.Sprintf("%s (%s)", feature.Name, feature.Since))
+			compat.Hints = append(compat.Hints, fmt.Sprintf("%s needs goctl %s or newer. %s", feature.Name, feature.Since, goctl.UpgradeHint))
+		}
+	}
+	if len(missing) > 0 {
+		check.Status, check.Fix = StatusWarn, install
+		check.Detail += ", lacks " + strings.Join(missing, ", ")
+	}
+	return compat, check
+}
+
+// checkTool finds protoc or a plugin with goctl's search strategies; goctl rpc protoc
+// only finds them in PATH, so one installed elsewhere is a warning
+func checkTool(name, install string) Check {
+	check := Check{Name: name}
+	path, err := goctl.DiscoverTool(name)
+	if err != nil {
+		check.Status, check.Detail, check.Fix = StatusFail, name+" not found; create_rpc_service and add_rpc_method need it", install
+		return check
+	}
+	check.Detail = path
+	if inPath, err := exec.LookPath(name); err != nil || !sameFile(inPath, path) {
+		check.Status = StatusWarn
+		check.Detail += ", but not in PATH, where goctl rpc protoc looks"
+		check.Fix = fmt.Sprintf("export PATH=\"$PATH:%s\"", filepath.Dir(path))
+		return check
+	}
+	check.Status = StatusPass
+	return check
+}
+
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// checkModuleCache checks that modules can be downloaded into GOMODCACHE
+func checkModuleCache(env *goEnv) Check {
+	check := Check{Name: "GOPATH/GOMODCACHE", Detail: fmt.Sprintf("GOPATH=%s GOMODCACHE=%s", env.GOPATH, env.GOMODCACHE)}
+	if env.GOMODCACHE == "" {
+		check.Status, check.Fix = StatusFail, "go env -w GOPATH=$HOME/go"
+		check.Detail += ", no module cache"
+		return check
+	}
+	if err := writable(env.GOMODCACHE); err != nil {
+		check.Status, check.Fix = StatusFail, "go env -w GOMODCACHE=$HOME/go/pkg/mod"
+		check.Detail += fmt.Sprintf(", not writable: %v", err)
+		return check
+	}
+	check.Status = StatusPass
+	return check
+}
+
+// checkGoproxy checks that the GOPROXY setting lets the dependency mode resolve modules
+func checkGoproxy(env *goEnv, deps fixer.Dependencies) Check {
+	check := Check{Name: "GOPROXY", Detail: "GOPROXY=" + env.GOPROXY}
+	switch deps.Mode {
+	case fixer.DepsOffline:
+		check.Status = StatusPass
+		if deps.ProxyDir != "" {
+			check.Detail += fmt.Sprintf(", replaced by file://%s in offline mode", deps.ProxyDir)
+		} else {
+			check.Detail += ", replaced by off in offline mode"
+		}
+		return check
+	case fixer.DepsVendor:
+		check.Status = StatusPass
+		check.Detail += ", unused in vendor mode"
+		return check
+	}
+
+	fix := "go env -w GOPROXY=https://proxy.golang.org,direct"
+	proxies := strings.FieldsFunc(env.GOPROXY, func(r rune) bool { return r == ',' || r == '|' })
+	switch {
+	case len(proxies) == 0 || proxies[0] == "off":
+		check.Status, check.Fix = StatusFail, fix+"  (or set dependency_mode to offline)"
+		check.Detail += ", module downloads are disabled"
+	case proxies[0] == "direct":
+		check.Status, check.Fix = StatusWarn, fix
+		check.Detail += ", modules are fetched from their version control hosts, which needs git and access to each host"
+	default:
+		check.Status = StatusPass
+	}
+	return check
+}
+
+// checkWorkspace checks that generated code can be written to the workspace
+func checkWorkspace(dir string) Check {
+	check := Check{Name: "workspace", Detail: dir}
+	info, err := os.Stat(dir)
+	switch {
+	case err != nil:
+		check.Status, check.Fix = StatusFail, fmt.Sprintf("mkdir -p %s", dir)
+		check.Detail += ", does not exist"
+	case !info.IsDir():
+		check.Status = StatusFail
+		check.Detail += ", not a directory"
+	default:
+		if err := writable(dir); err != nil {
+			check.Status, check.Fix = StatusFail, fmt.Sprintf("chmod u+w %s", dir)
+			check.Detail += fmt.Sprintf(", not writable: %v", err)
+		} else {
+			check.Status = StatusPass
+			check.Detail += ", writable"
+		}
+	}
+	return check
+}
+
+// checkGoZero looks for go-zero where the dependency mode resolves it without network access
+func checkGoZero(ctx context.Context, deps fixer.Dependencies) Check {
+	check := Check{Name: "go-zero module"}
+	if deps.Mode == fixer.DepsVendor {
+		check.Status, check.Detail = StatusPass, "taken from the vendor directory in vendor mode"
+		return check
+	}
+	versions, err := fixer.CachedVersions(ctx, fixer.GoZeroModule, deps)
+	if err != nil {
+		check.Status, check.Detail = StatusWarn, fmt.Sprintf("cannot read the module cache: %v", err)
+		return check
+	}
+	if len(versions) > 0 {
+		check.Status = StatusPass
+		check.Detail = fmt.Sprintf("%s@%s cached (%d versions)", fixer.GoZeroModule, versions[0], len(versions))
+		return check
+	}
+
+	check.Fix = "go mod download " + fixer.GoZeroModule + "@latest"
+	if deps.Mode == fixer.DepsOffline {
+		check.Status = StatusFail
+		check.Detail = "not in the module cache, and offline mode cannot download it"
+		check.Fix += "  (on a machine with network access, then copy $GOMODCACHE/cache/download)"
+		return check
+	}
+	check.Status = StatusWarn
+	check.Detail = "not in the module cache; the first generation downloads it"
+	return check
+}
+
+// writable checks that files can be created in dir or, when it does not exist yet, in its
+// nearest existing parent
+func writable(dir string) error {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return fmt.Errorf("no existing parent directory")
+		}
+		dir = parent
+	}
+	f, err := os.CreateTemp(dir, ".mcp-zero-doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// String renders the report as a table followed by the fixes
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Environment: %s (%d passed, %d warnings, %d failed)\n\n", r.Status, r.Passed, r.Warnings, r.Failures)
+	width := len("CHECK")
+	for _, check := range r.Checks {
+		width = max(width, len(check.Name))
+	}
+	fmt.Fprintf(&b, "%-6s %-*s %s\n", "STATUS", width, "CHECK", "DETAIL")
+	for _, check := range r.Checks {
+		fmt.Fprintf(&b, "%-6s %-*s %s\n", check.Status, width, check.Name, check.Detail)
+	}
+
+	if r.Goctl != nil {
+		fmt.Fprintf(&b, "\ngoctl compatibility (%s):\n", r.Goctl.Status)
+		b.WriteString(r.Goctl.String())
+	}
+
+	var fixes []Check
+	for _, check := range r.Checks {
+		if check.Fix != "" {
+			fixes = append(fixes, check)
+		}
+	}
+	if len(fixes) > 0 {
+		b.WriteString("\nFixes:\n")
+		for _, check := range fixes {
+			fmt.Fprintf(&b, "  %s: %s\n", check.Name, check.Fix)
+		}
+	}
+	return b.String()
+}
+
+// String renders the supported release and feature rows followed by the hints
+func (g *Goctl) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %s supported release (%s or newer)\n", checkMark(g.Status != "unsupported"), g.MinimumVersion)
+	for _, feature := range g.Features {
+		fmt.Fprintf(&b, "  %s %s (%s or newer)\n", checkMark(feature.Supported), feature.Name, feature.Since)
+	}
+	for _, hint := range g.Hints {
+		b.WriteString("  - " + hint + "\n")
+	}
+	return b.String()
+}
+
+// checkMark renders a pass or fail mark for a compatibility row
+func checkMark(ok bool) string {
+	if ok {
+		return "✓"
+	}
+	return "✗"
+}
@@ -0,0 +1,153 @@
+//go:build unix
+
+package doctor_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/doctor"
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+)
+
+// isolate runs the doctor against an empty GOPATH, module cache and PATH holding only go
+// and bin; it returns bin and the GOPATH
+func isolate(t *testing.T) (bin, gopath string) {
+	t.Helper()
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found in PATH")
+	}
+	bin = t.TempDir()
+	gopath = t.TempDir()
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+filepath.Dir(goPath))
+	// go writes telemetry to the config directory, possibly after the test ends, so
+	// keep it out of the temporary HOME
+	if config, err := os.UserConfigDir(); err == nil {
+		t.Setenv("XDG_CONFIG_HOME", config)
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GOPATH", gopath)
+	t.Setenv("GOMODCACHE", filepath.Join(gopath, "pkg", "mod"))
+	t.Setenv("GOPROXY", "https://proxy.golang.org,direct")
+	t.Setenv("GOCTL_PATH", "")
+	return bin, gopath
+}
+
+// writeExecutable writes a shell script to dir/name
+func writeExecutable(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func run(t *testing.T, opts doctor.Options) (*doctor.Report, map[string]doctor.Check) {
+	t.Helper()
+	if opts.Workspace == "" {
+		opts.Workspace = t.TempDir()
+	}
+	report := doctor.Run(context.Background(), opts)
+	checks := make(map[string]doctor.Check)
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+	return report, checks
+}
+
+func TestRunReportsMissingTools(t *testing.T) {
+	isolate(t)
+	report, checks := run(t, doctor.Options{})
+
+	if checks["go"].Status != doctor.StatusPass {
+		t.Errorf("go check = %+v", checks["go"])
+	}
+	for name, fix := range map[string]string{
+		"goctl":              "go install github.com/zeromicro/go-zero/tools/goctl@latest",
+		"protoc":             "goctl env check --install",
+		"protoc-gen-go":      "go install google.golang.org/protobuf/cmd/protoc-gen-go@latest",
+		"protoc-gen-go-grpc": "go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@latest",
+	} {
+		if check := checks[name]; check.Status != doctor.StatusFail || !strings.Contains(check.Fix, fix) {
+			t.Errorf("%s check = %+v, want a failure fixed by %q", name, check, fix)
+		}
+	}
+	if check := checks["go-zero module"]; check.Status != doctor.StatusWarn || check.Fix != "go mod download github.com/zeromicro/go-zero@latest" {
+		t.Errorf("go-zero check = %+v", check)
+	}
+	if report.Status != doctor.StatusFail || report.Failures != 4 {
+		t.Errorf("report status = %s with %d failures", report.Status, report.Failures)
+	}
+	text := report.String()
+	if !strings.Contains(text, "STATUS") || !strings.Contains(text, "Fixes:\n  goctl: go install") {
+		t.Errorf("unexpected table:\n%s", text)
+	}
+}
+
+func TestRunFindsToolsLikeGoctl(t *testing.T) {
+	bin, gopath := isolate(t)
+	writeExecutable(t, bin, "goctl", `echo "goctl version 1.2.0 linux/amd64"`)
+	writeExecutable(t, bin, "protoc", "exit 0")
+	writeExecutable(t, bin, "protoc-gen-go", "exit 0")
+	// Installed by go install, but GOPATH/bin is not in PATH
+	plugin := writeExecutable(t, filepath.Join(gopath, "bin"), "protoc-gen-go-grpc", "exit 0")
+
+	report, checks := run(t, doctor.Options{})
+	if check := checks["goctl"]; check.Status != doctor.StatusFail || !strings.Contains(check.Detail, "older than 1.3.0") {
+		t.Errorf("goctl check = %+v", check)
+	}
+	if report.Goctl == nil || report.Goctl.Status != "unsupported" || !strings.Contains(report.String(), "goctl compatibility (unsupported)") {
+		t.Errorf("goctl compatibility = %+v", report.Goctl)
+	}
+	for _, name := range []string{"protoc", "protoc-gen-go"} {
+		if checks[name].Status != doctor.StatusPass {
+			t.Errorf("%s check = %+v", name, checks[name])
+		}
+	}
+	check := checks["protoc-gen-go-grpc"]
+	if check.Status != doctor.StatusWarn || !strings.HasPrefix(check.Detail, plugin) || check.Fix != `export PATH="$PATH:`+filepath.Dir(plugin)+`"` {
+		t.Errorf("plugin outside PATH = %+v", check)
+	}
+}
+
+func TestRunChecksGoproxyForDependencyMode(t *testing.T) {
+	isolate(t)
+	for _, tc := range []struct {
+		goproxy string
+		deps    fixer.Dependencies
+		want    doctor.Status
+	}{
+		{"https://goproxy.cn,direct", fixer.Dependencies{}, doctor.StatusPass},
+		{"direct", fixer.Dependencies{}, doctor.StatusWarn},
+		{"off", fixer.Dependencies{}, doctor.StatusFail},
+		{"off", fixer.Dependencies{Mode: fixer.DepsOffline}, doctor.StatusPass},
+	} {
+		t.Setenv("GOPROXY", tc.goproxy)
+		_, checks := run(t, doctor.Options{Deps: tc.deps})
+		if check := checks["GOPROXY"]; check.Status != tc.want {
+			t.Errorf("GOPROXY=%s in mode %q = %+v, want %s", tc.goproxy, tc.deps.Mode, check, tc.want)
+		}
+	}
+}
+
+func TestRunChecksWorkspaceAndOfflineCache(t *testing.T) {
+	isolate(t)
+	missing := filepath.Join(t.TempDir(), "services")
+	_, checks := run(t, doctor.Options{Workspace: missing, Deps: fixer.Dependencies{Mode: fixer.DepsOffline}})
+
+	if check := checks["workspace"]; check.Status != doctor.StatusFail || check.Fix != "mkdir -p "+missing {
+		t.Errorf("workspace check = %+v", check)
+	}
+	if check := checks["go-zero module"]; check.Status != doctor.StatusFail || !strings.Contains(check.Detail, "offline") {
+		t.Errorf("go-zero check offline = %+v", check)
+	}
+}
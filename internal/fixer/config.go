@@ -1,71 +1,717 @@
 package fixer
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
+// ConfigEdit sets the value at a dotted key path such as Port, Prometheus.Port or Etcd.Hosts.0
+type ConfigEdit struct {
+	Path  string `json:"path"`
+	Value string `json:"value"` // parsed as YAML, so 8080 is an int and "8080" a string
+}
+
+// ConfigChange is an edit applied by EditConfig
+type ConfigChange struct {
+	Path  string `json:"path"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new"`
+	Added bool   `json:"added,omitempty"` // the key did not exist before
+}
+
+// ParseConfigEdit parses a path=value edit
+func ParseConfigEdit(s string) (ConfigEdit, error) {
+	path, value, ok := strings.Cut(s, "=")
+	path = strings.TrimSpace(path)
+	if !ok || path == "" {
+		return ConfigEdit{}, fmt.Errorf("invalid edit %q, expected path=value", s)
+	}
+	return ConfigEdit{Path: path, Value: strings.TrimSpace(value)}, nil
+}
+
 // UpdateConfigFile updates configuration files with correct settings
+// Only the top-level Name and Port keys are touched, in the config file matching serviceName
 func UpdateConfigFile(projectPath string, serviceName string, port int) error {
-	// Find config file in etc/ directory
-	etcDir := filepath.Join(projectPath, "etc")
-	configFiles, err := filepath.Glob(filepath.Join(etcDir, "*.yaml"))
+	configFile, err := FindConfigFile(projectPath, serviceName)
 	if err != nil {
-		return fmt.Errorf("failed to search for config files: %w", err)
+		return err
 	}
-
-	if len(configFiles) == 0 {
+	if configFile == "" {
 		// No config file found, skip
 		return nil
 	}
 
-	// Update first config file found
-	configFile := configFiles[0]
 	content, err := os.ReadFile(configFile)
 	if err != nil {
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	configContent := string(content)
-
-	// Update service name if needed
-	if strings.Contains(configContent, "Name:") && !strings.Contains(configContent, fmt.Sprintf("Name: %s", serviceName)) {
-		configContent = updateYAMLField(configContent, "Name", serviceName)
+	var edits []ConfigEdit
+	// Update service name if needed; goctl's demo-api or user.rpc already name the service
+	if name, ok := ConfigValue(content, "Name"); ok && !strings.HasPrefix(name, serviceName) {
+		edits = append(edits, ConfigEdit{Path: "Name", Value: serviceName})
 	}
-
 	// Update port if specified and field exists
-	if port > 0 && strings.Contains(configContent, "Port:") {
-		configContent = updateYAMLField(configContent, "Port", fmt.Sprintf("%d", port))
+	if _, ok := ConfigValue(content, "Port"); ok && port > 0 {
+		edits = append(edits, ConfigEdit{Path: "Port", Value: strconv.Itoa(port)})
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+
+	updated, _, err := EditConfig(content, edits)
+	if err != nil {
+		return fmt.Errorf("failed to update config file: %w", err)
 	}
 
 	// Write updated config
-	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+	if err := os.WriteFile(configFile, updated, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
-// updateYAMLField updates a field in YAML content
-func updateYAMLField(content string, fieldName string, newValue string) string {
-	lines := strings.Split(content, "\n")
-	for i, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, fieldName+":") {
-			// Preserve indentation
-			indent := ""
-			for _, ch := range line {
-				if ch == ' ' || ch == '\t' {
-					indent += string(ch)
-				} else {
-					break
+// FindConfigFile returns the etc/*.yaml file of a service, or "" when etc/ has none
+// The only file present wins, then a file named after the service (demo.yaml, demo-api.yaml,
+// demo-rpc.yaml), then one whose Name is the service (demo, demo-api, demo.rpc)
+func FindConfigFile(projectPath, serviceName string) (string, error) {
+	var configFiles []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(projectPath, "etc", pattern))
+		if err != nil {
+			return "", fmt.Errorf("failed to search for config files: %w", err)
+		}
+		configFiles = append(configFiles, matches...)
+	}
+	sort.Strings(configFiles)
+	switch len(configFiles) {
+	case 0:
+		return "", nil
+	case 1:
+		return configFiles[0], nil
+	}
+
+	names := map[string]bool{}
+	for _, name := range []string{serviceName, strings.ReplaceAll(serviceName, "-", "")} {
+		names[name] = true
+		names[name+"-api"] = true
+		names[name+"-rpc"] = true
+		names[name+".rpc"] = true
+	}
+	for _, file := range configFiles {
+		stem := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if names[stem] {
+			return file, nil
+		}
+	}
+	for _, file := range configFiles {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if name, ok := ConfigValue(content, "Name"); ok && names[name] {
+			return file, nil
+		}
+	}
+
+	for i, file := range configFiles {
+		configFiles[i] = filepath.Base(file)
+	}
+	return "", fmt.Errorf("found %d config files in %s (%s), none named after service %q",
+		len(configFiles), filepath.Join(projectPath, "etc"), strings.Join(configFiles, ", "), serviceName)
+}
+
+// ConfigValue returns the scalar value at a dotted key path
+func ConfigValue(content []byte, path string) (string, bool) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil || len(doc.Content) == 0 {
+		return "", false
+	}
+	node := doc.Content[0]
+	for _, key := range strings.Split(path, ".") {
+		next := child(node, key)
+		if next == nil {
+			return "", false
+		}
+		node = next
+	}
+	if node.Kind != yaml.ScalarNode {
+		return "", false
+	}
+	return node.Value, true
+}
+
+// EditConfig applies edits to YAML content, keeping comments, blank lines and key order
+// Missing keys are appended to their mapping, creating intermediate mappings as needed.
+// Every edit is made in the text: a replaced value's source lines are rewritten in place,
+// so comments around it stay where they are. An edit that cannot be made that way is an
+// error rather than a rewrite of the whole file
+func EditConfig(content []byte, edits []ConfigEdit) ([]byte, []ConfigChange, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML config: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("config root is not a mapping")
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	var spans []textSpan
+	owners := make(map[*yaml.Node]int)        // span rewriting a replaced value or a flow mapping
+	sources := make(map[*yaml.Node]yaml.Node) // replaced values as they were in the source
+	ends := make(map[*yaml.Node]int)          // last source line of replaced values
+
+	var changes []ConfigChange
+	for _, edit := range edits {
+		value, err := parseConfigValue(edit.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid value for %s: %w", edit.Path, err)
+		}
+		keys := strings.Split(edit.Path, ".")
+		for _, key := range keys {
+			if key == "" {
+				return nil, nil, fmt.Errorf("invalid path %q", edit.Path)
+			}
+		}
+
+		nodes, keyNodes := walkPath(root, keys)
+		old, parent, err := setPath(root, keys, value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot set %s: %w", edit.Path, err)
+		}
+		change := ConfigChange{Path: edit.Path, New: renderNode(value), Added: old == nil}
+		var span textSpan
+		var owner *yaml.Node
+		var ok bool
+		if old == nil {
+			key := keyNodes[slices.Index(nodes, parent)]
+			if parent.Style&yaml.FlowStyle != 0 {
+				owner = parent
+				span, ok = flowSpan(lines, parent)
+			} else {
+				span, ok = insertionSpan(lines, parent, key, ends)
+			}
+		} else {
+			change.Old = renderNode(old)
+			owner = old
+			source, seen := sources[old]
+			if !seen {
+				source = *old
+				sources[old] = source
+				ends[old] = lastLine(old, ends)
+			}
+			span, ok = scalarSpan(lines, &source, value)
+			if !ok {
+				span, ok = valueSpan(lines, nodes[len(keys)-1], keyNodes[len(keys)], &source, value, ends[old])
+			}
+			keepComments(value, &source)
+			// The source position stays so later insertions still find the end of the mapping
+			*old = *value
+			old.Line, old.Column = source.Line, source.Column
+		}
+		if !ok {
+			return nil, nil, fmt.Errorf("cannot set %s without rewriting the rest of the file; edit it by hand", edit.Path)
+		}
+
+		if i, seen := owners[owner]; owner != nil && seen {
+			span.seq = spans[i].seq
+			spans[i] = span
+		} else {
+			for _, other := range spans {
+				if span.overlaps(other) {
+					return nil, nil, fmt.Errorf("cannot set %s: it overlaps an earlier edit in the same call", edit.Path)
 				}
 			}
-			lines[i] = fmt.Sprintf("%s%s: %s", indent, fieldName, newValue)
+			span.seq = len(spans)
+			if owner != nil {
+				owners[owner] = len(spans)
+			}
+			spans = append(spans, span)
+		}
+		changes = append(changes, change)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode YAML config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	// Text edits are only kept when they mean exactly what the edited node tree means
+	edited := applySpans(lines, spans)
+	if !sameYAML(edited, buf.Bytes()) {
+		return nil, nil, fmt.Errorf("cannot apply the edits without rewriting the rest of the file; edit it by hand")
+	}
+	return edited, changes, nil
+}
+
+// parseConfigValue parses an edit value as a single YAML node; empty means an empty string
+func parseConfigValue(value string) (*yaml.Node, error) {
+	if value == "" {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle}, nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) != 1 {
+		return nil, fmt.Errorf("expected a single value")
+	}
+	node := doc.Content[0]
+	node.Line, node.Column = 0, 0
+	return node, nil
+}
+
+// child returns the value of a mapping key or sequence index; nil when absent
+func child(node *yaml.Node, key string) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node.Content) {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// setPath points keys at value; it returns the replaced node, which the caller
+// overwrites, or nil and the existing mapping that received a missing key
+func setPath(node *yaml.Node, keys []string, value *yaml.Node) (old, parent *yaml.Node, err error) {
+	for i, key := range keys {
+		last := i == len(keys)-1
+		if next := child(node, key); next != nil {
+			if last {
+				return next, nil, nil
+			}
+			if next.Kind == yaml.ScalarNode && next.Tag == "!!null" {
+				// An empty "Prometheus:" becomes the mapping that receives the key
+				*next = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", HeadComment: next.HeadComment, LineComment: next.LineComment}
+			}
+			node = next
+			continue
+		}
+
+		switch node.Kind {
+		case yaml.MappingNode:
+		case yaml.SequenceNode:
+			return nil, nil, fmt.Errorf("%s has no element %s", strings.Join(keys[:i], "."), key)
+		default:
+			return nil, nil, fmt.Errorf("%s is not a mapping", strings.Join(keys[:i], "."))
+		}
+
+		// Build the missing part of the path and append it as one key
+		for j := len(keys) - 1; j > i; j-- {
+			value = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Tag: "!!str", Value: keys[j]}, value,
+			}}
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+		return nil, node, nil
+	}
+	return nil, nil, nil
+}
+
+// keepComments moves the comments of the replaced node to its replacement and keeps a
+// quoted string quoted
+func keepComments(value, old *yaml.Node) {
+	value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+	if value.Kind == yaml.ScalarNode && old.Kind == yaml.ScalarNode && value.Tag == "!!str" && value.Style == 0 &&
+		(old.Style == yaml.DoubleQuotedStyle || old.Style == yaml.SingleQuotedStyle) {
+		value.Style = old.Style
+	}
+}
+
+// renderNode returns the YAML text of a node on one line where possible
+func renderNode(node *yaml.Node) string {
+	if node.Kind == yaml.ScalarNode && node.Style == 0 {
+		return node.Value
+	}
+	plain := *node
+	plain.HeadComment, plain.LineComment, plain.FootComment = "", "", ""
+	out, err := yaml.Marshal(&plain)
+	if err != nil {
+		return node.Value
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+// walkPath returns the nodes reached by each prefix of keys that exists, starting with node,
+// and the mapping key of each; the key is nil for the root and for sequence elements
+func walkPath(node *yaml.Node, keys []string) (nodes, keyNodes []*yaml.Node) {
+	nodes, keyNodes = []*yaml.Node{node}, []*yaml.Node{nil}
+	for _, key := range keys {
+		next := child(node, key)
+		if next == nil {
 			break
 		}
+		var keyNode *yaml.Node
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i+1] == next {
+					keyNode = node.Content[i]
+				}
+			}
+		}
+		nodes, keyNodes = append(nodes, next), append(keyNodes, keyNode)
+		node = next
+	}
+	return nodes, keyNodes
+}
+
+// textSpan replaces source text from an offset in one line to an offset in a later or the
+// same line
+type textSpan struct {
+	line, start  int // 0-based first line, byte offset within it
+	endLine, end int // 0-based last line, byte offset within it
+	text         string
+	depth        int // indentation of inserted keys; deeper mappings come first at the same place
+	seq          int // edit order, so insertions at the same place keep it
+}
+
+// overlaps reports whether two spans touch the same text; insertions at one point never
+// overlap each other
+func (s textSpan) overlaps(other textSpan) bool {
+	before := func(line, column, otherLine, otherColumn int) bool {
+		return line < otherLine || line == otherLine && column < otherColumn
+	}
+	return before(s.line, s.start, other.endLine, other.end) && before(other.line, other.start, s.endLine, s.end)
+}
+
+// scalarSpan locates old, a single-line scalar, in the source lines and renders its
+// replacement when that is a single-line scalar too
+func scalarSpan(lines []string, old, value *yaml.Node) (textSpan, bool) {
+	if old.Kind != yaml.ScalarNode || value.Kind != yaml.ScalarNode || old.Line < 1 || old.Line > len(lines) {
+		return textSpan{}, false
+	}
+	line := strings.TrimRight(lines[old.Line-1], "\r\n")
+	start := old.Column - 1
+	end := scalarEnd(line, start, old)
+	if end < 0 {
+		return textSpan{}, false
+	}
+
+	styled := *value
+	keepComments(&styled, old)
+	text := renderNode(&styled)
+	if strings.Contains(text, "\n") {
+		return textSpan{}, false
+	}
+	if start > 0 && line[start-1] == ':' {
+		// An empty value right after its key's colon
+		text = " " + text
+	}
+	return textSpan{line: old.Line - 1, start: start, endLine: old.Line - 1, end: end, text: text}, true
+}
+
+// scalarEnd returns the offset just past node, a single-line scalar starting at start
+// in line, or -1 when it is not there
+func scalarEnd(line string, start int, node *yaml.Node) int {
+	if start < 0 || start > len(line) {
+		return -1
+	}
+	switch node.Style {
+	case 0:
+		if strings.HasPrefix(line[start:], node.Value) {
+			return start + len(node.Value)
+		}
+	case yaml.DoubleQuotedStyle:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+			} else if line[i] == '"' {
+				return i + 1
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// flowEnd returns the offset just past the flow collection starting at start in line,
+// or -1 when it does not close on that line
+func flowEnd(line string, start int) int {
+	depth := 0
+	for i := start; i < len(line); i++ {
+		switch line[i] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case '"':
+			for i++; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' {
+					i++
+				}
+			}
+		case '\'':
+			for i++; i < len(line) && line[i] != '\''; i++ {
+			}
+		}
+	}
+	return -1
+}
+
+// keyColon returns the offset of the colon after key, a mapping key on its own source line
+func keyColon(lines []string, key *yaml.Node) (int, bool) {
+	if key.Line < 1 || key.Line > len(lines) {
+		return 0, false
+	}
+	line := strings.TrimRight(lines[key.Line-1], "\r\n")
+	end := scalarEnd(line, key.Column-1, key)
+	if end < 0 {
+		return 0, false
+	}
+	colon := end + len(line[end:]) - len(strings.TrimLeft(line[end:], " \t"))
+	if colon >= len(line) || line[colon] != ':' {
+		return 0, false
+	}
+	return colon, true
+}
+
+// valueSpan replaces old, a list, mapping or multi-line value or one replaced by one, with
+// value. The text from the key's colon, or from a sequence element's dash, through the last
+// source line of old is rewritten, with value indented under the key or element; a comment
+// after old, or after the key when old starts on the next line, is kept on the first line
+func valueSpan(lines []string, container, key, old, value *yaml.Node, last int) (textSpan, bool) {
+	if container.Style&yaml.FlowStyle != 0 || old.Line < 1 || last < old.Line || last > len(lines) {
+		return textSpan{}, false
+	}
+	first := old.Line - 1
+	line := strings.TrimRight(lines[first], "\r\n")
+	start := old.Column - 1
+	if start < 0 || start > len(line) {
+		return textSpan{}, false
+	}
+
+	// Find where old ends; anything after a single-line value on its line is its comment
+	block := old.Style&yaml.FlowStyle == 0 &&
+		(old.Kind != yaml.ScalarNode || old.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0)
+	endLine, end := last-1, 0
+	comment := ""
+	if block {
+		end = len(strings.TrimRight(lines[endLine], "\r\n"))
+	} else {
+		if last != old.Line {
+			return textSpan{}, false
+		}
+		if old.Kind == yaml.ScalarNode {
+			end = scalarEnd(line, start, old)
+		} else {
+			end = flowEnd(line, start)
+		}
+		if end < 0 {
+			return textSpan{}, false
+		}
+		comment = strings.TrimSpace(line[end:])
+		end = len(line)
+	}
+
+	plain := *value
+	plain.HeadComment, plain.LineComment, plain.FootComment = "", "", ""
+	span := textSpan{endLine: endLine, end: end}
+	var rows []string
+	var indent int
+	if key != nil {
+		colon, ok := keyColon(lines, key)
+		if !ok {
+			return textSpan{}, false
+		}
+		if key.Line != old.Line {
+			comment = strings.TrimSpace(strings.TrimRight(lines[key.Line-1], "\r\n")[colon+1:])
+		}
+		// Rendered as the value of a one-letter key, the rows are already indented under it
+		rendered, ok := encodeNode(&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "K"}, &plain,
+		}})
+		if !ok {
+			return textSpan{}, false
+		}
+		rows = strings.Split(rendered, "\n")
+		rows[0] = strings.TrimPrefix(rows[0], "K:")
+		span.line, span.start, indent = key.Line-1, colon+1, key.Column-1
+	} else {
+		if !strings.HasSuffix(strings.TrimSpace(line[:start]), "-") {
+			return textSpan{}, false
+		}
+		rendered, ok := encodeNode(&plain)
+		if !ok {
+			return textSpan{}, false
+		}
+		rows = strings.Split(rendered, "\n")
+		span.line, span.start, indent = first, start, start
+	}
+
+	var text strings.Builder
+	text.WriteString(rows[0])
+	if comment != "" {
+		text.WriteString(" " + comment)
+	}
+	for _, row := range rows[1:] {
+		text.WriteString("\n" + strings.Repeat(" ", indent) + row)
+	}
+	span.text = text.String()
+	return span, true
+}
+
+// flowSpan rewrites parent, a single-line flow mapping, with the keys it has now
+func flowSpan(lines []string, parent *yaml.Node) (textSpan, bool) {
+	if parent.Line < 1 || parent.Line > len(lines) {
+		return textSpan{}, false
+	}
+	line := strings.TrimRight(lines[parent.Line-1], "\r\n")
+	start := parent.Column - 1
+	if start < 0 || start >= len(line) {
+		return textSpan{}, false
+	}
+	end := flowEnd(line, start)
+	text := renderNode(parent)
+	if end < 0 || strings.Contains(text, "\n") {
+		return textSpan{}, false
+	}
+	return textSpan{line: parent.Line - 1, start: start, endLine: parent.Line - 1, end: end, text: text}, true
+}
+
+// insertionSpan renders the key appended last to parent, a block mapping, as lines after
+// the mapping's last source line. A mapping with no keys in the source is either the
+// empty value of key, which receives it on the following lines, or a new document's root
+func insertionSpan(lines []string, parent, key *yaml.Node, ends map[*yaml.Node]int) (textSpan, bool) {
+	first := parent.Content[0]
+	after, column := 0, 0
+	switch {
+	case first.Line >= 1:
+		for _, node := range parent.Content[:len(parent.Content)-2] {
+			after = max(after, lastLine(node, ends))
+		}
+		column = first.Column
+	case key != nil:
+		colon, ok := keyColon(lines, key)
+		if !ok {
+			return textSpan{}, false
+		}
+		if rest := strings.TrimSpace(strings.TrimRight(lines[key.Line-1], "\r\n")[colon+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return textSpan{}, false
+		}
+		after, column = key.Line, key.Column+2
+	case parent.Line == 0:
+		after, column = len(lines), 1
+		if after > 1 && lines[after-1] == "" {
+			after--
+		}
+	}
+	if after < 1 || after > len(lines) {
+		return textSpan{}, false
+	}
+
+	pair := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: parent.Content[len(parent.Content)-2:]}
+	rendered, ok := encodeNode(pair)
+	if !ok {
+		return textSpan{}, false
+	}
+
+	indent := strings.Repeat(" ", column-1)
+	if lines[after-1] != "" && !strings.HasSuffix(lines[after-1], "\n") {
+		lines[after-1] += "\n"
+	}
+	line := lines[after-1]
+	var text strings.Builder
+	for _, row := range strings.SplitAfter(rendered, "\n") {
+		text.WriteString(indent + row)
+	}
+	text.WriteString("\n")
+	return textSpan{line: after - 1, start: len(line), endLine: after - 1, end: len(line), text: text.String(), depth: len(indent)}, true
+}
+
+// encodeNode renders a node as block YAML indented by two spaces, without the final newline
+func encodeNode(node *yaml.Node) (string, bool) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil || encoder.Close() != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), true
+}
+
+// lastLine returns the last source line of a node, 0 for nodes not in the source
+// Replaced values are looked up in ends, which holds where they ended in the source
+func lastLine(node *yaml.Node, ends map[*yaml.Node]int) int {
+	if end, ok := ends[node]; ok {
+		return end
+	}
+	last := node.Line
+	if node.Kind == yaml.ScalarNode && node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 && last > 0 {
+		last += strings.Count(strings.TrimRight(node.Value, "\n"), "\n") + 1
+	}
+	for _, c := range node.Content {
+		last = max(last, lastLine(c, ends))
+	}
+	return last
+}
+
+// applySpans replaces source text, last first so earlier offsets stay valid
+func applySpans(lines []string, spans []textSpan) []byte {
+	lines = append([]string(nil), lines...)
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].line != spans[j].line {
+			return spans[i].line > spans[j].line
+		}
+		if spans[i].start != spans[j].start {
+			return spans[i].start > spans[j].start
+		}
+		if spans[i].depth != spans[j].depth {
+			return spans[i].depth < spans[j].depth
+		}
+		return spans[i].seq > spans[j].seq
+	})
+	for _, span := range spans {
+		lines[span.line] = lines[span.line][:span.start] + span.text + lines[span.endLine][span.end:]
+		for i := span.line + 1; i <= span.endLine; i++ {
+			lines[i] = ""
+		}
+	}
+	return []byte(strings.Join(lines, ""))
+}
+
+// sameYAML reports whether two documents decode to the same value
+func sameYAML(a, b []byte) bool {
+	var va, vb any
+	if yaml.Unmarshal(a, &va) != nil || yaml.Unmarshal(b, &vb) != nil {
+		return false
 	}
-	return strings.Join(lines, "\n")
+	return reflect.DeepEqual(va, vb)
 }
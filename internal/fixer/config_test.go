@@ -0,0 +1,226 @@
+package fixer_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+)
+
+const testConfig = `# demo service
+Name: demo-api
+Host: 0.0.0.0
+Port: 8888 # public port
+
+Etcd:
+  Hosts:
+  - 127.0.0.1:2379
+  Key: "demo.rpc"
+
+Prometheus:
+  Host: 0.0.0.0
+  Port: 9101
+`
+
+func TestEditConfigReplacesByPath(t *testing.T) {
+	updated, changes, err := fixer.EditConfig([]byte(testConfig), []fixer.ConfigEdit{
+		{Path: "Prometheus.Port", Value: "9102"},
+		{Path: "Port", Value: "8080"},
+		{Path: "Etcd.Key", Value: "user.rpc"},
+		{Path: "Etcd.Hosts.0", Value: "etcd:2379"},
+	})
+	if err != nil {
+		t.Fatalf("EditConfig() failed: %v", err)
+	}
+
+	want := strings.NewReplacer(
+		"Port: 8888", "Port: 8080",
+		"Port: 9101", "Port: 9102",
+		`Key: "demo.rpc"`, `Key: "user.rpc"`,
+		"- 127.0.0.1:2379", "- etcd:2379",
+	).Replace(testConfig)
+	if string(updated) != want {
+		t.Errorf("EditConfig() =\n%s\nwant\n%s", updated, want)
+	}
+	if len(changes) != 4 || changes[1].Old != "8888" || changes[1].New != "8080" || changes[1].Added {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestEditConfigAddsMissingKeys(t *testing.T) {
+	updated, changes, err := fixer.EditConfig([]byte(testConfig), []fixer.ConfigEdit{
+		{Path: "Telemetry.Name", Value: "demo"},
+		{Path: "Prometheus.Path", Value: "/metrics"},
+		{Path: "Etcd.User", Value: "root"},
+	})
+	if err != nil {
+		t.Fatalf("EditConfig() failed: %v", err)
+	}
+
+	want := strings.NewReplacer(
+		`  Key: "demo.rpc"`+"\n", `  Key: "demo.rpc"`+"\n  User: root\n",
+		"  Port: 9101\n", "  Port: 9101\n  Path: /metrics\nTelemetry:\n  Name: demo\n",
+	).Replace(testConfig)
+	if string(updated) != want {
+		t.Errorf("EditConfig() =\n%s\nwant\n%s", updated, want)
+	}
+	for _, change := range changes {
+		if !change.Added {
+			t.Errorf("change not marked as added: %+v", change)
+		}
+	}
+}
+
+func TestEditConfigFlowMapping(t *testing.T) {
+	updated, _, err := fixer.EditConfig([]byte("# flow\nRedis: {Host: localhost, Type: node}\n"), []fixer.ConfigEdit{
+		{Path: "Redis.Pass", Value: "secret"},
+	})
+	if err != nil {
+		t.Fatalf("EditConfig() failed: %v", err)
+	}
+	if value, _ := fixer.ConfigValue(updated, "Redis.Pass"); value != "secret" || !strings.Contains(string(updated), "# flow") {
+		t.Errorf("unexpected output:\n%s", updated)
+	}
+}
+
+const commentedConfig = `# name
+Name: demo-api # service name
+Host: 0.0.0.0
+
+Etcd:
+  # etcd cluster
+  Hosts:
+  - 127.0.0.1:2379 # local
+  Key: demo.rpc
+
+# logging
+Log:
+  Mode: console
+Empty:
+`
+
+func TestEditConfigReplacesLists(t *testing.T) {
+	for _, tc := range []struct {
+		name, value, want string
+	}{
+		{"flow", "[10.0.0.1:2379, 10.0.0.2:2379]", "  Hosts: ['10.0.0.1:2379', '10.0.0.2:2379']\n"},
+		{"block", "- 10.0.0.1:2379\n- 10.0.0.2:2379", "  Hosts:\n    - 10.0.0.1:2379\n    - 10.0.0.2:2379\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			updated, _, err := fixer.EditConfig([]byte(commentedConfig), []fixer.ConfigEdit{{Path: "Etcd.Hosts", Value: tc.value}})
+			if err != nil {
+				t.Fatalf("EditConfig() failed: %v", err)
+			}
+			want := strings.Replace(commentedConfig, "  Hosts:\n  - 127.0.0.1:2379 # local\n", tc.want, 1)
+			if string(updated) != want {
+				t.Errorf("EditConfig() =\n%s\nwant\n%s", updated, want)
+			}
+		})
+	}
+}
+
+func TestEditConfigReplacesMappings(t *testing.T) {
+	updated, _, err := fixer.EditConfig([]byte(commentedConfig), []fixer.ConfigEdit{
+		{Path: "Name", Value: "Service: demo\nGroup: api"},
+		{Path: "Log", Value: "{Mode: file, Path: logs}"},
+		{Path: "Empty", Value: "[a]"},
+		{Path: "Etcd.User", Value: "root"},
+	})
+	if err != nil {
+		t.Fatalf("EditConfig() failed: %v", err)
+	}
+	want := strings.NewReplacer(
+		"Name: demo-api # service name\n", "Name: # service name\n  Service: demo\n  Group: api\n",
+		"Log:\n  Mode: console\n", "Log: {Mode: file, Path: logs}\n",
+		"Empty:\n", "Empty: [a]\n",
+		"  Key: demo.rpc\n", "  Key: demo.rpc\n  User: root\n",
+	).Replace(commentedConfig)
+	if string(updated) != want {
+		t.Errorf("EditConfig() =\n%s\nwant\n%s", updated, want)
+	}
+
+	// A mapping becomes a scalar on its key's line, and an empty key receives new keys
+	updated, _, err = fixer.EditConfig([]byte(commentedConfig), []fixer.ConfigEdit{
+		{Path: "Etcd", Value: "none"},
+		{Path: "Empty.Level", Value: "info"},
+	})
+	if err != nil {
+		t.Fatalf("EditConfig() failed: %v", err)
+	}
+	want = strings.NewReplacer(
+		"Etcd:\n  # etcd cluster\n  Hosts:\n  - 127.0.0.1:2379 # local\n  Key: demo.rpc\n", "Etcd: none\n",
+		"Empty:\n", "Empty:\n  Level: info\n",
+	).Replace(commentedConfig)
+	if string(updated) != want {
+		t.Errorf("EditConfig() =\n%s\nwant\n%s", updated, want)
+	}
+}
+
+func TestEditConfigErrors(t *testing.T) {
+	for _, edit := range []fixer.ConfigEdit{
+		{Path: "Port.Value", Value: "1"},
+		{Path: "Etcd.Hosts.3", Value: "etcd:2379"},
+		{Path: "Etcd..Key", Value: "x"},
+		{Path: "Redis.Key", Value: "x"}, // inside a flow mapping spanning lines
+	} {
+		config := testConfig + "Redis: {Host: localhost,\n  Type: node}\n"
+		if _, _, err := fixer.EditConfig([]byte(config), []fixer.ConfigEdit{edit}); err == nil {
+			t.Errorf("EditConfig(%s) succeeded, want error", edit.Path)
+		}
+	}
+}
+
+func TestParseConfigEdit(t *testing.T) {
+	edit, err := fixer.ParseConfigEdit("Etcd.Key = user.rpc")
+	if err != nil || edit.Path != "Etcd.Key" || edit.Value != "user.rpc" {
+		t.Errorf("ParseConfigEdit() = %+v, %v", edit, err)
+	}
+	if _, err := fixer.ParseConfigEdit("Port"); err == nil {
+		t.Error("expected an error without '='")
+	}
+}
+
+func TestUpdateConfigFileUsesTopLevelKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"etc/another.yaml":  "Name: another\nPort: 1\n",
+		"etc/demo-api.yaml": testConfig,
+	})
+
+	if err := fixer.UpdateConfigFile(dir, "demo", 8080); err != nil {
+		t.Fatalf("UpdateConfigFile() failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "etc", "demo-api.yaml"))
+	if port, _ := fixer.ConfigValue(content, "Port"); port != "8080" {
+		t.Errorf("Port = %s, want 8080", port)
+	}
+	if port, _ := fixer.ConfigValue(content, "Prometheus.Port"); port != "9101" {
+		t.Errorf("Prometheus.Port = %s, want 9101", port)
+	}
+	other, _ := os.ReadFile(filepath.Join(dir, "etc", "another.yaml"))
+	if string(other) != "Name: another\nPort: 1\n" {
+		t.Errorf("another.yaml was changed:\n%s", other)
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"etc/a.yaml": "Name: user.rpc\n",
+		"etc/b.yaml": "Name: order.rpc\n",
+	})
+
+	file, err := fixer.FindConfigFile(dir, "order")
+	if err != nil || filepath.Base(file) != "b.yaml" {
+		t.Errorf("FindConfigFile(order) = %s, %v", file, err)
+	}
+	if _, err := fixer.FindConfigFile(dir, "payment"); err == nil {
+		t.Error("expected an error when no config file matches")
+	}
+	if file, err := fixer.FindConfigFile(t.TempDir(), "order"); file != "" || err != nil {
+		t.Errorf("FindConfigFile without etc/ = %q, %v", file, err)
+	}
+}
@@ -0,0 +1,387 @@
+package fixer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/zeromicro/mcp-zero/internal/goctl"
+)
+
+// Dependency modes select where go commands resolve modules from
+const (
+	DepsOnline  = "online"  // GOPROXY as configured in the environment
+	DepsOffline = "offline" // module cache only (GOPROXY=off), or a file-based module proxy directory
+	DepsVendor  = "vendor"  // the enclosing module's vendor directory (-mod=vendor)
+)
+
+// GoZeroModule is the module path of go-zero, pinned from the module cache when offline
+const GoZeroModule = "github.com/zeromicro/go-zero"
+
+// Dependencies configures module resolution for go mod tidy and go build
+type Dependencies struct {
+	Mode     string `json:"mode"`
+	ProxyDir string `json:"proxy_dir,omitempty"` // offline only: directory served as GOPROXY=file://
+}
+
+var (
+	dependenciesMu      sync.RWMutex
+	defaultDependencies = Dependencies{Mode: DepsOnline}
+)
+
+// NewDependencies validates a mode and proxy directory; an empty mode is online, or
+// offline when a proxy directory is given
+func NewDependencies(mode, proxyDir string) (Dependencies, error) {
+	if mode == "" {
+		mode = DepsOnline
+		if proxyDir != "" {
+			mode = DepsOffline
+		}
+	}
+	switch mode {
+	case DepsOnline, DepsOffline, DepsVendor:
+	default:
+		return Dependencies{}, fmt.Errorf("unknown dependency mode %q: use online, offline or vendor", mode)
+	}
+	if proxyDir == "" {
+		return Dependencies{Mode: mode}, nil
+	}
+	if mode != DepsOffline {
+		return Dependencies{}, fmt.Errorf("a module proxy directory only applies to offline mode, not %s", mode)
+	}
+	abs, err := filepath.Abs(proxyDir)
+	if err != nil {
+		return Dependencies{}, err
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return Dependencies{}, fmt.Errorf("module proxy directory not found: %s", abs)
+	}
+	return Dependencies{Mode: mode, ProxyDir: abs}, nil
+}
+
+// SetDefaultDependencies sets the server-wide mode used when a call does not choose one
+func SetDefaultDependencies(deps Dependencies) {
+	dependenciesMu.Lock()
+	defer dependenciesMu.Unlock()
+	defaultDependencies = deps
+}
+
+// DefaultDependencies returns the server-wide dependency mode
+func DefaultDependencies() Dependencies {
+	dependenciesMu.RLock()
+	defer dependenciesMu.RUnlock()
+	return defaultDependencies
+}
+
+// ResolveDependencies picks a call's dependency mode: its own mode or proxy directory
+// when given, otherwise the server-wide default
+func ResolveDependencies(mode, proxyDir string) (Dependencies, error) {
+	if mode == "" && proxyDir == "" {
+		return DefaultDependencies(), nil
+	}
+	return NewDependencies(mode, proxyDir)
+}
+
+type dependenciesKey struct{}
+
+// WithDependencies makes every go command run under ctx, such as the steps of a tool's
+// pipeline, resolve modules in deps' mode
+func WithDependencies(ctx context.Context, deps Dependencies) context.Context {
+	return context.WithValue(ctx, dependenciesKey{}, deps)
+}
+
+// dependenciesFrom returns the mode set by WithDependencies, or the server-wide default
+func dependenciesFrom(ctx context.Context) Dependencies {
+	if deps, ok := ctx.Value(dependenciesKey{}).(Dependencies); ok {
+		return deps
+	}
+	return DefaultDependencies()
+}
+
+// env returns the GOPROXY, GOSUMDB and GOTOOLCHAIN entries that keep go commands off the
+// network, and the -mod flag for GOFLAGS; online mode changes nothing
+// Checksums are not looked up offline: go.sum is filled from the module cache or proxy directory
+func (d Dependencies) env() (env []string, modFlag string) {
+	switch d.Mode {
+	case DepsOffline:
+		proxy := "off"
+		if d.ProxyDir != "" {
+			proxy = "file://" + fileURLPath(d.ProxyDir)
+		}
+		return []string{"GOPROXY=" + proxy, "GOSUMDB=off", "GOTOOLCHAIN=local"}, "-mod=mod"
+	case DepsVendor:
+		return []string{"GOPROXY=off", "GOSUMDB=off", "GOTOOLCHAIN=local"}, "-mod=vendor"
+	}
+	return nil, ""
+}
+
+// fileURLPath spells an absolute directory as the path of a file:// URL
+func fileURLPath(dir string) string {
+	p := filepath.ToSlash(dir)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // C:/proxy on Windows
+	}
+	return p
+}
+
+// MissingModulesError reports modules an offline or vendor build could not find
+type MissingModulesError struct {
+	Modules []string // module@version, or the imported package when no version was chosen
+	Deps    Dependencies
+	Output  string
+}
+
+func (e *MissingModulesError) Error() string {
+	var b strings.Builder
+	switch {
+	case e.Deps.Mode == DepsVendor:
+		fmt.Fprintf(&b, "modules missing from the vendor directory: %s\n", strings.Join(e.Modules, ", "))
+		b.WriteString("Run go mod vendor in the enclosing module on a machine with network access\n")
+	case e.Deps.ProxyDir != "":
+		fmt.Fprintf(&b, "modules missing from the module cache and the module proxy directory %s: %s\n", e.Deps.ProxyDir, strings.Join(e.Modules, ", "))
+		fmt.Fprintf(&b, "Add them to the proxy directory, e.g. copy $(go env GOMODCACHE)/cache/download after running on a connected machine: go mod download %s\n", strings.Join(e.Modules, " "))
+	default:
+		fmt.Fprintf(&b, "modules missing from the module cache (GOPROXY=off): %s\n", strings.Join(e.Modules, ", "))
+		fmt.Fprintf(&b, "On a machine with network access run: go mod download %s\nthen copy its module cache to this machine's GOMODCACHE\n", strings.Join(e.Modules, " "))
+	}
+	if e.Output != "" {
+		b.WriteString("\n" + e.Output)
+	}
+	return b.String()
+}
+
+var (
+	// missingVersionPattern matches module@version, or the imported package, in
+	// "M@V: module lookup disabled by GOPROXY=off" and
+	// "M@V: reading file:///proxy/M/@v/V.zip: no such file or directory"
+	missingVersionPattern = regexp.MustCompile(`([^\s:'"]*[./][^\s:'"]*): (?:module lookup disabled|reading file:)`)
+	// missingPackagePattern matches packages no module provides, offline or from vendor
+	missingPackagePattern = regexp.MustCompile(`cannot find module providing package ([^\s:'"]+)`)
+	// missingVendorPattern matches modules go.mod and vendor/modules.txt disagree about
+	missingVendorPattern = regexp.MustCompile(`([^\s:'"]+@v[^\s:'"]+): is [^\n]*vendor/modules\.txt`)
+)
+
+// MissingModules extracts the modules and packages a go command could not resolve from its output
+func MissingModules(output string) []string {
+	seen := make(map[string]bool)
+	var modules []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			modules = append(modules, name)
+		}
+	}
+	for _, match := range missingVersionPattern.FindAllStringSubmatch(output, -1) {
+		add(match[1])
+	}
+	for _, match := range missingPackagePattern.FindAllStringSubmatch(output, -1) {
+		add(match[1])
+	}
+	for _, match := range missingVendorPattern.FindAllStringSubmatch(output, -1) {
+		add(match[1])
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// ModuleCacheDir returns GOMODCACHE
+func ModuleCacheDir(ctx context.Context) (string, error) {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir, nil
+	}
+	result := goctl.Run(ctx, "", 0, "go", "env", "GOMODCACHE")
+	if result.Error != nil {
+		return "", fmt.Errorf("go env GOMODCACHE failed: %v\n%s", result.Error, result.Stderr)
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
+// CachedVersions lists the versions of a module available without network access, newest
+// first: complete downloads in the module cache and, when set, the module proxy directory
+func CachedVersions(ctx context.Context, modulePath string, deps Dependencies) ([]string, error) {
+	escaped, err := escapeModulePath(modulePath)
+	if err != nil {
+		return nil, err
+	}
+	var roots []string
+	if deps.ProxyDir != "" {
+		roots = append(roots, deps.ProxyDir)
+	}
+	cache, err := ModuleCacheDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	roots = append(roots, filepath.Join(cache, "cache", "download"))
+
+	seen := make(map[string]bool)
+	var versions []string
+	for _, root := range roots {
+		entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(escaped), "@v"))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			version, ok := strings.CutSuffix(entry.Name(), ".zip")
+			if !ok || seen[version] || !isFile(filepath.Join(root, filepath.FromSlash(escaped), "@v", version+".mod")) {
+				continue
+			}
+			seen[version] = true
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return compareVersions(versions[i], versions[j]) > 0 })
+	return versions, nil
+}
+
+// escapeModulePath applies the module cache's case encoding: Upper becomes !upper
+func escapeModulePath(modulePath string) (string, error) {
+	var b strings.Builder
+	for _, r := range modulePath {
+		switch {
+		case r == '!':
+			return "", fmt.Errorf("invalid module path %q", modulePath)
+		case unicode.IsUpper(r):
+			b.WriteByte('!')
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String(), nil
+}
+
+// compareVersions orders semantic versions such as v1.6.0 and v1.7.0-rc1; releases sort
+// above their pre-releases
+func compareVersions(a, b string) int {
+	coreA, preA, _ := strings.Cut(strings.TrimPrefix(strings.SplitN(a, "+", 2)[0], "v"), "-")
+	coreB, preB, _ := strings.Cut(strings.TrimPrefix(strings.SplitN(b, "+", 2)[0], "v"), "-")
+	partsA, partsB := strings.Split(coreA, "."), strings.Split(coreB, ".")
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			y, _ = strconv.Atoi(partsB[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return strings.Compare(preA, preB)
+}
+
+// pinGoZero requires the newest cached go-zero in the module holding projectPath when its
+// code imports go-zero without go.mod requiring it, since go mod tidy cannot look up the
+// latest version offline
+func pinGoZero(ctx context.Context, projectPath string, deps Dependencies) error {
+	module, err := DetectModule(projectPath)
+	if err != nil || module.Root == "" {
+		return err
+	}
+	goModPath := filepath.Join(module.Root, "go.mod")
+	required, err := requiresModule(goModPath, GoZeroModule)
+	if err != nil || required || !importsModule(module.Root, GoZeroModule) {
+		return err
+	}
+
+	versions, err := CachedVersions(ctx, GoZeroModule, deps)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return &MissingModulesError{Modules: []string{GoZeroModule + " (no version downloaded)"}, Deps: deps}
+	}
+	if err := runGo(ctx, module.Root, "mod", "edit", "-require="+GoZeroModule+"@"+versions[0]); err != nil {
+		return fmt.Errorf("failed to pin %s@%s: %w", GoZeroModule, versions[0], err)
+	}
+	return nil
+}
+
+// requiresModule reports whether go.mod has a require directive for modulePath
+func requiresModule(goModPath, modulePath string) (bool, error) {
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		return false, err
+	}
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(stripComment(scanner.Text()))
+		switch {
+		case len(fields) == 0:
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			if fields[0] == modulePath {
+				return true, nil
+			}
+		case fields[0] == "require" && len(fields) > 1 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "require" && len(fields) > 1 && fields[1] == modulePath:
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// errFound stops a walk early
+var errFound = errors.New("found")
+
+// importsModule reports whether a Go file under root mentions an import of modulePath
+func importsModule(root, modulePath string) bool {
+	exact, prefix := []byte(`"`+modulePath+`"`), []byte(`"`+modulePath+`/`)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if name := d.Name(); p != root && (name == "vendor" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") {
+			return nil
+		}
+		if content, err := os.ReadFile(p); err == nil && (bytes.Contains(content, exact) || bytes.Contains(content, prefix)) {
+			return errFound
+		}
+		return nil
+	})
+	return errors.Is(err, errFound)
+}
+
+// checkVendor fails when the module holding projectPath has no vendor directory to build from
+func checkVendor(projectPath string) error {
+	module, err := DetectModule(projectPath)
+	if err != nil {
+		return err
+	}
+	if module.Root == "" || !isFile(filepath.Join(module.Root, "vendor", "modules.txt")) {
+		return fmt.Errorf("vendor mode needs vendor/modules.txt in the enclosing module of %s; run go mod vendor there on a machine with network access, or use offline mode", projectPath)
+	}
+	return nil
+}
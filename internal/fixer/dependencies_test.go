@@ -0,0 +1,182 @@
+package fixer_test
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+)
+
+// writeProxyModule adds a module version to a file-based GOPROXY directory
+func writeProxyModule(t *testing.T, proxyDir, modulePath, version string, files map[string]string) {
+	t.Helper()
+	dir := filepath.Join(proxyDir, filepath.FromSlash(modulePath), "@v")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	goMod := "module " + modulePath + "\n\ngo 1.21\n"
+	if err := os.WriteFile(filepath.Join(dir, version+".mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, version+".info"), []byte(`{"Version":"`+version+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := os.Create(filepath.Join(dir, version+".zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+	archive := zip.NewWriter(out)
+	files["go.mod"] = goMod
+	for name, content := range files {
+		w, err := archive.Create(modulePath + "@" + version + "/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// useEmptyModuleCache points GOMODCACHE at a fresh directory the test can delete
+func useEmptyModuleCache(t *testing.T) {
+	t.Helper()
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("GOFLAGS", "-modcacherw")
+}
+
+func TestNewDependencies(t *testing.T) {
+	proxyDir := t.TempDir()
+	deps, err := fixer.NewDependencies("", proxyDir)
+	if err != nil || deps.Mode != fixer.DepsOffline || deps.ProxyDir != proxyDir {
+		t.Errorf("NewDependencies with a proxy = %+v, %v", deps, err)
+	}
+	if deps, err := fixer.NewDependencies("", ""); err != nil || deps.Mode != fixer.DepsOnline {
+		t.Errorf("NewDependencies() = %+v, %v", deps, err)
+	}
+
+	for _, tc := range []struct{ mode, proxy string }{
+		{"airgapped", ""},
+		{fixer.DepsVendor, proxyDir},
+		{fixer.DepsOffline, filepath.Join(proxyDir, "missing")},
+	} {
+		if _, err := fixer.NewDependencies(tc.mode, tc.proxy); err == nil {
+			t.Errorf("NewDependencies(%q, %q) succeeded, want error", tc.mode, tc.proxy)
+		}
+	}
+}
+
+func TestMissingModules(t *testing.T) {
+	output := `go: example.com/demo imports
+	github.com/zeromicro/go-zero/rest: cannot find module providing package github.com/zeromicro/go-zero/rest: module lookup disabled by GOPROXY=off
+main.go:3:8: github.com/zeromicro/go-zero@v1.6.0: module lookup disabled by GOPROXY=off
+go: example.com/lib@v1.2.0: reading file:///proxy/example.com/lib/@v/v1.2.0.mod: no such file or directory
+go: inconsistent vendoring in /work:
+	github.com/pkg/errors@v0.9.1: is explicitly required in go.mod, but not marked as explicit in vendor/modules.txt
+`
+	got := strings.Join(fixer.MissingModules(output), " ")
+	want := "example.com/lib@v1.2.0 github.com/pkg/errors@v0.9.1 github.com/zeromicro/go-zero/rest github.com/zeromicro/go-zero@v1.6.0"
+	if got != want {
+		t.Errorf("MissingModules() = %s\nwant %s", got, want)
+	}
+	if missing := fixer.MissingModules("main.go:3:2: undefined: foo"); len(missing) != 0 {
+		t.Errorf("compile errors reported as missing modules: %v", missing)
+	}
+}
+
+func TestCachedVersionsNewestFirst(t *testing.T) {
+	useEmptyModuleCache(t)
+	cache := filepath.Join(os.Getenv("GOMODCACHE"), "cache", "download")
+	for _, version := range []string{"v1.5.10", "v1.6.0-rc1", "v1.5.9"} {
+		writeProxyModule(t, cache, fixer.GoZeroModule, version, map[string]string{})
+	}
+	proxyDir := t.TempDir()
+	writeProxyModule(t, proxyDir, fixer.GoZeroModule, "v1.6.0", map[string]string{})
+	// Only go.mod was downloaded for this version, so it cannot be built
+	os.WriteFile(filepath.Join(cache, "github.com", "zeromicro", "go-zero", "@v", "v1.7.0.mod"), []byte("module x\n"), 0644)
+
+	versions, err := fixer.CachedVersions(context.Background(), fixer.GoZeroModule, fixer.Dependencies{Mode: fixer.DepsOffline, ProxyDir: proxyDir})
+	if err != nil {
+		t.Fatalf("CachedVersions() failed: %v", err)
+	}
+	if got := strings.Join(versions, " "); got != "v1.6.0 v1.6.0-rc1 v1.5.10 v1.5.9" {
+		t.Errorf("CachedVersions() = %s", got)
+	}
+}
+
+func TestTidyGoModuleOfflinePinsGoZero(t *testing.T) {
+	useEmptyModuleCache(t)
+	proxyDir := t.TempDir()
+	for _, version := range []string{"v1.5.0", "v1.6.0"} {
+		writeProxyModule(t, proxyDir, fixer.GoZeroModule, version, map[string]string{
+			"core/logx/logx.go": "package logx\n\nfunc Info(v ...any) {}\n",
+		})
+	}
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"go.mod":  "module example.com/demo\n\ngo 1.21\n",
+		"main.go": "package main\n\nimport \"github.com/zeromicro/go-zero/core/logx\"\n\nfunc main() { logx.Info(\"ok\") }\n",
+	})
+
+	deps, err := fixer.NewDependencies(fixer.DepsOffline, proxyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := fixer.WithDependencies(context.Background(), deps)
+	if err := fixer.TidyGoModule(ctx, dir); err != nil {
+		t.Fatalf("TidyGoModule() offline failed: %v", err)
+	}
+	goMod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if !strings.Contains(string(goMod), fixer.GoZeroModule+" v1.6.0") {
+		t.Errorf("go-zero not pinned to the newest cached version:\n%s", goMod)
+	}
+	if err := fixer.VerifyBuild(ctx, dir); err != nil {
+		t.Errorf("VerifyBuild() offline failed: %v", err)
+	}
+}
+
+func TestTidyGoModuleOfflineReportsMissingModules(t *testing.T) {
+	useEmptyModuleCache(t)
+	ctx := fixer.WithDependencies(context.Background(), fixer.Dependencies{Mode: fixer.DepsOffline})
+
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"go.mod":  "module example.com/demo\n\ngo 1.21\n",
+		"main.go": "package main\n\nimport _ \"github.com/zeromicro/go-zero/rest\"\n\nfunc main() {}\n",
+	})
+	var missing *fixer.MissingModulesError
+	if err := fixer.TidyGoModule(ctx, dir); !errors.As(err, &missing) || !strings.HasPrefix(missing.Modules[0], fixer.GoZeroModule) {
+		t.Errorf("expected go-zero to be reported missing, got %v", err)
+	}
+
+	writeTestFiles(t, dir, map[string]string{
+		"go.mod":  "module example.com/demo\n\ngo 1.21\n\nrequire example.com/lib v1.2.0\n",
+		"main.go": "package main\n\nimport _ \"example.com/lib\"\n\nfunc main() {}\n",
+	})
+	err := fixer.TidyGoModule(ctx, dir)
+	if !errors.As(err, &missing) || strings.Join(missing.Modules, " ") != "example.com/lib" {
+		t.Errorf("expected example.com/lib to be reported missing, got %v", err)
+	}
+}
+
+func TestTidyGoModuleVendorNeedsVendorDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{"go.mod": "module example.com/demo\n\ngo 1.21\n"})
+	ctx := fixer.WithDependencies(context.Background(), fixer.Dependencies{Mode: fixer.DepsVendor})
+
+	if err := fixer.TidyGoModule(ctx, dir); err == nil || !strings.Contains(err.Error(), "vendor/modules.txt") {
+		t.Errorf("expected a missing vendor directory error, got %v", err)
+	}
+	writeTestFiles(t, dir, map[string]string{"vendor/modules.txt": ""})
+	if err := fixer.TidyGoModule(ctx, dir); err != nil {
+		t.Errorf("TidyGoModule() with a vendor directory failed: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package fixer_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -39,7 +40,7 @@ func main() {
 
 	// Fix imports
 	moduleName := "github.com/test/project"
-	err = fixer.FixImports(tmpDir, moduleName)
+	_, err = fixer.FixImports(tmpDir, moduleName)
 	if err != nil {
 		t.Fatalf("FixImports() failed: %v", err)
 	}
@@ -85,7 +86,7 @@ func TestInitializeGoModule(t *testing.T) {
 	moduleName := "github.com/test/testmodule"
 
 	// Initialize module
-	err = fixer.InitializeGoModule(tmpDir, moduleName)
+	err = fixer.InitializeGoModule(context.Background(), tmpDir, moduleName)
 	if err != nil {
 		t.Fatalf("InitializeGoModule() failed: %v", err)
 	}
@@ -107,7 +108,7 @@ func TestInitializeGoModule(t *testing.T) {
 	}
 
 	// Test idempotency - calling again should not fail
-	err = fixer.InitializeGoModule(tmpDir, moduleName)
+	err = fixer.InitializeGoModule(context.Background(), tmpDir, moduleName)
 	if err != nil {
 		t.Errorf("InitializeGoModule() should be idempotent but failed on second call: %v", err)
 	}
@@ -122,7 +123,7 @@ func TestTidyGoModule(t *testing.T) {
 
 	// Create a simple Go module
 	moduleName := "github.com/test/tidytest"
-	err = fixer.InitializeGoModule(tmpDir, moduleName)
+	err = fixer.InitializeGoModule(context.Background(), tmpDir, moduleName)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -142,7 +143,7 @@ func main() {
 	}
 
 	// Run tidy
-	err = fixer.TidyGoModule(tmpDir)
+	err = fixer.TidyGoModule(context.Background(), tmpDir)
 	if err != nil {
 		t.Fatalf("TidyGoModule() failed: %v", err)
 	}
@@ -165,7 +166,7 @@ func TestVerifyBuild(t *testing.T) {
 
 		// Create a simple valid Go project
 		moduleName := "github.com/test/buildtest"
-		err = fixer.InitializeGoModule(tmpDir, moduleName)
+		err = fixer.InitializeGoModule(context.Background(), tmpDir, moduleName)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -183,13 +184,13 @@ func main() {
 			t.Fatal(err)
 		}
 
-		err = fixer.TidyGoModule(tmpDir)
+		err = fixer.TidyGoModule(context.Background(), tmpDir)
 		if err != nil {
 			t.Fatal(err)
 		}
 
 		// Verify build succeeds
-		err = fixer.VerifyBuild(tmpDir)
+		err = fixer.VerifyBuild(context.Background(), tmpDir)
 		if err != nil {
 			t.Errorf("VerifyBuild() should succeed for valid project, got error: %v", err)
 		}
@@ -205,7 +206,7 @@ func main() {
 
 		// Create project with syntax error
 		moduleName := "github.com/test/invalidtest"
-		err = fixer.InitializeGoModule(tmpDir, moduleName)
+		err = fixer.InitializeGoModule(context.Background(), tmpDir, moduleName)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -221,7 +222,7 @@ func main() {
 		}
 
 		// Verify build fails
-		err = fixer.VerifyBuild(tmpDir)
+		err = fixer.VerifyBuild(context.Background(), tmpDir)
 		if err == nil {
 			t.Error("VerifyBuild() should fail for invalid project")
 		}
@@ -343,7 +344,7 @@ func Handle() {}
 
 	// Fix imports
 	moduleName := "example.com/myproject"
-	err = fixer.FixImports(tmpDir, moduleName)
+	_, err = fixer.FixImports(tmpDir, moduleName)
 	if err != nil {
 		t.Fatalf("FixImports() failed: %v", err)
 	}
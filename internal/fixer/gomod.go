@@ -0,0 +1,371 @@
+package fixer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/goctl"
+)
+
+// Module describes the Go module and workspace that cover a directory
+type Module struct {
+	Root        string // directory holding go.mod; empty when no module covers the directory
+	Path        string // module path declared in go.mod
+	ImportPath  string // import path of the directory inside the module
+	WorkFile    string // enclosing go.work, if any
+	InWorkspace bool   // Root is listed in a use directive of WorkFile
+}
+
+// DetectModule walks up from dir, which need not exist yet, to the nearest go.mod and go.work
+func DetectModule(dir string) (*Module, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	module := &Module{}
+	for current := abs; ; current = filepath.Dir(current) {
+		if module.Root == "" {
+			if modPath, err := readModulePath(filepath.Join(current, "go.mod")); err == nil {
+				rel, _ := filepath.Rel(current, abs)
+				module.Root = current
+				module.Path = modPath
+				module.ImportPath = joinImportPath(modPath, rel)
+			} else if !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+		if module.WorkFile == "" {
+			if workFile := filepath.Join(current, "go.work"); isFile(workFile) {
+				module.WorkFile = workFile
+			}
+		}
+		if (module.Root != "" && module.WorkFile != "") || filepath.Dir(current) == current {
+			break
+		}
+	}
+
+	if module.Root != "" && module.WorkFile != "" {
+		uses, err := readWorkUses(module.WorkFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, use := range uses {
+			if use == module.Root {
+				module.InWorkspace = true
+			}
+		}
+	}
+	return module, nil
+}
+
+// Covers reports whether an existing module holds dir under the given import path
+func (m *Module) Covers(importPath string) bool {
+	return m.Root != "" && (importPath == "" || importPath == m.ImportPath)
+}
+
+// ModuleResolution is the import path chosen for new code and whether it needs its own go.mod
+type ModuleResolution struct {
+	ImportPath string
+	NewModule  bool
+	Enclosing  *Module
+}
+
+// ResolveModule picks the import path for code generated into dir
+// An explicit modulePath wins, then the enclosing module, then fallback; a new go.mod is
+// needed unless an enclosing module already provides that import path
+func ResolveModule(dir, modulePath, fallback string) (*ModuleResolution, error) {
+	module, err := DetectModule(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	importPath := modulePath
+	if importPath == "" {
+		importPath = fallback
+		if module.Root != "" {
+			importPath = module.ImportPath
+		}
+	}
+	return &ModuleResolution{
+		ImportPath: importPath,
+		NewModule:  !module.Covers(importPath),
+		Enclosing:  module,
+	}, nil
+}
+
+// AddToWorkspace adds the module in dir to the go.work file with go work use
+func AddToWorkspace(ctx context.Context, workFile, dir string) error {
+	rel, err := filepath.Rel(filepath.Dir(workFile), dir)
+	if err != nil {
+		return err
+	}
+	rel = "./" + filepath.ToSlash(rel)
+	result := goctl.Run(ctx, filepath.Dir(workFile), 0, "go", "work", "use", rel)
+	if result.Error != nil {
+		return fmt.Errorf("go work use failed: %v\n%s", result.Error, result.Stderr)
+	}
+	return nil
+}
+
+// AddToWorkspaceCopy adds dir to workCopy, a copy of workFile, as AddToWorkspace would add it
+// to workFile; dry runs use it to preview the change
+func AddToWorkspaceCopy(ctx context.Context, workCopy, workFile, dir string) error {
+	rel, err := filepath.Rel(filepath.Dir(workFile), dir)
+	if err != nil {
+		return err
+	}
+	rel = "./" + filepath.ToSlash(rel)
+	result := goctl.Run(ctx, filepath.Dir(workCopy), 0, "go", "work", "edit", "-use="+rel, workCopy)
+	if result.Error != nil {
+		return fmt.Errorf("go work edit failed: %v\n%s", result.Error, result.Stderr)
+	}
+	return nil
+}
+
+// requirement is a require directive of a go.mod
+type requirement struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+// MergeRequirements adds the requirements and checksums of the module in dir to modFile and
+// sumFile where they are missing or older, as go mod tidy adds them once that code is part of
+// the module. Dry runs generate into a staged copy outside the enclosing module and use it to
+// preview what tidying the enclosing module would change
+func MergeRequirements(ctx context.Context, modFile, sumFile, dir string) error {
+	from, err := readRequirements(ctx, filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return err
+	}
+	into, err := readRequirements(ctx, modFile)
+	if err != nil {
+		return err
+	}
+	current := make(map[string]string, len(into))
+	for _, req := range into {
+		current[req.Path] = req.Version
+	}
+
+	indirect := make(map[string]bool)
+	for _, req := range from {
+		version, ok := current[req.Path]
+		if ok && compareVersions(version, req.Version) >= 0 {
+			continue
+		}
+		result := goctl.Run(ctx, filepath.Dir(modFile), 0, "go", "mod", "edit", "-require="+req.Path+"@"+req.Version, modFile)
+		if result.Error != nil {
+			return fmt.Errorf("go mod edit failed: %v\n%s", result.Error, result.Stderr)
+		}
+		if !ok && req.Indirect {
+			indirect["\t"+req.Path+" "+req.Version] = true
+		}
+	}
+	// go mod edit cannot mark a requirement indirect, so the comment tidy adds is written here
+	if len(indirect) > 0 {
+		content, err := os.ReadFile(modFile)
+		if err != nil {
+			return err
+		}
+		lines := strings.Split(string(content), "\n")
+		for i, line := range lines {
+			if indirect[line] {
+				lines[i] = line + " // indirect"
+			}
+		}
+		if err := os.WriteFile(modFile, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+			return err
+		}
+	}
+	return mergeSums(sumFile, filepath.Join(dir, "go.sum"))
+}
+
+// readRequirements returns the require directives of a go.mod
+func readRequirements(ctx context.Context, modFile string) ([]requirement, error) {
+	result := goctl.Run(ctx, filepath.Dir(modFile), 0, "go", "mod", "edit", "-json", modFile)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to read %s: %v\n%s", modFile, result.Error, result.Stderr)
+	}
+	var parsed struct{ Require []requirement }
+	if err := json.Unmarshal([]byte(result.Stdout), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", modFile, err)
+	}
+	return parsed.Require, nil
+}
+
+// mergeSums adds the lines of the go.sum at from that sumFile lacks, keeping it sorted
+func mergeSums(sumFile, from string) error {
+	added, err := os.ReadFile(from)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(sumFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	lines := sumLines(existing)
+	seen := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		seen[line] = true
+	}
+	changed := false
+	for _, line := range sumLines(added) {
+		if !seen[line] {
+			seen[line] = true
+			lines = append(lines, line)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	sort.Strings(lines)
+	return os.WriteFile(sumFile, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
+
+// sumLines returns the non-empty lines of a go.sum
+func sumLines(content []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// goEnv returns the environment for go commands run in dir under the dependency mode
+// A module inside a go.work that does not list it is built on its own with GOWORK=off;
+// one listed in it drops -mod=mod from GOFLAGS, which workspace mode rejects
+func goEnv(dir string, deps Dependencies) []string {
+	env, modFlag := deps.env()
+	module, err := DetectModule(dir)
+	inWorkspace := false
+	if err == nil && module.WorkFile != "" && module.Root != "" {
+		if module.InWorkspace {
+			inWorkspace = true
+		} else {
+			env = append(env, "GOWORK=off")
+		}
+	}
+	if inWorkspace && modFlag == "-mod=mod" {
+		modFlag = ""
+	}
+	if !inWorkspace && modFlag == "" {
+		return env
+	}
+
+	goflags := os.Getenv("GOFLAGS")
+	var kept []string
+	for _, flag := range strings.Fields(goflags) {
+		if !strings.HasPrefix(flag, "-mod=") && !strings.HasPrefix(flag, "--mod=") {
+			kept = append(kept, flag)
+		}
+	}
+	if modFlag != "" {
+		kept = append(kept, modFlag)
+	}
+	if updated := strings.Join(kept, " "); updated != strings.Join(strings.Fields(goflags), " ") {
+		env = append(env, "GOFLAGS="+updated)
+	}
+	return env
+}
+
+// readModulePath returns the module path declared in a go.mod file
+func readModulePath(goModPath string) (string, error) {
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := stripComment(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			modPath := strings.TrimSpace(rest)
+			if unquoted, err := strconv.Unquote(modPath); err == nil {
+				modPath = unquoted
+			}
+			if modPath != "" {
+				return modPath, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("could not find module name in %s", goModPath)
+}
+
+// readWorkUses returns the absolute directories listed in use directives of a go.work file
+func readWorkUses(workFile string) ([]string, error) {
+	content, err := os.ReadFile(workFile)
+	if err != nil {
+		return nil, err
+	}
+	base := filepath.Dir(workFile)
+	var uses []string
+	add := func(dir string) {
+		if unquoted, err := strconv.Unquote(dir); err == nil {
+			dir = unquoted
+		}
+		if dir == "" {
+			return
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(base, filepath.FromSlash(dir))
+		}
+		uses = append(uses, filepath.Clean(dir))
+	}
+
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := stripComment(scanner.Text())
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock:
+			add(line)
+		case line == "use (":
+			inBlock = true
+		case strings.HasPrefix(line, "use "):
+			add(strings.TrimSpace(strings.TrimPrefix(line, "use ")))
+		}
+	}
+	return uses, nil
+}
+
+func stripComment(line string) string {
+	if i := strings.Index(line, "//"); i >= 0 {
+		line = line[:i]
+	}
+	return strings.TrimSpace(line)
+}
+
+func joinImportPath(modPath, rel string) string {
+	if rel == "." || rel == "" {
+		return modPath
+	}
+	return path.Join(modPath, filepath.ToSlash(rel))
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,145 @@
+package fixer_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+)
+
+func writeTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetectModule(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"go.work":           "go 1.21\n\nuse (\n\t./mono // services\n\t\"./tools\"\n)\n",
+		"mono/go.mod":       "// monorepo\nmodule example.com/mono\n\ngo 1.21\n",
+		"tools/go.mod":      "module example.com/tools\n",
+		"standalone/go.mod": "module example.com/standalone\n",
+	})
+
+	tests := []struct {
+		name        string
+		dir         string
+		root        string
+		importPath  string
+		inWorkspace bool
+	}{
+		{"module root", "mono", "mono", "example.com/mono", true},
+		{"missing subdirectory", "mono/services/user", "mono", "example.com/mono/services/user", true},
+		{"quoted use", "tools/gen", "tools", "example.com/tools/gen", true},
+		{"not in workspace", "standalone", "standalone", "example.com/standalone", false},
+		{"no module", "other/demo", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			module, err := fixer.DetectModule(filepath.Join(root, tt.dir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantRoot := ""
+			if tt.root != "" {
+				wantRoot = filepath.Join(root, tt.root)
+			}
+			if module.Root != wantRoot || module.ImportPath != tt.importPath || module.InWorkspace != tt.inWorkspace {
+				t.Errorf("DetectModule() = %+v, want root %q, import path %q, in workspace %v", module, wantRoot, tt.importPath, tt.inWorkspace)
+			}
+			if module.WorkFile != filepath.Join(root, "go.work") {
+				t.Errorf("WorkFile = %q", module.WorkFile)
+			}
+		})
+	}
+}
+
+func TestResolveModule(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{"mono/go.mod": "module example.com/mono\n"})
+
+	tests := []struct {
+		name       string
+		dir        string
+		modulePath string
+		importPath string
+		newModule  bool
+	}{
+		{"enclosing module", "mono/services/user", "", "example.com/mono/services/user", false},
+		{"matching module path", "mono/services/user", "example.com/mono/services/user", "example.com/mono/services/user", false},
+		{"nested module", "mono/services/user", "example.com/user", "example.com/user", true},
+		{"fallback", "standalone/user", "", "github.com/example/user", true},
+		{"explicit outside module", "standalone/user", "example.com/user", "example.com/user", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolution, err := fixer.ResolveModule(filepath.Join(root, tt.dir), tt.modulePath, "github.com/example/user")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resolution.ImportPath != tt.importPath || resolution.NewModule != tt.newModule {
+				t.Errorf("ResolveModule() = %q new=%v, want %q new=%v", resolution.ImportPath, resolution.NewModule, tt.importPath, tt.newModule)
+			}
+		})
+	}
+}
+
+func TestInitializeGoModuleInsideMonorepo(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{"go.mod": "module example.com/mono\n"})
+	serviceDir := filepath.Join(root, "services", "user")
+	if err := os.MkdirAll(serviceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, moduleName := range []string{"", "example.com/mono/services/user"} {
+		if err := fixer.InitializeGoModule(context.Background(), serviceDir, moduleName); err != nil {
+			t.Fatalf("InitializeGoModule(%q) failed: %v", moduleName, err)
+		}
+		if _, err := os.Stat(filepath.Join(serviceDir, "go.mod")); !os.IsNotExist(err) {
+			t.Fatalf("InitializeGoModule(%q) created a nested go.mod", moduleName)
+		}
+	}
+
+	if err := fixer.InitializeGoModule(context.Background(), t.TempDir(), ""); err == nil {
+		t.Error("expected an error without an enclosing module or module path")
+	}
+}
+
+func TestMergeRequirements(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"mono/go.mod":   "module example.com/mono\n\ngo 1.21\n\nrequire (\n\tgithub.com/a/kept v1.2.0\n\tgithub.com/b/old v1.0.0\n)\n",
+		"mono/go.sum":   "github.com/a/kept v1.2.0 h1:a=\ngithub.com/b/old v1.0.0 h1:b=\n",
+		"staged/go.mod": "module example.com/mono/services/user\n\ngo 1.21\n\nrequire (\n\tgithub.com/a/kept v1.1.0\n\tgithub.com/b/old v1.3.0\n\tgithub.com/c/new v0.4.0\n\tgithub.com/d/dep v0.1.0 // indirect\n)\n",
+		"staged/go.sum": "github.com/b/old v1.3.0 h1:b3=\ngithub.com/a/kept v1.2.0 h1:a=\ngithub.com/c/new v0.4.0 h1:c=\n",
+	})
+	modFile, sumFile := filepath.Join(root, "mono", "go.mod"), filepath.Join(root, "mono", "go.sum")
+
+	if err := fixer.MergeRequirements(context.Background(), modFile, sumFile, filepath.Join(root, "staged")); err != nil {
+		t.Fatalf("MergeRequirements failed: %v", err)
+	}
+
+	goMod, _ := os.ReadFile(modFile)
+	for _, want := range []string{"github.com/a/kept v1.2.0\n", "github.com/b/old v1.3.0\n", "github.com/c/new v0.4.0\n", "github.com/d/dep v0.1.0 // indirect\n"} {
+		if !strings.Contains(string(goMod), want) {
+			t.Errorf("go.mod missing %q:\n%s", want, goMod)
+		}
+	}
+	goSum, _ := os.ReadFile(sumFile)
+	want := "github.com/a/kept v1.2.0 h1:a=\ngithub.com/b/old v1.0.0 h1:b=\ngithub.com/b/old v1.3.0 h1:b3=\ngithub.com/c/new v0.4.0 h1:c=\n"
+	if string(goSum) != want {
+		t.Errorf("go.sum = %q, want %q", goSum, want)
+	}
+}
@@ -1,63 +1,271 @@
 package fixer
 
 import (
-"fmt"
-"os"
-"path/filepath"
-"regexp"
-"strings"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 )
 
+// ImportChange is an import path rewritten by FixImports
+type ImportChange struct {
+	File string `json:"file"` // slash-separated, relative to the project path
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
 // FixImports fixes import paths in generated code
-// Replaces absolute paths with local module names
-func FixImports(projectPath string, moduleName string) error {
-	// Find all .go files
+// Import specs holding the absolute project path, in any symlinked or Windows spelling,
+// are replaced with moduleName; comments and string literals are left alone. An empty
+// moduleName uses the import path of the enclosing module. Returns every rewritten import
+func FixImports(projectPath string, moduleName string) ([]ImportChange, error) {
+	if moduleName == "" {
+		module, err := DetectModule(projectPath)
+		if err != nil {
+			return nil, err
+		}
+		if module.Root == "" {
+			return nil, fmt.Errorf("no go.mod found for %s and no module name given", projectPath)
+		}
+		moduleName = module.ImportPath
+	}
+
+	roots, err := importRoots(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find all .go files, following the project path itself when it is a symlink
+	walkRoot := projectPath
+	if resolved, err := filepath.EvalSymlinks(projectPath); err == nil {
+		walkRoot = resolved
+	}
 	var goFiles []string
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
-if err != nil {
-return err
-}
-if !info.IsDir() && strings.HasSuffix(path, ".go") {
+	err = filepath.WalkDir(walkRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".go") {
 			goFiles = append(goFiles, path)
 		}
 		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("failed to walk project directory: %w", err)
+		return nil, fmt.Errorf("failed to walk project directory: %w", err)
 	}
 
 	// Fix imports in each file
+	var changes []ImportChange
 	for _, goFile := range goFiles {
-		if err := fixImportsInFile(goFile, projectPath, moduleName); err != nil {
-			return fmt.Errorf("failed to fix imports in %s: %w", goFile, err)
+		fileChanges, err := fixImportsInFile(goFile, roots, moduleName)
+		if err != nil {
+			return changes, fmt.Errorf("failed to fix imports in %s: %w", goFile, err)
+		}
+		rel, err := filepath.Rel(walkRoot, goFile)
+		if err != nil {
+			rel = goFile
+		}
+		for _, change := range fileChanges {
+			change.File = filepath.ToSlash(rel)
+			changes = append(changes, change)
 		}
 	}
 
-	return nil
+	return changes, nil
+}
+
+// importRoots returns the slash-separated spellings of the absolute project path,
+// including its target when it is reached through a symlink
+func importRoots(projectPath string) ([]string, error) {
+	abs, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	roots := []string{filepath.ToSlash(abs)}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil && resolved != abs {
+		roots = append(roots, filepath.ToSlash(resolved))
+	}
+	return roots, nil
+}
+
+// rewriteImportPath maps an absolute import path under one of roots to moduleName
+func rewriteImportPath(importPath string, roots []string, moduleName string) (string, bool) {
+	normalized := strings.ReplaceAll(importPath, `\`, "/")
+	for _, root := range roots {
+		if normalized == root {
+			return moduleName, true
+		}
+		if rest, ok := strings.CutPrefix(normalized, root+"/"); ok {
+			return moduleName + "/" + rest, true
+		}
+	}
+
+	// The import may spell a symlink to the project that the project path does not
+	if filepath.IsAbs(filepath.FromSlash(normalized)) {
+		if resolved, err := filepath.EvalSymlinks(filepath.FromSlash(normalized)); err == nil && filepath.ToSlash(resolved) != normalized {
+			return rewriteImportPath(filepath.ToSlash(resolved), roots, moduleName)
+		}
+	}
+	return "", false
+}
+
+// textEdit replaces src[start:end]
+type textEdit struct {
+	start, end int
+	text       string
 }
 
 // fixImportsInFile fixes imports in a single Go file
-func fixImportsInFile(filePath string, projectPath string, moduleName string) error {
-	content, err := os.ReadFile(filePath)
+// Files with rewritten imports get their import blocks regrouped, standard library first, and gofmt'd
+func fixImportsInFile(filePath string, roots []string, moduleName string) ([]ImportChange, error) {
+	src, err := os.ReadFile(filePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	originalContent := string(content)
-	modifiedContent := originalContent
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
 
-	// Fix absolute path imports
-	// Pattern: import "absolute/path/to/project/internal/..."
-	// Replace with: import "moduleName/internal/..."
-	absPathPattern := regexp.MustCompile(regexp.QuoteMeta(projectPath) + `(/[^"]+)`)
-	modifiedContent = absPathPattern.ReplaceAllString(modifiedContent, moduleName+"$1")
+	rewritten := make(map[*ast.ImportSpec]string)
+	var changes []ImportChange
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if newPath, ok := rewriteImportPath(importPath, roots, moduleName); ok {
+			rewritten[spec] = strconv.Quote(newPath)
+			changes = append(changes, ImportChange{From: importPath, To: newPath})
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	var edits []textEdit
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if block, ok := regroupImports(gen, file, src, rewritten, moduleName, offset); ok {
+			edits = append(edits, block)
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if path, ok := rewritten[spec.(*ast.ImportSpec)]; ok {
+				lit := spec.(*ast.ImportSpec).Path
+				edits = append(edits, textEdit{offset(lit.Pos()), offset(lit.End()), path})
+			}
+		}
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	modified := src
+	for _, edit := range edits {
+		modified = append(append(append([]byte{}, modified[:edit.start]...), edit.text...), modified[edit.end:]...)
+	}
+	formatted, err := format.Source(modified)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format rewritten imports: %w", err)
+	}
 
 	// Only write if content changed
-	if modifiedContent != originalContent {
-		if err := os.WriteFile(filePath, []byte(modifiedContent), 0644); err != nil {
-			return err
+	if !bytes.Equal(formatted, src) {
+		if err := os.WriteFile(filePath, formatted, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// regroupImports rebuilds a parenthesized import block as a standard library group and a
+// group for everything else, each sorted, applying the rewritten paths; ok is false when
+// the block has a single spec or comments not attached to a spec, which are left in place
+// Packages of moduleName always go in the second group
+func regroupImports(gen *ast.GenDecl, file *ast.File, src []byte, rewritten map[*ast.ImportSpec]string, moduleName string, offset func(token.Pos) int) (textEdit, bool) {
+	if !gen.Lparen.IsValid() || len(gen.Specs) < 2 {
+		return textEdit{}, false
+	}
+
+	attached := make(map[*ast.CommentGroup]bool)
+	for _, spec := range gen.Specs {
+		attached[spec.(*ast.ImportSpec).Doc] = true
+		attached[spec.(*ast.ImportSpec).Comment] = true
+	}
+	for _, group := range file.Comments {
+		if group.Pos() > gen.Lparen && group.End() < gen.Rparen && !attached[group] {
+			return textEdit{}, false
 		}
 	}
 
-	return nil
+	type entry struct {
+		path string
+		text string
+	}
+	var std, other []entry
+	for _, s := range gen.Specs {
+		spec := s.(*ast.ImportSpec)
+		start, end := spec.Pos(), spec.End()
+		if spec.Doc != nil {
+			start = spec.Doc.Pos()
+		}
+		if spec.Comment != nil {
+			end = spec.Comment.End()
+		}
+
+		lit := spec.Path.Value
+		if path, ok := rewritten[spec]; ok {
+			lit = path
+		}
+		text := string(src[offset(start):offset(spec.Path.Pos())]) + lit + string(src[offset(spec.Path.End()):offset(end)])
+
+		importPath, _ := strconv.Unquote(lit)
+		if isStandardImport(importPath, moduleName) {
+			std = append(std, entry{importPath, text})
+		} else {
+			other = append(other, entry{importPath, text})
+		}
+	}
+
+	var groups []string
+	for _, group := range [][]entry{std, other} {
+		if len(group) == 0 {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool { return group[i].path < group[j].path })
+		lines := make([]string, len(group))
+		for i, e := range group {
+			lines[i] = "\t" + e.text
+		}
+		groups = append(groups, strings.Join(lines, "\n"))
+	}
+	return textEdit{offset(gen.Lparen) + 1, offset(gen.Rparen), "\n" + strings.Join(groups, "\n\n") + "\n"}, true
+}
+
+// isStandardImport reports whether importPath is a standard library package. Packages of
+// moduleName never are, even when it has no dot like the fallback "model"; otherwise GOROOT
+// decides, and only without a GOROOT does a first path element lacking a dot count as standard
+func isStandardImport(importPath, moduleName string) bool {
+	if moduleName != "" && (importPath == moduleName || strings.HasPrefix(importPath, moduleName+"/")) {
+		return false
+	}
+	if src := filepath.Join(build.Default.GOROOT, "src"); build.Default.GOROOT != "" && isDir(src) {
+		return isDir(filepath.Join(src, filepath.FromSlash(importPath)))
+	}
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
 }
@@ -0,0 +1,147 @@
+package fixer_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+)
+
+func TestFixImportsOnlyTouchesImportSpecs(t *testing.T) {
+	dir := t.TempDir()
+	src := `package main
+
+import (
+	"` + dir + `/internal/config"
+	"github.com/zeromicro/go-zero/rest"
+	"fmt" // printing
+)
+
+// Generated from ` + dir + `/demo.api
+const source = "` + dir + `/demo.api"
+
+func main() {
+	fmt.Println(source, config.Config{}, rest.RestConf{})
+}
+`
+	writeTestFiles(t, dir, map[string]string{"main.go": src})
+
+	changes, err := fixer.FixImports(dir, "example.com/demo")
+	if err != nil {
+		t.Fatalf("FixImports() failed: %v", err)
+	}
+	want := []fixer.ImportChange{{File: "main.go", From: dir + "/internal/config", To: "example.com/demo/internal/config"}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "main.go"))
+	got := string(content)
+	wantImports := "import (\n\t\"fmt\" // printing\n\n\t\"example.com/demo/internal/config\"\n\t\"github.com/zeromicro/go-zero/rest\"\n)\n"
+	if !strings.Contains(got, wantImports) {
+		t.Errorf("imports not regrouped:\n%s", got)
+	}
+	for _, kept := range []string{"// Generated from " + dir + "/demo.api", `const source = "` + dir + `/demo.api"`} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("rewrote outside import specs, missing %q:\n%s", kept, got)
+		}
+	}
+}
+
+func TestFixImportsThroughSymlink(t *testing.T) {
+	real := t.TempDir()
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	writeTestFiles(t, real, map[string]string{
+		"a.go":            "package main\n\nimport \"" + real + "/internal/a\"\n\nvar _ = a.X\n",
+		"b.go":            "package main\n\nimport \"" + link + "/internal/b\"\n\nvar _ = b.X\n",
+		"internal/a/a.go": "package a\n\nconst X = 1\n",
+		"internal/b/b.go": "package b\n\nconst X = 1\n",
+	})
+
+	// Reached through the link, imports spelled with the real path are rewritten too, and the other way round
+	for _, projectPath := range []string{link, real} {
+		changes, err := fixer.FixImports(projectPath, "example.com/demo")
+		if err != nil {
+			t.Fatalf("FixImports(%s) failed: %v", projectPath, err)
+		}
+		if projectPath == link && len(changes) != 2 {
+			t.Errorf("FixImports(%s) changes = %+v, want 2", projectPath, changes)
+		}
+	}
+	for _, name := range []string{"a.go", "b.go"} {
+		content, _ := os.ReadFile(filepath.Join(real, name))
+		if !strings.Contains(string(content), `"example.com/demo/internal/`) {
+			t.Errorf("%s not rewritten:\n%s", name, content)
+		}
+	}
+}
+
+func TestFixImportsWindowsPath(t *testing.T) {
+	dir := t.TempDir()
+	winPath := strings.ReplaceAll(dir, "/", `\\`)
+	writeTestFiles(t, dir, map[string]string{
+		"main.go": "package main\n\nimport \"" + winPath + `\\internal\\config` + "\"\n\nvar _ = config.C\n",
+	})
+
+	changes, err := fixer.FixImports(dir, "example.com/demo")
+	if err != nil {
+		t.Fatalf("FixImports() failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].To != "example.com/demo/internal/config" {
+		t.Errorf("changes = %+v", changes)
+	}
+}
+
+func TestFixImportsDetectsModule(t *testing.T) {
+	root := t.TempDir()
+	serviceDir := filepath.Join(root, "services", "demo")
+	writeTestFiles(t, root, map[string]string{
+		"go.mod":                   "module example.com/mono\n",
+		"services/demo/handler.go": "package demo\n\nimport \"" + serviceDir + "/internal/logic\"\n\nvar _ = logic.X\n",
+	})
+
+	changes, err := fixer.FixImports(serviceDir, "")
+	if err != nil {
+		t.Fatalf("FixImports() failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].To != "example.com/mono/services/demo/internal/logic" {
+		t.Errorf("changes = %+v", changes)
+	}
+
+	if _, err := fixer.FixImports(t.TempDir(), ""); err == nil {
+		t.Error("expected an error without a go.mod or module name")
+	}
+}
+
+func TestFixImportsGroupsDotlessModules(t *testing.T) {
+	dir := t.TempDir()
+	src := `package main
+
+import (
+	"` + dir + `/internal/model"
+	"user/rpc/userclient"
+	"fmt"
+)
+
+func main() {
+	fmt.Println(model.X, userclient.Y)
+}
+`
+	writeTestFiles(t, dir, map[string]string{"main.go": src})
+
+	if _, err := fixer.FixImports(dir, "model"); err != nil {
+		t.Fatalf("FixImports() failed: %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, "main.go"))
+	// Neither the module being fixed nor another dotless module is the standard library
+	want := "import (\n\t\"fmt\"\n\n\t\"model/internal/model\"\n\t\"user/rpc/userclient\"\n)\n"
+	if !strings.Contains(string(content), want) {
+		t.Errorf("imports grouped with the standard library:\n%s", content)
+	}
+}
@@ -1,14 +1,18 @@
 package fixer
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+
+	"github.com/zeromicro/mcp-zero/internal/goctl"
 )
 
 // InitializeGoModule initializes a Go module in the project directory
-func InitializeGoModule(projectPath string, moduleName string) error {
+// Skipped when go.mod already exists there, or when an enclosing module already provides
+// moduleName as the directory's import path; an empty moduleName accepts any enclosing module
+func InitializeGoModule(ctx context.Context, projectPath string, moduleName string) error {
 	// Check if go.mod already exists
 	goModPath := filepath.Join(projectPath, "go.mod")
 	if _, err := os.Stat(goModPath); err == nil {
@@ -16,59 +20,76 @@ func InitializeGoModule(projectPath string, moduleName string) error {
 		return nil
 	}
 
-	// Run go mod init
-	cmd := exec.Command("go", "mod", "init", moduleName)
-	cmd.Dir = projectPath
-	output, err := cmd.CombinedOutput()
+	module, err := DetectModule(projectPath)
 	if err != nil {
-		return fmt.Errorf("go mod init failed: %s\n%s", err, string(output))
+		return err
+	}
+	if module.Covers(moduleName) {
+		// An enclosing module such as a monorepo root already covers the directory
+		return nil
+	}
+	if moduleName == "" {
+		return fmt.Errorf("no enclosing go.mod found for %s and no module path given", projectPath)
+	}
+
+	// Run go mod init
+	if err := runGo(ctx, projectPath, "mod", "init", moduleName); err != nil {
+		return fmt.Errorf("go mod init failed: %w", err)
 	}
 
 	// Run go mod tidy to resolve dependencies
-	return TidyGoModule(projectPath)
+	return TidyGoModule(ctx, projectPath)
 }
 
 // TidyGoModule runs go mod tidy to resolve dependencies
-func TidyGoModule(projectPath string) error {
-	cmd := exec.Command("go", "mod", "tidy")
-	cmd.Dir = projectPath
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("go mod tidy failed: %s\n%s", err, string(output))
+// Offline, go-zero is first pinned to a version in the module cache; in vendor mode the
+// vendor directory is only checked, as go mod tidy needs the source of every module
+func TidyGoModule(ctx context.Context, projectPath string) error {
+	switch deps := dependenciesFrom(ctx); deps.Mode {
+	case DepsVendor:
+		return checkVendor(projectPath)
+	case DepsOffline:
+		if err := pinGoZero(ctx, projectPath, deps); err != nil {
+			return err
+		}
+	}
+	if err := runGo(ctx, projectPath, "mod", "tidy"); err != nil {
+		return fmt.Errorf("go mod tidy failed: %w", err)
 	}
 
 	return nil
 }
 
-// VerifyBuild verifies the project builds successfully
-func VerifyBuild(projectPath string) error {
-	cmd := exec.Command("go", "build", "-o", "/dev/null", ".")
-	cmd.Dir = projectPath
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("build failed: %s\n%s", err, string(output))
-	}
+// VerifyBuild verifies every package under the project builds; compile errors are
+// returned as a *VerifyError. Use Verify to also run go vet, go test or gofmt
+func VerifyBuild(ctx context.Context, projectPath string) error {
+	return RunCheck(ctx, projectPath, CheckBuild)
+}
 
+// runGo runs the go command in dir with the command's default timeout, resolving modules
+// in the dependency mode of ctx
+// The returned error carries the combined output, or is a *MissingModulesError when an
+// offline or vendor run could not find modules
+func runGo(ctx context.Context, dir string, args ...string) error {
+	deps := dependenciesFrom(ctx)
+	result := goctl.RunEnv(ctx, dir, goEnv(dir, deps), 0, "go", args...)
+	if result.Error != nil {
+		output := result.Stdout + result.Stderr
+		if deps.Mode != DepsOnline {
+			if missing := MissingModules(output); len(missing) > 0 {
+				return &MissingModulesError{Modules: missing, Deps: deps, Output: output}
+			}
+		}
+		return fmt.Errorf("%v\n%s", result.Error, output)
+	}
 	return nil
 }
 
 // GetGoModuleName extracts module name from go.mod file
 func GetGoModuleName(projectPath string) (string, error) {
 	goModPath := filepath.Join(projectPath, "go.mod")
-	content, err := os.ReadFile(goModPath)
-	if err != nil {
+	if _, err := os.Stat(goModPath); err != nil {
 		return "", fmt.Errorf("failed to read go.mod: %w", err)
 	}
-
-	// Parse module name from first line: "module modulename"
-	lines := string(content)
-	if len(lines) > 7 && lines[:7] == "module " {
-		endIdx := 7
-		for endIdx < len(lines) && lines[endIdx] != '\n' && lines[endIdx] != '\r' {
-			endIdx++
-		}
-		return lines[7:endIdx], nil
-	}
-
-	return "", fmt.Errorf("could not find module name in go.mod")
+	return readModulePath(goModPath)
 }
@@ -0,0 +1,213 @@
+package fixer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// wordCase is how a naming style spells a word
+type wordCase int
+
+const (
+	lowerCase wordCase = iota // zero
+	titleCase                 // Zero
+	upperCase                 // ZERO
+)
+
+// NamingStyle is a goctl --style file naming format: "go" and "zero" written in the case
+// of the first and the following words, joined by the separator between them, such as
+// go_zero (get_user_handler.go), gozero (getuserhandler.go), goZero (getUserHandler.go)
+// or a custom format like Go-Zero (Get-User-Handler.go)
+type NamingStyle struct {
+	Format string
+
+	prefix, separator, suffix string
+	first, rest               wordCase
+}
+
+// ParseStyle parses a goctl style format
+func ParseStyle(format string) (NamingStyle, error) {
+	upper := strings.ToUpper(format)
+	goIndex := strings.Index(upper, "GO")
+	zeroIndex := strings.Index(upper, "ZERO")
+	if goIndex < 0 || zeroIndex < goIndex+2 {
+		return NamingStyle{}, fmt.Errorf("invalid style %q: it must contain go followed by zero, like go_zero, gozero or goZero", format)
+	}
+
+	style := NamingStyle{
+		Format:    format,
+		prefix:    format[:goIndex],
+		separator: format[goIndex+2 : zeroIndex],
+		suffix:    format[zeroIndex+4:],
+	}
+	var ok bool
+	if style.first, ok = parseWordCase(format[goIndex:goIndex+2], "go"); !ok {
+		return NamingStyle{}, fmt.Errorf("invalid style %q: write go as go, Go or GO", format)
+	}
+	if style.rest, ok = parseWordCase(format[zeroIndex:zeroIndex+4], "zero"); !ok {
+		return NamingStyle{}, fmt.Errorf("invalid style %q: write zero as zero, Zero or ZERO", format)
+	}
+	if strings.ContainsAny(style.prefix+style.separator+style.suffix, `/\.`) {
+		return NamingStyle{}, fmt.Errorf("invalid style %q: file names cannot contain '/', '\\' or '.'", format)
+	}
+	return style, nil
+}
+
+func parseWordCase(spelled, word string) (wordCase, bool) {
+	switch spelled {
+	case word:
+		return lowerCase, true
+	case strings.ToUpper(word[:1]) + word[1:]:
+		return titleCase, true
+	case strings.ToUpper(word):
+		return upperCase, true
+	}
+	return 0, false
+}
+
+// Name spells words, such as [get user handler], in the style
+func (s NamingStyle) Name(words []string) string {
+	spelled := make([]string, len(words))
+	for i, word := range words {
+		wc := s.rest
+		if i == 0 {
+			wc = s.first
+		}
+		spelled[i] = applyCase(strings.ToLower(word), wc)
+	}
+	return s.prefix + strings.Join(spelled, s.separator) + s.suffix
+}
+
+func applyCase(word string, wc wordCase) string {
+	switch {
+	case word == "":
+		return word
+	case wc == upperCase:
+		return strings.ToUpper(word)
+	case wc == titleCase:
+		return strings.ToUpper(word[:1]) + word[1:]
+	}
+	return word
+}
+
+// styleSuffixes are the trailing words goctl appends to generated names, used to split
+// flat names such as getuserhandler or servicecontext
+var styleSuffixes = []string{"handler", "logic", "server", "context"}
+
+// stemWords splits a file name without extension into words
+// ok is false when the name carries no style evidence: a single word, or irregular
+// separators and casing. Words are returned either way
+func stemWords(stem string) (words []string, style NamingStyle, ok bool) {
+	var parts, separators []string
+	regular := true
+	for rest := stem; rest != ""; {
+		n := strings.IndexFunc(rest, isSeparator)
+		if n < 0 {
+			parts = append(parts, rest)
+			break
+		}
+		if n == 0 {
+			regular = false
+		} else {
+			parts = append(parts, rest[:n])
+		}
+		rest = rest[n:]
+		m := strings.IndexFunc(rest, func(r rune) bool { return !isSeparator(r) })
+		if m < 0 {
+			regular = false
+			break
+		}
+		if len(parts) > 0 {
+			separators = append(separators, rest[:m])
+		}
+		rest = rest[m:]
+	}
+
+	camel := false
+	for _, part := range parts {
+		split := splitCamel(part)
+		camel = camel || len(split) > 1
+		words = append(words, split...)
+	}
+	if !regular || len(words) == 0 || (camel && len(separators) > 0) {
+		return words, NamingStyle{}, false
+	}
+	for _, sep := range separators {
+		if sep != separators[0] {
+			return words, NamingStyle{}, false
+		}
+	}
+
+	if len(words) == 1 {
+		word := words[0]
+		if word != strings.ToLower(word) {
+			return words, NamingStyle{}, false
+		}
+		for _, suffix := range styleSuffixes {
+			if strings.HasSuffix(word, suffix) && len(word) > len(suffix) {
+				words = []string{strings.TrimSuffix(word, suffix), suffix}
+				style, _ := ParseStyle("gozero")
+				return words, style, true
+			}
+		}
+		return words, NamingStyle{}, false
+	}
+
+	first, ok := wordCaseOf(words[0])
+	if !ok {
+		return words, NamingStyle{}, false
+	}
+	rest, ok := wordCaseOf(words[1])
+	if !ok {
+		return words, NamingStyle{}, false
+	}
+	for _, word := range words[2:] {
+		if wc, ok := wordCaseOf(word); !ok || wc != rest {
+			return words, NamingStyle{}, false
+		}
+	}
+	separator := ""
+	if len(separators) > 0 {
+		separator = separators[0]
+	}
+	style, err := ParseStyle(applyCase("go", first) + separator + applyCase("zero", rest))
+	if err != nil {
+		return words, NamingStyle{}, false
+	}
+	return words, style, true
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// splitCamel splits getUserHandler into get, User, Handler and HTTPServer into HTTP, Server
+func splitCamel(s string) []string {
+	runes := []rune(s)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		upper := unicode.IsUpper(runes[i])
+		prevUpper := unicode.IsUpper(runes[i-1])
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if upper && (!prevUpper || nextLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	return append(words, string(runes[start:]))
+}
+
+// wordCaseOf classifies a word; digits take no case
+func wordCaseOf(word string) (wordCase, bool) {
+	switch {
+	case word == strings.ToLower(word):
+		return lowerCase, true
+	case word == strings.ToUpper(word) && len(word) > 1:
+		return upperCase, true
+	case word == strings.ToUpper(word[:1])+strings.ToLower(word[1:]):
+		return titleCase, true
+	}
+	return 0, false
+}
@@ -2,31 +2,84 @@ package fixer
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
-// StyleConflictPair represents a pair of files that conflict due to different naming styles
-type StyleConflictPair struct {
-	GoZeroStyle string // go_zero style: snake_case (e.g., service_context.go)
-	GoZeroFlat  string // gozero style: flat (e.g., servicecontext.go)
+// styledDirs are the goctl output directories under internal/ whose file names follow --style
+var styledDirs = map[string]bool{
+	"handler": true,
+	"logic":   true,
+	"types":   true,
+	"svc":     true,
+	"config":  true,
+	"server":  true,
 }
 
-// knownStyleConflicts lists all known file pairs that can conflict between go_zero and gozero styles
-var knownStyleConflicts = []StyleConflictPair{
-	{GoZeroStyle: "service_context.go", GoZeroFlat: "servicecontext.go"},
-	// Add more known conflicts here if discovered
+// generatedHeader marks files goctl rewrites on every run
+const generatedHeader = "Code generated by goctl. DO NOT EDIT."
+
+// StyleConflict is a set of files in one directory that are the same generated file
+// named in different styles, such as service_context.go and servicecontext.go
+type StyleConflict struct {
+	Dir   string   `json:"dir"`   // slash-separated, relative to the project
+	Files []string `json:"files"` // base names
 }
 
-// CleanupStyleConflicts removes conflicting files based on the chosen style
-// This prevents duplicate type declarations when switching between go_zero and gozero styles
-func CleanupStyleConflicts(projectPath string, style string) error {
-	// Determine which style's files to keep
-	keepGoZeroStyle := (style == "go_zero")
+// StyleReport describes the naming styles of a project's generated files
+type StyleReport struct {
+	Styles    map[string][]string `json:"styles"`    // style format → files named in it
+	Conflicts []StyleConflict     `json:"conflicts"` // same file in several styles
+}
+
+// Mixed reports whether generated files use more than one naming style
+func (r *StyleReport) Mixed() bool {
+	return len(r.Styles) > 1
+}
+
+// Dominant returns the style naming the most files, or "" when no file shows a style
+func (r *StyleReport) Dominant() string {
+	best := ""
+	for style, files := range r.Styles {
+		if best == "" || len(files) > len(r.Styles[best]) || (len(files) == len(r.Styles[best]) && style < best) {
+			best = style
+		}
+	}
+	return best
+}
+
+// styledFile is a generated Go file whose name follows the naming style
+type styledFile struct {
+	rel    string // slash-separated, relative to the project
+	stem   string // base name without .go and suffix
+	suffix string // _test and _GOOS/_GOARCH build suffixes, which no style touches
+	words  []string
+	style  NamingStyle
+	known  bool // style was detected from the name
+}
+
+func (f styledFile) dir() string  { return path.Dir(f.rel) }
+func (f styledFile) base() string { return path.Base(f.rel) }
 
-	// Walk through project directories looking for conflicts
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+// key is the style-independent name of the file: getUserHandler.go and get_user_handler.go share it
+func (f styledFile) key() string {
+	return strings.ToLower(strings.Join(f.words, "")) + f.suffix
+}
+
+// renamed returns the base name of the file in another style
+func (f styledFile) renamed(style NamingStyle) string {
+	return style.Name(f.words) + f.suffix + ".go"
+}
+
+// styledFiles lists the Go files in goctl output directories such as internal/handler,
+// including group subdirectories and every service of a monorepo
+func styledFiles(projectPath string) ([]styledFile, error) {
+	var files []styledFile
+	err := filepath.WalkDir(projectPath, func(p string, d fs.DirEntry, err error) error {
 		if err != nil {
 			// Ignore "no such file" errors - we might have deleted the file during cleanup
 			if os.IsNotExist(err) {
@@ -34,79 +87,175 @@ func CleanupStyleConflicts(projectPath string, style string) error {
 			}
 			return err
 		}
-
-		// Skip if not a directory
-		if !info.IsDir() {
+		if d.IsDir() {
+			if name := d.Name(); p != projectPath && (name == "vendor" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
-
-		// Check each known conflict pair
-		for _, conflict := range knownStyleConflicts {
-			goZeroFile := filepath.Join(path, conflict.GoZeroStyle)
-			gozeroFile := filepath.Join(path, conflict.GoZeroFlat)
-
-			// Check if both files exist (conflict!)
-			goZeroExists := fileExists(goZeroFile)
-			gozeroExists := fileExists(gozeroFile)
-
-			if goZeroExists && gozeroExists {
-				// Both exist - remove the one we don't want
-				var fileToRemove string
-				if keepGoZeroStyle {
-					fileToRemove = gozeroFile
-				} else {
-					fileToRemove = goZeroFile
-				}
-
-				// Double-check file exists before removing
-				if fileExists(fileToRemove) {
-					if err := os.Remove(fileToRemove); err != nil {
-						return fmt.Errorf("failed to remove conflicting file %s: %w", fileToRemove, err)
-					}
-				}
-			}
+		if !strings.HasSuffix(p, ".go") {
+			return nil
+		}
+		rel, err := filepath.Rel(projectPath, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !inStyledDir(rel) {
+			return nil
 		}
 
+		stem, suffix := splitBuildSuffix(strings.TrimSuffix(path.Base(rel), ".go"))
+		words, style, known := stemWords(stem)
+		files = append(files, styledFile{rel: rel, stem: stem, suffix: suffix, words: words, style: style, known: known})
 		return nil
 	})
+	return files, err
+}
 
-	return err
+// buildSuffixes are the file name elements the go command reads as build constraints
+var buildSuffixes = map[string]bool{
+	"test": true,
+	// GOOS
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true, "illumos": true,
+	"ios": true, "js": true, "linux": true, "netbsd": true, "openbsd": true, "plan9": true,
+	"solaris": true, "wasip1": true, "windows": true,
+	// GOARCH
+	"386": true, "amd64": true, "arm": true, "arm64": true, "loong64": true, "mips": true,
+	"mips64": true, "mips64le": true, "mipsle": true, "ppc64": true, "ppc64le": true,
+	"riscv64": true, "s390x": true, "wasm": true,
 }
 
-// DetectExistingStyle detects which naming style is currently used in the project
-// Returns "go_zero" or "gozero", or empty string if cannot determine
-func DetectExistingStyle(projectPath string) string {
-	for _, conflict := range knownStyleConflicts {
-		// Check in common locations
-		commonDirs := []string{
-			filepath.Join(projectPath, "internal", "svc"),
-			filepath.Join(projectPath, "internal", "handler"),
-			filepath.Join(projectPath, "internal", "logic"),
+// splitBuildSuffix splits get_user_logic_linux_test into get_user_logic and _linux_test,
+// so renaming a file never changes the platforms it builds for
+func splitBuildSuffix(name string) (stem, suffix string) {
+	stem = name
+	for range 3 {
+		i := strings.LastIndex(stem, "_")
+		if i <= 0 || !buildSuffixes[stem[i+1:]] {
+			break
 		}
+		stem, suffix = stem[:i], stem[i:]+suffix
+	}
+	return stem, suffix
+}
+
+// inStyledDir reports whether rel lies under internal/<handler|logic|...>
+func inStyledDir(rel string) bool {
+	parts := strings.Split(path.Dir(rel), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "internal" && styledDirs[parts[i+1]] {
+			return true
+		}
+	}
+	return false
+}
+
+// AnalyzeStyles reports the naming styles used by generated files and the files that
+// exist in more than one style
+func AnalyzeStyles(projectPath string) (*StyleReport, error) {
+	files, err := styledFiles(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StyleReport{Styles: make(map[string][]string)}
+	for _, file := range files {
+		if file.known {
+			report.Styles[file.style.Format] = append(report.Styles[file.style.Format], file.rel)
+		}
+	}
+	for _, group := range conflictGroups(files) {
+		conflict := StyleConflict{Dir: group[0].dir()}
+		for _, file := range group {
+			conflict.Files = append(conflict.Files, file.base())
+		}
+		report.Conflicts = append(report.Conflicts, conflict)
+	}
+	return report, nil
+}
+
+// conflictGroups returns the sets of files in one directory sharing a style-independent name
+func conflictGroups(files []styledFile) [][]styledFile {
+	groups := make(map[string][]styledFile)
+	var keys []string
+	for _, file := range files {
+		key := file.dir() + "/" + file.key()
+		if len(groups[key]) == 0 {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], file)
+	}
+	sort.Strings(keys)
+
+	var conflicts [][]styledFile
+	for _, key := range keys {
+		if len(groups[key]) > 1 {
+			conflicts = append(conflicts, groups[key])
+		}
+	}
+	return conflicts
+}
 
-		for _, dir := range commonDirs {
-			goZeroFile := filepath.Join(dir, conflict.GoZeroStyle)
-			gozeroFile := filepath.Join(dir, conflict.GoZeroFlat)
+// CleanupStyleConflicts removes conflicting files based on the chosen style
+// This prevents duplicate type declarations when switching styles. Only files goctl
+// regenerates (marked DO NOT EDIT) and svc files are removed; hand-written logic or
+// config duplicates are left for ValidateNoStyleConflicts to report
+func CleanupStyleConflicts(projectPath string, style string) error {
+	chosen, err := ParseStyle(style)
+	if err != nil {
+		return err
+	}
+	files, err := styledFiles(projectPath)
+	if err != nil {
+		return err
+	}
 
-			if fileExists(goZeroFile) {
-				return "go_zero"
+	for _, group := range conflictGroups(files) {
+		// Keep the file already named in the chosen style, if any
+		keep := -1
+		for i, file := range group {
+			if file.renamed(chosen) == file.base() {
+				keep = i
+			}
+		}
+		if keep < 0 {
+			continue
+		}
+		for i, file := range group {
+			if i == keep {
+				continue
+			}
+			filePath := filepath.Join(projectPath, filepath.FromSlash(file.rel))
+			if !removableConflict(filePath, file) {
+				continue
 			}
-			if fileExists(gozeroFile) {
-				return "gozero"
+			if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove conflicting file %s: %w", filePath, err)
 			}
 		}
 	}
+	return nil
+}
 
-	return ""
+// removableConflict reports whether a duplicate may be deleted: goctl writes it again
+func removableConflict(filePath string, file styledFile) bool {
+	if strings.Contains("/"+file.dir()+"/", "/internal/svc/") {
+		return true
+	}
+	content, err := os.ReadFile(filePath)
+	return err == nil && strings.Contains(string(content), generatedHeader)
 }
 
-// fileExists checks if a file exists
-func fileExists(path string) bool {
-	info, err := os.Stat(path)
+// DetectExistingStyle detects which naming style is currently used in the project
+// Returns the style format naming the most generated files, such as "go_zero", "gozero"
+// or "goZero", or empty string if cannot determine
+func DetectExistingStyle(projectPath string) string {
+	report, err := AnalyzeStyles(projectPath)
 	if err != nil {
-		return false
+		return ""
 	}
-	return !info.IsDir()
+	return report.Dominant()
 }
 
 // SuggestStyleBasedOnExisting suggests which style to use based on existing files
@@ -121,38 +270,96 @@ func SuggestStyleBasedOnExisting(projectPath string, defaultStyle string) string
 // ValidateNoStyleConflicts checks if there are any style conflicts in the project
 // Returns an error if conflicts are found
 func ValidateNoStyleConflicts(projectPath string) error {
-	var conflicts []string
-
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+	report, err := AnalyzeStyles(projectPath)
+	if err != nil {
+		return err
+	}
+	if len(report.Conflicts) == 0 {
+		return nil
+	}
 
-		if !info.IsDir() {
-			return nil
-		}
+	conflicts := make([]string, len(report.Conflicts))
+	for i, conflict := range report.Conflicts {
+		files := conflict.Files
+		conflicts[i] = fmt.Sprintf("%s: both %s and %s exist", conflict.Dir, strings.Join(files[:len(files)-1], ", "), files[len(files)-1])
+	}
+	return fmt.Errorf("style conflicts detected:\n%s\nUse migrate_style to rename every generated file to one style", strings.Join(conflicts, "\n"))
+}
 
-		for _, conflict := range knownStyleConflicts {
-			goZeroFile := filepath.Join(path, conflict.GoZeroStyle)
-			gozeroFile := filepath.Join(path, conflict.GoZeroFlat)
+// StyleRename is a file renamed by MigrateStyle
+type StyleRename struct {
+	From string `json:"from"` // slash-separated, relative to the project
+	To   string `json:"to"`
+}
 
-			if fileExists(goZeroFile) && fileExists(gozeroFile) {
-				relPath, _ := filepath.Rel(projectPath, path)
-				conflicts = append(conflicts, fmt.Sprintf("%s: both %s and %s exist",
-					relPath, conflict.GoZeroStyle, conflict.GoZeroFlat))
-			}
+// PlanStyleMigration lists the renames that bring generated files to the target style
+// With from set, only files detected in that style move; single-word files such as
+// config.go follow the target style either way. Fails on existing style conflicts
+// and on renames that would overwrite a file
+func PlanStyleMigration(projectPath, to, from string) ([]StyleRename, error) {
+	target, err := ParseStyle(to)
+	if err != nil {
+		return nil, err
+	}
+	var source NamingStyle
+	if from != "" {
+		if source, err = ParseStyle(from); err != nil {
+			return nil, err
 		}
+	}
 
-		return nil
-	})
-
+	files, err := styledFiles(projectPath)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if groups := conflictGroups(files); len(groups) > 0 {
+		return nil, ValidateNoStyleConflicts(projectPath)
 	}
 
-	if len(conflicts) > 0 {
-		return fmt.Errorf("style conflicts detected:\n%s", strings.Join(conflicts, "\n"))
+	existing := make(map[string]bool)
+	for _, file := range files {
+		existing[strings.ToLower(file.rel)] = true
+	}
+	var renames []StyleRename
+	targets := make(map[string]string)
+	for _, file := range files {
+		if from != "" && file.known && file.style.Format != source.Format {
+			continue
+		}
+		newRel := path.Join(file.dir(), file.renamed(target))
+		if newRel == file.rel {
+			continue
+		}
+		lower := strings.ToLower(newRel)
+		if other, ok := targets[lower]; ok {
+			return nil, fmt.Errorf("%s and %s would both be renamed to %s", other, file.rel, newRel)
+		}
+		// A differently-cased target is the file itself on case-insensitive file systems
+		if existing[lower] && !strings.EqualFold(newRel, file.rel) {
+			return nil, fmt.Errorf("cannot rename %s to %s: the file already exists", file.rel, newRel)
+		}
+		targets[lower] = file.rel
+		renames = append(renames, StyleRename{From: file.rel, To: newRel})
 	}
+	return renames, nil
+}
 
+// ApplyStyleMigration renames files as planned by PlanStyleMigration
+func ApplyStyleMigration(projectPath string, renames []StyleRename) error {
+	for _, rename := range renames {
+		from := filepath.Join(projectPath, filepath.FromSlash(rename.From))
+		to := filepath.Join(projectPath, filepath.FromSlash(rename.To))
+		if strings.EqualFold(rename.From, rename.To) {
+			// Go through a temporary name so case-only renames work on case-insensitive file systems
+			tmp := from + ".style-migration"
+			if err := os.Rename(from, tmp); err != nil {
+				return fmt.Errorf("failed to rename %s: %w", rename.From, err)
+			}
+			from = tmp
+		}
+		if err := os.Rename(from, to); err != nil {
+			return fmt.Errorf("failed to rename %s to %s: %w", rename.From, rename.To, err)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,164 @@
+package fixer_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+)
+
+func TestParseStyle(t *testing.T) {
+	words := []string{"get", "user", "handler"}
+	for format, want := range map[string]string{
+		"go_zero": "get_user_handler",
+		"gozero":  "getuserhandler",
+		"goZero":  "getUserHandler",
+		"GoZero":  "GetUserHandler",
+		"Go-Zero": "Get-User-Handler",
+		"GO_ZERO": "GET_USER_HANDLER",
+	} {
+		style, err := fixer.ParseStyle(format)
+		if err != nil {
+			t.Errorf("ParseStyle(%s) failed: %v", format, err)
+			continue
+		}
+		if got := style.Name(words); got != want {
+			t.Errorf("%s.Name() = %s, want %s", format, got, want)
+		}
+	}
+
+	for _, format := range []string{"", "zero_go", "snake", "go/zero", "gO_zero"} {
+		if _, err := fixer.ParseStyle(format); err == nil {
+			t.Errorf("ParseStyle(%q) succeeded, want error", format)
+		}
+	}
+}
+
+func TestAnalyzeStylesDetectsMixedStyles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"internal/handler/routes.go":                  "package handler\n",
+		"internal/handler/get_user_handler.go":        "package handler\n",
+		"internal/handler/user/list_users_handler.go": "package user\n",
+		"internal/logic/getUserLogic.go":              "package logic\n",
+		"internal/logic/getUserLogic_test.go":         "package logic\n",
+		"internal/svc/servicecontext.go":              "package svc\n",
+		"internal/svc/service_context.go":             "package svc\n",
+		"cmd/not_generated.go":                        "package main\n",
+	})
+
+	report, err := fixer.AnalyzeStyles(dir)
+	if err != nil {
+		t.Fatalf("AnalyzeStyles() failed: %v", err)
+	}
+	if !report.Mixed() {
+		t.Error("expected mixed styles")
+	}
+	if got := len(report.Styles["go_zero"]); got != 3 {
+		t.Errorf("go_zero files = %v, want 3", report.Styles["go_zero"])
+	}
+	if got := len(report.Styles["goZero"]); got != 2 {
+		t.Errorf("goZero files = %v, want 2", report.Styles["goZero"])
+	}
+	if report.Dominant() != "go_zero" {
+		t.Errorf("Dominant() = %s, want go_zero", report.Dominant())
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Dir != "internal/svc" {
+		t.Errorf("unexpected conflicts: %+v", report.Conflicts)
+	}
+}
+
+func TestValidateNoStyleConflictsAcrossStyles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"internal/handler/getUserHandler.go":   "package handler\n",
+		"internal/handler/get_user_handler.go": "package handler\n",
+	})
+
+	err := fixer.ValidateNoStyleConflicts(dir)
+	if err == nil || !strings.Contains(err.Error(), "internal/handler: both getUserHandler.go and get_user_handler.go exist") {
+		t.Errorf("ValidateNoStyleConflicts() = %v", err)
+	}
+}
+
+func TestCleanupStyleConflictsKeepsHandWrittenLogic(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"internal/handler/get_user_handler.go": "// Code generated by goctl. DO NOT EDIT.\npackage handler\n",
+		"internal/handler/getUserHandler.go":   "package handler\n",
+		"internal/logic/get_user_logic.go":     "package logic\n",
+		"internal/logic/getUserLogic.go":       "package logic\n",
+	})
+
+	if err := fixer.CleanupStyleConflicts(dir, "goZero"); err != nil {
+		t.Fatalf("CleanupStyleConflicts() failed: %v", err)
+	}
+	for rel, want := range map[string]bool{
+		"internal/handler/get_user_handler.go": false,
+		"internal/handler/getUserHandler.go":   true,
+		"internal/logic/get_user_logic.go":     true,
+		"internal/logic/getUserLogic.go":       true,
+	} {
+		if _, err := os.Stat(filepath.Join(dir, rel)); (err == nil) != want {
+			t.Errorf("%s exists = %v, want %v", rel, err == nil, want)
+		}
+	}
+}
+
+func TestPlanStyleMigration(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"internal/config/config.go":             "package config\n",
+		"internal/handler/get_user_handler.go":  "package handler\n",
+		"internal/logic/get_user_logic_test.go": "package logic\n",
+		"internal/logic/ping_logic_linux.go":    "package logic\n",
+		"internal/svc/servicecontext.go":        "package svc\n",
+	})
+
+	renames, err := fixer.PlanStyleMigration(dir, "GoZero", "go_zero")
+	if err != nil {
+		t.Fatalf("PlanStyleMigration() failed: %v", err)
+	}
+	got := map[string]string{}
+	for _, rename := range renames {
+		got[rename.From] = rename.To
+	}
+	want := map[string]string{
+		"internal/config/config.go":             "internal/config/Config.go",
+		"internal/handler/get_user_handler.go":  "internal/handler/GetUserHandler.go",
+		"internal/logic/get_user_logic_test.go": "internal/logic/GetUserLogic_test.go",
+		"internal/logic/ping_logic_linux.go":    "internal/logic/PingLogic_linux.go",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("renames = %v, want %v", got, want)
+	}
+	for from, to := range want {
+		if got[from] != to {
+			t.Errorf("%s renamed to %s, want %s", from, got[from], to)
+		}
+	}
+
+	if err := fixer.ApplyStyleMigration(dir, renames); err != nil {
+		t.Fatalf("ApplyStyleMigration() failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "internal", "config", "Config.go")); err != nil {
+		t.Errorf("case-only rename failed: %v", err)
+	}
+	if style := fixer.DetectExistingStyle(dir); style != "GoZero" {
+		t.Errorf("DetectExistingStyle() = %s, want GoZero", style)
+	}
+}
+
+func TestPlanStyleMigrationRefusesCollisions(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"internal/handler/get_user_handler.go": "package handler\n",
+		"internal/handler/getUser_handler.go":  "package handler\n",
+	})
+
+	if _, err := fixer.PlanStyleMigration(dir, "goZero", ""); err == nil {
+		t.Error("expected an error when two files map to one name")
+	}
+}
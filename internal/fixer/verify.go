@@ -0,0 +1,397 @@
+package fixer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/scanner"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Verification checks, run in this order
+const (
+	CheckBuild = "build" // go build ./...
+	CheckVet   = "vet"   // go vet ./...
+	CheckTest  = "test"  // go test ./...
+	CheckGofmt = "gofmt" // gofmt -l, in process
+)
+
+var allChecks = []string{CheckBuild, CheckVet, CheckTest, CheckGofmt}
+
+// VerifyOptions selects the checks run after go build ./..., which always runs
+type VerifyOptions struct {
+	Vet   bool
+	Test  bool
+	Gofmt bool
+}
+
+var (
+	verifyMu      sync.RWMutex
+	defaultVerify = VerifyOptions{Vet: true}
+)
+
+// ParseChecks builds options from check names such as ["vet", "gofmt"]; "build" is
+// accepted and always implied, and "all" enables every check
+func ParseChecks(names []string) (VerifyOptions, error) {
+	var opts VerifyOptions
+	for _, name := range names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case CheckBuild, "":
+		case CheckVet:
+			opts.Vet = true
+		case CheckTest:
+			opts.Test = true
+		case CheckGofmt:
+			opts.Gofmt = true
+		case "all":
+			opts = VerifyOptions{Vet: true, Test: true, Gofmt: true}
+		default:
+			return VerifyOptions{}, fmt.Errorf("unknown verification check %q: use build, vet, test, gofmt or all", name)
+		}
+	}
+	return opts, nil
+}
+
+// Checks lists the enabled checks in the order they run
+func (o VerifyOptions) Checks() []string {
+	enabled := map[string]bool{CheckBuild: true, CheckVet: o.Vet, CheckTest: o.Test, CheckGofmt: o.Gofmt}
+	var checks []string
+	for _, check := range allChecks {
+		if enabled[check] {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// SetDefaultVerifyOptions sets the server-wide checks used when a call does not choose any
+func SetDefaultVerifyOptions(opts VerifyOptions) {
+	verifyMu.Lock()
+	defer verifyMu.Unlock()
+	defaultVerify = opts
+}
+
+// DefaultVerifyOptions returns the server-wide checks: go build and go vet unless configured
+func DefaultVerifyOptions() VerifyOptions {
+	verifyMu.RLock()
+	defer verifyMu.RUnlock()
+	return defaultVerify
+}
+
+// ResolveVerifyOptions picks a call's checks: its own when given, otherwise the server-wide default
+func ResolveVerifyOptions(names []string) (VerifyOptions, error) {
+	if len(names) == 0 {
+		return DefaultVerifyOptions(), nil
+	}
+	return ParseChecks(names)
+}
+
+// CheckStep names the command a check runs, for pipeline steps and messages
+func CheckStep(check string) string {
+	if check == CheckGofmt {
+		return "gofmt"
+	}
+	return "go " + check
+}
+
+// Diagnostic is a problem reported by a verification check
+type Diagnostic struct {
+	Check   string `json:"check"`
+	File    string `json:"file,omitempty"` // slash-separated, relative to the verified directory
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	switch {
+	case d.File == "":
+		return d.Message
+	case d.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+	case d.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+	}
+	return d.File + ": " + d.Message
+}
+
+// VerifyError reports the checks that failed and their diagnostics
+type VerifyError struct {
+	Checks      []string     `json:"failed_checks"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+func (e *VerifyError) Error() string {
+	var b strings.Builder
+	for i, check := range e.Checks {
+		var lines []string
+		for _, d := range e.Diagnostics {
+			if d.Check == check {
+				lines = append(lines, "  "+strings.ReplaceAll(d.String(), "\n", "\n    "))
+			}
+		}
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s failed with %d problem(s):\n%s", checkCommand(check), len(lines), strings.Join(lines, "\n"))
+	}
+	return b.String()
+}
+
+func checkCommand(check string) string {
+	if check == CheckGofmt {
+		return "gofmt -l ."
+	}
+	return "go " + check + " ./..."
+}
+
+// Verify runs the selected checks on every package under projectPath
+// go vet and go test are skipped when the build fails, since they would repeat its errors;
+// all diagnostics are returned in a single *VerifyError
+func Verify(ctx context.Context, projectPath string, opts VerifyOptions) error {
+	failed := &VerifyError{}
+	buildFailed := false
+	for _, check := range opts.Checks() {
+		if buildFailed && (check == CheckVet || check == CheckTest) {
+			continue
+		}
+		err := RunCheck(ctx, projectPath, check)
+		var verr *VerifyError
+		switch {
+		case err == nil:
+		case errors.As(err, &verr):
+			failed.Checks = append(failed.Checks, verr.Checks...)
+			failed.Diagnostics = append(failed.Diagnostics, verr.Diagnostics...)
+			buildFailed = buildFailed || check == CheckBuild
+		default:
+			return err
+		}
+	}
+	if len(failed.Checks) > 0 {
+		return failed
+	}
+	return nil
+}
+
+// RunCheck runs one verification check; problems found are returned as a *VerifyError,
+// while failures to run the check at all, such as missing modules, are returned as they are
+func RunCheck(ctx context.Context, projectPath string, check string) error {
+	var diagnostics []Diagnostic
+	switch check {
+	case CheckBuild, CheckVet:
+		output, err := runGoOutput(ctx, projectPath, check, "./...")
+		if err == nil {
+			return nil
+		}
+		if diagnostics = parseCompilerOutput(projectPath, check, output); len(diagnostics) == 0 {
+			return err
+		}
+	case CheckTest:
+		output, err := runGoOutput(ctx, projectPath, "test", "-json", "./...")
+		if err == nil {
+			return nil
+		}
+		if diagnostics = parseTestOutput(projectPath, output); len(diagnostics) == 0 {
+			return err
+		}
+	case CheckGofmt:
+		var err error
+		if diagnostics, err = gofmtDiagnostics(projectPath); err != nil {
+			return err
+		}
+		if len(diagnostics) == 0 {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unknown verification check %q", check)
+	}
+	return &VerifyError{Checks: []string{check}, Diagnostics: diagnostics}
+}
+
+// runGoOutput is runGo returning the combined output of a failed command with its error
+func runGoOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	err := runGo(ctx, dir, args...)
+	var missing *MissingModulesError
+	if err == nil || errors.As(err, &missing) {
+		return "", err
+	}
+	_, output, _ := strings.Cut(err.Error(), "\n")
+	return output, err
+}
+
+// diagnosticPattern matches file:line[:column]: message lines of the go toolchain
+var diagnosticPattern = regexp.MustCompile(`^(?:vet: )?((?:[A-Za-z]:)?[^\s:]+\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// parseCompilerOutput turns go build or go vet output into diagnostics; indented lines
+// continue the previous diagnostic, and output without positions becomes a single one
+func parseCompilerOutput(projectPath, check, output string) []Diagnostic {
+	var diagnostics []Diagnostic
+	var other []string
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(line, "#"):
+		case (strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    ")) && len(diagnostics) > 0:
+			last := &diagnostics[len(diagnostics)-1]
+			last.Message += "\n" + trimmed
+		default:
+			if d, ok := parseDiagnosticLine(projectPath, "", check, trimmed); ok {
+				diagnostics = append(diagnostics, d)
+			} else {
+				other = append(other, trimmed)
+			}
+		}
+	}
+	if len(diagnostics) == 0 && len(other) > 0 {
+		diagnostics = append(diagnostics, Diagnostic{Check: check, Message: strings.Join(other, "\n")})
+	}
+	return diagnostics
+}
+
+// parseDiagnosticLine parses file:line:col: message; pkgDir is the directory test output
+// file names are relative to
+func parseDiagnosticLine(projectPath, pkgDir, check, line string) (Diagnostic, bool) {
+	match := diagnosticPattern.FindStringSubmatch(line)
+	if match == nil {
+		return Diagnostic{}, false
+	}
+	d := Diagnostic{Check: check, File: relativeFile(projectPath, pkgDir, match[1]), Message: match[4]}
+	d.Line, _ = strconv.Atoi(match[2])
+	d.Column, _ = strconv.Atoi(match[3])
+	return d, true
+}
+
+// relativeFile makes a reported file name slash-separated and relative to projectPath
+func relativeFile(projectPath, pkgDir, file string) string {
+	if filepath.IsAbs(file) {
+		if rel, err := filepath.Rel(projectPath, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+		return filepath.ToSlash(file)
+	}
+	file = strings.TrimPrefix(filepath.ToSlash(file), "./")
+	if pkgDir != "" && !strings.Contains(file, "/") {
+		file = path.Join(pkgDir, file)
+	}
+	return file
+}
+
+// testEvent is a line of go test -json output
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Output  string
+}
+
+// parseTestOutput turns go test -json output into diagnostics: the file:line messages
+// of failing tests, a diagnostic per failing test that printed none, and build errors
+func parseTestOutput(projectPath, output string) []Diagnostic {
+	modulePath := ""
+	if module, err := DetectModule(projectPath); err == nil && module.Root != "" {
+		modulePath = module.ImportPath
+	}
+	pkgDir := func(pkg string) string {
+		if rest, ok := strings.CutPrefix(pkg, modulePath+"/"); ok && modulePath != "" {
+			return rest
+		}
+		return ""
+	}
+
+	var diagnostics []Diagnostic
+	var plain strings.Builder
+	reported := make(map[string]bool) // package/test with a diagnostic
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var event testEvent
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if line[0] != '{' || json.Unmarshal(line, &event) != nil {
+			plain.WriteString(string(line) + "\n")
+			continue
+		}
+		key := event.Package + "/" + event.Test
+		switch {
+		case event.Action == "build-output":
+			plain.WriteString(event.Output)
+		case event.Action == "output" && event.Test != "":
+			if d, ok := parseDiagnosticLine(projectPath, pkgDir(event.Package), CheckTest, strings.TrimSpace(event.Output)); ok {
+				d.Message = event.Test + ": " + d.Message
+				diagnostics = append(diagnostics, d)
+				reported[key] = true
+			}
+		case event.Action == "fail" && event.Test != "" && !reported[key]:
+			diagnostics = append(diagnostics, Diagnostic{Check: CheckTest, File: pkgDir(event.Package), Message: event.Test + " failed"})
+			reported[key] = true
+		}
+	}
+	return append(parseCompilerOutput(projectPath, CheckTest, plain.String()), diagnostics...)
+}
+
+// gofmtDiagnostics reports Go files that gofmt would change, at the first changed line
+func gofmtDiagnostics(projectPath string) ([]Diagnostic, error) {
+	var diagnostics []Diagnostic
+	err := filepath.WalkDir(projectPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); p != projectPath && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") {
+			return nil
+		}
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(projectPath, p)
+		rel = filepath.ToSlash(rel)
+
+		formatted, err := format.Source(src)
+		if err != nil {
+			var list scanner.ErrorList
+			if errors.As(err, &list) && len(list) > 0 {
+				diagnostics = append(diagnostics, Diagnostic{Check: CheckGofmt, File: rel, Line: list[0].Pos.Line, Column: list[0].Pos.Column, Message: list[0].Msg})
+			} else {
+				diagnostics = append(diagnostics, Diagnostic{Check: CheckGofmt, File: rel, Message: err.Error()})
+			}
+			return nil
+		}
+		if !bytes.Equal(src, formatted) {
+			diagnostics = append(diagnostics, Diagnostic{Check: CheckGofmt, File: rel, Line: firstChangedLine(src, formatted), Message: "not gofmt-formatted; run gofmt -w " + rel})
+		}
+		return nil
+	})
+	return diagnostics, err
+}
+
+// firstChangedLine returns the 1-based line where a and b first differ
+func firstChangedLine(a, b []byte) int {
+	line := 1
+	for i := 0; i < len(a) && i < len(b) && a[i] == b[i]; i++ {
+		if a[i] == '\n' {
+			line++
+		}
+	}
+	return line
+}
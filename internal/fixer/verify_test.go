@@ -0,0 +1,109 @@
+package fixer_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+)
+
+func TestParseChecks(t *testing.T) {
+	opts, err := fixer.ParseChecks([]string{"build", "Vet", " gofmt"})
+	if err != nil || strings.Join(opts.Checks(), ",") != "build,vet,gofmt" {
+		t.Errorf("ParseChecks() = %v, %v", opts.Checks(), err)
+	}
+	if opts, _ := fixer.ParseChecks([]string{"all"}); strings.Join(opts.Checks(), ",") != "build,vet,test,gofmt" {
+		t.Errorf("all enables %v", opts.Checks())
+	}
+	if _, err := fixer.ParseChecks([]string{"staticcheck"}); err == nil {
+		t.Error("expected an unknown check to be rejected")
+	}
+	if opts, _ := fixer.ResolveVerifyOptions(nil); strings.Join(opts.Checks(), ",") != "build,vet" {
+		t.Errorf("default checks = %v, want build,vet", opts.Checks())
+	}
+}
+
+// verifyErrorOf runs Verify and returns its *VerifyError
+func verifyErrorOf(t *testing.T, dir string, opts fixer.VerifyOptions) *fixer.VerifyError {
+	t.Helper()
+	err := fixer.Verify(context.Background(), dir, opts)
+	var verr *fixer.VerifyError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Verify() = %v, want a *VerifyError", err)
+	}
+	return verr
+}
+
+func TestVerifyBuildsSubPackages(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"go.mod":                "module example.com/demo\n\ngo 1.21\n",
+		"main.go":               "package main\n\nfunc main() {}\n",
+		"internal/logic/a.go":   "package logic\n\nfunc A() int {\n\treturn missing\n}\n",
+		"internal/logic/b.go":   "package logic\n\nimport \"fmt\"\n\nfunc B() string { return fmt.Sprintf(\"%d\", \"b\") }\n",
+		"internal/types/x.go":   "package types\n\nvar X = 1 +\n",
+		"internal/svc/fine.go":  "package svc\n",
+		"internal/svc/ugly.go":  "package svc\n\nvar  Y = 2\n",
+		"vendor/skipped/bad.go": "package  skipped\n",
+	})
+
+	verr := verifyErrorOf(t, dir, fixer.VerifyOptions{Vet: true, Gofmt: true})
+	if strings.Join(verr.Checks, ",") != "build,gofmt" {
+		t.Errorf("failed checks = %v; vet should be skipped after a failed build", verr.Checks)
+	}
+	got := map[string]fixer.Diagnostic{}
+	for _, d := range verr.Diagnostics {
+		got[d.Check+" "+d.File] = d
+	}
+	if d := got["build internal/logic/a.go"]; d.Line != 4 || d.Column != 9 || !strings.Contains(d.Message, "undefined: missing") {
+		t.Errorf("build diagnostic = %+v", d)
+	}
+	if d := got["build internal/types/x.go"]; d.Line == 0 {
+		t.Errorf("syntax error not reported: %+v", verr.Diagnostics)
+	}
+	if d := got["gofmt internal/svc/ugly.go"]; d.Line != 3 {
+		t.Errorf("gofmt diagnostic = %+v", d)
+	}
+	if _, ok := got["gofmt vendor/skipped/bad.go"]; ok {
+		t.Error("vendor directory was checked by gofmt")
+	}
+	if !strings.Contains(verr.Error(), "go build ./... failed with") {
+		t.Errorf("unexpected message:\n%s", verr.Error())
+	}
+
+	writeTestFiles(t, dir, map[string]string{
+		"internal/logic/a.go":  "package logic\n\nfunc A() int {\n\treturn 1\n}\n",
+		"internal/types/x.go":  "package types\n\nvar X = 1\n",
+		"internal/svc/ugly.go": "package svc\n\nvar Y = 2\n",
+	})
+	verr = verifyErrorOf(t, dir, fixer.VerifyOptions{Vet: true})
+	if len(verr.Diagnostics) != 1 || verr.Diagnostics[0].File != "internal/logic/b.go" || verr.Diagnostics[0].Line != 5 {
+		t.Errorf("vet diagnostics = %+v", verr.Diagnostics)
+	}
+}
+
+func TestVerifyReportsFailingTests(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFiles(t, dir, map[string]string{
+		"go.mod":                      "module example.com/demo\n\ngo 1.21\n",
+		"internal/logic/ping.go":      "package logic\n\nfunc Ping() string { return \"pong\" }\n",
+		"internal/logic/ping_test.go": "package logic\n\nimport \"testing\"\n\nfunc TestPing(t *testing.T) {\n\tif Ping() != \"ping\" {\n\t\tt.Errorf(\"got %s\", Ping())\n\t}\n}\n\nfunc TestFailsSilently(t *testing.T) { t.FailNow() }\n",
+	})
+
+	if err := fixer.Verify(context.Background(), dir, fixer.VerifyOptions{}); err != nil {
+		t.Fatalf("Verify() without tests failed: %v", err)
+	}
+	verr := verifyErrorOf(t, dir, fixer.VerifyOptions{Test: true})
+	if len(verr.Diagnostics) != 2 {
+		t.Fatalf("test diagnostics = %+v, want 2", verr.Diagnostics)
+	}
+	d := verr.Diagnostics[0]
+	if d.File != "internal/logic/ping_test.go" || d.Line != 7 || d.Message != "TestPing: got pong" {
+		t.Errorf("test diagnostic = %+v", d)
+	}
+	if d := verr.Diagnostics[1]; d.File != "internal/logic" || d.Message != "TestFailsSilently failed" {
+		t.Errorf("failing test without output = %+v", d)
+	}
+}
@@ -17,16 +17,27 @@ func DiscoverGoctl() (string, error) {
 		}
 	}
 
+	if path, err := DiscoverTool("goctl"); err == nil {
+		return path, nil
+	}
+
+	// Not found - return actionable error
+	return "", fmt.Errorf("goctl not found. Install with:\n  go install github.com/zeromicro/go-zero/tools/goctl@latest\nOr set GOCTL_PATH environment variable")
+}
+
+// DiscoverTool finds an executable installed like goctl, such as protoc or a protoc plugin,
+// in common installation locations, GOPATH/bin or PATH
+func DiscoverTool(name string) (string, error) {
 	// Strategy 2: Search common installation locations
 	commonPaths := []string{
-		"/usr/local/bin/goctl",
-		filepath.Join(os.Getenv("HOME"), "go", "bin", "goctl"),
-		filepath.Join(os.Getenv("HOME"), "Develop", "go", "bin", "goctl"),
+		filepath.Join("/usr/local/bin", name),
+		filepath.Join(os.Getenv("HOME"), "go", "bin", name),
+		filepath.Join(os.Getenv("HOME"), "Develop", "go", "bin", name),
 	}
 
 	// Add GOPATH/bin if GOPATH is set
 	if goPath := os.Getenv("GOPATH"); goPath != "" {
-		commonPaths = append(commonPaths, filepath.Join(goPath, "bin", "goctl"))
+		commonPaths = append(commonPaths, filepath.Join(goPath, "bin", name))
 	}
 
 	for _, path := range commonPaths {
@@ -36,12 +47,11 @@ func DiscoverGoctl() (string, error) {
 	}
 
 	// Strategy 3: Search in PATH
-	if path, err := exec.LookPath("goctl"); err == nil {
+	if path, err := exec.LookPath(name); err == nil {
 		return path, nil
 	}
 
-	// Not found - return actionable error
-	return "", fmt.Errorf("goctl not found. Install with:\n  go install github.com/zeromicro/go-zero/tools/goctl@latest\nOr set GOCTL_PATH environment variable")
+	return "", fmt.Errorf("%s not found in common locations, GOPATH/bin or PATH", name)
 }
 
 // isExecutable checks if a file exists and is executable
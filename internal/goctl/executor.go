@@ -1,28 +1,45 @@
 package goctl
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"os/exec"
 	"path/filepath"
+	"time"
 )
 
 // Executor handles safe execution of goctl commands
 type Executor struct {
 	goctlPath string
+
+	// version is the output of goctl --version, zero when versionErr is set
+	version    Version
+	versionErr error
+
+	// Timeout bounds each command; zero uses the per-command default
+	Timeout time.Duration
+
+	// Templates are passed to every goctl command that renders templates
+	Templates Templates
 }
 
 // NewExecutor creates a new goctl executor
-// Discovers goctl path on initialization
+// Discovers goctl path and version on initialization, refusing releases older than MinimumVersion
 func NewExecutor() (*Executor, error) {
 	goctlPath, err := DiscoverGoctl()
 	if err != nil {
 		return nil, err
 	}
 
-	return &Executor{
-		goctlPath: goctlPath,
-	}, nil
+	version, versionErr := DetectVersion(goctlPath)
+	e := &Executor{
+		goctlPath:  goctlPath,
+		version:    version,
+		versionErr: versionErr,
+	}
+	if err := e.CheckSupported(); err != nil {
+		return nil, err
+	}
+	return e, nil
 }
 
 // ExecuteResult contains the result of a goctl command execution
@@ -31,69 +48,39 @@ type ExecuteResult struct {
 	Stderr   string
 	ExitCode int
 	Error    error
+	Status   Status
+	Duration time.Duration
 }
 
 // Execute runs a goctl command with the given arguments
 // Uses absolute paths and captures both stdout and stderr
 func (e *Executor) Execute(args ...string) *ExecuteResult {
-	cmd := exec.Command(e.goctlPath, args...)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err := cmd.Run()
-	result := &ExecuteResult{
-		Stdout: stdout.String(),
-		Stderr: stderr.String(),
-	}
-
-	if err != nil {
-		result.Error = err
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			result.ExitCode = exitErr.ExitCode()
-		} else {
-			result.ExitCode = -1
-		}
-	}
+	return e.ExecuteContext(context.Background(), args...)
+}
 
-	return result
+// ExecuteContext runs a goctl command, stopping it when ctx is done or the timeout elapses
+func (e *Executor) ExecuteContext(ctx context.Context, args ...string) *ExecuteResult {
+	return Run(ctx, "", e.Timeout, e.goctlPath, e.withTemplates(args)...)
 }
 
 // ExecuteInDir runs a goctl command in a specific directory
 func (e *Executor) ExecuteInDir(dir string, args ...string) *ExecuteResult {
+	return e.ExecuteInDirContext(context.Background(), dir, args...)
+}
+
+// ExecuteInDirContext runs a goctl command in dir, stopping it when ctx is done or the timeout elapses
+func (e *Executor) ExecuteInDirContext(ctx context.Context, dir string, args ...string) *ExecuteResult {
 	// Ensure directory is absolute
 	absDir, err := filepath.Abs(dir)
 	if err != nil {
 		return &ExecuteResult{
 			Error:    fmt.Errorf("failed to get absolute path: %w", err),
 			ExitCode: -1,
+			Status:   StatusStartError,
 		}
 	}
 
-	cmd := exec.Command(e.goctlPath, args...)
-	cmd.Dir = absDir
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err = cmd.Run()
-	result := &ExecuteResult{
-		Stdout: stdout.String(),
-		Stderr: stderr.String(),
-	}
-
-	if err != nil {
-		result.Error = err
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			result.ExitCode = exitErr.ExitCode()
-		} else {
-			result.ExitCode = -1
-		}
-	}
-
-	return result
+	return Run(ctx, absDir, e.Timeout, e.goctlPath, e.withTemplates(args)...)
 }
 
 // GetPath returns the discovered goctl path
@@ -0,0 +1,149 @@
+//go:build unix
+
+package goctl
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// fakeGoctl writes an executable shell script standing in for goctl
+func fakeGoctl(t *testing.T, body string) *Executor {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "goctl")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatalf("failed to write fake goctl: %v", err)
+	}
+	return &Executor{goctlPath: path}
+}
+
+func TestExecuteContextStatus(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		e := fakeGoctl(t, `echo "$@"; echo warn >&2`)
+		result := e.ExecuteContext(context.Background(), "api", "new", "demo")
+		if result.Status != StatusOK || result.Error != nil || result.ExitCode != 0 {
+			t.Fatalf("got status %s, exit %d, err %v", result.Status, result.ExitCode, result.Error)
+		}
+		if strings.TrimSpace(result.Stdout) != "api new demo" || strings.TrimSpace(result.Stderr) != "warn" {
+			t.Errorf("unexpected output: stdout %q, stderr %q", result.Stdout, result.Stderr)
+		}
+	})
+
+	t.Run("exit error", func(t *testing.T) {
+		e := fakeGoctl(t, `echo boom >&2; exit 3`)
+		result := e.ExecuteContext(context.Background(), "rpc", "protoc")
+		if result.Status != StatusExitError || result.ExitCode != 3 || result.Error == nil {
+			t.Fatalf("got status %s, exit %d, err %v", result.Status, result.ExitCode, result.Error)
+		}
+		if strings.TrimSpace(result.Stderr) != "boom" {
+			t.Errorf("stderr = %q", result.Stderr)
+		}
+	})
+
+	t.Run("start error", func(t *testing.T) {
+		e := &Executor{goctlPath: filepath.Join(t.TempDir(), "missing")}
+		result := e.ExecuteContext(context.Background(), "api")
+		if result.Status != StatusStartError || result.ExitCode != -1 {
+			t.Fatalf("got status %s, exit %d", result.Status, result.ExitCode)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		e := fakeGoctl(t, `sleep 30`)
+		e.Timeout = 200 * time.Millisecond
+		start := time.Now()
+		result := e.ExecuteContext(context.Background(), "rpc", "protoc")
+		if result.Status != StatusTimeout {
+			t.Fatalf("got status %s, err %v", result.Status, result.Error)
+		}
+		if !strings.Contains(result.Error.Error(), "timed out after 200ms") {
+			t.Errorf("error = %v", result.Error)
+		}
+		if elapsed := time.Since(start); elapsed > 10*time.Second {
+			t.Errorf("timeout took %s", elapsed)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		e := fakeGoctl(t, `sleep 30`)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(200*time.Millisecond, cancel)
+		result := e.ExecuteInDirContext(ctx, t.TempDir(), "api", "go")
+		if result.Status != StatusCanceled {
+			t.Fatalf("got status %s, err %v", result.Status, result.Error)
+		}
+		if !errors.Is(result.Error, context.Canceled) {
+			t.Errorf("error = %v, want context.Canceled", result.Error)
+		}
+	})
+}
+
+func TestExecuteContextKillsProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	e := fakeGoctl(t, `sleep 30 &
+echo $! > "`+pidFile+`"
+wait`)
+	e.Timeout = 500 * time.Millisecond
+
+	result := e.ExecuteContext(context.Background(), "rpc", "protoc")
+	if result.Status != StatusTimeout {
+		t.Fatalf("got status %s, err %v", result.Status, result.Error)
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("child pid not recorded: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("bad pid %q", data)
+	}
+
+	// The child is killed with the group; allow a moment for it to be reaped
+	deadline := time.Now().Add(5 * time.Second)
+	for syscall.Kill(pid, 0) == nil {
+		if time.Now().After(deadline) {
+			syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("child process %d outlived the timeout", pid)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestTimeoutFor(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want time.Duration
+	}{
+		{"/usr/local/bin/goctl", []string{"api", "new", "demo", "--style", "go_zero"}, 2 * time.Minute},
+		{"goctl", []string{"template", "init"}, time.Minute},
+		{"goctl", []string{"-h"}, FallbackTimeout},
+		{"go", []string{"mod", "init", "example.com/demo"}, 30 * time.Second},
+		{"go", []string{"build", "-o", os.DevNull, "."}, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := TimeoutFor(tt.name, tt.args...); got != tt.want {
+			t.Errorf("TimeoutFor(%s %v) = %s, want %s", tt.name, tt.args, got, tt.want)
+		}
+	}
+
+	SetTimeout("go fmt", 10*time.Second)
+	defer SetTimeout("go fmt", 30*time.Second)
+	if got := TimeoutFor("go", "fmt", "main.go"); got != 10*time.Second {
+		t.Errorf("per-command timeout = %s", got)
+	}
+
+	SetDefaultTimeout(time.Second)
+	defer SetDefaultTimeout(0)
+	if got := TimeoutFor("goctl", "rpc", "protoc"); got != time.Second {
+		t.Errorf("override timeout = %s", got)
+	}
+}
@@ -0,0 +1,165 @@
+package goctl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status classifies how a command finished
+type Status string
+
+const (
+	StatusOK         Status = "ok"
+	StatusExitError  Status = "exit_error"  // the command ran and exited non-zero
+	StatusStartError Status = "start_error" // the command could not be started
+	StatusTimeout    Status = "timeout"     // the command outlived its timeout and was killed
+	StatusCanceled   Status = "canceled"    // the caller's context was canceled
+)
+
+// FallbackTimeout bounds commands without a per-command default
+const FallbackTimeout = 5 * time.Minute
+
+// waitDelay is how long to wait for output pipes after the process group is killed
+const waitDelay = 5 * time.Second
+
+var (
+	timeoutsMu sync.RWMutex
+
+	// defaultTimeouts are keyed by command: the goctl subcommand such as "rpc",
+	// or the go invocation such as "go mod tidy"
+	defaultTimeouts = map[string]time.Duration{
+		"api":         2 * time.Minute,
+		"rpc":         5 * time.Minute,
+		"model":       5 * time.Minute,
+		"template":    time.Minute,
+		"go mod init": 30 * time.Second,
+		"go mod tidy": 5 * time.Minute,
+		"go mod edit": 30 * time.Second,
+		"go env":      30 * time.Second,
+		"go work":     30 * time.Second,
+		"go build":    5 * time.Minute,
+		"go vet":      5 * time.Minute,
+		"go test":     10 * time.Minute,
+		"go fmt":      30 * time.Second,
+	}
+
+	// timeoutOverride replaces every per-command default when positive
+	timeoutOverride time.Duration
+)
+
+// SetTimeout changes the default timeout of a single command, such as "rpc" or "go mod tidy"
+func SetTimeout(command string, d time.Duration) {
+	timeoutsMu.Lock()
+	defer timeoutsMu.Unlock()
+	defaultTimeouts[command] = d
+}
+
+// SetDefaultTimeout overrides the default timeout of every command; zero restores the per-command defaults
+func SetDefaultTimeout(d time.Duration) {
+	timeoutsMu.Lock()
+	defer timeoutsMu.Unlock()
+	timeoutOverride = d
+}
+
+// TimeoutFor returns the default timeout of the command started by name with args
+func TimeoutFor(name string, args ...string) time.Duration {
+	timeoutsMu.RLock()
+	defer timeoutsMu.RUnlock()
+	if timeoutOverride > 0 {
+		return timeoutOverride
+	}
+	if d, ok := defaultTimeouts[commandKey(name, args)]; ok && d > 0 {
+		return d
+	}
+	return FallbackTimeout
+}
+
+// commandKey names a command for timeout lookup: "rpc" for goctl, "go mod tidy" for go
+func commandKey(name string, args []string) string {
+	base := strings.TrimSuffix(filepath.Base(name), ".exe")
+	var words []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		words = append(words, arg)
+	}
+	switch {
+	case base == "go" && len(words) > 0 && words[0] == "mod" && len(words) > 1:
+		return "go mod " + words[1]
+	case base == "go" && len(words) > 0:
+		return "go " + words[0]
+	case len(words) > 0:
+		return words[0]
+	}
+	return base
+}
+
+// Run executes name with args in dir, bounded by ctx and timeout
+// A zero timeout uses the command's default; on timeout or cancellation the whole
+// process group is killed so children such as protoc do not outlive the call
+func Run(ctx context.Context, dir string, timeout time.Duration, name string, args ...string) *ExecuteResult {
+	return RunEnv(ctx, dir, nil, timeout, name, args...)
+}
+
+// RunEnv is Run with extra KEY=value environment entries appended to the server's environment
+func RunEnv(ctx context.Context, dir string, env []string, timeout time.Duration, name string, args ...string) *ExecuteResult {
+	if timeout <= 0 {
+		timeout = TimeoutFor(name, args...)
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, name, args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	setProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	result := &ExecuteResult{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Status:   StatusOK,
+		Duration: time.Since(start),
+	}
+	if err == nil {
+		return result
+	}
+
+	command := commandKey(name, args)
+	result.ExitCode = -1
+	switch {
+	case ctx.Err() != nil:
+		result.Status = StatusCanceled
+		result.Error = fmt.Errorf("%s canceled: %w", command, ctx.Err())
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		result.Status = StatusTimeout
+		result.Error = fmt.Errorf("%s timed out after %s", command, timeout)
+	default:
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.Status = StatusExitError
+			result.ExitCode = exitErr.ExitCode()
+		} else {
+			result.Status = StatusStartError
+		}
+		result.Error = err
+	}
+	return result
+}
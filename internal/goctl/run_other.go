@@ -0,0 +1,8 @@
+//go:build !unix
+
+package goctl
+
+import "os/exec"
+
+// setProcessGroup is a no-op where process groups are unavailable; cancel kills the process only
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,74 @@
+package goctl
+
+import "strings"
+
+// Templates selects where goctl reads its templates from: a local home directory
+// or a git repository; the zero Templates uses goctl's built-in defaults
+type Templates struct {
+	Home   string `json:"home,omitempty"`
+	Remote string `json:"remote,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	Source string `json:"source,omitempty"` // where the setting came from, such as a config file
+}
+
+// Empty reports whether goctl's built-in templates are used
+func (t Templates) Empty() bool {
+	return t.Home == "" && t.Remote == ""
+}
+
+// Args returns the goctl flags selecting the templates
+func (t Templates) Args() []string {
+	switch {
+	case t.Remote != "":
+		args := []string{"--remote", t.Remote}
+		if t.Branch != "" {
+			args = append(args, "--branch", t.Branch)
+		}
+		return args
+	case t.Home != "":
+		return []string{"--home", t.Home}
+	}
+	return nil
+}
+
+func (t Templates) String() string {
+	switch {
+	case t.Remote != "" && t.Branch != "":
+		return t.Remote + "@" + t.Branch
+	case t.Remote != "":
+		return t.Remote
+	case t.Home != "":
+		return t.Home
+	}
+	return "goctl defaults"
+}
+
+// templateCommands are the goctl commands that render templates and accept --home,
+// --remote and --branch
+var templateCommands = []string{"api new", "api go", "rpc new", "rpc protoc", "model"}
+
+// acceptsTemplates reports whether the goctl command in args renders templates
+func acceptsTemplates(args []string) bool {
+	var words []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		words = append(words, arg)
+	}
+	key := strings.Join(words, " ")
+	for _, command := range templateCommands {
+		if key == command || strings.HasPrefix(key, command+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// withTemplates appends the template flags to commands that render templates
+func (e *Executor) withTemplates(args []string) []string {
+	if e.Templates.Empty() || !acceptsTemplates(args) {
+		return args
+	}
+	return append(append([]string{}, args...), e.Templates.Args()...)
+}
@@ -0,0 +1,49 @@
+//go:build unix
+
+package goctl
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTemplatesArgs(t *testing.T) {
+	for _, tc := range []struct {
+		templates Templates
+		want      string
+	}{
+		{Templates{}, ""},
+		{Templates{Home: "/tpl"}, "--home /tpl"},
+		{Templates{Remote: "https://example.com/tpl.git"}, "--remote https://example.com/tpl.git"},
+		{Templates{Remote: "https://example.com/tpl.git", Branch: "v2"}, "--remote https://example.com/tpl.git --branch v2"},
+		// goctl prefers --remote, so the home is dropped instead of sending both
+		{Templates{Home: "/tpl", Remote: "git@example.com:tpl.git"}, "--remote git@example.com:tpl.git"},
+	} {
+		if got := strings.Join(tc.templates.Args(), " "); got != tc.want {
+			t.Errorf("%+v.Args() = %q, want %q", tc.templates, got, tc.want)
+		}
+	}
+}
+
+func TestExecutorPassesTemplates(t *testing.T) {
+	e := fakeGoctl(t, `echo "$@"`)
+	e.Templates = Templates{Home: "/tpl"}
+
+	for args, want := range map[string]string{
+		"api new demo --style go_zero":                "api new demo --style go_zero --home /tpl",
+		"api go -api demo.api -dir .":                 "api go -api demo.api -dir . --home /tpl",
+		"rpc protoc user.proto --go_out=.":            "rpc protoc user.proto --go_out=. --home /tpl",
+		"model mysql datasource -url dsn -table user": "model mysql datasource -url dsn -table user --home /tpl",
+		"api format --dir .":                          "api format --dir .",
+		"template init --home /other":                 "template init --home /other",
+	} {
+		result := e.ExecuteContext(context.Background(), strings.Fields(args)...)
+		if result.Error != nil {
+			t.Fatalf("%s: %v", args, result.Error)
+		}
+		if got := strings.TrimSpace(result.Stdout); got != want {
+			t.Errorf("goctl %s ran as %q, want %q", args, got, want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package goctl
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Version is a goctl release such as 1.6.3; the zero Version means it could not be detected
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	Pre   string // pre-release suffix such as "beta", ignored when comparing
+}
+
+// Releases mcp-zero is tested against; older releases are refused, as they lack
+// goctl rpc protoc, and newer ones get a warning. Flags only some supported releases
+// have are Features, checked with Executor.Require before a command uses them
+var (
+	MinimumVersion = Version{Major: 1, Minor: 3}
+	NewestTested   = Version{Major: 1, Minor: 8}
+)
+
+// versionTimeout bounds goctl --version
+const versionTimeout = 10 * time.Second
+
+var versionPattern = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?(?:-([0-9A-Za-z.]+))?`)
+
+// ParseVersion reads a version from goctl --version output, such as
+// "goctl version 1.6.3 darwin/arm64"
+func ParseVersion(output string) (Version, error) {
+	match := versionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return Version{}, fmt.Errorf("no version in goctl --version output %q", strings.TrimSpace(output))
+	}
+	var v Version
+	v.Major, _ = strconv.Atoi(match[1])
+	v.Minor, _ = strconv.Atoi(match[2])
+	v.Patch, _ = strconv.Atoi(match[3])
+	v.Pre = match[4]
+	return v, nil
+}
+
+// Known reports whether the version was detected
+func (v Version) Known() bool {
+	return v != Version{}
+}
+
+// AtLeast reports whether v is the same release as min or newer
+func (v Version) AtLeast(min Version) bool {
+	if v.Major != min.Major {
+		return v.Major > min.Major
+	}
+	if v.Minor != min.Minor {
+		return v.Minor > min.Minor
+	}
+	return v.Patch >= min.Patch
+}
+
+func (v Version) String() string {
+	if !v.Known() {
+		return "unknown"
+	}
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// Untested reports whether v is a newer minor release than NewestTested
+func Untested(v Version) bool {
+	return v.AtLeast(Version{Major: NewestTested.Major, Minor: NewestTested.Minor + 1})
+}
+
+// Feature is a goctl capability that only some releases have
+type Feature struct {
+	Name  string
+	Since Version
+}
+
+// Supports reports whether release v has feature; an undetected version is assumed to
+// support everything rather than block unusual builds
+func (v Version) Supports(feature Feature) bool {
+	return !v.Known() || v.AtLeast(feature.Since)
+}
+
+var (
+	// FeatureStyleFormat names generated files in a custom --style format such as Go-Zero or
+	// go#zero; every supported release accepts the ClassicStyles
+	FeatureStyleFormat = Feature{Name: "goctl --style custom formats", Since: Version{Major: 1, Minor: 4}}
+
+	// FeatureRPCMultiple generates one client per service of a proto declaring several
+	FeatureRPCMultiple = Feature{Name: "goctl rpc protoc --multiple", Since: Version{Major: 1, Minor: 5}}
+)
+
+// Features lists every gated feature, oldest first
+var Features = []Feature{FeatureStyleFormat, FeatureRPCMultiple}
+
+// ClassicStyles are the --style values every supported release accepts
+var ClassicStyles = []string{"gozero", "go_zero", "goZero"}
+
+// UnsupportedError reports a feature the installed goctl is too old for
+type UnsupportedError struct {
+	Feature Feature
+	Version Version
+	Path    string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%s requires goctl %s or newer, but %s is %s. %s", e.Feature.Name, e.Feature.Since, e.Path, e.Version, UpgradeHint)
+}
+
+// UpgradeHint tells how to install the latest goctl
+const UpgradeHint = "Upgrade with:\n  go install github.com/zeromicro/go-zero/tools/goctl@latest"
+
+// cachedVersion is a detected version, valid while the binary is unchanged
+type cachedVersion struct {
+	modTime time.Time
+	size    int64
+	version Version
+	err     error
+}
+
+var (
+	versionsMu sync.Mutex
+	versions   = map[string]cachedVersion{}
+)
+
+// DetectVersion runs goctl --version, caching the result until the binary changes
+func DetectVersion(goctlPath string) (Version, error) {
+	info, err := os.Stat(goctlPath)
+	if err != nil {
+		return Version{}, err
+	}
+	versionsMu.Lock()
+	cached, ok := versions[goctlPath]
+	versionsMu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.version, cached.err
+	}
+
+	// Run outside the caller's directory so nothing can be written there
+	result := Run(context.Background(), os.TempDir(), versionTimeout, goctlPath, "--version")
+	var version Version
+	if err = result.Error; err == nil {
+		version, err = ParseVersion(result.Stdout + result.Stderr)
+	}
+	versionsMu.Lock()
+	versions[goctlPath] = cachedVersion{modTime: info.ModTime(), size: info.Size(), version: version, err: err}
+	versionsMu.Unlock()
+	return version, err
+}
+
+// Version returns the goctl release detected when the executor was created
+func (e *Executor) Version() Version {
+	return e.version
+}
+
+// VersionError returns why the version could not be detected, if it was not
+func (e *Executor) VersionError() error {
+	return e.versionErr
+}
+
+// Supports reports whether the installed goctl has feature
+func (e *Executor) Supports(feature Feature) bool {
+	return e.version.Supports(feature)
+}
+
+// Require returns an *UnsupportedError when the installed goctl lacks feature
+func (e *Executor) Require(feature Feature) error {
+	if e.Supports(feature) {
+		return nil
+	}
+	return &UnsupportedError{Feature: feature, Version: e.version, Path: e.goctlPath}
+}
+
+// RequireStyle returns an *UnsupportedError when the installed goctl cannot name files in
+// style, a custom format on a release older than FeatureStyleFormat
+func (e *Executor) RequireStyle(style string) error {
+	if slices.Contains(ClassicStyles, style) {
+		return nil
+	}
+	return e.Require(FeatureStyleFormat)
+}
+
+// CheckSupported rejects goctl releases older than MinimumVersion
+func (e *Executor) CheckSupported() error {
+	if !e.version.Known() || e.version.AtLeast(MinimumVersion) {
+		return nil
+	}
+	return fmt.Errorf("goctl %s at %s is older than %s, the oldest release mcp-zero supports. %s", e.version, e.goctlPath, MinimumVersion, UpgradeHint)
+}
@@ -0,0 +1,141 @@
+//go:build unix
+
+package goctl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseVersion(t *testing.T) {
+	for output, want := range map[string]Version{
+		"goctl version 1.6.3 darwin/arm64\n":   {Major: 1, Minor: 6, Patch: 3},
+		"goctl version 1.3.0-beta linux/amd64": {Major: 1, Minor: 3, Pre: "beta"},
+		"goctl version 1.8 windows/amd64":      {Major: 1, Minor: 8},
+	} {
+		got, err := ParseVersion(output)
+		if err != nil || got != want {
+			t.Errorf("ParseVersion(%q) = %+v, %v, want %+v", output, got, err, want)
+		}
+	}
+	if _, err := ParseVersion("goctl: unknown flag --version"); err == nil {
+		t.Error("expected an error for output without a version")
+	}
+}
+
+func TestVersionSupports(t *testing.T) {
+	for _, tc := range []struct {
+		version Version
+		want    bool
+	}{
+		{Version{Major: 1, Minor: 4, Patch: 9}, false},
+		{Version{Major: 1, Minor: 5}, true},
+		{Version{Major: 1, Minor: 5, Pre: "beta"}, true},
+		{Version{Major: 2}, true},
+		{Version{}, true}, // undetected versions are not gated
+	} {
+		if got := tc.version.Supports(FeatureRPCMultiple); got != tc.want {
+			t.Errorf("%s supports --multiple = %v, want %v", tc.version, got, tc.want)
+		}
+	}
+	for i := 1; i < len(Features); i++ {
+		if !Features[i].Since.AtLeast(Features[i-1].Since) {
+			t.Errorf("Features are not ordered oldest first: %s before %s", Features[i-1].Name, Features[i].Name)
+		}
+	}
+	if !Untested(Version{Major: 1, Minor: 9}) || Untested(Version{Major: 1, Minor: 8, Patch: 7}) {
+		t.Error("Untested() should only flag releases newer than 1.8")
+	}
+}
+
+func TestRequireStyle(t *testing.T) {
+	for _, tc := range []struct {
+		version, style string
+		want           bool
+	}{
+		{"1.3.0", "go_zero", true},
+		{"1.3.0", "goZero", true},
+		{"1.3.4", "Go-Zero", false},
+		{"1.4.0", "Go-Zero", true},
+		{"1.8.4", "go#zero", true},
+	} {
+		path, _ := writeVersionedGoctl(t, tc.version)
+		t.Setenv("GOCTL_PATH", path)
+		e, err := NewExecutor()
+		if err != nil {
+			t.Fatalf("NewExecutor() failed: %v", err)
+		}
+		err = e.RequireStyle(tc.style)
+		if (err == nil) != tc.want {
+			t.Errorf("goctl %s RequireStyle(%s) = %v, want allowed %v", tc.version, tc.style, err, tc.want)
+		}
+		if err != nil && !strings.Contains(err.Error(), "custom formats requires goctl 1.4.0 or newer") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+// writeVersionedGoctl writes a fake goctl that prints version and counts its runs in calls
+func writeVersionedGoctl(t *testing.T, version string) (path, calls string) {
+	t.Helper()
+	dir := t.TempDir()
+	path = filepath.Join(dir, "goctl")
+	calls = filepath.Join(dir, "calls")
+	script := "#!/bin/sh\necho run >> " + calls + "\necho \"goctl version " + version + " linux/amd64\"\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path, calls
+}
+
+func TestDetectVersionCachesUntilBinaryChanges(t *testing.T) {
+	path, calls := writeVersionedGoctl(t, "1.4.2")
+	for range 3 {
+		if v, err := DetectVersion(path); err != nil || v.String() != "1.4.2" {
+			t.Fatalf("DetectVersion() = %s, %v", v, err)
+		}
+	}
+	if runs, _ := os.ReadFile(calls); strings.Count(string(runs), "run") != 1 {
+		t.Errorf("goctl --version ran %d times, want 1", strings.Count(string(runs), "run"))
+	}
+
+	// Upgrading goctl in place replaces the binary
+	script := "#!/bin/sh\necho \"goctl version 1.7.0 linux/amd64\"\n"
+	os.WriteFile(path, []byte(script), 0755)
+	os.Chtimes(path, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	if v, _ := DetectVersion(path); v.String() != "1.7.0" {
+		t.Errorf("DetectVersion() after upgrade = %s, want 1.7.0", v)
+	}
+}
+
+func TestNewExecutorGatesByVersion(t *testing.T) {
+	path, _ := writeVersionedGoctl(t, "1.2.5")
+	t.Setenv("GOCTL_PATH", path)
+	_, err := NewExecutor()
+	if err == nil || !strings.Contains(err.Error(), "older than 1.3.0") || !strings.Contains(err.Error(), "go install") {
+		t.Errorf("expected goctl 1.2.5 to be refused with an upgrade hint, got %v", err)
+	}
+
+	path, _ = writeVersionedGoctl(t, "1.4.2")
+	t.Setenv("GOCTL_PATH", path)
+	e, err := NewExecutor()
+	if err != nil {
+		t.Fatalf("NewExecutor() failed: %v", err)
+	}
+	if e.Version().String() != "1.4.2" {
+		t.Errorf("Version() = %s", e.Version())
+	}
+	err = e.Require(FeatureRPCMultiple)
+	if err == nil || !strings.Contains(err.Error(), "--multiple requires goctl 1.5.0 or newer, but "+path+" is 1.4.2") {
+		t.Errorf("Require(--multiple) = %v", err)
+	}
+
+	path, _ = writeVersionedGoctl(t, "devel")
+	t.Setenv("GOCTL_PATH", path)
+	if e, err := NewExecutor(); err != nil || e.VersionError() == nil || e.Require(FeatureRPCMultiple) != nil {
+		t.Errorf("an undetected version should not block generation: %v", err)
+	}
+}
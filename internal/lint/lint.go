@@ -0,0 +1,183 @@
+// Package lint checks .api specifications against go-zero conventions
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+)
+
+// Severity ranks an issue
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Issue is a single rule violation
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+}
+
+// String formats the issue as file:line:column: severity: message (rule)
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s (%s)", i.File, i.Line, i.Column, i.Severity, i.Message, i.Rule)
+}
+
+// Rule is a named check
+type Rule struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+	Default     bool     `json:"default"` // enabled when the project config does not mention it
+	check       func(p *pass)
+}
+
+// Result is the outcome of linting a spec
+type Result struct {
+	Issues   []Issue  `json:"issues"`
+	Rules    []string `json:"rules"` // rules that ran
+	Errors   int      `json:"errors"`
+	Warnings int      `json:"warnings"`
+	Info     int      `json:"info"`
+}
+
+// Rules returns every rule in a stable order
+func Rules() []*Rule {
+	return rules
+}
+
+// Lookup returns the rule with the given name, or nil
+func Lookup(name string) *Rule {
+	for _, r := range rules {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// Lint runs the enabled rules over spec
+// overrides enables or disables rules by name; unknown names are an error
+func Lint(spec *apispec.Spec, overrides map[string]bool) (*Result, error) {
+	for name := range overrides {
+		if Lookup(name) == nil {
+			return nil, fmt.Errorf("unknown lint rule %q", name)
+		}
+	}
+
+	result := &Result{Issues: []Issue{}}
+	p := newPass(spec)
+	for _, r := range rules {
+		enabled := r.Default
+		if v, ok := overrides[r.Name]; ok {
+			enabled = v
+		}
+		if !enabled {
+			continue
+		}
+		result.Rules = append(result.Rules, r.Name)
+		p.rule = r
+		r.check(p)
+	}
+
+	sort.SliceStable(p.issues, func(i, j int) bool {
+		a, b := p.issues[i], p.issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	result.Issues = append(result.Issues, p.issues...)
+	for _, issue := range result.Issues {
+		switch issue.Severity {
+		case SeverityError:
+			result.Errors++
+		case SeverityWarning:
+			result.Warnings++
+		default:
+			result.Info++
+		}
+	}
+	return result, nil
+}
+
+// route is a route with its service block and file
+type route struct {
+	*apispec.Route
+	svc  *apispec.ServiceDecl
+	file string
+}
+
+func (r route) fullPath() string {
+	prefix := strings.TrimSuffix(r.svc.Prefix(), "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return prefix + r.Path
+}
+
+// pass holds the state shared by the rules of one Lint call
+type pass struct {
+	spec     *apispec.Spec
+	rule     *Rule
+	routes   []route
+	typeFile map[*apispec.TypeDecl]string
+	issues   []Issue
+}
+
+func newPass(spec *apispec.Spec) *pass {
+	p := &pass{spec: spec, typeFile: make(map[*apispec.TypeDecl]string)}
+	for _, f := range spec.Files {
+		for _, t := range f.Types {
+			p.typeFile[t] = f.Path
+		}
+		for _, svc := range f.Services {
+			for _, r := range svc.Routes {
+				p.routes = append(p.routes, route{Route: r, svc: svc, file: f.Path})
+			}
+		}
+	}
+	return p
+}
+
+func (p *pass) report(file string, pos apispec.Pos, format string, args ...any) {
+	p.issues = append(p.issues, Issue{
+		Rule:     p.rule.Name,
+		Severity: p.rule.Severity,
+		Message:  fmt.Sprintf(format, args...),
+		File:     file,
+		Line:     pos.Line,
+		Column:   pos.Column,
+	})
+}
+
+// fields calls fn for every field of every type, including inline struct fields
+func (p *pass) fields(fn func(file string, owner string, f *apispec.Field)) {
+	var walk func(file, owner string, fields []*apispec.Field)
+	walk = func(file, owner string, fields []*apispec.Field) {
+		for _, f := range fields {
+			fn(file, owner, f)
+			for t := f.Type; t != nil; t = t.Elem {
+				if t.Kind == apispec.KindStruct {
+					walk(file, owner+"."+f.Name, t.Fields)
+				}
+			}
+		}
+	}
+	for _, t := range p.spec.Types() {
+		walk(p.typeFile[t], t.Name, t.Fields)
+	}
+}
@@ -0,0 +1,91 @@
+package lint_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+	"github.com/zeromicro/mcp-zero/internal/lint"
+)
+
+func load(t *testing.T, path string) *apispec.Spec {
+	t.Helper()
+	spec, err := apispec.Load(path, nil)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", path, err)
+	}
+	return spec
+}
+
+func TestLintViolations(t *testing.T) {
+	result, err := lint.Lint(load(t, "testdata/violations.api"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, issue := range result.Issues {
+		if !strings.HasSuffix(issue.File, "violations.api") {
+			t.Errorf("issue without file position: %+v", issue)
+		}
+		got = append(got, fmt.Sprintf("%s:%d", issue.Rule, issue.Line))
+	}
+	want := []string{
+		"missing-json-tag:16",
+		"any-type:17",
+		"any-type:18",
+		"undefined-type:19",
+		"unused-type:22",
+		"missing-doc:22",
+		"duplicate-handler:36",
+		"handler-naming:36",
+		"duplicate-route:37",
+		"path-param-tag:37",
+		"handler-naming:40",
+		"path-param-tag:41",
+		"handler-naming:43",
+		"missing-doc:44",
+		"undefined-type:44",
+		"duplicate-route:54",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("issues:\n got %v\nwant %v\n%v", got, want, result.Issues)
+	}
+	if result.Errors != 9 || result.Warnings != 5 || result.Info != 2 {
+		t.Errorf("counts = %d/%d/%d", result.Errors, result.Warnings, result.Info)
+	}
+}
+
+func TestLintClean(t *testing.T) {
+	result, err := lint.Lint(load(t, "testdata/clean.api"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) != 0 {
+		t.Errorf("expected no issues, got %v", result.Issues)
+	}
+	if len(result.Rules) != len(lint.Rules()) {
+		t.Errorf("expected every rule to run, got %v", result.Rules)
+	}
+}
+
+func TestLintOverrides(t *testing.T) {
+	spec := load(t, "testdata/violations.api")
+	result, err := lint.Lint(spec, map[string]bool{"missing-doc": false, "unused-type": false, "handler-naming": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range result.Issues {
+		if issue.Rule == "missing-doc" || issue.Rule == "unused-type" || issue.Rule == "handler-naming" {
+			t.Errorf("disabled rule reported %v", issue)
+		}
+	}
+	if len(result.Rules) != len(lint.Rules())-3 {
+		t.Errorf("Rules = %v", result.Rules)
+	}
+
+	if _, err := lint.Lint(spec, map[string]bool{"no-such-rule": true}); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
@@ -0,0 +1,261 @@
+package lint
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+)
+
+// rules lists every rule in the order they run
+var rules = []*Rule{
+	{Name: "undefined-type", Description: "Routes and fields must only refer to declared types", Severity: SeverityError, Default: true, check: checkUndefinedTypes},
+	{Name: "unused-type", Description: "Types should be used by a route or another type", Severity: SeverityWarning, Default: true, check: checkUnusedTypes},
+	{Name: "duplicate-handler", Description: "Handler names must be unique", Severity: SeverityError, Default: true, check: checkDuplicateHandlers},
+	{Name: "duplicate-route", Description: "Method and path pairs must be unique across groups", Severity: SeverityError, Default: true, check: checkDuplicateRoutes},
+	{Name: "any-type", Description: "Fields must not use any or interface{}, which goctl does not support", Severity: SeverityError, Default: true, check: checkAnyTypes},
+	{Name: "missing-json-tag", Description: "Fields need a json, form, path or header tag", Severity: SeverityWarning, Default: true, check: checkMissingTags},
+	{Name: "path-param-tag", Description: "Every :param in a route path needs a matching path tag in the request type", Severity: SeverityError, Default: true, check: checkPathParams},
+	{Name: "handler-naming", Description: "Handlers should be UpperCamelCase without a Handler suffix", Severity: SeverityWarning, Default: true, check: checkHandlerNaming},
+	{Name: "missing-doc", Description: "Routes and types should be documented with @doc or a comment", Severity: SeverityInfo, Default: true, check: checkMissingDocs},
+}
+
+// builtinTypes are the Go types allowed in .api files
+var builtinTypes = map[string]bool{
+	"bool": true, "string": true, "byte": true, "rune": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "any": true,
+}
+
+// bindingTags are the struct tags go-zero binds fields with
+var bindingTags = []string{"json", "form", "path", "header"}
+
+var upperCamel = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+func checkUndefinedTypes(p *pass) {
+	defined := func(name string) bool {
+		return name == "" || builtinTypes[name] || p.spec.Type(name) != nil
+	}
+	for _, r := range p.routes {
+		for _, t := range []*apispec.TypeExpr{r.Request, r.Response} {
+			if t != nil && !defined(t.BaseName()) {
+				p.report(r.file, t.Pos, "route %s %s refers to undefined type %s", strings.ToUpper(r.Method), r.fullPath(), t.BaseName())
+			}
+		}
+	}
+	p.fields(func(file, owner string, f *apispec.Field) {
+		if name := f.Type.BaseName(); !defined(name) {
+			p.report(file, f.Type.Pos, "field %s.%s refers to undefined type %s", owner, fieldName(f), name)
+		}
+	})
+}
+
+func checkUnusedTypes(p *pass) {
+	if len(p.routes) == 0 {
+		// A file without routes only declares shared types
+		return
+	}
+	used := make(map[string]bool)
+	var use func(name string)
+	use = func(name string) {
+		t := p.spec.Type(name)
+		if t == nil || used[name] {
+			return
+		}
+		used[name] = true
+		var walk func(fields []*apispec.Field)
+		walk = func(fields []*apispec.Field) {
+			for _, f := range fields {
+				use(f.Type.BaseName())
+				for t := f.Type; t != nil; t = t.Elem {
+					if t.Kind == apispec.KindStruct {
+						walk(t.Fields)
+					}
+				}
+			}
+		}
+		walk(t.Fields)
+	}
+	for _, r := range p.routes {
+		if r.Request != nil {
+			use(r.Request.BaseName())
+		}
+		if r.Response != nil {
+			use(r.Response.BaseName())
+		}
+	}
+	for _, t := range p.spec.Types() {
+		if !used[t.Name] {
+			p.report(p.typeFile[t], t.Pos, "type %s is not used by any route", t.Name)
+		}
+	}
+}
+
+func checkDuplicateHandlers(p *pass) {
+	seen := make(map[string]route)
+	for _, r := range p.routes {
+		if r.Handler == "" {
+			continue
+		}
+		// goctl derives file names from handlers, so names differing only in case collide
+		key := r.svc.Group() + "/" + strings.ToLower(r.Handler)
+		if first, ok := seen[key]; ok {
+			p.report(r.file, handlerPos(r), "handler %s is already declared at %s:%d", r.Handler, first.file, handlerPos(first).Line)
+			continue
+		}
+		seen[key] = r
+	}
+}
+
+func checkDuplicateRoutes(p *pass) {
+	seen := make(map[string]route)
+	for _, r := range p.routes {
+		// Parameter names do not distinguish routes: /users/:id and /users/:uid collide
+		segments := strings.Split(r.fullPath(), "/")
+		for i, seg := range segments {
+			if strings.HasPrefix(seg, ":") {
+				segments[i] = ":"
+			}
+		}
+		key := strings.ToUpper(r.Method) + " " + strings.Join(segments, "/")
+		if first, ok := seen[key]; ok {
+			p.report(r.file, r.Pos, "route %s %s conflicts with %s %s at %s:%d", strings.ToUpper(r.Method), r.fullPath(), strings.ToUpper(first.Method), first.fullPath(), first.file, first.Pos.Line)
+			continue
+		}
+		seen[key] = r
+	}
+}
+
+func checkAnyTypes(p *pass) {
+	p.fields(func(file, owner string, f *apispec.Field) {
+		for t := f.Type; t != nil; t = t.Elem {
+			if t.Kind == apispec.KindInterface || (t.Kind == apispec.KindIdent && t.Name == "any") ||
+				(t.Key != nil && t.Key.Kind == apispec.KindInterface) {
+				p.report(file, f.Pos, "field %s.%s uses %s; define a concrete type instead", owner, fieldName(f), f.Type)
+				return
+			}
+		}
+	})
+}
+
+func checkMissingTags(p *pass) {
+	p.fields(func(file, owner string, f *apispec.Field) {
+		if f.Embedded() {
+			return
+		}
+		for _, key := range bindingTags {
+			if _, ok := f.TagValue(key); ok {
+				return
+			}
+		}
+		p.report(file, f.Pos, "field %s.%s has no json, form, path or header tag", owner, f.Name)
+	})
+}
+
+func checkPathParams(p *pass) {
+	for _, r := range p.routes {
+		params := r.PathParams()
+		if len(params) == 0 {
+			continue
+		}
+		if r.Request == nil {
+			p.report(r.file, r.Pos, "route %s %s has path parameters but no request type to bind them", strings.ToUpper(r.Method), r.fullPath())
+			continue
+		}
+		typ := p.spec.Type(r.Request.BaseName())
+		if typ == nil {
+			continue
+		}
+		tags := pathTags(p.spec, typ, make(map[string]bool))
+		for _, param := range params {
+			if !tags[param] {
+				p.report(r.file, r.Pos, "path parameter :%s has no field tagged path:\"%s\" in %s", param, param, typ.Name)
+			}
+		}
+	}
+}
+
+// pathTags collects the path tag names of a type, following embedded types
+func pathTags(spec *apispec.Spec, typ *apispec.TypeDecl, visited map[string]bool) map[string]bool {
+	tags := make(map[string]bool)
+	if visited[typ.Name] {
+		return tags
+	}
+	visited[typ.Name] = true
+	for _, f := range typ.Fields {
+		if f.Embedded() {
+			if embedded := spec.Type(f.Type.BaseName()); embedded != nil {
+				for name := range pathTags(spec, embedded, visited) {
+					tags[name] = true
+				}
+			}
+			continue
+		}
+		if value, ok := f.TagValue("path"); ok {
+			name, _, _ := strings.Cut(value, ",")
+			tags[name] = true
+		}
+	}
+	return tags
+}
+
+func checkHandlerNaming(p *pass) {
+	for _, r := range p.routes {
+		switch {
+		case r.Handler == "":
+			continue
+		case !upperCamel.MatchString(r.Handler):
+			p.report(r.file, handlerPos(r), "handler %s should be UpperCamelCase, such as %s", r.Handler, upperCamelCase(r.Handler))
+		case strings.HasSuffix(r.Handler, "Handler") && r.Handler != "Handler":
+			p.report(r.file, handlerPos(r), "handler %s ends with Handler; goctl appends the suffix itself", r.Handler)
+		}
+	}
+}
+
+func checkMissingDocs(p *pass) {
+	for _, r := range p.routes {
+		if r.AtDoc == nil && len(r.Doc) == 0 && r.Comment == "" {
+			p.report(r.file, r.Pos, "route %s %s has no @doc or comment", strings.ToUpper(r.Method), r.fullPath())
+		}
+	}
+	for _, t := range p.spec.Types() {
+		if len(t.Doc) == 0 && t.Comment == "" {
+			p.report(p.typeFile[t], t.Pos, "type %s has no doc comment", t.Name)
+		}
+	}
+}
+
+// upperCamelCase converts snake, kebab and lowerCamel names
+func upperCamelCase(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, c := range name {
+		if c == '_' || c == '-' || c == '.' {
+			upper = true
+			continue
+		}
+		if upper {
+			b.WriteString(strings.ToUpper(string(c)))
+			upper = false
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// handlerPos returns the position of @handler, or of the route for legacy @server(handler: ...)
+func handlerPos(r route) apispec.Pos {
+	if r.AtHandlerPos.Line > 0 {
+		return r.AtHandlerPos
+	}
+	return r.Pos
+}
+
+func fieldName(f *apispec.Field) string {
+	if f.Embedded() {
+		return f.Type.BaseName()
+	}
+	return f.Name
+}
@@ -0,0 +1,218 @@
+// Package lock serializes tool calls that write to overlapping directory trees
+package lock
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Manager grants read and write locks on sets of paths. A path covers its whole tree, so
+// locks on a directory and on a file or directory below it overlap. Overlapping locks
+// conflict unless both are read locks; requests are granted in arrival order, so a
+// waiting writer is not starved by a stream of readers
+type Manager struct {
+	mu        sync.Mutex
+	held      []*Lock
+	queue     []*Lock
+	onRelease []func(paths []string)
+}
+
+// Lock is a granted lock; release it with Unlock
+type Lock struct {
+	manager *Manager
+	paths   []string
+	write   bool
+	ready   chan struct{}
+	granted bool
+	once    sync.Once
+}
+
+// NewManager creates a lock manager with no locks held
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// OnRelease registers fn to run with the resolved paths of every released write lock
+func (m *Manager) OnRelease(fn func(paths []string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onRelease = append(m.onRelease, fn)
+}
+
+// Lock takes a write lock on paths, waiting until no overlapping lock is held or
+// requested before it, or until ctx is done
+func (m *Manager) Lock(ctx context.Context, paths ...string) (*Lock, error) {
+	return m.acquire(ctx, true, paths)
+}
+
+// RLock takes a read lock on paths, shared with other read locks
+func (m *Manager) RLock(ctx context.Context, paths ...string) (*Lock, error) {
+	return m.acquire(ctx, false, paths)
+}
+
+func (m *Manager) acquire(ctx context.Context, write bool, paths []string) (*Lock, error) {
+	l := &Lock{manager: m, write: write, ready: make(chan struct{})}
+	for _, path := range paths {
+		resolved, err := Resolve(path)
+		if err != nil {
+			return nil, err
+		}
+		l.paths = append(l.paths, resolved)
+	}
+
+	m.mu.Lock()
+	m.queue = append(m.queue, l)
+	m.grant()
+	m.mu.Unlock()
+
+	select {
+	case <-l.ready:
+		return l, nil
+	case <-ctx.Done():
+	}
+
+	m.mu.Lock()
+	if l.granted {
+		// Granted while giving up; hand it back
+		m.mu.Unlock()
+		l.Unlock()
+		return nil, ctx.Err()
+	}
+	for i, queued := range m.queue {
+		if queued == l {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			break
+		}
+	}
+	// Requests queued behind this one may be free to go now
+	m.grant()
+	m.mu.Unlock()
+	return nil, ctx.Err()
+}
+
+// grant hands out every queued lock that conflicts with no held lock and no lock queued
+// before it; m.mu must be held
+func (m *Manager) grant() {
+	var waiting []*Lock
+	for _, l := range m.queue {
+		if conflictsWithAny(l, m.held) || conflictsWithAny(l, waiting) {
+			waiting = append(waiting, l)
+			continue
+		}
+		l.granted = true
+		m.held = append(m.held, l)
+		close(l.ready)
+	}
+	m.queue = waiting
+}
+
+// Unlock releases the lock; later calls do nothing
+func (l *Lock) Unlock() {
+	l.once.Do(func() {
+		m := l.manager
+		m.mu.Lock()
+		for i, held := range m.held {
+			if held == l {
+				m.held = append(m.held[:i], m.held[i+1:]...)
+				break
+			}
+		}
+		m.grant()
+		callbacks := append([]func([]string){}, m.onRelease...)
+		m.mu.Unlock()
+
+		if l.write {
+			for _, fn := range callbacks {
+				fn(l.paths)
+			}
+		}
+	})
+}
+
+// Held reports how many locks are granted and how many are waiting
+func (m *Manager) Held() (held, waiting int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.held), len(m.queue)
+}
+
+func conflictsWithAny(l *Lock, others []*Lock) bool {
+	for _, other := range others {
+		if (l.write || other.write) && overlapsAny(l.paths, other.paths) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlapsAny(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if Overlaps(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Overlaps reports whether two resolved paths are the same or one is inside the other
+func Overlaps(a, b string) bool {
+	return Within(a, b) || Within(b, a)
+}
+
+// Within reports whether resolved path is dir or inside it
+func Within(path, dir string) bool {
+	if path == dir {
+		return true
+	}
+	if !strings.HasSuffix(dir, string(filepath.Separator)) {
+		dir += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, dir)
+}
+
+// Resolve returns path as a clean absolute path with symlinks resolved, so that aliases
+// such as /tmp and /private/tmp name the same tree. Components are resolved one at a time,
+// so ".." after a symlink leaves the directory the link points to, as it does for the
+// operating system; components that do not exist yet are kept as they are
+func Resolve(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", err
+		}
+		// Joined by hand: filepath.Join would clean ".." before symlinks are resolved
+		path = cwd + string(filepath.Separator) + path
+	}
+	volume := filepath.VolumeName(path)
+	resolved := volume + string(filepath.Separator)
+	// Split on the separator bytes only: multi-byte runes in names are never separators
+	rest := path[len(volume):]
+	if filepath.Separator != '/' {
+		rest = strings.ReplaceAll(rest, "/", string(filepath.Separator))
+	}
+	for _, part := range strings.Split(rest, string(filepath.Separator)) {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+		next := filepath.Join(resolved, part)
+		real, err := filepath.EvalSymlinks(next)
+		switch {
+		case err == nil:
+			resolved = real
+		case os.IsNotExist(err):
+			resolved = next
+		default:
+			return "", err
+		}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,231 @@
+package lock_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeromicro/mcp-zero/internal/lock"
+)
+
+func TestOverlaps(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want bool
+	}{
+		{"/work/app", "/work/app", true},
+		{"/work/app", "/work/app/internal", true},
+		{"/work/app/go.mod", "/work", true},
+		{"/work/app", "/work/application", false},
+		{"/work/api", "/work/rpc", false},
+		{"/", "/work", true},
+	} {
+		if got := lock.Overlaps(tc.a, tc.b); got != tc.want {
+			t.Errorf("Overlaps(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	root := t.TempDir()
+	real := filepath.Join(root, "real")
+	if err := os.Mkdir(real, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	// ".." after a symlink leaves its target, not the directory holding the link
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	up := filepath.Join(root, "up")
+	if err := os.Symlink(nested, up); err != nil {
+		t.Fatal(err)
+	}
+	resolvedReal, err := filepath.EvalSymlinks(real)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolvedNested, err := filepath.EvalSymlinks(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]string{
+		link:                                   resolvedReal,
+		filepath.Join(link, "svc", "go.mod"):   filepath.Join(resolvedReal, "svc", "go.mod"),
+		filepath.Join(link, "..", "real", "x"): filepath.Join(resolvedReal, "x"),
+		link + "/../real/new/../x":             filepath.Join(resolvedReal, "x"),
+		up + "/../c":                           filepath.Join(filepath.Dir(resolvedNested), "c"),
+		// U+012F is not a separator, even though its low byte is '/'
+		real + "/sub/a\u012fb/../../../c": filepath.Join(filepath.Dir(resolvedReal), "c"),
+	} {
+		got, err := lock.Resolve(path)
+		if err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", path, got, err, want)
+		}
+	}
+}
+
+// acquired reports whether ch is closed within a short wait
+func acquired(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	case <-time.After(100 * time.Millisecond):
+		return false
+	}
+}
+
+// lockAsync takes a lock in a goroutine, closing the returned channel once it is granted
+func lockAsync(t *testing.T, m *lock.Manager, write bool, paths ...string) (<-chan struct{}, func() *lock.Lock) {
+	t.Helper()
+	done := make(chan struct{})
+	var l *lock.Lock
+	go func() {
+		var err error
+		if write {
+			l, err = m.Lock(context.Background(), paths...)
+		} else {
+			l, err = m.RLock(context.Background(), paths...)
+		}
+		if err != nil {
+			t.Error(err)
+		}
+		close(done)
+	}()
+	return done, func() *lock.Lock { <-done; return l }
+}
+
+func TestWriteLocksSerializeOverlappingTrees(t *testing.T) {
+	root := t.TempDir()
+	m := lock.NewManager()
+	ctx := context.Background()
+
+	parent, err := m.Lock(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A child of a locked tree waits, an unrelated tree does not
+	child, childLock := lockAsync(t, m, true, filepath.Join(root, "svc"))
+	other, err := m.Lock(ctx, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Unlock()
+	if acquired(child) {
+		t.Fatal("lock on a child was granted while its parent was locked")
+	}
+
+	parent.Unlock()
+	if !acquired(child) {
+		t.Fatal("lock on the child was not granted after the parent was released")
+	}
+	childLock().Unlock()
+	if held, waiting := m.Held(); held != 0 || waiting != 0 {
+		t.Errorf("Held() = %d, %d after every unlock", held, waiting)
+	}
+}
+
+func TestReadLocks(t *testing.T) {
+	root := t.TempDir()
+	m := lock.NewManager()
+	ctx := context.Background()
+
+	r1, err := m.RLock(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := m.RLock(ctx, filepath.Join(root, "svc"))
+	if err != nil {
+		t.Fatal("read locks on overlapping trees should be shared")
+	}
+
+	writer, writerLock := lockAsync(t, m, true, filepath.Join(root, "svc"))
+	if acquired(writer) {
+		t.Fatal("write lock granted while read locks were held")
+	}
+
+	// A reader arriving after the waiting writer queues behind it
+	reader, readerLock := lockAsync(t, m, false, root)
+	if acquired(reader) {
+		t.Fatal("reader overtook a waiting writer")
+	}
+
+	r1.Unlock()
+	r2.Unlock()
+	if !acquired(writer) {
+		t.Fatal("writer not granted after the readers left")
+	}
+	if acquired(reader) {
+		t.Fatal("reader granted while the writer held the lock")
+	}
+	writerLock().Unlock()
+	if !acquired(reader) {
+		t.Fatal("reader not granted after the writer left")
+	}
+	readerLock().Unlock()
+}
+
+func TestLockCanceled(t *testing.T) {
+	root := t.TempDir()
+	m := lock.NewManager()
+
+	held, err := m.Lock(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := m.Lock(ctx, root); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Lock() = %v, want a deadline error", err)
+	}
+	if _, waiting := m.Held(); waiting != 0 {
+		t.Errorf("the canceled request is still queued")
+	}
+	held.Unlock()
+	held.Unlock()
+
+	again, err := m.Lock(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again.Unlock()
+}
+
+func TestOnRelease(t *testing.T) {
+	root := t.TempDir()
+	m := lock.NewManager()
+	var mu sync.Mutex
+	var released [][]string
+	m.OnRelease(func(paths []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		released = append(released, paths)
+	})
+
+	r, err := m.RLock(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Unlock()
+	w, err := m.Lock(context.Background(), filepath.Join(root, "svc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Unlock()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(released) != 1 || len(released[0]) != 1 || filepath.Base(released[0][0]) != "svc" {
+		t.Errorf("OnRelease saw %v, want only the write lock", released)
+	}
+}
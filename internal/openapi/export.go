@@ -0,0 +1,623 @@
+package openapi
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+)
+
+// ExportOptions controls how a .api spec is exported
+type ExportOptions struct {
+	Version   string // "3.0" (default) or "3.1"
+	ServerURL string // optional base URL added to servers
+}
+
+// exporter converts one spec; schemas are added to components as they are referenced
+type exporter struct {
+	spec     *apispec.Spec
+	v31      bool
+	schemas  *Map[*Schema]
+	building map[string]bool
+}
+
+// bodyMethods are the methods whose form fields travel in the request body
+var bodyMethods = map[string]bool{"post": true, "put": true, "patch": true}
+
+// Export converts a parsed .api spec into an OpenAPI document
+func Export(spec *apispec.Spec, opts ExportOptions) (*Document, error) {
+	doc := &Document{Paths: NewMap[*PathItem]()}
+	switch opts.Version {
+	case "", "3.0", "3.0.3":
+		doc.OpenAPI = "3.0.3"
+	case "3.1", "3.1.0":
+		doc.OpenAPI = "3.1.0"
+	default:
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, use 3.0 or 3.1", opts.Version)
+	}
+
+	e := &exporter{
+		spec:     spec,
+		v31:      strings.HasPrefix(doc.OpenAPI, "3.1"),
+		schemas:  NewMap[*Schema](),
+		building: make(map[string]bool),
+	}
+
+	doc.Info = Info{Title: spec.ServiceName(), Version: "1.0.0"}
+	if info := spec.Root.Info; info != nil {
+		if title := info.Get("title"); title != "" {
+			doc.Info.Title = title
+		}
+		if version := info.Get("version"); version != "" {
+			doc.Info.Version = version
+		}
+		doc.Info.Description = info.Get("desc")
+		if doc.Info.Description == "" {
+			doc.Info.Description = info.Get("description")
+		}
+	}
+	if opts.ServerURL != "" {
+		doc.Servers = []*Server{{URL: opts.ServerURL}}
+	}
+
+	securitySchemes := NewMap[*SecurityScheme]()
+	tagIndex := make(map[string]*Tag)
+
+	for _, svc := range spec.Services() {
+		tag := tagName(svc)
+		if tag != "" {
+			if existing, ok := tagIndex[tag]; !ok {
+				t := &Tag{Name: tag, Description: serviceDoc(svc)}
+				tagIndex[tag] = t
+				doc.Tags = append(doc.Tags, t)
+			} else if existing.Description == "" {
+				existing.Description = serviceDoc(svc)
+			}
+		}
+
+		jwt := svc.JWT()
+		if jwt != "" {
+			securitySchemes.Set(jwt, &SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"})
+		}
+
+		for _, route := range svc.Routes {
+			method := strings.ToLower(route.Method)
+			routePath := openAPIPath(joinPath(svc.Prefix(), route.Path))
+
+			op, err := e.operation(method, route)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), routePath, err)
+			}
+			if tag != "" {
+				op.Tags = []string{tag}
+			}
+			if jwt != "" {
+				op.Security = []SecurityRequirement{{jwt: []string{}}}
+			}
+
+			item, ok := doc.Paths.Get(routePath)
+			if !ok {
+				item = &PathItem{}
+				doc.Paths.Set(routePath, item)
+			}
+			if item.Operation(method) != nil {
+				return nil, fmt.Errorf("duplicate route %s %s", strings.ToUpper(method), routePath)
+			}
+			if err := item.SetOperation(method, op); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if e.schemas.Len() > 0 || securitySchemes.Len() > 0 {
+		doc.Components = &Components{}
+		if e.schemas.Len() > 0 {
+			doc.Components.Schemas = e.schemas
+		}
+		if securitySchemes.Len() > 0 {
+			doc.Components.SecuritySchemes = securitySchemes
+		}
+	}
+
+	return doc, nil
+}
+
+func (e *exporter) operation(method string, route *apispec.Route) (*Operation, error) {
+	op := &Operation{
+		OperationID: route.Handler,
+		Summary:     route.Summary(),
+		Description: apispec.CommentText(route.Doc...),
+		Responses:   NewMap[*Response](),
+	}
+	// Comments above @doc belong to the annotation
+	if route.AtDoc != nil && len(route.AtDoc.Doc) > 0 {
+		op.Description = apispec.CommentText(append(append([]string{}, route.AtDoc.Doc...), route.Doc...)...)
+	}
+	if route.AtDoc != nil && route.AtDoc.Get("description") != "" {
+		op.Description = route.AtDoc.Get("description")
+	}
+
+	declared := make(map[string]bool)
+	if route.Request != nil {
+		if err := e.request(op, method, route.Request, declared); err != nil {
+			return nil, err
+		}
+	}
+
+	// Path parameters without a path-tagged field are still part of the contract
+	for _, param := range route.PathParams() {
+		if !declared["path:"+param] {
+			op.Parameters = append(op.Parameters, &Parameter{Name: param, In: "path", Required: true, Schema: &Schema{Type: SchemaType{"string"}}})
+		}
+	}
+
+	response := &Response{Description: "A successful response."}
+	if route.Response != nil {
+		schema, err := e.typeSchema(route.Response)
+		if err != nil {
+			return nil, err
+		}
+		response.Content = NewMap[*MediaType]()
+		response.Content.Set("application/json", &MediaType{Schema: schema})
+	}
+	op.Responses.Set("200", response)
+
+	return op, nil
+}
+
+// request adds parameters and a body for the request type, recording declared parameters
+func (e *exporter) request(op *Operation, method string, expr *apispec.TypeExpr, declared map[string]bool) error {
+	if expr.Kind != apispec.KindIdent || isPrimitive(expr.Name) {
+		schema, err := e.typeSchema(expr)
+		if err != nil {
+			return err
+		}
+		op.RequestBody = jsonBody(schema)
+		return nil
+	}
+
+	typ := e.spec.Type(expr.Name)
+	if typ == nil {
+		return fmt.Errorf("undefined type %s", expr.Name)
+	}
+	fields, err := e.flatten(typ.Fields, map[string]bool{typ.Name: true})
+	if err != nil {
+		return err
+	}
+
+	var formFields []*apispec.Field
+	hasJSON := false
+	for _, f := range fields {
+		if name, _ := jsonName(f); name != "" {
+			hasJSON = true
+		}
+		for _, source := range []string{"path", "header", "form"} {
+			value, ok := f.TagValue(source)
+			if !ok {
+				continue
+			}
+			if source == "form" && bodyMethods[method] {
+				formFields = append(formFields, f)
+				continue
+			}
+			param, err := e.parameter(f, source, value)
+			if err != nil {
+				return err
+			}
+			declared[param.In+":"+param.Name] = true
+			op.Parameters = append(op.Parameters, param)
+		}
+	}
+
+	switch {
+	case hasJSON:
+		schema, err := e.ref(typ.Name)
+		if err != nil {
+			return err
+		}
+		op.RequestBody = jsonBody(schema)
+		// Form fields next to a JSON body can only come from the query string
+		for _, f := range formFields {
+			value, _ := f.TagValue("form")
+			param, err := e.parameter(f, "form", value)
+			if err != nil {
+				return err
+			}
+			op.Parameters = append(op.Parameters, param)
+		}
+	case len(formFields) > 0:
+		schema := &Schema{Type: SchemaType{"object"}, Properties: NewMap[*Schema]()}
+		for _, f := range formFields {
+			value, _ := f.TagValue("form")
+			name, opts := splitTag(value, f.Name)
+			prop, err := e.fieldSchema(f, opts)
+			if err != nil {
+				return err
+			}
+			schema.Properties.Set(name, prop)
+			if isRequired(opts) {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+		op.RequestBody = &RequestBody{Required: true, Content: NewMap[*MediaType]()}
+		op.RequestBody.Content.Set("application/x-www-form-urlencoded", &MediaType{Schema: schema})
+	}
+	return nil
+}
+
+// parameter converts a path, header or form tagged field to a parameter
+func (e *exporter) parameter(f *apispec.Field, source, tagValue string) (*Parameter, error) {
+	name, opts := splitTag(tagValue, f.Name)
+	schema, err := e.fieldSchema(f, opts)
+	if err != nil {
+		return nil, err
+	}
+	param := &Parameter{Name: name, In: source, Required: isRequired(opts), Schema: schema}
+	if source == "form" {
+		param.In = "query"
+	}
+	if source == "path" {
+		param.Required = true
+	}
+	// Descriptions live on the parameter, not its schema
+	param.Description, schema.Description = schema.Description, ""
+	return param, nil
+}
+
+// ref returns a reference to a named type, adding its schema to components on first use
+func (e *exporter) ref(name string) (*Schema, error) {
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if _, ok := e.schemas.Get(name); ok || e.building[name] {
+		return ref, nil
+	}
+
+	typ := e.spec.Type(name)
+	if typ == nil {
+		return nil, fmt.Errorf("undefined type %s", name)
+	}
+
+	e.building[name] = true
+	defer delete(e.building, name)
+
+	schema, err := e.objectSchema(typ.Fields, map[string]bool{name: true})
+	if err != nil {
+		return nil, err
+	}
+	schema.Description = describe(typ.Doc, typ.Comment)
+	e.schemas.Set(name, schema)
+	return ref, nil
+}
+
+// objectSchema builds an object from the fields that appear in JSON
+func (e *exporter) objectSchema(fields []*apispec.Field, seen map[string]bool) (*Schema, error) {
+	flat, err := e.flatten(fields, seen)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{Type: SchemaType{"object"}, Properties: NewMap[*Schema]()}
+	for _, f := range flat {
+		name, opts := jsonName(f)
+		if name == "" {
+			continue
+		}
+		prop, err := e.fieldSchema(f, opts)
+		if err != nil {
+			return nil, err
+		}
+		schema.Properties.Set(name, prop)
+		if isRequired(opts) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	if schema.Properties.Len() == 0 {
+		schema.Properties = nil
+	}
+	return schema, nil
+}
+
+// flatten replaces embedded types with their fields, the way encoding/json does
+func (e *exporter) flatten(fields []*apispec.Field, seen map[string]bool) ([]*apispec.Field, error) {
+	var flat []*apispec.Field
+	for _, f := range fields {
+		if !f.Embedded() {
+			flat = append(flat, f)
+			continue
+		}
+		name := f.Type.BaseName()
+		typ := e.spec.Type(name)
+		if typ == nil {
+			return nil, fmt.Errorf("undefined type %s", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("type %s embeds itself", name)
+		}
+		seen[name] = true
+		embedded, err := e.flatten(typ.Fields, seen)
+		delete(seen, name)
+		if err != nil {
+			return nil, err
+		}
+		flat = append(flat, embedded...)
+	}
+	return flat, nil
+}
+
+// fieldSchema returns the schema of a field with its tag options applied
+func (e *exporter) fieldSchema(f *apispec.Field, opts []string) (*Schema, error) {
+	schema, err := e.typeSchema(f.Type)
+	if err != nil {
+		return nil, err
+	}
+	description := describe(f.Doc, f.Comment)
+
+	constraints := &Schema{Description: description}
+	for _, opt := range opts {
+		key, value, _ := strings.Cut(opt, "=")
+		switch key {
+		case "default":
+			constraints.Default = typedValue(schema, value)
+		case "options":
+			for _, option := range splitOptions(value) {
+				constraints.Enum = append(constraints.Enum, typedValue(schema, option))
+			}
+		case "range":
+			if err := e.applyRange(constraints, value); err != nil {
+				return nil, fmt.Errorf("field %s: %w", f.Name, err)
+			}
+		}
+	}
+
+	if schema.Ref == "" {
+		schema.Description = constraints.Description
+		schema.Default = constraints.Default
+		schema.Enum = constraints.Enum
+		schema.Minimum, schema.Maximum = constraints.Minimum, constraints.Maximum
+		schema.ExclusiveMinimum, schema.ExclusiveMaximum = constraints.ExclusiveMinimum, constraints.ExclusiveMaximum
+		return schema, nil
+	}
+	if constraints.Description == "" && constraints.Default == nil {
+		return schema, nil
+	}
+	// 3.0 ignores keywords next to $ref, so wrap the reference
+	if e.v31 {
+		schema.Description = constraints.Description
+		schema.Default = constraints.Default
+		return schema, nil
+	}
+	return &Schema{AllOf: []*Schema{schema}, Description: constraints.Description, Default: constraints.Default}, nil
+}
+
+// applyRange maps a go-zero range such as [1:100] or (0:] to minimum and maximum
+func (e *exporter) applyRange(schema *Schema, value string) error {
+	if len(value) < 3 || !strings.ContainsAny(value[:1], "[(") || !strings.ContainsAny(value[len(value)-1:], "])") {
+		return fmt.Errorf("invalid range %q", value)
+	}
+	low, high, ok := strings.Cut(value[1:len(value)-1], ":")
+	if !ok {
+		return fmt.Errorf("invalid range %q", value)
+	}
+
+	bound := func(text string, exclusive bool, inclusive **float64, excl *any) error {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil
+		}
+		n, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return fmt.Errorf("invalid range %q", value)
+		}
+		switch {
+		case !exclusive:
+			*inclusive = &n
+		case e.v31:
+			*excl = n
+		default:
+			*inclusive = &n
+			*excl = true
+		}
+		return nil
+	}
+	if err := bound(low, value[0] == '(', &schema.Minimum, &schema.ExclusiveMinimum); err != nil {
+		return err
+	}
+	return bound(high, value[len(value)-1] == ')', &schema.Maximum, &schema.ExclusiveMaximum)
+}
+
+// typeSchema returns the schema of a type expression
+func (e *exporter) typeSchema(expr *apispec.TypeExpr) (*Schema, error) {
+	switch expr.Kind {
+	case apispec.KindPointer:
+		return e.typeSchema(expr.Elem)
+	case apispec.KindArray:
+		if expr.Elem.Kind == apispec.KindIdent && (expr.Elem.Name == "byte" || expr.Elem.Name == "uint8") {
+			return &Schema{Type: SchemaType{"string"}, Format: "byte"}, nil
+		}
+		items, err := e.typeSchema(expr.Elem)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: SchemaType{"array"}, Items: items}, nil
+	case apispec.KindMap:
+		values, err := e.typeSchema(expr.Elem)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: SchemaType{"object"}, AdditionalProperties: values}, nil
+	case apispec.KindStruct:
+		return e.objectSchema(expr.Fields, map[string]bool{})
+	case apispec.KindInterface:
+		return &Schema{}, nil
+	}
+
+	if schema := primitiveSchema(expr.Name); schema != nil {
+		return schema, nil
+	}
+	return e.ref(expr.Name)
+}
+
+func primitiveSchema(name string) *Schema {
+	switch name {
+	case "string":
+		return &Schema{Type: SchemaType{"string"}}
+	case "bool":
+		return &Schema{Type: SchemaType{"boolean"}}
+	case "int", "int8", "int16", "uint", "uint8", "uint16", "byte":
+		return &Schema{Type: SchemaType{"integer"}}
+	case "int32", "uint32", "rune":
+		return &Schema{Type: SchemaType{"integer"}, Format: "int32"}
+	case "int64", "uint64":
+		return &Schema{Type: SchemaType{"integer"}, Format: "int64"}
+	case "float32":
+		return &Schema{Type: SchemaType{"number"}, Format: "float"}
+	case "float64":
+		return &Schema{Type: SchemaType{"number"}, Format: "double"}
+	case "any":
+		return &Schema{}
+	}
+	return nil
+}
+
+func isPrimitive(name string) bool {
+	return primitiveSchema(name) != nil
+}
+
+// typedValue converts a tag value to the JSON type of the schema
+func typedValue(schema *Schema, value string) any {
+	switch {
+	case schema.Type.Is("integer"):
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case schema.Type.Is("number"):
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case schema.Type.Is("boolean"):
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// jsonName returns the JSON key and options of a field; an empty key means the field is not in JSON
+func jsonName(f *apispec.Field) (string, []string) {
+	value, ok := f.TagValue("json")
+	if !ok {
+		if f.Tag != "" {
+			return "", nil
+		}
+		return f.Name, nil
+	}
+	if value == "-" {
+		return "", nil
+	}
+	return splitTag(value, f.Name)
+}
+
+// splitTag splits a tag value into its name and options, keeping options=[a,b] intact
+func splitTag(value, fieldName string) (string, []string) {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range value {
+		switch r {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case ',':
+			if depth <= 0 {
+				parts = append(parts, strings.TrimSpace(value[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, strings.TrimSpace(value[start:]))
+
+	name := parts[0]
+	if name == "" {
+		name = fieldName
+	}
+	return name, parts[1:]
+}
+
+// splitOptions splits options=a|b|c or options=[a,b,c]
+func splitOptions(value string) []string {
+	value = strings.TrimSpace(value)
+	sep := "|"
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		value, sep = value[1:len(value)-1], ","
+	}
+	var options []string
+	for _, option := range strings.Split(value, sep) {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// isRequired reports whether tag options leave a field required
+func isRequired(opts []string) bool {
+	for _, opt := range opts {
+		if opt == "optional" || opt == "omitempty" || strings.HasPrefix(opt, "default=") {
+			return false
+		}
+	}
+	return true
+}
+
+// describe joins doc comments and a trailing line comment into a description
+func describe(doc []string, comment string) string {
+	comments := append([]string{}, doc...)
+	return apispec.CommentText(append(comments, comment)...)
+}
+
+func jsonBody(schema *Schema) *RequestBody {
+	body := &RequestBody{Required: true, Content: NewMap[*MediaType]()}
+	body.Content.Set("application/json", &MediaType{Schema: schema})
+	return body
+}
+
+// serviceDoc returns the comments above a service block or its @server annotation
+func serviceDoc(svc *apispec.ServiceDecl) string {
+	var comments []string
+	if svc.Server != nil {
+		comments = append(comments, svc.Server.Doc...)
+	}
+	return apispec.CommentText(append(comments, svc.Doc...)...)
+}
+
+// tagName returns the OpenAPI tag of a service block: its group, or else its prefix
+func tagName(svc *apispec.ServiceDecl) string {
+	if group := svc.Group(); group != "" {
+		return group
+	}
+	return strings.Trim(svc.Prefix(), "/")
+}
+
+// joinPath prepends a @server prefix to a route path
+func joinPath(prefix, routePath string) string {
+	if prefix == "" {
+		return routePath
+	}
+	joined := path.Join("/", prefix, routePath)
+	if strings.HasSuffix(routePath, "/") && !strings.HasSuffix(joined, "/") {
+		joined += "/"
+	}
+	return joined
+}
+
+// openAPIPath converts :name path segments to {name}
+func openAPIPath(routePath string) string {
+	segments := strings.Split(routePath, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") && len(seg) > 1 {
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,186 @@
+package openapi_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+	"github.com/zeromicro/mcp-zero/internal/openapi"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// exportCases are the documents generated for every root spec in testdata
+var exportCases = []struct {
+	suffix  string
+	version string
+	format  string
+}{
+	{".openapi30.json", "3.0", "json"},
+	{".openapi31.yaml", "3.1", "yaml"},
+}
+
+func TestExportGolden(t *testing.T) {
+	for _, c := range exportCases {
+		t.Run(c.suffix, func(t *testing.T) {
+			spec, err := apispec.Load(filepath.Join("testdata", "user.api"), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := openapi.Export(spec, openapi.ExportOptions{Version: c.version, ServerURL: "https://api.example.com"})
+			if err != nil {
+				t.Fatalf("Export() failed: %v", err)
+			}
+			got, err := openapi.Marshal(doc, c.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", "user"+c.suffix)
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create): %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("Document does not match %s (run with -update to refresh):\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, c := range exportCases {
+		t.Run(c.suffix, func(t *testing.T) {
+			golden, err := os.ReadFile(filepath.Join("testdata", "user"+c.suffix))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			doc, err := openapi.Unmarshal(golden)
+			if err != nil {
+				t.Fatalf("Unmarshal() failed: %v", err)
+			}
+			again, err := openapi.Marshal(doc, c.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(golden) {
+				t.Errorf("Document changed after decoding and encoding again:\n%s", again)
+			}
+
+			// The same document in the other format must decode to the same content
+			other := "yaml"
+			if c.format == "yaml" {
+				other = "json"
+			}
+			converted, err := openapi.Marshal(doc, other)
+			if err != nil {
+				t.Fatal(err)
+			}
+			back, err := openapi.Unmarshal(converted)
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) failed: %v", other, err)
+			}
+			final, err := openapi.Marshal(back, c.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(final) != string(golden) {
+				t.Errorf("Document changed after converting through %s:\n%s", other, final)
+			}
+		})
+	}
+}
+
+func TestExportMapping(t *testing.T) {
+	spec, err := apispec.Load(filepath.Join("testdata", "user.api"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := openapi.Export(spec, openapi.ExportOptions{})
+	if err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+
+	if doc.OpenAPI != "3.0.3" || doc.Info.Title != "User API" || doc.Info.Version != "1.2.0" {
+		t.Errorf("Unexpected header: %s %+v", doc.OpenAPI, doc.Info)
+	}
+	if got := strings.Join(doc.Paths.Keys(), ","); got != "/api/v1/users/{id},/api/v1/users,/auth/login" {
+		t.Errorf("Unexpected paths: %s", got)
+	}
+
+	item, _ := doc.Paths.Get("/api/v1/users/{id}")
+	get := item.Get
+	if get.OperationID != "GetUser" || get.Summary != "Get a user by id" || get.Tags[0] != "user" {
+		t.Errorf("Unexpected operation: %+v", get)
+	}
+	if _, ok := get.Security[0]["Auth"]; !ok {
+		t.Error("Expected the jwt scheme to be required")
+	}
+	wantParams := []string{"path:id:true", "query:verbose:false", "header:X-Trace-Id:false"}
+	for i, p := range get.Parameters {
+		if got := p.In + ":" + p.Name + ":" + map[bool]string{true: "true", false: "false"}[p.Required]; got != wantParams[i] {
+			t.Errorf("Parameter %d = %s, want %s", i, got, wantParams[i])
+		}
+	}
+	if item.Delete == nil || len(item.Delete.Parameters) != 1 || item.Delete.Parameters[0].Name != "id" {
+		t.Error("Expected a path parameter for a route without a request type")
+	}
+
+	schema, _ := doc.Components.Schemas.Get("UpdateUserReq")
+	if got := strings.Join(schema.Required, ","); got != "name" {
+		t.Errorf("Expected only name to be required, got %s", got)
+	}
+	if _, ok := schema.Properties.Get("id"); ok {
+		t.Error("Path fields must not appear in the body schema")
+	}
+	score, _ := schema.Properties.Get("score")
+	if *score.Minimum != 0 || score.ExclusiveMinimum != true || *score.Maximum != 10 {
+		t.Errorf("Unexpected range mapping: %+v", score)
+	}
+
+	list, _ := doc.Paths.Get("/api/v1/users")
+	page := list.Get.Parameters[0]
+	if page.Required || page.Schema.Default != int64(1) {
+		t.Errorf("Expected page to be optional with default 1, got %+v", page.Schema)
+	}
+	if list.Get.Description != "Supports paging and filtering" {
+		t.Errorf("Expected doc comment as description, got %q", list.Get.Description)
+	}
+
+	login, _ := doc.Paths.Get("/auth/login")
+	if login.Post.Tags[0] != "auth" || login.Post.Security != nil {
+		t.Errorf("Expected prefix tag and no security, got %+v", login.Post)
+	}
+	if _, ok := login.Post.RequestBody.Content.Get("application/x-www-form-urlencoded"); !ok {
+		t.Error("Expected a form body for POST form fields")
+	}
+}
+
+func TestExportVersion31(t *testing.T) {
+	spec, err := apispec.Load(filepath.Join("testdata", "user.api"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := openapi.Export(spec, openapi.ExportOptions{Version: "3.1"})
+	if err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+	schema, _ := doc.Components.Schemas.Get("UpdateUserReq")
+	score, _ := schema.Properties.Get("score")
+	if score.Minimum != nil || score.ExclusiveMinimum != float64(0) {
+		t.Errorf("Expected a numeric exclusiveMinimum, got %+v", score)
+	}
+
+	if _, err := openapi.Export(spec, openapi.ExportOptions{Version: "2.0"}); err == nil {
+		t.Error("Expected an error for an unsupported version")
+	}
+}
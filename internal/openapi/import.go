@@ -0,0 +1,920 @@
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ImportOptions controls how an OpenAPI document becomes a .api spec
+type ImportOptions struct {
+	ServiceName string // service name without the -api suffix; defaults to the document title
+}
+
+// ImportResult is a generated .api spec and the constructs that could not be represented
+type ImportResult struct {
+	Source      []byte
+	ServiceName string
+	Types       int
+	Routes      int
+	Groups      []string
+	Warnings    []string
+}
+
+// apiType is a type declaration of the generated spec
+type apiType struct {
+	name   string
+	doc    string
+	embeds []string
+	fields []apiField
+}
+
+type apiField struct {
+	name string
+	typ  string
+	tag  string
+	doc  string
+}
+
+type apiRoute struct {
+	method      string
+	path        string
+	handler     string
+	summary     string
+	description string
+	request     string
+	response    string
+}
+
+// apiBlock is one @server block; routes sharing group, jwt and prefix go together
+type apiBlock struct {
+	group  string
+	jwt    string
+	prefix string
+	doc    string
+	routes []*apiRoute
+}
+
+// importer converts one document
+type importer struct {
+	doc         *Document
+	types       []*apiType
+	names       map[string]bool   // declared type names
+	schemaTypes map[string]string // component schema name -> declared type name
+	resolving   map[string]bool   // non-object components being inlined
+	handlers    map[string]bool
+	warnings    []string
+	warned      map[string]bool
+}
+
+// Import converts an OpenAPI 3 document, or a converted Swagger 2 document, to .api source
+func Import(doc *Document, opts ImportOptions) (*ImportResult, error) {
+	i := &importer{
+		doc:         doc,
+		names:       make(map[string]bool),
+		schemaTypes: make(map[string]string),
+		resolving:   make(map[string]bool),
+		handlers:    make(map[string]bool),
+		warned:      make(map[string]bool),
+	}
+
+	serviceName := opts.ServiceName
+	if serviceName == "" {
+		serviceName = serviceSlug(doc.Info.Title)
+	}
+	serviceName = strings.TrimSuffix(serviceName, "-api")
+	if serviceName == "" {
+		serviceName = "api"
+	}
+
+	// Declare object components first so references resolve in any order
+	var components []string
+	if doc.Components != nil {
+		for _, name := range doc.Components.Schemas.Keys() {
+			schema, _ := doc.Components.Schemas.Get(name)
+			if isObject(schema) {
+				i.schemaTypes[name] = i.reserve(goName(name))
+				components = append(components, name)
+			}
+		}
+	}
+	for _, name := range components {
+		schema, _ := doc.Components.Schemas.Get(name)
+		i.objectType(i.schemaTypes[name], schema, name)
+	}
+
+	prefix := serverPrefix(doc.Servers)
+	tagDocs := make(map[string]string)
+	for _, tag := range doc.Tags {
+		tagDocs[tag.Name] = tag.Description
+	}
+
+	var blocks []*apiBlock
+	blockIndex := make(map[string]*apiBlock)
+	documented := make(map[string]bool)
+	routes := 0
+	for _, p := range doc.Paths.Keys() {
+		item, _ := doc.Paths.Get(p)
+		if item.Ref != "" {
+			i.warn("%s: path item references are not supported", p)
+			continue
+		}
+		for _, method := range Methods {
+			op := item.Operation(method)
+			if op == nil {
+				continue
+			}
+			where := strings.ToUpper(method) + " " + p
+
+			route := &apiRoute{
+				method:      method,
+				path:        i.routePath(p, where),
+				handler:     i.handlerName(op.OperationID, method, p),
+				summary:     op.Summary,
+				description: op.Description,
+			}
+			route.request = i.requestType(route.handler, mergeParameters(i.parameters(item.Parameters, where), i.parameters(op.Parameters, where)), op.RequestBody, where)
+			route.response = i.responseType(route.handler, op.Responses, where)
+
+			group := ""
+			if len(op.Tags) > 0 {
+				group = groupName(op.Tags[0])
+			}
+			security := op.Security
+			if security == nil {
+				security = doc.Security
+			}
+			jwt := i.jwt(security, where)
+
+			key := group + "|" + jwt
+			block, ok := blockIndex[key]
+			if !ok {
+				block = &apiBlock{group: group, jwt: jwt, prefix: prefix}
+				// The tag description goes above the first block of the group
+				if len(op.Tags) > 0 && !documented[group] {
+					block.doc = tagDocs[op.Tags[0]]
+					documented[group] = true
+				}
+				blockIndex[key] = block
+				blocks = append(blocks, block)
+			}
+			block.routes = append(block.routes, route)
+			routes++
+		}
+	}
+	if routes == 0 {
+		return nil, fmt.Errorf("document has no operations")
+	}
+
+	result := &ImportResult{
+		Source:      i.render(serviceName, blocks),
+		ServiceName: serviceName,
+		Types:       len(i.types),
+		Routes:      routes,
+		Warnings:    i.warnings,
+	}
+	for _, block := range blocks {
+		if block.group != "" {
+			result.Groups = append(result.Groups, block.group)
+		}
+	}
+	return result, nil
+}
+
+func (i *importer) warn(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if !i.warned[msg] {
+		i.warned[msg] = true
+		i.warnings = append(i.warnings, msg)
+	}
+}
+
+// reserve returns a type name that is not declared yet and marks it as used
+func (i *importer) reserve(name string) string {
+	if name == "" {
+		name = "Type"
+	}
+	unique := name
+	for n := 2; i.names[unique]; n++ {
+		unique = name + strconv.Itoa(n)
+	}
+	i.names[unique] = true
+	return unique
+}
+
+// component returns a schema from components by reference
+func (i *importer) component(ref string) (string, *Schema, bool) {
+	name := strings.TrimPrefix(ref, "#/components/schemas/")
+	if name == ref || i.doc.Components == nil {
+		return name, nil, false
+	}
+	schema, ok := i.doc.Components.Schemas.Get(name)
+	return name, schema, ok
+}
+
+// deref follows references to non-object components, returning the schema that carries constraints
+func (i *importer) deref(s *Schema) *Schema {
+	for depth := 0; s != nil && s.Ref != "" && depth < 10; depth++ {
+		_, target, ok := i.component(s.Ref)
+		if !ok {
+			return s
+		}
+		s = target
+	}
+	return s
+}
+
+// describe returns the description of a schema, or of the component it references
+func (i *importer) describe(s *Schema) string {
+	if s.Description != "" {
+		return s.Description
+	}
+	if target := i.deref(s); target != nil && !isObject(target) {
+		return target.Description
+	}
+	return ""
+}
+
+// objectType declares a type for an object schema
+func (i *importer) objectType(name string, s *Schema, where string) *apiType {
+	t := &apiType{name: name, doc: s.Description}
+	i.types = append(i.types, t)
+
+	fieldNames := make(map[string]bool)
+	i.addObject(t, s, where, fieldNames)
+	return t
+}
+
+// addObject adds the properties of s, and of its allOf parts, to t
+func (i *importer) addObject(t *apiType, s *Schema, where string, fieldNames map[string]bool) {
+	for _, part := range s.AllOf {
+		if part == nil {
+			continue
+		}
+		if part.Ref != "" {
+			if name, ok := i.schemaTypes[strings.TrimPrefix(part.Ref, "#/components/schemas/")]; ok {
+				t.embeds = append(t.embeds, name)
+				continue
+			}
+		}
+		if resolved := i.deref(part); resolved != nil {
+			i.addObject(t, resolved, where, fieldNames)
+		}
+	}
+	if len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		i.warn("%s: oneOf/anyOf cannot be represented and was dropped", where)
+	}
+
+	required := make(map[string]bool)
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	for _, key := range s.Properties.Keys() {
+		prop, _ := s.Properties.Get(key)
+		if prop == nil {
+			prop = &Schema{}
+		}
+		field := apiField{
+			name: uniqueField(goName(key), fieldNames),
+			typ:  i.goType(prop, where+"."+key, t.name+goName(key)),
+			doc:  i.describe(prop),
+		}
+		field.tag = i.tag("json", key, prop, required[key], where+"."+key)
+		t.fields = append(t.fields, field)
+	}
+}
+
+// goType returns the .api type of a schema, declaring types for inline objects
+func (i *importer) goType(s *Schema, where, hoist string) string {
+	if s == nil {
+		i.warn("%s: missing schema, using interface{}", where)
+		return "interface{}"
+	}
+	if s.Ref != "" {
+		name, target, ok := i.component(s.Ref)
+		if !ok {
+			i.warn("%s: unresolved reference %s, using interface{}", where, s.Ref)
+			return "interface{}"
+		}
+		if typ, ok := i.schemaTypes[name]; ok {
+			return typ
+		}
+		if i.resolving[name] {
+			i.warn("%s: recursive schema %s cannot be represented, using interface{}", where, name)
+			return "interface{}"
+		}
+		i.resolving[name] = true
+		defer delete(i.resolving, name)
+		return i.goType(target, where, goName(name))
+	}
+
+	if len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		i.warn("%s: oneOf/anyOf cannot be represented, using interface{}", where)
+		return "interface{}"
+	}
+	if len(s.AllOf) == 1 && s.Properties.Len() == 0 {
+		return i.goType(s.AllOf[0], where, hoist)
+	}
+	if s.Not != nil {
+		i.warn("%s: not cannot be represented and was dropped", where)
+	}
+
+	switch primaryType(s) {
+	case "string":
+		if s.Format == "binary" {
+			i.warn("%s: binary content cannot be represented, using string", where)
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + i.goType(s.Items, where+"[]", hoist+"Item")
+	}
+
+	if isObject(s) {
+		return i.objectType(i.reserve(hoist), s, where).name
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.Not == nil {
+		values := s.AdditionalProperties
+		if isAny(values) {
+			i.warn("%s: map values allow any type, using interface{}", where)
+			return "map[string]interface{}"
+		}
+		return "map[string]" + i.goType(values, where+"{}", hoist+"Value")
+	}
+	i.warn("%s: schema allows any value, using interface{}", where)
+	return "interface{}"
+}
+
+// tag builds a go-zero tag such as json:"name,optional,options=a|b"
+func (i *importer) tag(key, name string, s *Schema, required bool, where string) string {
+	opts := []string{name}
+	c := i.deref(s)
+	if c == nil {
+		c = &Schema{}
+	}
+
+	if c.Default != nil {
+		if value, ok := tagValue(c.Default); ok {
+			opts = append(opts, "default="+value)
+		} else {
+			i.warn("%s: default %v cannot be written in a tag", where, c.Default)
+		}
+	} else if !required {
+		opts = append(opts, "optional")
+	}
+
+	if len(c.Enum) > 0 {
+		var values []string
+		for _, v := range c.Enum {
+			value, ok := tagValue(v)
+			if !ok || strings.Contains(value, "|") {
+				i.warn("%s: enum value %v cannot be written in a tag", where, v)
+				values = nil
+				break
+			}
+			values = append(values, value)
+		}
+		if len(values) > 0 {
+			opts = append(opts, "options="+strings.Join(values, "|"))
+		}
+	}
+
+	if r := rangeOption(c); r != "" {
+		opts = append(opts, "range="+r)
+	}
+	return fmt.Sprintf(`%s:"%s"`, key, strings.Join(opts, ","))
+}
+
+// parameters resolves parameter references
+func (i *importer) parameters(params []*Parameter, where string) []*Parameter {
+	var resolved []*Parameter
+	for _, p := range params {
+		if p.Ref != "" {
+			name := strings.TrimPrefix(p.Ref, "#/components/parameters/")
+			var target *Parameter
+			if i.doc.Components != nil {
+				target, _ = i.doc.Components.Parameters.Get(name)
+			}
+			if target == nil {
+				i.warn("%s: unresolved parameter %s", where, p.Ref)
+				continue
+			}
+			p = target
+		}
+		resolved = append(resolved, p)
+	}
+	return resolved
+}
+
+// requestType returns the request type of an operation, declaring <Handler>Req when needed
+func (i *importer) requestType(handler string, params []*Parameter, body *RequestBody, where string) string {
+	if body != nil && body.Ref != "" {
+		name := strings.TrimPrefix(body.Ref, "#/components/requestBodies/")
+		var target *RequestBody
+		if i.doc.Components != nil {
+			target, _ = i.doc.Components.RequestBodies.Get(name)
+		}
+		if target == nil {
+			i.warn("%s: unresolved request body %s", where, body.Ref)
+		}
+		body = target
+	}
+
+	var bodySchema *Schema
+	bodyTag := "json"
+	if body != nil {
+		mediaType, media := pickContent(body.Content)
+		switch {
+		case media == nil:
+		case isJSON(mediaType):
+			bodySchema = media.Schema
+		case mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data":
+			bodySchema, bodyTag = media.Schema, "form"
+		default:
+			i.warn("%s: request body of type %s cannot be represented", where, mediaType)
+		}
+	}
+
+	// A JSON body that is a declared type with no parameters needs no wrapper
+	if len(params) == 0 && bodyTag == "json" && bodySchema != nil && bodySchema.Ref != "" {
+		if name, ok := i.schemaTypes[strings.TrimPrefix(bodySchema.Ref, "#/components/schemas/")]; ok {
+			return name
+		}
+	}
+
+	t := &apiType{name: handler + "Req"}
+	fieldNames := make(map[string]bool)
+	for _, p := range params {
+		key := map[string]string{"path": "path", "query": "form", "header": "header"}[p.In]
+		if key == "" {
+			i.warn("%s: %s parameter %s cannot be represented", where, p.In, p.Name)
+			continue
+		}
+		schema := p.Schema
+		if schema == nil {
+			schema = &Schema{Type: SchemaType{"string"}}
+		}
+		field := apiField{
+			name: uniqueField(goName(p.Name), fieldNames),
+			typ:  i.goType(schema, where+" "+p.Name, handler+goName(p.Name)),
+			doc:  p.Description,
+		}
+		field.tag = i.tag(key, p.Name, schema, p.Required || p.In == "path", where+" "+p.Name)
+		t.fields = append(t.fields, field)
+	}
+
+	if bodySchema != nil {
+		resolved := i.deref(bodySchema)
+		switch {
+		case bodySchema.Ref != "" && i.schemaTypes[strings.TrimPrefix(bodySchema.Ref, "#/components/schemas/")] != "" && bodyTag == "json":
+			t.embeds = append(t.embeds, i.schemaTypes[strings.TrimPrefix(bodySchema.Ref, "#/components/schemas/")])
+		case isObject(resolved):
+			i.addObject(t, resolved, where+" body", fieldNames)
+			if bodyTag == "form" {
+				for n := range t.fields {
+					t.fields[n].tag = strings.Replace(t.fields[n].tag, `json:"`, `form:"`, 1)
+				}
+			}
+		default:
+			i.warn("%s: request body that is not an object cannot be represented", where)
+		}
+	}
+
+	if len(t.fields) == 0 && len(t.embeds) == 0 {
+		return ""
+	}
+	t.name = i.reserve(t.name)
+	i.types = append(i.types, t)
+	return t.name
+}
+
+// responseType returns the type of the success response, declaring <Handler>Resp when needed
+func (i *importer) responseType(handler string, responses *Map[*Response], where string) string {
+	code := ""
+	for _, candidate := range []string{"200", "201", "202", "203"} {
+		if _, ok := responses.Get(candidate); ok {
+			code = candidate
+			break
+		}
+	}
+	if code == "" {
+		for _, candidate := range responses.Keys() {
+			if strings.HasPrefix(candidate, "2") {
+				code = candidate
+				break
+			}
+		}
+	}
+	if code == "" {
+		code = "default"
+	}
+	resp, ok := responses.Get(code)
+	if !ok {
+		return ""
+	}
+	if resp.Ref != "" {
+		name := strings.TrimPrefix(resp.Ref, "#/components/responses/")
+		var target *Response
+		if i.doc.Components != nil {
+			target, _ = i.doc.Components.Responses.Get(name)
+		}
+		if target == nil {
+			i.warn("%s: unresolved response %s", where, resp.Ref)
+			return ""
+		}
+		resp = target
+	}
+
+	mediaType, media := pickContent(resp.Content)
+	if media == nil {
+		return ""
+	}
+	if !isJSON(mediaType) {
+		i.warn("%s: response of type %s cannot be represented", where, mediaType)
+		return ""
+	}
+
+	typ := i.goType(media.Schema, where+" response", handler+"Resp")
+	base := strings.TrimLeft(typ, "[]")
+	if !i.names[base] {
+		i.warn("%s: response of type %s cannot be represented", where, typ)
+		return ""
+	}
+	return typ
+}
+
+// jwt maps a security requirement to a jwt config name
+func (i *importer) jwt(security []SecurityRequirement, where string) string {
+	if len(security) == 0 {
+		return ""
+	}
+	if len(security) > 1 {
+		i.warn("%s: only the first of %d alternative security requirements is used", where, len(security))
+	}
+	var names []string
+	for name := range security[0] {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+
+	name := names[0]
+	var scheme *SecurityScheme
+	if i.doc.Components != nil {
+		scheme, _ = i.doc.Components.SecuritySchemes.Get(name)
+	}
+	switch {
+	case scheme == nil:
+		i.warn("%s: undefined security scheme %s mapped to jwt", where, name)
+	case scheme.Type == "http" && !strings.EqualFold(scheme.Scheme, "bearer"):
+		i.warn("security scheme %s (http %s) cannot be represented", name, scheme.Scheme)
+		return ""
+	case scheme.Type == "apiKey":
+		i.warn("security scheme %s (apiKey) mapped to jwt", name)
+	}
+	return goName(name)
+}
+
+// routePath converts {name} parameters to :name
+func (i *importer) routePath(p, where string) string {
+	segments := strings.Split(p, "/")
+	for n, seg := range segments {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			segments[n] = ":" + seg[1:len(seg)-1]
+		} else if strings.ContainsAny(seg, "{}") {
+			i.warn("%s: path segment %s mixes text and parameters", where, seg)
+			segments[n] = strings.NewReplacer("{", ":", "}", "").Replace(seg)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// handlerName returns a unique handler from the operationId, or from the method and path
+func (i *importer) handlerName(operationID, method, p string) string {
+	name := goName(operationID)
+	if name == "" {
+		name = goName(method + " " + strings.NewReplacer("{", "", "}", "").Replace(p))
+	}
+	unique := name
+	for n := 2; i.handlers[unique]; n++ {
+		unique = name + strconv.Itoa(n)
+	}
+	i.handlers[unique] = true
+	return unique
+}
+
+// render prints the spec with tab indentation and aligned fields
+func (i *importer) render(serviceName string, blocks []*apiBlock) []byte {
+	var b strings.Builder
+	b.WriteString("syntax = \"v1\"\n")
+
+	info := [][2]string{{"title", i.doc.Info.Title}, {"desc", i.doc.Info.Description}, {"version", i.doc.Info.Version}}
+	var props [][2]string
+	for _, p := range info {
+		if p[1] != "" {
+			props = append(props, [2]string{p[0], quote(p[1])})
+		}
+	}
+	if len(props) > 0 {
+		b.WriteString("\ninfo (\n")
+		writeProperties(&b, props)
+		b.WriteString(")\n")
+	}
+
+	for _, t := range i.types {
+		b.WriteString("\n")
+		writeComment(&b, "", t.doc)
+		fmt.Fprintf(&b, "type %s {\n", t.name)
+		for _, embed := range t.embeds {
+			fmt.Fprintf(&b, "\t%s\n", embed)
+		}
+		nameWidth, typeWidth := 0, 0
+		for _, f := range t.fields {
+			nameWidth = max(nameWidth, len(f.name))
+			typeWidth = max(typeWidth, len(f.typ))
+		}
+		for _, f := range t.fields {
+			writeComment(&b, "\t", f.doc)
+			fmt.Fprintf(&b, "\t%-*s %-*s `%s`\n", nameWidth, f.name, typeWidth, f.typ, f.tag)
+		}
+		b.WriteString("}\n")
+	}
+
+	for _, block := range blocks {
+		b.WriteString("\n")
+		writeComment(&b, "", block.doc)
+		var props [][2]string
+		if block.group != "" {
+			props = append(props, [2]string{"group", block.group})
+		}
+		if block.jwt != "" {
+			props = append(props, [2]string{"jwt", block.jwt})
+		}
+		if block.prefix != "" {
+			props = append(props, [2]string{"prefix", block.prefix})
+		}
+		if len(props) > 0 {
+			b.WriteString("@server (\n")
+			writeProperties(&b, props)
+			b.WriteString(")\n")
+		}
+		fmt.Fprintf(&b, "service %s-api {\n", serviceName)
+		for n, route := range block.routes {
+			if n > 0 {
+				b.WriteString("\n")
+			}
+			writeComment(&b, "\t", route.description)
+			if route.summary != "" {
+				fmt.Fprintf(&b, "\t@doc %s\n", quote(route.summary))
+			}
+			fmt.Fprintf(&b, "\t@handler %s\n", route.handler)
+			fmt.Fprintf(&b, "\t%s %s", route.method, route.path)
+			if route.request != "" {
+				fmt.Fprintf(&b, " (%s)", route.request)
+			}
+			if route.response != "" {
+				fmt.Fprintf(&b, " returns (%s)", route.response)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("}\n")
+	}
+	return []byte(b.String())
+}
+
+func writeProperties(b *strings.Builder, props [][2]string) {
+	width := 0
+	for _, p := range props {
+		width = max(width, len(p[0])+1)
+	}
+	for _, p := range props {
+		fmt.Fprintf(b, "\t%-*s %s\n", width, p[0]+":", p[1])
+	}
+}
+
+func writeComment(b *strings.Builder, indent, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			fmt.Fprintf(b, "%s// %s\n", indent, line)
+		}
+	}
+}
+
+// quote returns a double-quoted .api string; the .api syntax has no escapes, so quotes are replaced
+func quote(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return `"` + strings.ReplaceAll(s, `"`, "'") + `"`
+}
+
+// mergeParameters lets operation parameters override path item parameters with the same name and location
+func mergeParameters(shared, own []*Parameter) []*Parameter {
+	merged := append([]*Parameter{}, own...)
+	for _, p := range shared {
+		overridden := false
+		for _, o := range own {
+			if o.Name == p.Name && o.In == p.In {
+				overridden = true
+			}
+		}
+		if !overridden {
+			merged = append([]*Parameter{p}, merged...)
+		}
+	}
+	return merged
+}
+
+// pickContent prefers a JSON media type, then form media types, then the first one
+func pickContent(content *Map[*MediaType]) (string, *MediaType) {
+	keys := content.Keys()
+	for _, prefer := range []func(string) bool{
+		isJSON,
+		func(t string) bool { return t == "application/x-www-form-urlencoded" || t == "multipart/form-data" },
+		func(string) bool { return true },
+	} {
+		for _, key := range keys {
+			if prefer(key) {
+				media, _ := content.Get(key)
+				if media == nil {
+					media = &MediaType{}
+				}
+				return key, media
+			}
+		}
+	}
+	return "", nil
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || mediaType == "*/*"
+}
+
+// primaryType returns the schema type, ignoring null in 3.1 type lists
+func primaryType(s *Schema) string {
+	for _, t := range s.Type {
+		if t != "null" {
+			return t
+		}
+	}
+	return ""
+}
+
+// isObject reports whether a schema has properties to declare as a type
+func isObject(s *Schema) bool {
+	if s == nil || s.Ref != "" {
+		return false
+	}
+	typ := primaryType(s)
+	if typ != "" && typ != "object" {
+		return false
+	}
+	return s.Properties.Len() > 0 || len(s.AllOf) > 1 || (len(s.AllOf) == 1 && typ == "object")
+}
+
+// isAny reports whether a schema accepts any value
+func isAny(s *Schema) bool {
+	return s.Ref == "" && len(s.Type) == 0 && s.Properties.Len() == 0 && s.Items == nil && s.AdditionalProperties == nil &&
+		len(s.AllOf) == 0 && len(s.OneOf) == 0 && len(s.AnyOf) == 0
+}
+
+// rangeOption returns a go-zero range such as [1:100] or (0:] from 3.0 or 3.1 bounds
+func rangeOption(s *Schema) string {
+	low, high := "", ""
+	open, closing := "[", "]"
+	if s.Minimum != nil {
+		low = formatNumber(*s.Minimum)
+	}
+	if s.Maximum != nil {
+		high = formatNumber(*s.Maximum)
+	}
+	switch v := s.ExclusiveMinimum.(type) {
+	case bool:
+		if v {
+			open = "("
+		}
+	case int, float64:
+		low, open = fmt.Sprint(v), "("
+	}
+	switch v := s.ExclusiveMaximum.(type) {
+	case bool:
+		if v {
+			closing = ")"
+		}
+	case int, float64:
+		high, closing = fmt.Sprint(v), ")"
+	}
+	if low == "" && high == "" {
+		return ""
+	}
+	return open + low + ":" + high + closing
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// tagValue formats a default or enum value for a tag
+func tagValue(v any) (string, bool) {
+	var s string
+	switch value := v.(type) {
+	case string:
+		s = value
+	case float64:
+		s = formatNumber(value)
+	case int, int64, bool:
+		s = fmt.Sprint(value)
+	default:
+		return "", false
+	}
+	if s == "" || strings.ContainsAny(s, ",\"` ") {
+		return "", false
+	}
+	return s, true
+}
+
+// serverPrefix returns the path of the first server URL as a route prefix
+func serverPrefix(servers []*Server) string {
+	if len(servers) == 0 || strings.Contains(servers[0].URL, "{") {
+		return ""
+	}
+	u, err := url.Parse(servers[0].URL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(u.Path, "/")
+}
+
+// goName converts a name such as user_id, x-trace-id or user.Profile to an exported identifier
+func goName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "N" + name
+	}
+	return name
+}
+
+func uniqueField(name string, used map[string]bool) string {
+	if name == "" {
+		name = "Field"
+	}
+	unique := name
+	for n := 2; used[unique]; n++ {
+		unique = name + strconv.Itoa(n)
+	}
+	used[unique] = true
+	return unique
+}
+
+// groupName converts a tag to a go-zero group, which becomes a package directory
+func groupName(tag string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(tag) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	name := b.String()
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "g" + name
+	}
+	return name
+}
+
+// serviceSlug converts a title such as "Pet Store" to pet-store
+func serviceSlug(title string) string {
+	var parts []string
+	for _, field := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9')
+	}) {
+		parts = append(parts, field)
+	}
+	return strings.Join(parts, "-")
+}
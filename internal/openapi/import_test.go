@@ -0,0 +1,144 @@
+package openapi_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+	"github.com/zeromicro/mcp-zero/internal/openapi"
+)
+
+func TestImportGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "import", "*.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file, ".golden") {
+			continue
+		}
+		name := filepath.Base(file)
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := openapi.Parse(data)
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+			result, err := openapi.Import(doc, openapi.ImportOptions{})
+			if err != nil {
+				t.Fatalf("Import() failed: %v", err)
+			}
+
+			// The generated spec must be accepted by the .api parser
+			if _, err := apispec.Parse(name+".api", result.Source); err != nil {
+				t.Fatalf("Generated spec does not parse: %v\n%s", err, result.Source)
+			}
+
+			got := string(result.Source)
+			if len(result.Warnings) > 0 {
+				got += "\n# warnings\n" + strings.Join(result.Warnings, "\n") + "\n"
+			}
+
+			golden := strings.TrimSuffix(file, filepath.Ext(file)) + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create): %v", err)
+			}
+			if got != string(want) {
+				t.Errorf("Import of %s does not match %s (run with -update to refresh):\n%s", name, golden, got)
+			}
+		})
+	}
+}
+
+// TestExportImportRoundTrip exports a spec and imports it again, comparing routes and types
+func TestExportImportRoundTrip(t *testing.T) {
+	spec, err := apispec.Load(filepath.Join("testdata", "user.api"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := openapi.Export(spec, openapi.ExportOptions{})
+	if err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+	data, err := openapi.Marshal(doc, "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := openapi.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+	result, err := openapi.Import(parsed, openapi.ImportOptions{ServiceName: "user"})
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+	imported, err := apispec.Parse("user.api", result.Source)
+	if err != nil {
+		t.Fatalf("Imported spec does not parse: %v\n%s", err, result.Source)
+	}
+
+	type route struct{ method, path, handler, jwt, group string }
+	collect := func(services []*apispec.ServiceDecl) map[route]bool {
+		routes := make(map[route]bool)
+		for _, svc := range services {
+			for _, r := range svc.Routes {
+				path := r.Path
+				if prefix := svc.Prefix(); prefix != "" {
+					path = strings.TrimSuffix(prefix, "/") + path
+				}
+				routes[route{r.Method, path, r.Handler, svc.JWT(), svc.Group()}] = true
+			}
+		}
+		return routes
+	}
+	want := collect(spec.Services())
+	got := collect(imported.Services)
+	// Groups come back from tags; the prefix-only block is tagged with its prefix
+	want[route{"post", "/auth/login", "Login", "", ""}] = false
+	want[route{"post", "/auth/login", "Login", "", "auth"}] = true
+	for r, ok := range want {
+		if ok && !got[r] {
+			t.Errorf("Route %+v is missing after the round trip; got %v", r, got)
+		}
+	}
+
+	for _, name := range []string{"User", "UpdateUserReq", "UserListResp"} {
+		var found *apispec.TypeDecl
+		for _, typ := range imported.Types {
+			if typ.Name == name {
+				found = typ
+			}
+		}
+		if found == nil {
+			t.Errorf("Type %s is missing after the round trip", name)
+		}
+	}
+	for _, typ := range imported.Types {
+		if typ.Name == "UpdateUserReq" {
+			if tag, _ := typ.Field("Role").TagValue("json"); tag != "role,default=member,options=admin|member" {
+				t.Errorf("Unexpected Role tag after the round trip: %q", tag)
+			}
+			if tag, _ := typ.Field("Score").TagValue("json"); tag != "score,optional,range=(0:10]" {
+				t.Errorf("Unexpected Score tag after the round trip: %q", tag)
+			}
+		}
+	}
+}
+
+func TestParseRejectsUnknownDocuments(t *testing.T) {
+	if _, err := openapi.Parse([]byte("title: not openapi\n")); err == nil {
+		t.Error("Expected an error for a document without a version")
+	}
+}
@@ -0,0 +1,410 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is an OpenAPI 3.0 or 3.1 document
+type Document struct {
+	OpenAPI    string                `json:"openapi" yaml:"openapi"`
+	Info       Info                  `json:"info" yaml:"info"`
+	Servers    []*Server             `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Tags       []*Tag                `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Paths      *Map[*PathItem]       `json:"paths" yaml:"paths"`
+	Components *Components           `json:"components,omitempty" yaml:"components,omitempty"`
+	Security   []SecurityRequirement `json:"security,omitempty" yaml:"security,omitempty"`
+}
+
+// Info is the document metadata
+type Info struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+// Server is a base URL of the API
+type Server struct {
+	URL         string `json:"url" yaml:"url"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// Tag groups operations
+type Tag struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// PathItem holds the operations of one path
+type PathItem struct {
+	Ref         string       `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Summary     string       `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string       `json:"description,omitempty" yaml:"description,omitempty"`
+	Get         *Operation   `json:"get,omitempty" yaml:"get,omitempty"`
+	Put         *Operation   `json:"put,omitempty" yaml:"put,omitempty"`
+	Post        *Operation   `json:"post,omitempty" yaml:"post,omitempty"`
+	Delete      *Operation   `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Options     *Operation   `json:"options,omitempty" yaml:"options,omitempty"`
+	Head        *Operation   `json:"head,omitempty" yaml:"head,omitempty"`
+	Patch       *Operation   `json:"patch,omitempty" yaml:"patch,omitempty"`
+	Parameters  []*Parameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+// Methods lists the HTTP methods of a path item in the order OpenAPI declares them
+var Methods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// Operation returns the operation for a lower-case HTTP method, or nil
+func (p *PathItem) Operation(method string) *Operation {
+	switch method {
+	case "get":
+		return p.Get
+	case "put":
+		return p.Put
+	case "post":
+		return p.Post
+	case "delete":
+		return p.Delete
+	case "options":
+		return p.Options
+	case "head":
+		return p.Head
+	case "patch":
+		return p.Patch
+	}
+	return nil
+}
+
+// SetOperation sets the operation for a lower-case HTTP method
+func (p *PathItem) SetOperation(method string, op *Operation) error {
+	switch method {
+	case "get":
+		p.Get = op
+	case "put":
+		p.Put = op
+	case "post":
+		p.Post = op
+	case "delete":
+		p.Delete = op
+	case "options":
+		p.Options = op
+	case "head":
+		p.Head = op
+	case "patch":
+		p.Patch = op
+	default:
+		return fmt.Errorf("unsupported HTTP method %q", method)
+	}
+	return nil
+}
+
+// Operation is a single API operation on a path
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+	OperationID string                `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   *Map[*Response]       `json:"responses" yaml:"responses"`
+	Security    []SecurityRequirement `json:"security,omitempty" yaml:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+}
+
+// Parameter is a path, query, header or cookie parameter
+type Parameter struct {
+	Ref         string  `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Name        string  `json:"name,omitempty" yaml:"name,omitempty"`
+	In          string  `json:"in,omitempty" yaml:"in,omitempty"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool    `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// RequestBody describes the body of a request
+type RequestBody struct {
+	Ref         string           `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Description string           `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool             `json:"required,omitempty" yaml:"required,omitempty"`
+	Content     *Map[*MediaType] `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// MediaType is the schema of one content type
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// Response describes one response of an operation
+type Response struct {
+	Ref         string           `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Description string           `json:"description" yaml:"description"`
+	Content     *Map[*MediaType] `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// Components holds reusable objects
+type Components struct {
+	Schemas         *Map[*Schema]         `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	Parameters      *Map[*Parameter]      `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBodies   *Map[*RequestBody]    `json:"requestBodies,omitempty" yaml:"requestBodies,omitempty"`
+	Responses       *Map[*Response]       `json:"responses,omitempty" yaml:"responses,omitempty"`
+	SecuritySchemes *Map[*SecurityScheme] `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how requests are authenticated
+type SecurityScheme struct {
+	Type         string `json:"type" yaml:"type"`
+	Description  string `json:"description,omitempty" yaml:"description,omitempty"`
+	Name         string `json:"name,omitempty" yaml:"name,omitempty"`
+	In           string `json:"in,omitempty" yaml:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty" yaml:"bearerFormat,omitempty"`
+}
+
+// SecurityRequirement maps security scheme names to required scopes
+type SecurityRequirement map[string][]string
+
+// Schema is a JSON schema as used by OpenAPI
+// ExclusiveMinimum and ExclusiveMaximum are booleans in 3.0 and numbers in 3.1
+type Schema struct {
+	Ref                  string        `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 SchemaType    `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string        `json:"format,omitempty" yaml:"format,omitempty"`
+	Title                string        `json:"title,omitempty" yaml:"title,omitempty"`
+	Description          string        `json:"description,omitempty" yaml:"description,omitempty"`
+	Nullable             bool          `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Properties           *Map[*Schema] `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string      `json:"required,omitempty" yaml:"required,omitempty"`
+	Items                *Schema       `json:"items,omitempty" yaml:"items,omitempty"`
+	AdditionalProperties *Schema       `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	AllOf                []*Schema     `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+	OneOf                []*Schema     `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	AnyOf                []*Schema     `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+	Not                  *Schema       `json:"not,omitempty" yaml:"not,omitempty"`
+	Enum                 []any         `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Default              any           `json:"default,omitempty" yaml:"default,omitempty"`
+	Example              any           `json:"example,omitempty" yaml:"example,omitempty"`
+	Minimum              *float64      `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum              *float64      `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	ExclusiveMinimum     any           `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     any           `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
+	MinLength            *int          `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength            *int          `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Pattern              string        `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	MinItems             *int          `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems             *int          `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	ReadOnly             bool          `json:"readOnly,omitempty" yaml:"readOnly,omitempty"`
+	WriteOnly            bool          `json:"writeOnly,omitempty" yaml:"writeOnly,omitempty"`
+	Deprecated           bool          `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+}
+
+// UnmarshalYAML accepts boolean schemas such as additionalProperties: true
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!bool" {
+		*s = Schema{}
+		if node.Value == "false" {
+			s.Not = &Schema{}
+		}
+		return nil
+	}
+	type plain Schema
+	return node.Decode((*plain)(s))
+}
+
+// SchemaType is the schema type; 3.1 documents may list several, such as [string, null]
+type SchemaType []string
+
+// Is reports whether the type list contains typ
+func (t SchemaType) Is(typ string) bool {
+	for _, candidate := range t {
+		if candidate == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON writes a single type as a plain string
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// MarshalYAML writes a single type as a plain string
+func (t SchemaType) MarshalYAML() (interface{}, error) {
+	if len(t) == 1 {
+		return t[0], nil
+	}
+	return []string(t), nil
+}
+
+// UnmarshalYAML accepts a string or a list of strings
+func (t *SchemaType) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = SchemaType{node.Value}
+		return nil
+	}
+	var types []string
+	if err := node.Decode(&types); err != nil {
+		return err
+	}
+	*t = types
+	return nil
+}
+
+// Map is a string-keyed map that keeps insertion order when encoded
+type Map[V any] struct {
+	keys   []string
+	values map[string]V
+}
+
+// NewMap returns an empty Map
+func NewMap[V any]() *Map[V] {
+	return &Map[V]{values: make(map[string]V)}
+}
+
+// Set adds or replaces a value; new keys go to the end
+func (m *Map[V]) Set(key string, value V) {
+	if m.values == nil {
+		m.values = make(map[string]V)
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get returns the value of a key
+func (m *Map[V]) Get(key string) (V, bool) {
+	var zero V
+	if m == nil {
+		return zero, false
+	}
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Keys returns the keys in insertion order
+func (m *Map[V]) Keys() []string {
+	if m == nil {
+		return nil
+	}
+	return m.keys
+}
+
+// Len returns the number of entries
+func (m *Map[V]) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.keys)
+}
+
+// MarshalJSON writes the entries in insertion order
+func (m *Map[V]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.Keys() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := marshalJSON(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := marshalJSON(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML writes the entries in insertion order
+func (m *Map[V]) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, key := range m.Keys() {
+		var value yaml.Node
+		if err := value.Encode(m.values[key]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &value)
+	}
+	return node, nil
+}
+
+// UnmarshalYAML reads a mapping, keeping its order
+func (m *Map[V]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping", node.Line)
+	}
+	*m = Map[V]{values: make(map[string]V)}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var value V
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return err
+		}
+		m.Set(node.Content[i].Value, value)
+	}
+	return nil
+}
+
+// marshalJSON encodes v without escaping HTML characters
+func marshalJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// Marshal encodes a document as "json" (indented) or "yaml"
+func Marshal(doc *Document, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "", "json":
+		raw, err := marshalJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, raw, "", "  "); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	case "yaml", "yml":
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported format %q, use json or yaml", format)
+}
+
+// Unmarshal decodes a JSON or YAML OpenAPI 3 document
+func Unmarshal(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAPI document: %w", err)
+	}
+	if doc.Paths == nil {
+		doc.Paths = NewMap[*PathItem]()
+	}
+	return &doc, nil
+}
@@ -0,0 +1,323 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// swagger2 is the subset of a Swagger 2.0 document that is converted to OpenAPI 3
+type swagger2 struct {
+	Swagger             string                        `yaml:"swagger"`
+	Info                Info                          `yaml:"info"`
+	Host                string                        `yaml:"host"`
+	BasePath            string                        `yaml:"basePath"`
+	Schemes             []string                      `yaml:"schemes"`
+	Consumes            []string                      `yaml:"consumes"`
+	Produces            []string                      `yaml:"produces"`
+	Tags                []*Tag                        `yaml:"tags"`
+	Paths               *Map[*swagger2PathItem]       `yaml:"paths"`
+	Definitions         *Map[*Schema]                 `yaml:"definitions"`
+	Parameters          *Map[*swagger2Parameter]      `yaml:"parameters"`
+	Responses           *Map[*swagger2Response]       `yaml:"responses"`
+	SecurityDefinitions *Map[*swagger2SecurityScheme] `yaml:"securityDefinitions"`
+	Security            []SecurityRequirement         `yaml:"security"`
+}
+
+type swagger2PathItem struct {
+	Get        *swagger2Operation   `yaml:"get"`
+	Put        *swagger2Operation   `yaml:"put"`
+	Post       *swagger2Operation   `yaml:"post"`
+	Delete     *swagger2Operation   `yaml:"delete"`
+	Options    *swagger2Operation   `yaml:"options"`
+	Head       *swagger2Operation   `yaml:"head"`
+	Patch      *swagger2Operation   `yaml:"patch"`
+	Parameters []*swagger2Parameter `yaml:"parameters"`
+}
+
+type swagger2Operation struct {
+	Tags        []string                `yaml:"tags"`
+	Summary     string                  `yaml:"summary"`
+	Description string                  `yaml:"description"`
+	OperationID string                  `yaml:"operationId"`
+	Consumes    []string                `yaml:"consumes"`
+	Produces    []string                `yaml:"produces"`
+	Parameters  []*swagger2Parameter    `yaml:"parameters"`
+	Responses   *Map[*swagger2Response] `yaml:"responses"`
+	Security    []SecurityRequirement   `yaml:"security"`
+	Deprecated  bool                    `yaml:"deprecated"`
+}
+
+// swagger2Parameter keeps the schema keywords that Swagger 2 puts on non-body parameters
+type swagger2Parameter struct {
+	Ref              string   `yaml:"$ref"`
+	Name             string   `yaml:"name"`
+	In               string   `yaml:"in"`
+	Description      string   `yaml:"description"`
+	Required         bool     `yaml:"required"`
+	Schema           *Schema  `yaml:"schema"`
+	Type             string   `yaml:"type"`
+	Format           string   `yaml:"format"`
+	Items            *Schema  `yaml:"items"`
+	Enum             []any    `yaml:"enum"`
+	Default          any      `yaml:"default"`
+	Minimum          *float64 `yaml:"minimum"`
+	Maximum          *float64 `yaml:"maximum"`
+	ExclusiveMinimum bool     `yaml:"exclusiveMinimum"`
+	ExclusiveMaximum bool     `yaml:"exclusiveMaximum"`
+}
+
+type swagger2Response struct {
+	Ref         string  `yaml:"$ref"`
+	Description string  `yaml:"description"`
+	Schema      *Schema `yaml:"schema"`
+}
+
+type swagger2SecurityScheme struct {
+	Type        string `yaml:"type"`
+	Description string `yaml:"description"`
+	Name        string `yaml:"name"`
+	In          string `yaml:"in"`
+}
+
+// Parse decodes an OpenAPI 3 or Swagger 2 document in JSON or YAML
+// Swagger 2 documents are converted to OpenAPI 3
+func Parse(data []byte) (*Document, error) {
+	var header struct {
+		Swagger string `yaml:"swagger"`
+		OpenAPI string `yaml:"openapi"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAPI document: %w", err)
+	}
+
+	switch {
+	case strings.HasPrefix(header.OpenAPI, "3."):
+		return Unmarshal(data)
+	case strings.HasPrefix(header.Swagger, "2."):
+		var doc swagger2
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to decode Swagger document: %w", err)
+		}
+		return doc.convert(), nil
+	}
+	return nil, fmt.Errorf("not an OpenAPI 3 or Swagger 2 document: missing openapi or swagger version")
+}
+
+// convert returns the equivalent OpenAPI 3.0 document
+func (s *swagger2) convert() *Document {
+	doc := &Document{
+		OpenAPI:  "3.0.3",
+		Info:     s.Info,
+		Tags:     s.Tags,
+		Paths:    NewMap[*PathItem](),
+		Security: s.Security,
+	}
+
+	if s.Host != "" || s.BasePath != "" {
+		url := s.BasePath
+		if s.Host != "" {
+			scheme := "https"
+			if len(s.Schemes) > 0 {
+				scheme = s.Schemes[0]
+			}
+			url = scheme + "://" + s.Host + s.BasePath
+		}
+		doc.Servers = []*Server{{URL: url}}
+	}
+
+	components := &Components{}
+	if s.Definitions.Len() > 0 {
+		components.Schemas = NewMap[*Schema]()
+		for _, name := range s.Definitions.Keys() {
+			schema, _ := s.Definitions.Get(name)
+			components.Schemas.Set(name, convertSchema(schema))
+		}
+	}
+	if s.Parameters.Len() > 0 {
+		components.Parameters = NewMap[*Parameter]()
+		for _, name := range s.Parameters.Keys() {
+			param, _ := s.Parameters.Get(name)
+			if param.In != "body" && param.In != "formData" {
+				components.Parameters.Set(name, param.convert())
+			}
+		}
+	}
+	if s.Responses.Len() > 0 {
+		components.Responses = NewMap[*Response]()
+		for _, name := range s.Responses.Keys() {
+			resp, _ := s.Responses.Get(name)
+			components.Responses.Set(name, resp.convert(s.Produces))
+		}
+	}
+	if s.SecurityDefinitions.Len() > 0 {
+		components.SecuritySchemes = NewMap[*SecurityScheme]()
+		for _, name := range s.SecurityDefinitions.Keys() {
+			scheme, _ := s.SecurityDefinitions.Get(name)
+			converted := &SecurityScheme{Type: scheme.Type, Description: scheme.Description, Name: scheme.Name, In: scheme.In}
+			if scheme.Type == "basic" {
+				converted.Type, converted.Scheme = "http", "basic"
+			}
+			components.SecuritySchemes.Set(name, converted)
+		}
+	}
+	if components.Schemas != nil || components.Parameters != nil || components.Responses != nil || components.SecuritySchemes != nil {
+		doc.Components = components
+	}
+
+	for _, p := range s.Paths.Keys() {
+		item, _ := s.Paths.Get(p)
+		converted := &PathItem{}
+		ops := map[string]*swagger2Operation{
+			"get": item.Get, "put": item.Put, "post": item.Post, "delete": item.Delete,
+			"options": item.Options, "head": item.Head, "patch": item.Patch,
+		}
+		for _, method := range Methods {
+			if op := ops[method]; op != nil {
+				converted.SetOperation(method, s.convertOperation(op, item.Parameters))
+			}
+		}
+		doc.Paths.Set(p, converted)
+	}
+
+	return doc
+}
+
+func (s *swagger2) convertOperation(op *swagger2Operation, shared []*swagger2Parameter) *Operation {
+	converted := &Operation{
+		Tags:        op.Tags,
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: op.OperationID,
+		Responses:   NewMap[*Response](),
+		Security:    op.Security,
+		Deprecated:  op.Deprecated,
+	}
+
+	consumes := op.Consumes
+	if len(consumes) == 0 {
+		consumes = s.Consumes
+	}
+	produces := op.Produces
+	if len(produces) == 0 {
+		produces = s.Produces
+	}
+
+	var form *Schema
+	for _, param := range append(append([]*swagger2Parameter{}, shared...), op.Parameters...) {
+		if param.Ref != "" {
+			if resolved, ok := s.Parameters.Get(strings.TrimPrefix(param.Ref, "#/parameters/")); ok {
+				if resolved.In != "body" && resolved.In != "formData" {
+					converted.Parameters = append(converted.Parameters, &Parameter{Ref: "#/components/parameters/" + strings.TrimPrefix(param.Ref, "#/parameters/")})
+					continue
+				}
+				param = resolved
+			}
+		}
+
+		switch param.In {
+		case "body":
+			mediaType := "application/json"
+			if len(consumes) > 0 && !strings.Contains(consumes[0], "form") {
+				mediaType = consumes[0]
+			}
+			converted.RequestBody = &RequestBody{Description: param.Description, Required: param.Required, Content: NewMap[*MediaType]()}
+			converted.RequestBody.Content.Set(mediaType, &MediaType{Schema: convertSchema(param.Schema)})
+		case "formData":
+			if form == nil {
+				form = &Schema{Type: SchemaType{"object"}, Properties: NewMap[*Schema]()}
+			}
+			prop := param.convert().Schema
+			prop.Description = param.Description
+			form.Properties.Set(param.Name, prop)
+			if param.Required {
+				form.Required = append(form.Required, param.Name)
+			}
+		default:
+			converted.Parameters = append(converted.Parameters, param.convert())
+		}
+	}
+	if form != nil && converted.RequestBody == nil {
+		mediaType := "application/x-www-form-urlencoded"
+		for _, c := range consumes {
+			if c == "multipart/form-data" {
+				mediaType = c
+			}
+		}
+		converted.RequestBody = &RequestBody{Required: len(form.Required) > 0, Content: NewMap[*MediaType]()}
+		converted.RequestBody.Content.Set(mediaType, &MediaType{Schema: form})
+	}
+
+	for _, code := range op.Responses.Keys() {
+		resp, _ := op.Responses.Get(code)
+		converted.Responses.Set(code, resp.convert(produces))
+	}
+	return converted
+}
+
+// convert returns the OpenAPI 3 parameter, moving schema keywords into the schema
+func (p *swagger2Parameter) convert() *Parameter {
+	param := &Parameter{Name: p.Name, In: p.In, Description: p.Description, Required: p.Required}
+	schema := &Schema{
+		Format:  p.Format,
+		Items:   convertSchema(p.Items),
+		Enum:    p.Enum,
+		Default: p.Default,
+		Minimum: p.Minimum,
+		Maximum: p.Maximum,
+	}
+	if p.Type != "" {
+		schema.Type = SchemaType{p.Type}
+	}
+	if p.Type == "file" {
+		schema.Type, schema.Format = SchemaType{"string"}, "binary"
+	}
+	if p.ExclusiveMinimum {
+		schema.ExclusiveMinimum = true
+	}
+	if p.ExclusiveMaximum {
+		schema.ExclusiveMaximum = true
+	}
+	param.Schema = schema
+	return param
+}
+
+func (r *swagger2Response) convert(produces []string) *Response {
+	if r.Ref != "" {
+		return &Response{Ref: "#/components/responses/" + strings.TrimPrefix(r.Ref, "#/responses/")}
+	}
+	resp := &Response{Description: r.Description}
+	if r.Schema != nil {
+		mediaType := "application/json"
+		if len(produces) > 0 {
+			mediaType = produces[0]
+		}
+		resp.Content = NewMap[*MediaType]()
+		resp.Content.Set(mediaType, &MediaType{Schema: convertSchema(r.Schema)})
+	}
+	return resp
+}
+
+// convertSchema rewrites #/definitions references to #/components/schemas
+func convertSchema(s *Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+	if strings.HasPrefix(s.Ref, "#/definitions/") {
+		s.Ref = "#/components/schemas/" + strings.TrimPrefix(s.Ref, "#/definitions/")
+	}
+	for _, name := range s.Properties.Keys() {
+		prop, _ := s.Properties.Get(name)
+		convertSchema(prop)
+	}
+	convertSchema(s.Items)
+	convertSchema(s.AdditionalProperties)
+	convertSchema(s.Not)
+	for _, list := range [][]*Schema{s.AllOf, s.OneOf, s.AnyOf} {
+		for _, sub := range list {
+			convertSchema(sub)
+		}
+	}
+	return s
+}
@@ -0,0 +1,144 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Step status values recorded in Timing
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// Step is a named stage of a pipeline
+type Step struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Progress describes the pipeline before a step runs, or after the last one finishes
+type Progress struct {
+	Step      string        // step about to run, empty once the pipeline has finished
+	Index     int           // 1-based index of Step, Total once finished
+	Completed int           // steps finished so far
+	Total     int           // number of steps
+	Elapsed   time.Duration // time since the pipeline started
+}
+
+// Reporter is notified before each step and once more when every step has succeeded
+type Reporter func(ctx context.Context, p Progress)
+
+// Timing records how long a step ran
+type Timing struct {
+	Step       string        `json:"step"`
+	Status     string        `json:"status"`
+	DurationMS int64         `json:"duration_ms"`
+	Duration   time.Duration `json:"-"`
+}
+
+// Result is the per-step timing breakdown of a run
+type Result struct {
+	Steps     []Timing      `json:"steps"`
+	Elapsed   time.Duration `json:"-"`
+	ElapsedMS int64         `json:"elapsed_ms"`
+}
+
+// String formats the breakdown as an indented list for tool messages
+func (r *Result) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Steps (%s):\n", round(r.Elapsed))
+	for i, t := range r.Steps {
+		fmt.Fprintf(&b, "  %d. %s: %s", i+1, t.Step, round(t.Duration))
+		if t.Status != StatusOK {
+			fmt.Fprintf(&b, " (%s)", t.Status)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// StepError is returned when a step fails; Error is the step's own message
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e *StepError) Error() string { return e.Err.Error() }
+
+func (e *StepError) Unwrap() error { return e.Err }
+
+// Pipeline runs steps in order, stopping at the first failure
+type Pipeline struct {
+	steps    []Step
+	reporter Reporter
+}
+
+// New creates an empty pipeline; reporter may be nil
+func New(reporter Reporter) *Pipeline {
+	return &Pipeline{reporter: reporter}
+}
+
+// Add appends a step
+func (p *Pipeline) Add(name string, run func(ctx context.Context) error) *Pipeline {
+	p.steps = append(p.steps, Step{Name: name, Run: run})
+	return p
+}
+
+// Len returns the number of steps
+func (p *Pipeline) Len() int {
+	return len(p.steps)
+}
+
+// Run executes the steps in order and returns the timings of the steps that ran
+// A canceled ctx stops the pipeline before the next step starts
+func (p *Pipeline) Run(ctx context.Context) (*Result, error) {
+	start := time.Now()
+	result := &Result{}
+	finish := func() {
+		result.Elapsed = time.Since(start)
+		result.ElapsedMS = result.Elapsed.Milliseconds()
+	}
+
+	total := len(p.steps)
+	for i, step := range p.steps {
+		if err := ctx.Err(); err != nil {
+			finish()
+			return result, &StepError{Step: step.Name, Err: fmt.Errorf("%s canceled: %w", step.Name, err)}
+		}
+		p.report(ctx, Progress{Step: step.Name, Index: i + 1, Completed: i, Total: total, Elapsed: time.Since(start)})
+
+		stepStart := time.Now()
+		err := step.Run(ctx)
+		timing := Timing{Step: step.Name, Status: StatusOK, Duration: time.Since(stepStart)}
+		timing.DurationMS = timing.Duration.Milliseconds()
+		if err != nil {
+			timing.Status = StatusFailed
+		}
+		result.Steps = append(result.Steps, timing)
+		if err != nil {
+			finish()
+			return result, &StepError{Step: step.Name, Err: err}
+		}
+	}
+
+	finish()
+	p.report(ctx, Progress{Index: total, Completed: total, Total: total, Elapsed: result.Elapsed})
+	return result, nil
+}
+
+func (p *Pipeline) report(ctx context.Context, progress Progress) {
+	if p.reporter != nil {
+		p.reporter(ctx, progress)
+	}
+}
+
+// round trims durations to a readable precision
+func round(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(100 * time.Millisecond)
+	}
+	return d.Round(time.Millisecond)
+}
@@ -0,0 +1,97 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/pipeline"
+)
+
+func TestPipelineRun(t *testing.T) {
+	var progress []pipeline.Progress
+	var ran []string
+	p := pipeline.New(func(ctx context.Context, pr pipeline.Progress) {
+		progress = append(progress, pr)
+	})
+	for _, name := range []string{"generate", "fix imports", "build"} {
+		p.Add(name, func(ctx context.Context) error {
+			ran = append(ran, name)
+			return nil
+		})
+	}
+
+	result, err := p.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if strings.Join(ran, ",") != "generate,fix imports,build" {
+		t.Errorf("ran %v", ran)
+	}
+	if len(result.Steps) != 3 || result.Steps[1].Step != "fix imports" || result.Steps[1].Status != pipeline.StatusOK {
+		t.Errorf("unexpected timings: %+v", result.Steps)
+	}
+
+	// One notification per step plus a final one, with strictly increasing progress
+	if len(progress) != 4 {
+		t.Fatalf("got %d progress notifications, want 4", len(progress))
+	}
+	for i, pr := range progress[:3] {
+		if pr.Index != i+1 || pr.Completed != i || pr.Total != 3 || pr.Step != ran[i] {
+			t.Errorf("progress[%d] = %+v", i, pr)
+		}
+	}
+	if last := progress[3]; last.Step != "" || last.Completed != 3 {
+		t.Errorf("final progress = %+v", last)
+	}
+
+	summary := result.String()
+	for _, want := range []string{"Steps (", "1. generate:", "3. build:"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+}
+
+func TestPipelineStopsOnFailure(t *testing.T) {
+	boom := errors.New("failed to tidy Go module: network down")
+	var ran []string
+	p := pipeline.New(nil).
+		Add("init", func(ctx context.Context) error { ran = append(ran, "init"); return nil }).
+		Add("tidy", func(ctx context.Context) error { ran = append(ran, "tidy"); return boom }).
+		Add("build", func(ctx context.Context) error { ran = append(ran, "build"); return nil })
+
+	result, err := p.Run(context.Background())
+	var stepErr *pipeline.StepError
+	if !errors.As(err, &stepErr) || stepErr.Step != "tidy" || !errors.Is(err, boom) {
+		t.Fatalf("Run() error = %v, want step error from tidy", err)
+	}
+	if err.Error() != boom.Error() {
+		t.Errorf("error message = %q", err.Error())
+	}
+	if strings.Join(ran, ",") != "init,tidy" {
+		t.Errorf("ran %v", ran)
+	}
+	if len(result.Steps) != 2 || result.Steps[1].Status != pipeline.StatusFailed {
+		t.Errorf("unexpected timings: %+v", result.Steps)
+	}
+	if !strings.Contains(result.String(), "tidy: ") || !strings.Contains(result.String(), "(failed)") {
+		t.Errorf("summary does not flag the failed step:\n%s", result.String())
+	}
+}
+
+func TestPipelineCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := pipeline.New(nil).
+		Add("generate", func(ctx context.Context) error { cancel(); return nil }).
+		Add("build", func(ctx context.Context) error { t.Error("build ran after cancel"); return nil })
+
+	_, err := p.Run(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+	if !strings.Contains(err.Error(), "build canceled") {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+// Package project loads the per-project mcp-zero configuration file
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// ConfigNames are the file names searched for, in order
+var ConfigNames = []string{".mcp-zero.yaml", ".mcp-zero.yml"}
+
+// TemplateDirName is the directory goctl templates are detected from when no
+// configuration file names one
+const TemplateDirName = ".goctl"
+
+// Config is the content of a .mcp-zero.yaml file
+type Config struct {
+	Path      string          `yaml:"-"` // file the configuration was read from, empty for defaults
+	Lint      LintConfig      `yaml:"lint"`
+	Templates TemplatesConfig `yaml:"templates"`
+}
+
+// TemplatesConfig selects the goctl templates the generation tools use
+type TemplatesConfig struct {
+	Home   string `yaml:"home"`   // template directory, relative to the configuration file
+	Remote string `yaml:"remote"` // git repository holding the templates, used instead of home
+	Branch string `yaml:"branch"` // branch of remote
+}
+
+// LintConfig configures lint_api_spec
+type LintConfig struct {
+	// Rules enables or disables rules by name; rules not listed keep their default
+	Rules map[string]bool `yaml:"rules"`
+}
+
+// FindConfig returns the nearest configuration file in dir or its parents, or empty string
+func FindConfig(ctx context.Context, dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	// The search stops at the allowed roots, and files leading out of them are skipped
+	for validation.CheckAllowed(ctx, dir) == nil {
+		for _, name := range ConfigNames {
+			path := filepath.Join(dir, name)
+			if validation.CheckAllowed(ctx, path) != nil {
+				continue
+			}
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+	return ""
+}
+
+// TemplateHome returns the configured template directory as an absolute path, or empty string
+func (c *Config) TemplateHome() string {
+	home := c.Templates.Home
+	if home == "" || filepath.IsAbs(home) {
+		return home
+	}
+	if strings.HasPrefix(home, "~/") {
+		if userHome, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(userHome, home[2:])
+		}
+	}
+	return filepath.Join(filepath.Dir(c.Path), home)
+}
+
+// FindTemplateHome returns the nearest .goctl directory in dir or its parents, or empty string
+// The search stops below the user's home directory, whose .goctl is goctl's own,
+// per-release template cache rather than a project's templates, and at the allowed roots
+func FindTemplateHome(ctx context.Context, dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	userHome, _ := os.UserHomeDir()
+	for dir != userHome && validation.CheckAllowed(ctx, dir) == nil {
+		path := filepath.Join(dir, TemplateDirName)
+		if info, err := os.Stat(path); err == nil && info.IsDir() && validation.CheckAllowed(ctx, path) == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+	return ""
+}
+
+// LoadConfig reads the nearest configuration file above dir
+// An empty configuration is returned when there is none
+func LoadConfig(ctx context.Context, dir string) (*Config, error) {
+	path := FindConfig(ctx, dir)
+	if path == "" {
+		return &Config{}, nil
+	}
+	return ReadConfig(path)
+}
+
+// ReadConfig reads a configuration file
+func ReadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project config: %w", err)
+	}
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse project config %s: %w", path, err)
+	}
+	config.Path = path
+	return config, nil
+}
@@ -0,0 +1,97 @@
+package project_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/project"
+)
+
+func TestLoadConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "api", "user")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := project.LoadConfig(context.Background(), nested)
+	if err != nil {
+		t.Fatalf("LoadConfig() without a file failed: %v", err)
+	}
+	if config.Path != "" || len(config.Lint.Rules) != 0 {
+		t.Errorf("expected an empty config, got %+v", config)
+	}
+
+	path := filepath.Join(root, ".mcp-zero.yaml")
+	content := "# project settings\nlint:\n  rules:\n    missing-doc: false\n    unused-type: true\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := project.FindConfig(context.Background(), nested); got != path {
+		t.Errorf("FindConfig() = %q, want %q", got, path)
+	}
+	config, err = project.LoadConfig(context.Background(), nested)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if config.Path != path || config.Lint.Rules["missing-doc"] || !config.Lint.Rules["unused-type"] {
+		t.Errorf("unexpected config %+v", config)
+	}
+
+	if err := os.WriteFile(path, []byte("lint: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := project.LoadConfig(context.Background(), nested); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
+
+func TestTemplateHome(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "services", "user")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := project.FindTemplateHome(context.Background(), nested); got != "" {
+		t.Errorf("FindTemplateHome() without a .goctl directory = %q", got)
+	}
+	home := filepath.Join(root, ".goctl")
+	if err := os.Mkdir(home, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := project.FindTemplateHome(context.Background(), nested); got != home {
+		t.Errorf("FindTemplateHome() = %q, want %q", got, home)
+	}
+
+	path := filepath.Join(root, ".mcp-zero.yaml")
+	content := "templates:\n  home: build/templates\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := project.LoadConfig(context.Background(), nested)
+	if err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if got, want := config.TemplateHome(), filepath.Join(root, "build", "templates"); got != want {
+		t.Errorf("TemplateHome() = %q, want %q", got, want)
+	}
+}
+
+func TestFindTemplateHomeSkipsUserHome(t *testing.T) {
+	userHome := t.TempDir()
+	t.Setenv("HOME", userHome)
+	dir := filepath.Join(userHome, "work", "demo")
+	if err := os.MkdirAll(filepath.Join(userHome, ".goctl"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := project.FindTemplateHome(context.Background(), dir); got != "" {
+		t.Errorf("FindTemplateHome() returned goctl's own cache %q", got)
+	}
+}
@@ -0,0 +1,315 @@
+package protospec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// File is the syntax tree of a single .proto file
+type File struct {
+	Path     string     `json:"path,omitempty"`
+	Syntax   string     `json:"syntax,omitempty"`  // proto2 or proto3; empty when an edition is declared
+	Edition  string     `json:"edition,omitempty"` // editions syntax such as 2023
+	Package  string     `json:"package,omitempty"`
+	Imports  []*Import  `json:"imports,omitempty"`
+	Options  []*Option  `json:"options,omitempty"`
+	Messages []*Message `json:"messages,omitempty"`
+	Enums    []*Enum    `json:"enums,omitempty"`
+	Services []*Service `json:"services,omitempty"`
+	Extends  []*Extend  `json:"extends,omitempty"`
+}
+
+// Option returns the value of a file option such as go_package, or empty string
+func (f *File) Option(name string) string {
+	return getOption(f.Options, name)
+}
+
+// GoPackage returns the go_package option without the optional ";name" suffix
+func (f *File) GoPackage() string {
+	goPackage := f.Option("go_package")
+	if i := strings.Index(goPackage, ";"); i >= 0 {
+		goPackage = goPackage[:i]
+	}
+	return goPackage
+}
+
+// Import is a single import statement
+type Import struct {
+	Pos      Pos    `json:"pos"`
+	Path     string `json:"path"`
+	Modifier string `json:"modifier,omitempty"` // public or weak
+	Resolved string `json:"-"`                  // file the import was found at, empty if unresolved
+}
+
+// Option is an option statement or a [key = value] field option
+// Aggregate values are kept as their source text
+type Option struct {
+	Pos   Pos    `json:"pos"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Message is a message declaration, possibly nested
+type Message struct {
+	Pos           Pos        `json:"pos"`
+	Name          string     `json:"name"`
+	FullName      string     `json:"full_name"` // package-qualified name, including parent messages
+	Fields        []*Field   `json:"fields,omitempty"`
+	Oneofs        []*Oneof   `json:"oneofs,omitempty"`
+	Messages      []*Message `json:"messages,omitempty"`
+	Enums         []*Enum    `json:"enums,omitempty"`
+	Options       []*Option  `json:"options,omitempty"`
+	Reserved      []Range    `json:"reserved,omitempty"`
+	ReservedNames []string   `json:"reserved_names,omitempty"`
+	Extensions    []Range    `json:"extensions,omitempty"`
+	Doc           []string   `json:"doc,omitempty"`
+	EndPos        Pos        `json:"end_pos"` // position of the closing brace
+}
+
+// Field returns the field with the given name, searching oneofs too, or nil
+func (m *Message) Field(name string) *Field {
+	for _, f := range m.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// IsReserved reports whether a field number is reserved
+func (m *Message) IsReserved(number int) bool {
+	for _, r := range m.Reserved {
+		if r.Contains(number) {
+			return true
+		}
+	}
+	return false
+}
+
+// Field is a message field; fields declared in a oneof carry its name
+type Field struct {
+	Pos     Pos       `json:"pos"`
+	Name    string    `json:"name"`
+	Label   string    `json:"label,omitempty"` // optional, repeated or required
+	Type    string    `json:"type"`            // value type for maps
+	KeyType string    `json:"key_type,omitempty"`
+	Number  int       `json:"number"`
+	Oneof   string    `json:"oneof,omitempty"`
+	Options []*Option `json:"options,omitempty"`
+	Doc     []string  `json:"doc,omitempty"`
+	Comment string    `json:"comment,omitempty"`
+}
+
+// IsMap reports whether the field is a map<key, value>
+func (f *Field) IsMap() bool {
+	return f.KeyType != ""
+}
+
+// IsRepeated reports whether the field is a repeated field
+func (f *Field) IsRepeated() bool {
+	return f.Label == "repeated"
+}
+
+// TypeString returns the field type as written in source, including the label
+func (f *Field) TypeString() string {
+	if f.IsMap() {
+		return fmt.Sprintf("map<%s, %s>", f.KeyType, f.Type)
+	}
+	if f.Label != "" {
+		return f.Label + " " + f.Type
+	}
+	return f.Type
+}
+
+// Option returns the value of a field option such as json_name, or empty string
+func (f *Field) Option(name string) string {
+	return getOption(f.Options, name)
+}
+
+// Oneof is a oneof group; its fields also appear in Message.Fields
+type Oneof struct {
+	Pos     Pos       `json:"pos"`
+	Name    string    `json:"name"`
+	Fields  []string  `json:"fields"`
+	Options []*Option `json:"options,omitempty"`
+}
+
+// Range is an inclusive field number range used by reserved and extensions
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Contains reports whether n lies within the range
+func (r Range) Contains(n int) bool {
+	return n >= r.Start && n <= r.End
+}
+
+// MaxFieldNumber is the value of the max keyword in ranges
+const MaxFieldNumber = 536870911
+
+// Enum is an enum declaration, possibly nested in a message
+type Enum struct {
+	Pos           Pos          `json:"pos"`
+	Name          string       `json:"name"`
+	FullName      string       `json:"full_name"`
+	Values        []*EnumValue `json:"values,omitempty"`
+	Options       []*Option    `json:"options,omitempty"`
+	Reserved      []Range      `json:"reserved,omitempty"`
+	ReservedNames []string     `json:"reserved_names,omitempty"`
+	Doc           []string     `json:"doc,omitempty"`
+}
+
+// EnumValue is a single enum constant
+type EnumValue struct {
+	Pos     Pos       `json:"pos"`
+	Name    string    `json:"name"`
+	Number  int       `json:"number"`
+	Options []*Option `json:"options,omitempty"`
+	Doc     []string  `json:"doc,omitempty"`
+	Comment string    `json:"comment,omitempty"`
+}
+
+// Service is a service declaration
+type Service struct {
+	Pos     Pos       `json:"pos"`
+	Name    string    `json:"name"`
+	Methods []*Method `json:"methods,omitempty"`
+	Options []*Option `json:"options,omitempty"`
+	Doc     []string  `json:"doc,omitempty"`
+	EndPos  Pos       `json:"end_pos"` // position of the closing brace
+}
+
+// Method returns the rpc with the given name, or nil
+func (s *Service) Method(name string) *Method {
+	for _, m := range s.Methods {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// Method is a single rpc declaration
+type Method struct {
+	Pos             Pos       `json:"pos"`
+	Name            string    `json:"name"`
+	Request         string    `json:"request"`
+	Response        string    `json:"response"`
+	ClientStreaming bool      `json:"client_streaming,omitempty"`
+	ServerStreaming bool      `json:"server_streaming,omitempty"`
+	Options         []*Option `json:"options,omitempty"`
+	Doc             []string  `json:"doc,omitempty"`
+	Comment         string    `json:"comment,omitempty"`
+}
+
+// Option returns the value of a method option such as (google.api.http).get, or empty string
+func (m *Method) Option(name string) string {
+	return getOption(m.Options, name)
+}
+
+// Extend is an extend block; its fields extend another message
+type Extend struct {
+	Pos    Pos      `json:"pos"`
+	Target string   `json:"target"`
+	Fields []*Field `json:"fields,omitempty"`
+}
+
+// Spec is a root .proto file together with every file it imports
+type Spec struct {
+	Root  *File   `json:"root"`
+	Files []*File `json:"files"` // root first, then imports in resolution order
+
+	// Unresolved lists imports that were not found but are known to protoc,
+	// such as google/protobuf/empty.proto
+	Unresolved []string `json:"unresolved,omitempty"`
+}
+
+// Messages returns every message declared across all files, nested messages included
+func (s *Spec) Messages() []*Message {
+	var messages []*Message
+	var walk func(list []*Message)
+	walk = func(list []*Message) {
+		for _, m := range list {
+			messages = append(messages, m)
+			walk(m.Messages)
+		}
+	}
+	for _, f := range s.Files {
+		walk(f.Messages)
+	}
+	return messages
+}
+
+// Message looks up a message by full name, or by a name relative to the root package
+func (s *Spec) Message(name string) *Message {
+	name = strings.TrimPrefix(name, ".")
+	for _, m := range s.Messages() {
+		if m.FullName == name {
+			return m
+		}
+	}
+	if s.Root.Package != "" {
+		qualified := s.Root.Package + "." + name
+		for _, m := range s.Messages() {
+			if m.FullName == qualified {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+// Enums returns every enum declared across all files, nested enums included
+func (s *Spec) Enums() []*Enum {
+	var enums []*Enum
+	var walk func(list []*Message)
+	walk = func(list []*Message) {
+		for _, m := range list {
+			enums = append(enums, m.Enums...)
+			walk(m.Messages)
+		}
+	}
+	for _, f := range s.Files {
+		enums = append(enums, f.Enums...)
+		walk(f.Messages)
+	}
+	return enums
+}
+
+// FileOf returns the file declaring the given message, or nil
+func (s *Spec) FileOf(msg *Message) *File {
+	for _, f := range s.Files {
+		prefix := ""
+		if f.Package != "" {
+			prefix = f.Package + "."
+		}
+		if !strings.HasPrefix(msg.FullName, prefix) {
+			continue
+		}
+		for _, m := range f.Messages {
+			if m == msg || containsMessage(m, msg) {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+func containsMessage(parent, msg *Message) bool {
+	for _, m := range parent.Messages {
+		if m == msg || containsMessage(m, msg) {
+			return true
+		}
+	}
+	return false
+}
+
+func getOption(options []*Option, name string) string {
+	for _, o := range options {
+		if o.Name == name {
+			return o.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,243 @@
+package protospec
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// NewMethod describes an rpc to add to a service
+type NewMethod struct {
+	Name            string
+	Request         string
+	Response        string
+	ClientStreaming bool
+	ServerStreaming bool
+	Doc             string
+}
+
+// NewMessage describes a message to add alongside a method
+type NewMessage struct {
+	Name   string
+	Fields []NewField
+}
+
+// NewField is a field of a NewMessage; Type may carry a label or be a map<k, v>
+type NewField struct {
+	Name   string
+	Type   string
+	Number int
+}
+
+// Edit is the result of an in-place change to a .proto file
+type Edit struct {
+	Path    string // file that was changed
+	Source  []byte // complete new content of the file
+	Service string // service the method was added to
+}
+
+// insertion is text to insert at a byte offset
+type insertion struct {
+	offset int
+	text   string
+}
+
+// AddMethod inserts an rpc into a service of the root file and adds messages after the
+// last top-level message. An empty service name selects the only service
+// Only the inserted lines change; the rest of the file is kept byte for byte
+func AddMethod(spec *Spec, service string, method NewMethod, messages []NewMessage) (*Edit, error) {
+	root := spec.Root
+
+	svc, err := findService(root, service)
+	if err != nil {
+		return nil, err
+	}
+	if svc.Method(method.Name) != nil {
+		return nil, fmt.Errorf("rpc %s already exists in service %s", method.Name, svc.Name)
+	}
+
+	added := make(map[string]bool)
+	for _, msg := range messages {
+		if resolveMessage(spec, root, msg.Name) != nil || added[msg.Name] {
+			return nil, fmt.Errorf("message %s already exists", msg.Name)
+		}
+		added[msg.Name] = true
+	}
+	for _, typeName := range []string{method.Request, method.Response} {
+		if typeName == "" {
+			return nil, fmt.Errorf("rpc %s needs a request and a response message", method.Name)
+		}
+		if !added[typeName] && resolveMessage(spec, root, typeName) == nil && !isWellKnown(typeName) {
+			return nil, fmt.Errorf("undefined message %s", typeName)
+		}
+	}
+
+	src, err := os.ReadFile(root.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proto file: %w", err)
+	}
+	lines := lineStarts(src)
+	indent := detectIndent(src, lines, root)
+
+	inserts := []insertion{methodInsertion(src, lines, svc, method, indent)}
+	if len(messages) > 0 {
+		inserts = append(inserts, messagesInsertion(src, lines, root, messages, indent))
+	}
+
+	sort.SliceStable(inserts, func(i, j int) bool { return inserts[i].offset > inserts[j].offset })
+	out := string(src)
+	for _, ins := range inserts {
+		out = out[:ins.offset] + ins.text + out[ins.offset:]
+	}
+
+	if _, err := Parse(root.Path, []byte(out)); err != nil {
+		return nil, fmt.Errorf("edited proto is invalid: %w", err)
+	}
+	return &Edit{Path: root.Path, Source: []byte(out), Service: svc.Name}, nil
+}
+
+func findService(file *File, name string) (*Service, error) {
+	if name == "" {
+		if len(file.Services) != 1 {
+			return nil, fmt.Errorf("proto declares %d services; specify one", len(file.Services))
+		}
+		return file.Services[0], nil
+	}
+	for _, svc := range file.Services {
+		if svc.Name == name {
+			return svc, nil
+		}
+	}
+	return nil, fmt.Errorf("service %s not found", name)
+}
+
+func methodInsertion(src []byte, lines []int, svc *Service, method NewMethod, indent string) insertion {
+	if len(svc.Methods) > 0 {
+		indent = leadingSpace(src, lines, svc.Methods[0].Pos.Line)
+	}
+
+	var b strings.Builder
+	if method.Doc != "" {
+		for _, line := range strings.Split(method.Doc, "\n") {
+			fmt.Fprintf(&b, "%s// %s\n", indent, line)
+		}
+	}
+	request, response := method.Request, method.Response
+	if method.ClientStreaming {
+		request = "stream " + request
+	}
+	if method.ServerStreaming {
+		response = "stream " + response
+	}
+	fmt.Fprintf(&b, "%srpc %s(%s) returns (%s);\n", indent, method.Name, request, response)
+
+	end := offsetOf(src, lines, svc.EndPos)
+	lineStart := lines[svc.EndPos.Line-1]
+	if strings.TrimSpace(string(src[lineStart:end])) == "" {
+		return insertion{offset: lineStart, text: b.String()}
+	}
+	// The closing brace shares its line with other code
+	return insertion{offset: end, text: "\n" + b.String()}
+}
+
+func messagesInsertion(src []byte, lines []int, file *File, messages []NewMessage, indent string) insertion {
+	var b strings.Builder
+	for i, msg := range messages {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		writeMessage(&b, indent, msg)
+	}
+
+	if len(file.Messages) > 0 {
+		last := file.Messages[len(file.Messages)-1]
+		return insertion{offset: lineEnd(src, lines, last.EndPos.Line), text: "\n" + b.String()}
+	}
+
+	// No messages yet: put them before the first service
+	if len(file.Services) > 0 {
+		svc := file.Services[0]
+		line := svc.Pos.Line
+		for _, doc := range svc.Doc {
+			line -= strings.Count(doc, "\n") + 1
+		}
+		return insertion{offset: lines[line-1], text: b.String() + "\n"}
+	}
+
+	text := "\n" + b.String()
+	if len(src) > 0 && src[len(src)-1] != '\n' {
+		text = "\n" + text
+	}
+	return insertion{offset: len(src), text: text}
+}
+
+func writeMessage(b *strings.Builder, indent string, msg NewMessage) {
+	if len(msg.Fields) == 0 {
+		fmt.Fprintf(b, "message %s {}\n", msg.Name)
+		return
+	}
+	fmt.Fprintf(b, "message %s {\n", msg.Name)
+	for _, f := range msg.Fields {
+		fmt.Fprintf(b, "%s%s %s = %d;\n", indent, f.Type, f.Name, f.Number)
+	}
+	b.WriteString("}\n")
+}
+
+// detectIndent returns the indentation used inside messages and services, defaulting to two spaces
+func detectIndent(src []byte, lines []int, file *File) string {
+	for _, msg := range file.Messages {
+		if len(msg.Fields) > 0 {
+			if indent := leadingSpace(src, lines, msg.Fields[0].Pos.Line); indent != "" {
+				return indent
+			}
+		}
+	}
+	for _, svc := range file.Services {
+		if len(svc.Methods) > 0 {
+			if indent := leadingSpace(src, lines, svc.Methods[0].Pos.Line); indent != "" {
+				return indent
+			}
+		}
+	}
+	return "  "
+}
+
+// lineStarts returns the byte offset at which each line begins
+func lineStarts(src []byte) []int {
+	starts := []int{0}
+	for i, c := range src {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// offsetOf converts a position to a byte offset
+func offsetOf(src []byte, lines []int, pos Pos) int {
+	off := lines[pos.Line-1]
+	for col := 1; col < pos.Column && off < len(src); col++ {
+		_, size := utf8.DecodeRune(src[off:])
+		off += size
+	}
+	return off
+}
+
+// lineEnd returns the offset just past the newline ending line
+func lineEnd(src []byte, lines []int, line int) int {
+	if line < len(lines) {
+		return lines[line]
+	}
+	return len(src)
+}
+
+func leadingSpace(src []byte, lines []int, line int) string {
+	start := lines[line-1]
+	end := start
+	for end < len(src) && (src[end] == ' ' || src[end] == '\t') {
+		end++
+	}
+	return string(src[start:end])
+}
@@ -0,0 +1,156 @@
+package protospec_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/protospec"
+)
+
+const editBase = `syntax = "proto3";
+
+package user;
+option go_package = "./user";
+
+// GetUserReq asks for one user
+message GetUserReq {
+    int64 id = 1;
+}
+
+message GetUserResp {
+    string name = 1; // display name
+}
+
+service User {
+    // fetch one user
+    rpc GetUser(GetUserReq) returns (GetUserResp);
+}
+`
+
+func loadEditSpec(t *testing.T, content string) *protospec.Spec {
+	t.Helper()
+	protoFile := filepath.Join(t.TempDir(), "user.proto")
+	if err := os.WriteFile(protoFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := protospec.Load(protoFile, nil, nil)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	return spec
+}
+
+func TestAddMethod(t *testing.T) {
+	spec := loadEditSpec(t, editBase)
+
+	edit, err := protospec.AddMethod(spec, "", protospec.NewMethod{
+		Name:     "ListUsers",
+		Request:  "ListUsersReq",
+		Response: "ListUsersResp",
+		Doc:      "list users",
+	}, []protospec.NewMessage{
+		{Name: "ListUsersReq", Fields: []protospec.NewField{{Name: "page", Type: "int32", Number: 1}}},
+		{Name: "ListUsersResp", Fields: []protospec.NewField{{Name: "users", Type: "repeated GetUserResp", Number: 1}, {Name: "total", Type: "int64", Number: 2}}},
+	})
+	if err != nil {
+		t.Fatalf("AddMethod() failed: %v", err)
+	}
+	if edit.Service != "User" {
+		t.Errorf("Expected service User, got %s", edit.Service)
+	}
+
+	want := strings.Replace(editBase, "    string name = 1; // display name\n}\n",
+		"    string name = 1; // display name\n}\n\nmessage ListUsersReq {\n    int32 page = 1;\n}\n\nmessage ListUsersResp {\n    repeated GetUserResp users = 1;\n    int64 total = 2;\n}\n", 1)
+	want = strings.Replace(want, "    rpc GetUser(GetUserReq) returns (GetUserResp);\n}\n",
+		"    rpc GetUser(GetUserReq) returns (GetUserResp);\n    // list users\n    rpc ListUsers(ListUsersReq) returns (ListUsersResp);\n}\n", 1)
+
+	if string(edit.Source) != want {
+		t.Errorf("Unexpected edited source:\n%s\nwant:\n%s", edit.Source, want)
+	}
+}
+
+func TestAddMethodStreamingWithExistingMessages(t *testing.T) {
+	spec := loadEditSpec(t, editBase)
+
+	edit, err := protospec.AddMethod(spec, "User", protospec.NewMethod{
+		Name:            "WatchUser",
+		Request:         "GetUserReq",
+		Response:        "GetUserResp",
+		ServerStreaming: true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("AddMethod() failed: %v", err)
+	}
+
+	want := strings.Replace(editBase, "returns (GetUserResp);\n}\n", "returns (GetUserResp);\n    rpc WatchUser(GetUserReq) returns (stream GetUserResp);\n}\n", 1)
+	if string(edit.Source) != want {
+		t.Errorf("Unexpected edited source:\n%s", edit.Source)
+	}
+}
+
+func TestAddMethodMessagesBeforeFirstService(t *testing.T) {
+	content := "syntax = \"proto3\";\n\npackage ping;\n\n// the service\nservice Ping {}\n"
+	spec := loadEditSpec(t, content)
+
+	edit, err := protospec.AddMethod(spec, "", protospec.NewMethod{Name: "Ping", Request: "PingReq", Response: "PingResp"},
+		[]protospec.NewMessage{{Name: "PingReq"}, {Name: "PingResp", Fields: []protospec.NewField{{Name: "pong", Type: "string", Number: 1}}}})
+	if err != nil {
+		t.Fatalf("AddMethod() failed: %v", err)
+	}
+
+	want := "syntax = \"proto3\";\n\npackage ping;\n\nmessage PingReq {}\n\nmessage PingResp {\n  string pong = 1;\n}\n\n// the service\nservice Ping {\n  rpc Ping(PingReq) returns (PingResp);\n}\n"
+	if string(edit.Source) != want {
+		t.Errorf("Unexpected edited source:\n%s\nwant:\n%s", edit.Source, want)
+	}
+}
+
+func TestAddMethodErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		service  string
+		method   protospec.NewMethod
+		messages []protospec.NewMessage
+		wantErr  string
+	}{
+		{
+			name:    "duplicate rpc",
+			method:  protospec.NewMethod{Name: "GetUser", Request: "GetUserReq", Response: "GetUserResp"},
+			wantErr: "rpc GetUser already exists in service User",
+		},
+		{
+			name:     "existing message",
+			method:   protospec.NewMethod{Name: "Other", Request: "GetUserReq", Response: "GetUserResp"},
+			messages: []protospec.NewMessage{{Name: "GetUserReq"}},
+			wantErr:  "message GetUserReq already exists",
+		},
+		{
+			name:    "undefined message",
+			method:  protospec.NewMethod{Name: "Other", Request: "Missing", Response: "GetUserResp"},
+			wantErr: "undefined message Missing",
+		},
+		{
+			name:    "unknown service",
+			service: "Order",
+			method:  protospec.NewMethod{Name: "Other", Request: "GetUserReq", Response: "GetUserResp"},
+			wantErr: "service Order not found",
+		},
+		{
+			name:     "reused field number",
+			method:   protospec.NewMethod{Name: "Other", Request: "OtherReq", Response: "GetUserResp"},
+			messages: []protospec.NewMessage{{Name: "OtherReq", Fields: []protospec.NewField{{Name: "a", Type: "string", Number: 1}, {Name: "b", Type: "string", Number: 1}}}},
+			wantErr:  "edited proto is invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := loadEditSpec(t, editBase)
+			_, err := protospec.AddMethod(spec, tt.service, tt.method, tt.messages)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("AddMethod() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,1130 @@
+package protospec
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Error is a syntax error with a file position
+type Error struct {
+	Filename string
+	Pos      Pos
+	Msg      string
+}
+
+func (e *Error) Error() string {
+	if e.Filename != "" {
+		return fmt.Sprintf("%s:%d:%d: %s", e.Filename, e.Pos.Line, e.Pos.Column, e.Msg)
+	}
+	return fmt.Sprintf("%d:%d: %s", e.Pos.Line, e.Pos.Column, e.Msg)
+}
+
+// wellKnownPrefix marks imports bundled with protoc that need no include path
+const wellKnownPrefix = "google/protobuf/"
+
+// fieldLabels are the cardinality keywords that may precede a field type
+var fieldLabels = map[string]bool{
+	"optional": true,
+	"repeated": true,
+	"required": true,
+}
+
+// parser builds a File from tokens
+type parser struct {
+	lex *lexer
+
+	tok      Token // current token
+	prevLine int   // line on which the previous non-comment token ended
+
+	comments []Token // comments read since the last consumed token
+}
+
+// ParseFile reads and parses a single .proto file
+func ParseFile(path string) (*File, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read proto file: %w", err)
+	}
+	return Parse(path, src)
+}
+
+// Parse parses .proto source; filename is only used for error positions
+func Parse(filename string, src []byte) (*File, error) {
+	p := &parser{lex: newLexer(filename, src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	file := &File{Path: filename}
+	if err := p.parseFile(file); err != nil {
+		return nil, err
+	}
+	qualifyNames(file)
+	return file, nil
+}
+
+// Load parses path and, recursively, every file it imports
+// Imports are resolved against includePaths in order, like protoc -I;
+// with no include paths the directory of path is used. When checkImport is set, every
+// resolved import is passed to it before it is read, and an error stops the load
+func Load(path string, includePaths []string, checkImport func(path string) error) (*Spec, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve proto file path: %w", err)
+	}
+
+	if len(includePaths) == 0 {
+		includePaths = []string{filepath.Dir(absPath)}
+	}
+	var dirs []string
+	for _, dir := range includePaths {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve include path %s: %w", dir, err)
+		}
+		dirs = append(dirs, abs)
+	}
+
+	spec := &Spec{}
+	seen := make(map[string]bool)
+
+	var load func(path string) error
+	load = func(path string) error {
+		if seen[path] {
+			return nil
+		}
+		seen[path] = true
+
+		file, err := ParseFile(path)
+		if err != nil {
+			return err
+		}
+		spec.Files = append(spec.Files, file)
+
+		for _, imp := range file.Imports {
+			imp.Resolved = resolveImport(imp.Path, dirs)
+			if imp.Resolved == "" {
+				if strings.HasPrefix(imp.Path, wellKnownPrefix) {
+					spec.addUnresolved(imp.Path)
+					continue
+				}
+				return &Error{Filename: path, Pos: imp.Pos, Msg: fmt.Sprintf("import %q not found in include paths: %s", imp.Path, strings.Join(dirs, ", "))}
+			}
+			if checkImport != nil {
+				if err := checkImport(imp.Resolved); err != nil {
+					return &Error{Filename: path, Pos: imp.Pos, Msg: fmt.Sprintf("cannot import %q: %v", imp.Path, err)}
+				}
+			}
+			if err := load(imp.Resolved); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := load(absPath); err != nil {
+		return nil, err
+	}
+	spec.Root = spec.Files[0]
+
+	if err := checkMethodTypes(spec); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+func resolveImport(importPath string, dirs []string) string {
+	for _, dir := range dirs {
+		candidate := filepath.Join(dir, filepath.FromSlash(importPath))
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return filepath.Clean(candidate)
+		}
+	}
+	return ""
+}
+
+func (s *Spec) addUnresolved(path string) {
+	for _, existing := range s.Unresolved {
+		if existing == path {
+			return
+		}
+	}
+	s.Unresolved = append(s.Unresolved, path)
+}
+
+// checkMethodTypes verifies every rpc request and response refers to a known message
+func checkMethodTypes(spec *Spec) error {
+	for _, f := range spec.Files {
+		for _, svc := range f.Services {
+			for _, m := range svc.Methods {
+				for _, typeName := range []string{m.Request, m.Response} {
+					if resolveMessage(spec, f, typeName) == nil && !isWellKnown(typeName) {
+						return &Error{Filename: f.Path, Pos: m.Pos, Msg: fmt.Sprintf("rpc %s uses undefined message %q", m.Name, typeName)}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolveMessage looks a type name up relative to the package of the referring file
+func resolveMessage(spec *Spec, from *File, name string) *Message {
+	if strings.HasPrefix(name, ".") {
+		return spec.Message(name)
+	}
+	if from.Package != "" {
+		if m := spec.Message(from.Package + "." + name); m != nil {
+			return m
+		}
+	}
+	return spec.Message(name)
+}
+
+func isWellKnown(typeName string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(typeName, "."), "google.protobuf.")
+}
+
+// qualifyNames fills in package-qualified names once the whole file is parsed
+func qualifyNames(file *File) {
+	var walk func(prefix string, messages []*Message, enums []*Enum)
+	walk = func(prefix string, messages []*Message, enums []*Enum) {
+		for _, e := range enums {
+			e.FullName = prefix + e.Name
+		}
+		for _, m := range messages {
+			m.FullName = prefix + m.Name
+			walk(m.FullName+".", m.Messages, m.Enums)
+		}
+	}
+
+	prefix := ""
+	if file.Package != "" {
+		prefix = file.Package + "."
+	}
+	walk(prefix, file.Messages, file.Enums)
+}
+
+// advance moves to the next non-comment token, collecting comments on the way
+func (p *parser) advance() error {
+	if p.tok.End > 0 {
+		p.prevLine = p.tok.Pos.Line + strings.Count(p.tok.Text, "\n")
+	}
+	for {
+		tok, err := p.lex.next()
+		if err != nil {
+			return err
+		}
+		if tok.Kind == COMMENT {
+			p.comments = append(p.comments, tok)
+			continue
+		}
+		p.tok = tok
+		return nil
+	}
+}
+
+// peek returns the token after the current one without consuming it
+func (p *parser) peek() Token {
+	lex := *p.lex
+	for {
+		tok, err := lex.next()
+		if err != nil || tok.Kind != COMMENT {
+			return tok
+		}
+	}
+}
+
+func (p *parser) errorf(pos Pos, format string, args ...interface{}) error {
+	return &Error{Filename: p.lex.filename, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) unexpected(want string) error {
+	if p.tok.Kind == EOF {
+		return p.errorf(p.tok.Pos, "expected %s, found end of file", want)
+	}
+	return p.errorf(p.tok.Pos, "expected %s, found %q", want, p.tok.Text)
+}
+
+func (p *parser) expect(kind TokenKind) (Token, error) {
+	tok := p.tok
+	if tok.Kind != kind {
+		return tok, p.unexpected(kind.String())
+	}
+	return tok, p.advance()
+}
+
+func (p *parser) expectIdent(text string) error {
+	if p.tok.Kind != IDENT || p.tok.Text != text {
+		return p.unexpected(fmt.Sprintf("%q", text))
+	}
+	return p.advance()
+}
+
+// takeDoc returns the comments preceding the current token on earlier lines
+func (p *parser) takeDoc() []string {
+	var doc []string
+	for _, c := range p.comments {
+		if c.Pos.Line == p.prevLine {
+			continue // trailing comment of the previous statement
+		}
+		doc = append(doc, c.Text)
+	}
+	p.comments = nil
+	return doc
+}
+
+// takeLineComment returns a comment starting on line, leaving the others as doc for the next node
+func (p *parser) takeLineComment(line int) string {
+	for i, c := range p.comments {
+		if c.Pos.Line == line {
+			p.comments = append(p.comments[:i:i], p.comments[i+1:]...)
+			return c.Text
+		}
+	}
+	return ""
+}
+
+func (p *parser) parseFile(file *File) error {
+	for p.tok.Kind != EOF {
+		if p.tok.Kind == SEMI {
+			if err := p.advance(); err != nil {
+				return err
+			}
+			continue
+		}
+		if p.tok.Kind != IDENT {
+			return p.unexpected("declaration")
+		}
+
+		switch p.tok.Text {
+		case "syntax", "edition":
+			keyword := p.tok
+			if file.Syntax != "" || file.Edition != "" {
+				return p.errorf(keyword.Pos, "duplicate %s declaration", keyword.Text)
+			}
+			if err := p.advance(); err != nil {
+				return err
+			}
+			if _, err := p.expect(ASSIGN); err != nil {
+				return err
+			}
+			tok, err := p.expect(STRING)
+			if err != nil {
+				return err
+			}
+			value := unquote(tok.Text)
+			if keyword.Text == "syntax" {
+				if value != "proto2" && value != "proto3" {
+					return p.errorf(tok.Pos, "unsupported syntax %q, expected proto2 or proto3", value)
+				}
+				file.Syntax = value
+			} else {
+				file.Edition = value
+			}
+			if _, err := p.expect(SEMI); err != nil {
+				return err
+			}
+		case "package":
+			if file.Package != "" {
+				return p.errorf(p.tok.Pos, "duplicate package declaration")
+			}
+			if err := p.advance(); err != nil {
+				return err
+			}
+			tok, err := p.expect(IDENT)
+			if err != nil {
+				return err
+			}
+			file.Package = tok.Text
+			if _, err := p.expect(SEMI); err != nil {
+				return err
+			}
+		case "import":
+			imp, err := p.parseImport()
+			if err != nil {
+				return err
+			}
+			file.Imports = append(file.Imports, imp)
+		case "option":
+			opt, err := p.parseOption()
+			if err != nil {
+				return err
+			}
+			file.Options = append(file.Options, opt)
+		case "message":
+			msg, err := p.parseMessage()
+			if err != nil {
+				return err
+			}
+			file.Messages = append(file.Messages, msg)
+		case "enum":
+			enum, err := p.parseEnum()
+			if err != nil {
+				return err
+			}
+			file.Enums = append(file.Enums, enum)
+		case "service":
+			svc, err := p.parseService()
+			if err != nil {
+				return err
+			}
+			file.Services = append(file.Services, svc)
+		case "extend":
+			ext, err := p.parseExtend()
+			if err != nil {
+				return err
+			}
+			file.Extends = append(file.Extends, ext)
+		default:
+			return p.errorf(p.tok.Pos, "unexpected %q, expected syntax, package, import, option, message, enum, service or extend", p.tok.Text)
+		}
+	}
+	return nil
+}
+
+func (p *parser) parseImport() (*Import, error) {
+	p.takeDoc()
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	imp := &Import{}
+	if p.tok.Kind == IDENT && (p.tok.Text == "public" || p.tok.Text == "weak") {
+		imp.Modifier = p.tok.Text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	tok, err := p.expect(STRING)
+	if err != nil {
+		return nil, err
+	}
+	imp.Pos = tok.Pos
+	imp.Path = unquote(tok.Text)
+	if _, err := p.expect(SEMI); err != nil {
+		return nil, err
+	}
+	return imp, nil
+}
+
+// parseOption parses an option statement
+func (p *parser) parseOption() (*Option, error) {
+	p.takeDoc()
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	opt, err := p.parseOptionAssignment()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(SEMI); err != nil {
+		return nil, err
+	}
+	return opt, nil
+}
+
+// parseOptionAssignment parses name = value, where name may contain (extension) parts
+func (p *parser) parseOptionAssignment() (*Option, error) {
+	opt := &Option{Pos: p.tok.Pos}
+
+	var name strings.Builder
+	for p.tok.Kind == IDENT || p.tok.Kind == LPAREN {
+		if p.tok.Kind == IDENT {
+			name.WriteString(p.tok.Text)
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		tok, err := p.expect(IDENT)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(RPAREN); err != nil {
+			return nil, err
+		}
+		name.WriteString("(" + tok.Text + ")")
+	}
+	if name.Len() == 0 {
+		return nil, p.unexpected("option name")
+	}
+	opt.Name = name.String()
+
+	if _, err := p.expect(ASSIGN); err != nil {
+		return nil, err
+	}
+	value, err := p.parseConstant()
+	if err != nil {
+		return nil, err
+	}
+	opt.Value = value
+	return opt, nil
+}
+
+// parseConstant parses an option value; aggregate { ... } values are returned as source text
+func (p *parser) parseConstant() (string, error) {
+	switch p.tok.Kind {
+	case STRING:
+		// Adjacent string literals are concatenated
+		var value strings.Builder
+		for p.tok.Kind == STRING {
+			value.WriteString(unquote(p.tok.Text))
+			if err := p.advance(); err != nil {
+				return "", err
+			}
+		}
+		return value.String(), nil
+	case IDENT, NUMBER:
+		value := p.tok.Text
+		return value, p.advance()
+	case LBRACE:
+		start := p.tok.Offset
+		depth := 0
+		for {
+			switch p.tok.Kind {
+			case LBRACE:
+				depth++
+			case RBRACE:
+				depth--
+			case EOF:
+				return "", p.unexpected("'}'")
+			}
+			end := p.tok.End
+			if err := p.advance(); err != nil {
+				return "", err
+			}
+			if depth == 0 {
+				return string(p.lex.src[start:end]), nil
+			}
+		}
+	}
+	return "", p.unexpected("option value")
+}
+
+// parseFieldOptions parses an optional [name = value, ...] list
+func (p *parser) parseFieldOptions() ([]*Option, error) {
+	if p.tok.Kind != LBRACK {
+		return nil, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var options []*Option
+	for {
+		opt, err := p.parseOptionAssignment()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, opt)
+		if p.tok.Kind != COMMA {
+			break
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.expect(RBRACK); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+func (p *parser) parseNumber(what string) (int, Token, error) {
+	tok := p.tok
+	if tok.Kind != NUMBER {
+		return 0, tok, p.unexpected(what)
+	}
+	n, err := strconv.ParseInt(tok.Text, 0, 64)
+	if err != nil {
+		return 0, tok, p.errorf(tok.Pos, "invalid %s %q", what, tok.Text)
+	}
+	return int(n), tok, p.advance()
+}
+
+func (p *parser) parseMessage() (*Message, error) {
+	msg := &Message{Doc: p.takeDoc()}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	name, err := p.expect(IDENT)
+	if err != nil {
+		return nil, err
+	}
+	msg.Pos = name.Pos
+	msg.Name = name.Text
+
+	if _, err := p.expect(LBRACE); err != nil {
+		return nil, err
+	}
+
+	for p.tok.Kind != RBRACE {
+		switch p.tok.Kind {
+		case SEMI:
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			continue
+		case IDENT:
+		default:
+			return nil, p.unexpected("field or '}'")
+		}
+
+		switch p.tok.Text {
+		case "message":
+			nested, err := p.parseMessage()
+			if err != nil {
+				return nil, err
+			}
+			msg.Messages = append(msg.Messages, nested)
+		case "enum":
+			enum, err := p.parseEnum()
+			if err != nil {
+				return nil, err
+			}
+			msg.Enums = append(msg.Enums, enum)
+		case "option":
+			opt, err := p.parseOption()
+			if err != nil {
+				return nil, err
+			}
+			msg.Options = append(msg.Options, opt)
+		case "oneof":
+			if err := p.parseOneof(msg); err != nil {
+				return nil, err
+			}
+		case "reserved":
+			if err := p.parseReserved(&msg.Reserved, &msg.ReservedNames); err != nil {
+				return nil, err
+			}
+		case "extensions":
+			if err := p.parseExtensions(msg); err != nil {
+				return nil, err
+			}
+		case "extend":
+			// Nested extensions do not change the message itself
+			if _, err := p.parseExtend(); err != nil {
+				return nil, err
+			}
+		case "group":
+			return nil, p.errorf(p.tok.Pos, "groups are not supported")
+		default:
+			field, err := p.parseField("")
+			if err != nil {
+				return nil, err
+			}
+			if err := addField(p, msg, field); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	msg.EndPos = p.tok.Pos
+	p.takeDoc()
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// addField appends a field after checking for clashes with earlier fields and reservations
+func addField(p *parser, msg *Message, field *Field) error {
+	for _, existing := range msg.Fields {
+		if existing.Name == field.Name {
+			return p.errorf(field.Pos, "duplicate field %q in message %s", field.Name, msg.Name)
+		}
+		if existing.Number == field.Number {
+			return p.errorf(field.Pos, "field %s reuses number %d of field %s in message %s", field.Name, field.Number, existing.Name, msg.Name)
+		}
+	}
+	if msg.IsReserved(field.Number) {
+		return p.errorf(field.Pos, "field %s uses reserved number %d in message %s", field.Name, field.Number, msg.Name)
+	}
+	for _, name := range msg.ReservedNames {
+		if name == field.Name {
+			return p.errorf(field.Pos, "field name %q is reserved in message %s", field.Name, msg.Name)
+		}
+	}
+	msg.Fields = append(msg.Fields, field)
+	return nil
+}
+
+// parseField parses a normal or map field; oneof is the enclosing oneof name, if any
+func (p *parser) parseField(oneof string) (*Field, error) {
+	field := &Field{Pos: p.tok.Pos, Oneof: oneof, Doc: p.takeDoc()}
+
+	if p.tok.Kind == IDENT && fieldLabels[p.tok.Text] && p.peek().Kind == IDENT {
+		if oneof != "" {
+			return nil, p.errorf(p.tok.Pos, "oneof field cannot have label %q", p.tok.Text)
+		}
+		field.Label = p.tok.Text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.tok.Kind == IDENT && p.tok.Text == "map" && p.peek().Kind == LANGLE {
+		if field.Label != "" || oneof != "" {
+			return nil, p.errorf(p.tok.Pos, "map fields cannot be %s", labelOrOneof(field.Label))
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		key, err := p.expect(IDENT)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(COMMA); err != nil {
+			return nil, err
+		}
+		value, err := p.expect(IDENT)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(RANGLE); err != nil {
+			return nil, err
+		}
+		field.KeyType = key.Text
+		field.Type = value.Text
+	} else {
+		typeTok, err := p.expect(IDENT)
+		if err != nil {
+			return nil, err
+		}
+		field.Type = typeTok.Text
+	}
+
+	name, err := p.expect(IDENT)
+	if err != nil {
+		return nil, err
+	}
+	field.Name = name.Text
+
+	if _, err := p.expect(ASSIGN); err != nil {
+		return nil, err
+	}
+	number, numberTok, err := p.parseNumber("field number")
+	if err != nil {
+		return nil, err
+	}
+	if number < 1 || number > MaxFieldNumber {
+		return nil, p.errorf(numberTok.Pos, "field number %d out of range 1 to %d", number, MaxFieldNumber)
+	}
+	field.Number = number
+
+	if field.Options, err = p.parseFieldOptions(); err != nil {
+		return nil, err
+	}
+	semi, err := p.expect(SEMI)
+	if err != nil {
+		return nil, err
+	}
+	field.Comment = p.takeLineComment(semi.Pos.Line)
+	return field, nil
+}
+
+func labelOrOneof(label string) string {
+	if label != "" {
+		return label
+	}
+	return "declared in a oneof"
+}
+
+func (p *parser) parseOneof(msg *Message) error {
+	p.takeDoc()
+	if err := p.advance(); err != nil {
+		return err
+	}
+	name, err := p.expect(IDENT)
+	if err != nil {
+		return err
+	}
+	oneof := &Oneof{Pos: name.Pos, Name: name.Text}
+
+	if _, err := p.expect(LBRACE); err != nil {
+		return err
+	}
+	for p.tok.Kind != RBRACE {
+		switch {
+		case p.tok.Kind == SEMI:
+			if err := p.advance(); err != nil {
+				return err
+			}
+		case p.tok.Kind == IDENT && p.tok.Text == "option":
+			opt, err := p.parseOption()
+			if err != nil {
+				return err
+			}
+			oneof.Options = append(oneof.Options, opt)
+		case p.tok.Kind == IDENT:
+			field, err := p.parseField(oneof.Name)
+			if err != nil {
+				return err
+			}
+			if err := addField(p, msg, field); err != nil {
+				return err
+			}
+			oneof.Fields = append(oneof.Fields, field.Name)
+		default:
+			return p.unexpected("oneof field or '}'")
+		}
+	}
+	p.takeDoc()
+	if err := p.advance(); err != nil {
+		return err
+	}
+
+	msg.Oneofs = append(msg.Oneofs, oneof)
+	return nil
+}
+
+// parseReserved parses reserved ranges or reserved names
+func (p *parser) parseReserved(ranges *[]Range, names *[]string) error {
+	p.takeDoc()
+	if err := p.advance(); err != nil {
+		return err
+	}
+
+	if p.tok.Kind == STRING || p.tok.Kind == IDENT {
+		for {
+			switch p.tok.Kind {
+			case STRING:
+				*names = append(*names, unquote(p.tok.Text))
+			case IDENT:
+				// Editions write reserved names as bare identifiers
+				*names = append(*names, p.tok.Text)
+			default:
+				return p.unexpected("reserved name")
+			}
+			if err := p.advance(); err != nil {
+				return err
+			}
+			if p.tok.Kind != COMMA {
+				break
+			}
+			if err := p.advance(); err != nil {
+				return err
+			}
+		}
+		_, err := p.expect(SEMI)
+		return err
+	}
+
+	parsed, err := p.parseRanges()
+	if err != nil {
+		return err
+	}
+	*ranges = append(*ranges, parsed...)
+	_, err = p.expect(SEMI)
+	return err
+}
+
+// parseRanges parses a comma-separated list of N or N to M ranges
+func (p *parser) parseRanges() ([]Range, error) {
+	var ranges []Range
+	for {
+		start, _, err := p.parseNumber("range start")
+		if err != nil {
+			return nil, err
+		}
+		r := Range{Start: start, End: start}
+		if p.tok.Kind == IDENT && p.tok.Text == "to" {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if p.tok.Kind == IDENT && p.tok.Text == "max" {
+				r.End = MaxFieldNumber
+				if err := p.advance(); err != nil {
+					return nil, err
+				}
+			} else {
+				end, endTok, err := p.parseNumber("range end")
+				if err != nil {
+					return nil, err
+				}
+				if end < start {
+					return nil, p.errorf(endTok.Pos, "range end %d is before start %d", end, start)
+				}
+				r.End = end
+			}
+		}
+		ranges = append(ranges, r)
+
+		if p.tok.Kind != COMMA {
+			return ranges, nil
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *parser) parseExtensions(msg *Message) error {
+	p.takeDoc()
+	if err := p.advance(); err != nil {
+		return err
+	}
+	ranges, err := p.parseRanges()
+	if err != nil {
+		return err
+	}
+	msg.Extensions = append(msg.Extensions, ranges...)
+	if _, err := p.parseFieldOptions(); err != nil {
+		return err
+	}
+	_, err = p.expect(SEMI)
+	return err
+}
+
+func (p *parser) parseExtend() (*Extend, error) {
+	p.takeDoc()
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	target, err := p.expect(IDENT)
+	if err != nil {
+		return nil, err
+	}
+	ext := &Extend{Pos: target.Pos, Target: target.Text}
+
+	if _, err := p.expect(LBRACE); err != nil {
+		return nil, err
+	}
+	for p.tok.Kind != RBRACE {
+		if p.tok.Kind == SEMI {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if p.tok.Kind != IDENT {
+			return nil, p.unexpected("field or '}'")
+		}
+		field, err := p.parseField("")
+		if err != nil {
+			return nil, err
+		}
+		ext.Fields = append(ext.Fields, field)
+	}
+	p.takeDoc()
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return ext, nil
+}
+
+func (p *parser) parseEnum() (*Enum, error) {
+	enum := &Enum{Doc: p.takeDoc()}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.expect(IDENT)
+	if err != nil {
+		return nil, err
+	}
+	enum.Pos = name.Pos
+	enum.Name = name.Text
+
+	if _, err := p.expect(LBRACE); err != nil {
+		return nil, err
+	}
+	for p.tok.Kind != RBRACE {
+		switch {
+		case p.tok.Kind == SEMI:
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		case p.tok.Kind == IDENT && p.tok.Text == "option" && p.peek().Kind != ASSIGN:
+			opt, err := p.parseOption()
+			if err != nil {
+				return nil, err
+			}
+			enum.Options = append(enum.Options, opt)
+		case p.tok.Kind == IDENT && p.tok.Text == "reserved" && p.peek().Kind != ASSIGN:
+			if err := p.parseReserved(&enum.Reserved, &enum.ReservedNames); err != nil {
+				return nil, err
+			}
+		case p.tok.Kind == IDENT:
+			value := &EnumValue{Pos: p.tok.Pos, Name: p.tok.Text, Doc: p.takeDoc()}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(ASSIGN); err != nil {
+				return nil, err
+			}
+			number, _, err := p.parseNumber("enum value")
+			if err != nil {
+				return nil, err
+			}
+			value.Number = number
+			if value.Options, err = p.parseFieldOptions(); err != nil {
+				return nil, err
+			}
+			semi, err := p.expect(SEMI)
+			if err != nil {
+				return nil, err
+			}
+			value.Comment = p.takeLineComment(semi.Pos.Line)
+			enum.Values = append(enum.Values, value)
+		default:
+			return nil, p.unexpected("enum value or '}'")
+		}
+	}
+	p.takeDoc()
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return enum, nil
+}
+
+func (p *parser) parseService() (*Service, error) {
+	svc := &Service{Doc: p.takeDoc()}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.expect(IDENT)
+	if err != nil {
+		return nil, err
+	}
+	svc.Pos = name.Pos
+	svc.Name = name.Text
+
+	if _, err := p.expect(LBRACE); err != nil {
+		return nil, err
+	}
+	for p.tok.Kind != RBRACE {
+		switch {
+		case p.tok.Kind == SEMI:
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		case p.tok.Kind == IDENT && p.tok.Text == "option":
+			opt, err := p.parseOption()
+			if err != nil {
+				return nil, err
+			}
+			svc.Options = append(svc.Options, opt)
+		case p.tok.Kind == IDENT && p.tok.Text == "rpc":
+			method, err := p.parseMethod()
+			if err != nil {
+				return nil, err
+			}
+			if svc.Method(method.Name) != nil {
+				return nil, p.errorf(method.Pos, "duplicate rpc %q in service %s", method.Name, svc.Name)
+			}
+			svc.Methods = append(svc.Methods, method)
+		case p.tok.Kind == EOF:
+			return nil, p.unexpected("'}'")
+		default:
+			return nil, p.errorf(p.tok.Pos, "unexpected %q in service, expected rpc or option", p.tok.Text)
+		}
+	}
+
+	svc.EndPos = p.tok.Pos
+	p.takeDoc()
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+func (p *parser) parseMethod() (*Method, error) {
+	method := &Method{Doc: p.takeDoc()}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.expect(IDENT)
+	if err != nil {
+		return nil, err
+	}
+	method.Pos = name.Pos
+	method.Name = name.Text
+
+	if method.Request, method.ClientStreaming, err = p.parseMethodType(); err != nil {
+		return nil, err
+	}
+	if err := p.expectIdent("returns"); err != nil {
+		return nil, err
+	}
+	if method.Response, method.ServerStreaming, err = p.parseMethodType(); err != nil {
+		return nil, err
+	}
+
+	endLine := p.tok.Pos.Line
+	switch p.tok.Kind {
+	case SEMI:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	case LBRACE:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		for p.tok.Kind != RBRACE {
+			switch {
+			case p.tok.Kind == SEMI:
+				if err := p.advance(); err != nil {
+					return nil, err
+				}
+			case p.tok.Kind == IDENT && p.tok.Text == "option":
+				opt, err := p.parseOption()
+				if err != nil {
+					return nil, err
+				}
+				method.Options = append(method.Options, opt)
+			default:
+				return nil, p.unexpected("option or '}'")
+			}
+		}
+		endLine = p.tok.Pos.Line
+		p.takeDoc()
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.Kind == SEMI {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, p.unexpected("';' or '{'")
+	}
+
+	method.Comment = p.takeLineComment(endLine)
+	return method, nil
+}
+
+// parseMethodType parses ( [stream] Type )
+func (p *parser) parseMethodType() (string, bool, error) {
+	if _, err := p.expect(LPAREN); err != nil {
+		return "", false, err
+	}
+	stream := false
+	if p.tok.Kind == IDENT && p.tok.Text == "stream" && p.peek().Kind == IDENT {
+		stream = true
+		if err := p.advance(); err != nil {
+			return "", false, err
+		}
+	}
+	typeTok, err := p.expect(IDENT)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := p.expect(RPAREN); err != nil {
+		return "", false, err
+	}
+	return typeTok.Text, stream, nil
+}
@@ -0,0 +1,259 @@
+package protospec_test
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/protospec"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestParseGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*", "*.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no testdata .proto files found")
+	}
+
+	for _, file := range files {
+		name := filepath.ToSlash(strings.TrimPrefix(file, "testdata"+string(filepath.Separator)))
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ast, err := protospec.Parse(name, src)
+			if err != nil {
+				t.Fatalf("Parse(%s) failed: %v", name, err)
+			}
+
+			got, err := json.MarshalIndent(ast, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := strings.TrimSuffix(file, ".proto") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file (run with -update to create): %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("AST for %s does not match %s (run with -update to refresh):\n%s", name, golden, got)
+			}
+		})
+	}
+}
+
+func TestLoadResolvesIncludePaths(t *testing.T) {
+	spec, err := protospec.Load(filepath.Join("testdata", "user", "user.proto"), []string{"testdata"}, nil)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if len(spec.Files) != 2 {
+		t.Fatalf("Expected 2 files (root + common/types.proto), got %d", len(spec.Files))
+	}
+	if len(spec.Unresolved) != 1 || spec.Unresolved[0] != "google/protobuf/timestamp.proto" {
+		t.Errorf("Unresolved = %v, want [google/protobuf/timestamp.proto]", spec.Unresolved)
+	}
+
+	root := spec.Root
+	if root.Package != "user.v1" || root.GoPackage() != "./user" {
+		t.Errorf("Package = %q, GoPackage() = %q", root.Package, root.GoPackage())
+	}
+	if len(root.Services) != 2 {
+		t.Fatalf("Expected 2 services, got %d", len(root.Services))
+	}
+
+	getUser := root.Services[0].Method("GetUser")
+	if getUser == nil {
+		t.Fatal("GetUser method not found")
+	}
+	if got := getUser.Option("(google.api.http)"); !strings.Contains(got, `get: "/v1/users/{id}"`) {
+		t.Errorf("(google.api.http) option = %q", got)
+	}
+	if getUser.Doc == nil || getUser.Doc[0] != "// GetUser returns a single user" {
+		t.Errorf("GetUser doc = %v", getUser.Doc)
+	}
+	if got := root.Services[0].Method("ListUsers").Comment; got != "// paginated" {
+		t.Errorf("ListUsers comment = %q", got)
+	}
+
+	events := root.Services[1]
+	streaming := map[string][2]bool{
+		"Subscribe": {false, true},
+		"Publish":   {true, false},
+		"Chat":      {true, true},
+	}
+	for name, want := range streaming {
+		m := events.Method(name)
+		if m == nil {
+			t.Fatalf("%s method not found", name)
+		}
+		if m.ClientStreaming != want[0] || m.ServerStreaming != want[1] {
+			t.Errorf("%s streaming = (%v, %v), want %v", name, m.ClientStreaming, m.ServerStreaming, want)
+		}
+	}
+
+	if spec.Message("common.PageReq") == nil || spec.Message("User.Address") == nil {
+		t.Error("Expected imported and nested messages to be resolvable")
+	}
+	if got := len(spec.Messages()); got != 8 {
+		t.Errorf("Expected 8 messages across files, got %d", got)
+	}
+	if got := len(spec.Enums()); got != 2 {
+		t.Errorf("Expected 2 enums, got %d", got)
+	}
+
+	user := spec.Message("user.v1.User")
+	if user == nil {
+		t.Fatal("User message not found")
+	}
+	if f := user.Field("labels"); f == nil || !f.IsMap() || f.TypeString() != "map<string, string>" {
+		t.Errorf("labels field = %+v", f)
+	}
+	if f := user.Field("phone"); f == nil || f.Oneof != "contact" {
+		t.Errorf("phone field = %+v", f)
+	}
+	if !user.IsReserved(25) || user.IsReserved(12) {
+		t.Error("IsReserved() does not match reserved ranges")
+	}
+	if got := spec.FileOf(spec.Message("common.Empty")); got == nil || !strings.HasSuffix(got.Path, "types.proto") {
+		t.Errorf("FileOf(common.Empty) = %v", got)
+	}
+}
+
+func TestLoadMissingImport(t *testing.T) {
+	_, err := protospec.Load(filepath.Join("testdata", "user", "user.proto"), nil, nil)
+	if err == nil {
+		t.Fatal("Expected error when common/types.proto is outside the include paths")
+	}
+	if !strings.Contains(err.Error(), "user.proto:6:8: import \"common/types.proto\" not found") {
+		t.Errorf("Expected error to point at the import, got: %v", err)
+	}
+}
+
+func TestLoadRefusedImport(t *testing.T) {
+	var checked []string
+	_, err := protospec.Load(filepath.Join("testdata", "user", "user.proto"), []string{"testdata"}, func(path string) error {
+		checked = append(checked, path)
+		return errors.New("not allowed")
+	})
+	if err == nil || !strings.Contains(err.Error(), "user.proto:6:8: cannot import \"common/types.proto\": not allowed") {
+		t.Errorf("Expected refused import error, got: %v", err)
+	}
+	if len(checked) != 1 || !strings.HasSuffix(checked[0], filepath.Join("common", "types.proto")) {
+		t.Errorf("checkImport got %v, want only the import", checked)
+	}
+}
+
+func TestLoadUndefinedMethodType(t *testing.T) {
+	dir := t.TempDir()
+	protoFile := filepath.Join(dir, "a.proto")
+	content := "syntax = \"proto3\";\n\nmessage Req {}\n\nservice A {\n  rpc Do(Req) returns (Resp);\n}\n"
+	if err := os.WriteFile(protoFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := protospec.Load(protoFile, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "a.proto:6:7: rpc Do uses undefined message \"Resp\"") {
+		t.Errorf("Expected undefined message error, got: %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{
+			name:    "unsupported syntax",
+			src:     "syntax = \"proto4\";\n",
+			wantErr: "test.proto:1:10: unsupported syntax \"proto4\", expected proto2 or proto3",
+		},
+		{
+			name:    "missing semicolon",
+			src:     "syntax = \"proto3\"\npackage a;\n",
+			wantErr: "test.proto:2:1: expected ';', found \"package\"",
+		},
+		{
+			name:    "duplicate field number",
+			src:     "message A {\n  string a = 1;\n  string b = 1;\n}\n",
+			wantErr: "test.proto:3:3: field b reuses number 1 of field a in message A",
+		},
+		{
+			name:    "reserved field number",
+			src:     "message A {\n  reserved 2 to 4;\n  string a = 3;\n}\n",
+			wantErr: "test.proto:3:3: field a uses reserved number 3 in message A",
+		},
+		{
+			name:    "field number out of range",
+			src:     "message A {\n  string a = 0;\n}\n",
+			wantErr: "test.proto:2:14: field number 0 out of range 1 to 536870911",
+		},
+		{
+			name:    "label in oneof",
+			src:     "message A {\n  oneof x {\n    repeated string a = 1;\n  }\n}\n",
+			wantErr: "test.proto:3:5: oneof field cannot have label \"repeated\"",
+		},
+		{
+			name:    "unterminated message",
+			src:     "message A {\n  string a = 1;\n",
+			wantErr: "test.proto:3:1: expected field or '}', found end of file",
+		},
+		{
+			name:    "garbage in service",
+			src:     "service S {\n  message A {}\n}\n",
+			wantErr: "test.proto:2:3: unexpected \"message\" in service, expected rpc or option",
+		},
+		{
+			name:    "duplicate rpc",
+			src:     "service S {\n  rpc A(R) returns (R);\n  rpc A(R) returns (R);\n}\n",
+			wantErr: "test.proto:3:7: duplicate rpc \"A\" in service S",
+		},
+		{
+			name:    "missing returns",
+			src:     "service S {\n  rpc A(R) (R);\n}\n",
+			wantErr: "test.proto:2:12: expected \"returns\", found \"(\"",
+		},
+		{
+			name:    "unknown top-level",
+			src:     "syntax = \"proto3\";\nrpc A(R) returns (R);\n",
+			wantErr: "test.proto:2:1: unexpected \"rpc\", expected syntax, package, import, option, message, enum, service or extend",
+		},
+		{
+			name:    "unterminated string",
+			src:     "import \"a.proto;\n",
+			wantErr: "test.proto:1:8: string literal not terminated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := protospec.Parse("test.proto", []byte(tt.src))
+			if err == nil {
+				t.Fatalf("Expected error %q, got none", tt.wantErr)
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("Parse() error = %q, want %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
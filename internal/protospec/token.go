@@ -0,0 +1,284 @@
+package protospec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind identifies the lexical class of a token
+type TokenKind int
+
+const (
+	EOF     TokenKind = iota
+	IDENT             // identifiers and dotted names such as google.protobuf.Empty
+	STRING            // "double" or 'single' quoted
+	NUMBER            // integer or float literal, optionally signed
+	COMMENT           // // line or /* block */ comment
+	LPAREN
+	RPAREN
+	LBRACE
+	RBRACE
+	LBRACK
+	RBRACK
+	LANGLE
+	RANGLE
+	SEMI
+	COMMA
+	COLON
+	ASSIGN
+)
+
+var tokenNames = map[TokenKind]string{
+	EOF:     "end of file",
+	IDENT:   "identifier",
+	STRING:  "string",
+	NUMBER:  "number",
+	COMMENT: "comment",
+	LPAREN:  "'('",
+	RPAREN:  "')'",
+	LBRACE:  "'{'",
+	RBRACE:  "'}'",
+	LBRACK:  "'['",
+	RBRACK:  "']'",
+	LANGLE:  "'<'",
+	RANGLE:  "'>'",
+	SEMI:    "';'",
+	COMMA:   "','",
+	COLON:   "':'",
+	ASSIGN:  "'='",
+}
+
+func (k TokenKind) String() string {
+	if name, ok := tokenNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("token(%d)", int(k))
+}
+
+// Pos is a position in a source file, 1-based
+type Pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Token is a lexical token
+type Token struct {
+	Kind   TokenKind
+	Text   string // raw source text of the token
+	Pos    Pos
+	Offset int // byte offset of the token start
+	End    int // byte offset just past the token
+}
+
+// lexer splits .proto source into tokens
+type lexer struct {
+	filename string
+	src      []byte
+	offset   int
+	line     int
+	column   int
+}
+
+func newLexer(filename string, src []byte) *lexer {
+	return &lexer{filename: filename, src: src, line: 1, column: 1}
+}
+
+func (l *lexer) errorf(pos Pos, format string, args ...interface{}) error {
+	return &Error{Filename: l.filename, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) pos() Pos {
+	return Pos{Line: l.line, Column: l.column}
+}
+
+func (l *lexer) peekRune() rune {
+	if l.offset >= len(l.src) {
+		return -1
+	}
+	r, _ := utf8.DecodeRune(l.src[l.offset:])
+	return r
+}
+
+func (l *lexer) peekRuneAt(n int) rune {
+	off := l.offset
+	for i := 0; i < n; i++ {
+		if off >= len(l.src) {
+			return -1
+		}
+		_, size := utf8.DecodeRune(l.src[off:])
+		off += size
+	}
+	if off >= len(l.src) {
+		return -1
+	}
+	r, _ := utf8.DecodeRune(l.src[off:])
+	return r
+}
+
+func (l *lexer) advance() rune {
+	if l.offset >= len(l.src) {
+		return -1
+	}
+	r, size := utf8.DecodeRune(l.src[l.offset:])
+	l.offset += size
+	if r == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+	return r
+}
+
+func (l *lexer) skipSpace() {
+	for {
+		r := l.peekRune()
+		if r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == '\f' || r == '\v' || r == '\uFEFF' {
+			l.advance()
+			continue
+		}
+		return
+	}
+}
+
+// next returns the next token, including comments
+func (l *lexer) next() (Token, error) {
+	l.skipSpace()
+
+	start := l.offset
+	pos := l.pos()
+	tok := Token{Pos: pos, Offset: start}
+
+	r := l.peekRune()
+	switch {
+	case r == -1:
+		tok.Kind = EOF
+	case r == '/' && l.peekRuneAt(1) == '/':
+		for r := l.peekRune(); r != -1 && r != '\n'; r = l.peekRune() {
+			l.advance()
+		}
+		tok.Kind = COMMENT
+	case r == '/' && l.peekRuneAt(1) == '*':
+		l.advance()
+		l.advance()
+		closed := false
+		for r := l.peekRune(); r != -1; r = l.peekRune() {
+			l.advance()
+			if r == '*' && l.peekRune() == '/' {
+				l.advance()
+				closed = true
+				break
+			}
+		}
+		if !closed {
+			return tok, l.errorf(pos, "comment not terminated")
+		}
+		tok.Kind = COMMENT
+	case r == '"' || r == '\'':
+		quote := l.advance()
+		for {
+			r := l.advance()
+			if r == -1 || r == '\n' {
+				return tok, l.errorf(pos, "string literal not terminated")
+			}
+			if r == '\\' {
+				l.advance()
+				continue
+			}
+			if r == quote {
+				break
+			}
+		}
+		tok.Kind = STRING
+	case isIdentStart(r) || (r == '.' && isIdentStart(l.peekRuneAt(1))):
+		l.advance()
+		for r := l.peekRune(); isIdentPart(r) || (r == '.' && isIdentStart(l.peekRuneAt(1))); r = l.peekRune() {
+			l.advance()
+		}
+		tok.Kind = IDENT
+	case unicode.IsDigit(r) || ((r == '-' || r == '+' || r == '.') && unicode.IsDigit(l.peekRuneAt(1))):
+		l.advance()
+		for {
+			r := l.peekRune()
+			prev := l.src[l.offset-1]
+			if unicode.IsDigit(r) || unicode.IsLetter(r) || r == '.' || ((r == '-' || r == '+') && (prev == 'e' || prev == 'E')) {
+				l.advance()
+				continue
+			}
+			break
+		}
+		tok.Kind = NUMBER
+	case r == '-' && isIdentStart(l.peekRuneAt(1)):
+		// -inf and -nan
+		l.advance()
+		for r := l.peekRune(); isIdentPart(r); r = l.peekRune() {
+			l.advance()
+		}
+		tok.Kind = NUMBER
+	default:
+		l.advance()
+		switch r {
+		case '(':
+			tok.Kind = LPAREN
+		case ')':
+			tok.Kind = RPAREN
+		case '{':
+			tok.Kind = LBRACE
+		case '}':
+			tok.Kind = RBRACE
+		case '[':
+			tok.Kind = LBRACK
+		case ']':
+			tok.Kind = RBRACK
+		case '<':
+			tok.Kind = LANGLE
+		case '>':
+			tok.Kind = RANGLE
+		case ';':
+			tok.Kind = SEMI
+		case ',':
+			tok.Kind = COMMA
+		case ':':
+			tok.Kind = COLON
+		case '=':
+			tok.Kind = ASSIGN
+		default:
+			return tok, l.errorf(pos, "unexpected character %q", r)
+		}
+	}
+
+	tok.End = l.offset
+	tok.Text = string(l.src[start:l.offset])
+	return tok, nil
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// unquote returns the value of a single or double quoted string literal
+func unquote(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+	body := s[1 : len(s)-1]
+	if s[0] == '\'' {
+		body = strings.ReplaceAll(body, `\'`, `'`)
+		body = strings.ReplaceAll(body, `"`, `\"`)
+	}
+	if value, err := strconv.Unquote(`"` + body + `"`); err == nil {
+		return value
+	}
+	return body
+}
@@ -34,6 +34,20 @@ func FormatError(message string) (*mcp.CallToolResult, any, error) {
 	}, nil, fmt.Errorf("%s", message)
 }
 
+// FormatErrorWithData reports a failure along with structured data, such as diagnostics,
+// the caller can act on. The error is returned in the result rather than as a Go error,
+// which would drop the data
+func FormatErrorWithData(message string, data any) (*mcp.CallToolResult, any, error) {
+	fullMessage := fmt.Sprintf("Error: %s", message)
+	if jsonData, err := json.MarshalIndent(data, "", "  "); err == nil {
+		fullMessage += "\n\n" + string(jsonData)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: fullMessage}},
+		IsError: true,
+	}, data, nil
+}
+
 func FormatValidationError(field, value, reason, suggestion string) (*mcp.CallToolResult, any, error) {
 	message := fmt.Sprintf("Validation Error\n\nField: %s\nValue: %s\nReason: %s", field, value, reason)
 	if suggestion != "" {
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the server configuration file passed with -config
+type Config struct {
+	Roots []string `yaml:"roots"` // directories tools may read and write; relative to the file
+}
+
+// LoadConfig reads a server configuration file
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server config: %w", err)
+	}
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse server config %s: %w", path, err)
+	}
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	for i, root := range config.Roots {
+		if !filepath.IsAbs(root) {
+			config.Roots[i] = filepath.Join(dir, root)
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// rootsTimeout bounds how long a tool call waits for a client to list its roots
+const rootsTimeout = 5 * time.Second
+
+// clientRoots asks each connected client for its roots before its first tool call, and again
+// after it reports a change. A session's roots narrow the server's roots for its own tool
+// calls only, so one client never widens or narrows what another may touch
+type clientRoots struct {
+	server *mcp.Server
+
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession][]string // roots listed by each session; nil when it has none
+}
+
+func newClientRoots() *clientRoots {
+	return &clientRoots{sessions: make(map[*mcp.ServerSession][]string)}
+}
+
+// middleware runs each tool with the calling session's roots in its context
+func (c *clientRoots) middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method == "tools/call" {
+			if session, ok := req.GetSession().(*mcp.ServerSession); ok {
+				ctx = validation.WithClientRoots(ctx, c.refresh(ctx, session))
+			}
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// changed forgets a session's roots so they are listed again before its next tool call
+func (c *clientRoots) changed(ctx context.Context, req *mcp.RootsListChangedRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.sessions, req.Session)
+}
+
+// refresh returns the session's roots, listing them unless they are known, and forgets
+// sessions that have closed. A client that cannot list roots has none; roots that are not
+// local file URIs are kept as listed, so the roots check rejects them instead of ignoring them
+func (c *clientRoots) refresh(ctx context.Context, session *mcp.ServerSession) []string {
+	c.mu.Lock()
+	roots, known := c.sessions[session]
+	c.mu.Unlock()
+	if known {
+		return roots
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, rootsTimeout)
+	defer cancel()
+	if result, err := session.ListRoots(listCtx, &mcp.ListRootsParams{}); err == nil {
+		for _, root := range result.Roots {
+			if path, ok := rootPath(root.URI); ok {
+				roots = append(roots, path)
+			} else {
+				roots = append(roots, root.URI)
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sessions[session] = roots
+	live := make(map[*mcp.ServerSession]bool)
+	for s := range c.server.Sessions() {
+		live[s] = true
+	}
+	for s := range c.sessions {
+		if !live[s] && s != session {
+			delete(c.sessions, s)
+		}
+	}
+	return roots
+}
+
+// rootPath converts a file:// root URI to a path
+func rootPath(uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" || (u.Host != "" && u.Host != "localhost") {
+		return "", false
+	}
+	path := u.Path
+	// file:///C:/work has the path /C:/work
+	if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		return "", false
+	}
+	return path, true
+}
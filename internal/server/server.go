@@ -0,0 +1,160 @@
+package server
+
+import (
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+// NewServer creates the MCP server with every mcp-zero tool registered
+// The same server is shared by all transports so tools behave identically
+func NewServer(name, version string) *mcp.Server {
+	roots := newClientRoots()
+	server := mcp.NewServer(&mcp.Implementation{
+		Name:    name,
+		Version: version,
+	}, &mcp.ServerOptions{
+		RootsListChangedHandler: roots.changed,
+	})
+	roots.server = server
+	server.AddReceivingMiddleware(roots.middleware)
+
+	RegisterTools(server)
+
+	return server
+}
+
+// RegisterTools registers all mcp-zero tools on the given server
+func RegisterTools(server *mcp.Server) {
+	// Register create_api_service tool (T034 - User Story 1)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "create_api_service",
+		Description: "Create a new go-zero API service with proper structure and configuration",
+	}, tools.CreateAPIService)
+
+	// Register generate_api_from_spec tool (T047 - User Story 2)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "generate_api_from_spec",
+		Description: "Generate go-zero API code from API specification file",
+	}, tools.GenerateAPIFromSpec)
+
+	// Register create_rpc_service tool (T058 - User Story 3)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "create_rpc_service",
+		Description: "Create a new go-zero RPC service with protobuf definition",
+	}, tools.CreateRPCService)
+
+	// Register generate_model tool (T071 - User Story 4)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "generate_model",
+		Description: "Generate go-zero database model from table schema",
+	}, tools.GenerateModel)
+
+	// Register create_api_spec tool (T081 - User Story 5)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "create_api_spec",
+		Description: "Create a sample API specification file for go-zero. IMPORTANT: Always define concrete types for request and response - do NOT use 'any' type in .api files as it's not supported by go-zero",
+	}, tools.CreateAPISpec)
+
+	// Register analyze_project tool (T097 - User Story 6)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "analyze_project",
+		Description: "Analyze existing go-zero project structure and dependencies",
+	}, tools.AnalyzeProject)
+
+	// Register validate_config tool (T109 - User Story 7)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "validate_config",
+		Description: "Validate go-zero service configuration file",
+	}, tools.ValidateConfig)
+
+	// Register generate_config_template tool (T109 - User Story 7)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "generate_config_template",
+		Description: "Generate configuration template for go-zero service",
+	}, tools.GenerateConfigTemplate)
+
+	// Register update_config tool (path-based config edits)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "update_config",
+		Description: "Set keys of a go-zero YAML config by path (Port, Prometheus.Port, Etcd.Key), adding missing keys and keeping comments and layout; picks the etc/ file matching the service name",
+	}, tools.UpdateConfig)
+
+	// Register migrate_style tool (goctl naming style migration)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "migrate_style",
+		Description: "Rename generated handler, logic, types, svc, config and server files from one goctl naming style (go_zero, gozero, goZero or a custom format) to another, reporting mixed styles and rolling back if the build breaks",
+	}, tools.MigrateStyle)
+
+	// Register generate_template tool (T123 - User Story 8)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "generate_template",
+		Description: "Generate common code templates (middleware, error handlers, deployment configs)",
+	}, tools.GenerateTemplate)
+
+	// Register add_api_endpoint tool (incremental route addition)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "add_api_endpoint",
+		Description: "Add a route and its request/response types to an existing .api file, then regenerate the service with goctl without overwriting hand-written logic",
+	}, tools.AddAPIEndpoint)
+
+	// Register add_rpc_method tool (incremental rpc addition)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "add_rpc_method",
+		Description: "Add an rpc and its request/response messages to the .proto file of an existing RPC service, then regenerate the pb/grpc code with goctl without overwriting hand-written logic",
+	}, tools.AddRPCMethod)
+
+	// Register export_openapi tool (OpenAPI 3 export)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "export_openapi",
+		Description: "Export a go-zero .api specification as an OpenAPI 3.0 or 3.1 document in JSON or YAML, including parameters, validation rules, jwt security and tags",
+	}, tools.ExportOpenAPI)
+
+	// Register import_openapi tool (OpenAPI/Swagger import)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "import_openapi",
+		Description: "Convert an OpenAPI 3 or Swagger 2 document into a go-zero .api specification, grouping routes by tag and reporting constructs that cannot be represented; optionally generate code from it",
+	}, tools.ImportOpenAPI)
+
+	// Register diff_contract tool (breaking-change detection)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "diff_contract",
+		Description: "Compare two versions of an .api or .proto file, or a file against a git revision, and report breaking changes such as removed routes, changed paths, retyped fields, changed json tags, new required fields, removed RPCs and reused field numbers",
+	}, tools.DiffContract)
+
+	// Register lint_api_spec tool (.api linting)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "lint_api_spec",
+		Description: "Lint an .api specification for undefined and unused types, duplicate handlers and routes, any/interface{} fields, missing json tags, unbound path parameters, handler naming and missing docs; rules can be toggled in .mcp-zero.yaml",
+	}, tools.LintAPISpec)
+
+	// Register format_api_spec tool (.api formatting)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "format_api_spec",
+		Description: "Format an .api file in place with aligned fields and tags, normalized blocks and preserved comments, like goctl api format; check mode returns a unified diff without writing",
+	}, tools.FormatAPISpec)
+
+	// Register query_docs tool (T134 - User Story 9)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "query_docs",
+		Description: "Query go-zero framework documentation and migration guides",
+	}, tools.QueryDocs)
+
+	// Register doctor tool (goctl version and compatibility)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "doctor",
+		Description: "Report the goctl binary in use, its version and which goctl features it supports, with upgrade hints for releases mcp-zero refuses or gates",
+	}, tools.Doctor)
+
+	// Register environment_doctor tool (toolchain checks)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "environment_doctor",
+		Description: "Check the whole toolchain before generating: go version, goctl path and version, protoc, protoc-gen-go, protoc-gen-go-grpc, GOPATH/GOMODCACHE, GOPROXY, workspace write access and go-zero in the module cache; returns a pass/warn/fail table with the command that fixes each problem, and which gated goctl features the installed release supports",
+	}, tools.EnvironmentDoctor)
+
+	// Register manage_goctl_templates tool (project template homes)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "manage_goctl_templates",
+		Description: "Manage a project's goctl template home (a .goctl directory or templates.home in .mcp-zero.yaml): init copies goctl's default templates, diff shows customized, missing and outdated templates, update takes new defaults after a goctl upgrade while keeping customized templates",
+	}, tools.ManageGoctlTemplates)
+}
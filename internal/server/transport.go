@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Supported transports
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http" // MCP streamable HTTP transport
+	TransportSSE   = "sse"  // legacy HTTP+SSE transport (2024-11-05)
+)
+
+// DefaultAddr is the listen address used by the HTTP transports when none is given
+const DefaultAddr = "127.0.0.1:8080"
+
+// shutdownTimeout bounds how long in-flight HTTP requests may take to drain
+const shutdownTimeout = 10 * time.Second
+
+// TransportOptions configures how the MCP server is exposed
+type TransportOptions struct {
+	Transport string // "stdio", "http" or "sse"
+	Addr      string // listen address for http/sse
+	AuthToken string // optional bearer token required on every HTTP request
+}
+
+// ValidateTransport checks that the transport name is supported
+func ValidateTransport(transport string) error {
+	switch transport {
+	case TransportStdio, TransportHTTP, TransportSSE:
+		return nil
+	default:
+		return fmt.Errorf("unsupported transport %q (use %q, %q or %q)", transport, TransportStdio, TransportHTTP, TransportSSE)
+	}
+}
+
+// NewHTTPHandler returns an http.Handler serving the MCP server over the given HTTP transport
+// When authToken is non-empty, requests must carry "Authorization: Bearer <token>"
+func NewHTTPHandler(server *mcp.Server, transport string, authToken string) (http.Handler, error) {
+	getServer := func(*http.Request) *mcp.Server { return server }
+
+	var handler http.Handler
+	switch transport {
+	case TransportHTTP:
+		handler = mcp.NewStreamableHTTPHandler(getServer, nil)
+	case TransportSSE:
+		handler = mcp.NewSSEHandler(getServer, nil)
+	default:
+		return nil, fmt.Errorf("transport %q is not served over HTTP", transport)
+	}
+
+	if authToken != "" {
+		handler = auth.RequireBearerToken(staticTokenVerifier(authToken), nil)(handler)
+	}
+
+	return handler, nil
+}
+
+// Serve runs the MCP server on the configured transport until ctx is cancelled
+// HTTP transports are shut down gracefully, letting in-flight tool calls finish
+func Serve(ctx context.Context, server *mcp.Server, opts TransportOptions) error {
+	if opts.Transport == "" {
+		opts.Transport = TransportStdio
+	}
+	if err := ValidateTransport(opts.Transport); err != nil {
+		return err
+	}
+
+	if opts.Transport == TransportStdio {
+		err := server.Run(ctx, &mcp.StdioTransport{})
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+
+	handler, err := NewHTTPHandler(server, opts.Transport, opts.AuthToken)
+	if err != nil {
+		return err
+	}
+
+	addr := opts.Addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	return serveHTTP(ctx, listener, handler)
+}
+
+// serveHTTP serves handler on listener and shuts down when ctx is done
+func serveHTTP(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		// Long-lived SSE streams never go idle on their own, so force-close them
+		httpServer.Close()
+	}
+
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// staticTokenVerifier accepts only the configured bearer token
+func staticTokenVerifier(expected string) auth.TokenVerifier {
+	return func(ctx context.Context, token string, req *http.Request) (*auth.TokenInfo, error) {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			return nil, auth.ErrInvalidToken
+		}
+		// RequireBearerToken rejects tokens whose expiration is in the past
+		return &auth.TokenInfo{Expiration: time.Now().Add(time.Hour)}, nil
+	}
+}
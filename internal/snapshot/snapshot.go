@@ -0,0 +1,281 @@
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Snapshot records the content of every file under a directory at one point in time
+type Snapshot struct {
+	Root  string
+	files map[string][]byte // keyed by slash-separated path relative to Root
+	modes map[string]os.FileMode
+	dirs  map[string]bool // directories that existed, "." for Root
+	only  []string        // set by TakeFiles to restrict the snapshot to these paths
+}
+
+// MaxSize bounds the bytes a snapshot reads into memory; Take refuses larger trees
+var MaxSize int64 = 256 << 20
+
+// skippedDirs are never written by a generation step, and can be far larger than the code
+var skippedDirs = map[string]bool{
+	".git":         true,
+	".hg":          true,
+	".svn":         true,
+	"vendor":       true,
+	"node_modules": true,
+}
+
+// TooLargeError reports a tree whose files exceed MaxSize
+type TooLargeError struct {
+	Root  string
+	Limit int64
+}
+
+func (e *TooLargeError) Error() string {
+	return fmt.Sprintf("%s holds more than %d MiB of files, too much to snapshot for rollback; generate into a smaller directory or move large files out of it", e.Root, e.Limit>>20)
+}
+
+// Changes lists files that differ between two snapshots, relative to the root
+type Changes struct {
+	Created  []string `json:"created,omitempty"`
+	Modified []string `json:"modified,omitempty"`
+	Deleted  []string `json:"deleted,omitempty"`
+}
+
+// Empty reports whether no file changed
+func (c *Changes) Empty() bool {
+	return len(c.Created) == 0 && len(c.Modified) == 0 && len(c.Deleted) == 0
+}
+
+// Take reads every regular file under root, skipping version control, vendor and
+// node_modules directories. A missing root yields an empty snapshot, and a tree over
+// MaxSize a *TooLargeError
+func Take(root string) (*Snapshot, error) {
+	s := &Snapshot{
+		Root:  root,
+		files: make(map[string][]byte),
+		modes: make(map[string]os.FileMode),
+		dirs:  make(map[string]bool),
+	}
+
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return s, nil
+	}
+
+	var size int64
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && skippedDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			s.dirs[filepath.ToSlash(rel)] = true
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if size += info.Size(); size > MaxSize {
+			return &TooLargeError{Root: root, Limit: MaxSize}
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		s.files[rel] = content
+		s.modes[rel] = info.Mode().Perm()
+		return nil
+	})
+	var tooLarge *TooLargeError
+	if errors.As(err, &tooLarge) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot %s: %w", root, err)
+	}
+
+	return s, nil
+}
+
+// TakeFiles records only the named files under root, such as go.mod and go.sum
+// Missing files are recorded as absent, so Rollback removes them if they appear
+func TakeFiles(root string, rels ...string) (*Snapshot, error) {
+	s := &Snapshot{
+		Root:  root,
+		files: make(map[string][]byte),
+		modes: make(map[string]os.FileMode),
+		dirs:  make(map[string]bool),
+	}
+	for _, rel := range rels {
+		rel = filepath.ToSlash(rel)
+		s.only = append(s.only, rel)
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s: %w", path, err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s: %w", path, err)
+		}
+		s.files[rel] = content
+		s.modes[rel] = info.Mode().Perm()
+	}
+	return s, nil
+}
+
+// Retake snapshots the same root, or the same files for a TakeFiles snapshot, again
+func (s *Snapshot) Retake() (*Snapshot, error) {
+	if s.only != nil {
+		return TakeFiles(s.Root, s.only...)
+	}
+	return Take(s.Root)
+}
+
+// Files returns the relative paths of all files in the snapshot, sorted
+func (s *Snapshot) Files() []string {
+	paths := make([]string, 0, len(s.files))
+	for path := range s.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Content returns the recorded content of a relative path
+func (s *Snapshot) Content(rel string) ([]byte, bool) {
+	content, ok := s.files[filepath.ToSlash(rel)]
+	return content, ok
+}
+
+// Compare returns the files created, modified and deleted between s and after
+func (s *Snapshot) Compare(after *Snapshot) *Changes {
+	changes := &Changes{}
+	for _, path := range after.Files() {
+		before, ok := s.files[path]
+		switch {
+		case !ok:
+			changes.Created = append(changes.Created, path)
+		case !bytes.Equal(before, after.files[path]):
+			changes.Modified = append(changes.Modified, path)
+		}
+	}
+	for _, path := range s.Files() {
+		if _, ok := after.files[path]; !ok {
+			changes.Deleted = append(changes.Deleted, path)
+		}
+	}
+	return changes
+}
+
+// Restore writes the recorded content of a relative path back to disk
+func (s *Snapshot) Restore(rel string) error {
+	rel = filepath.ToSlash(rel)
+	content, ok := s.files[rel]
+	if !ok {
+		return fmt.Errorf("%s is not in the snapshot", rel)
+	}
+	path := filepath.Join(s.Root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, s.modes[rel])
+}
+
+// CopyTo recreates the recorded directories and files under dir, keeping their modes
+func (s *Snapshot) CopyTo(dir string) error {
+	for rel := range s.dirs {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.FromSlash(rel)), 0755); err != nil {
+			return err
+		}
+	}
+	for rel, content := range s.files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, s.modes[rel]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollback returns Root to the recorded state and reports what was undone
+// Created files are removed, modified and deleted files are written back, and
+// directories that did not exist are removed once empty, including Root itself
+func (s *Snapshot) Rollback() (*Changes, error) {
+	current, err := s.Retake()
+	if err != nil {
+		return nil, err
+	}
+	changes := s.Compare(current)
+
+	var errs []error
+	for _, rel := range changes.Created {
+		if err := os.Remove(filepath.Join(s.Root, filepath.FromSlash(rel))); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	for _, rel := range append(append([]string{}, changes.Modified...), changes.Deleted...) {
+		if err := s.Restore(rel); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", rel, err))
+		}
+	}
+
+	// Deepest directories first so parents are empty by the time they are removed
+	var created []string
+	for dir := range current.dirs {
+		if !s.dirs[dir] {
+			created = append(created, dir)
+		}
+	}
+	sort.Slice(created, func(i, j int) bool { return depth(created[i]) > depth(created[j]) })
+	for _, dir := range created {
+		// Directories still holding untracked entries such as .git are left alone
+		os.Remove(filepath.Join(s.Root, filepath.FromSlash(dir)))
+	}
+
+	return changes, errors.Join(errs...)
+}
+
+// depth counts the path elements of a relative directory; Root has depth zero
+func depth(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
+
+// Under filters paths to those inside the slash-separated directory dir
+func Under(paths []string, dir string) []string {
+	dir = strings.TrimSuffix(filepath.ToSlash(dir), "/") + "/"
+	var matched []string
+	for _, path := range paths {
+		if strings.HasPrefix(path, dir) {
+			matched = append(matched, path)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,237 @@
+package snapshot_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
+)
+
+func TestCompareAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("keep.go", "package a\n")
+	write("internal/logic/edit.go", "package logic\n")
+	write("gone.go", "package a\n")
+	write(".git/HEAD", "ref\n")
+
+	before, err := snapshot.Take(dir)
+	if err != nil {
+		t.Fatalf("Take() failed: %v", err)
+	}
+
+	write("internal/logic/edit.go", "package logic\n\n// changed\n")
+	write("internal/logic/new.go", "package logic\n")
+	write(".git/HEAD", "other\n")
+	os.Remove(filepath.Join(dir, "gone.go"))
+
+	after, err := snapshot.Take(dir)
+	if err != nil {
+		t.Fatalf("Take() failed: %v", err)
+	}
+
+	changes := before.Compare(after)
+	want := &snapshot.Changes{
+		Created:  []string{"internal/logic/new.go"},
+		Modified: []string{"internal/logic/edit.go"},
+		Deleted:  []string{"gone.go"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Compare() = %+v, want %+v", changes, want)
+	}
+
+	if got := snapshot.Under(append(changes.Created, changes.Deleted...), "internal/logic"); len(got) != 1 || got[0] != "internal/logic/new.go" {
+		t.Errorf("Under() = %v", got)
+	}
+
+	if err := before.Restore("internal/logic/edit.go"); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, "internal", "logic", "edit.go"))
+	if string(content) != "package logic\n" {
+		t.Errorf("Restore() wrote %q", content)
+	}
+}
+
+func TestTakeMissingDirectory(t *testing.T) {
+	s, err := snapshot.Take(filepath.Join(t.TempDir(), "missing"))
+	if err != nil {
+		t.Fatalf("Take() failed: %v", err)
+	}
+	if len(s.Files()) != 0 {
+		t.Errorf("Expected empty snapshot, got %v", s.Files())
+	}
+}
+
+func TestTakeSkipsVendorAndRefusesLargeTrees(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"main.go":                     "package main\n",
+		"vendor/modules.txt":          "# github.com/zeromicro/go-zero v1.6.0\n",
+		"web/node_modules/x/index.js": "module.exports = {}\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := snapshot.Take(dir)
+	if err != nil {
+		t.Fatalf("Take() failed: %v", err)
+	}
+	if got := s.Files(); !reflect.DeepEqual(got, []string{"main.go"}) {
+		t.Errorf("Files() = %v, want only main.go", got)
+	}
+
+	defer func(limit int64) { snapshot.MaxSize = limit }(snapshot.MaxSize)
+	snapshot.MaxSize = 8
+	_, err = snapshot.Take(dir)
+	var tooLarge *snapshot.TooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("Take() = %v, want a *TooLargeError", err)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "svc")
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("go.mod", "module svc\n")
+	write("etc/svc.yaml", "Port: 8888\n")
+	write("internal/types/types.go", "package types\n")
+
+	before, err := snapshot.Take(dir)
+	if err != nil {
+		t.Fatalf("Take() failed: %v", err)
+	}
+
+	write("go.mod", "module svc\n\nrequire example.com/x v1.0.0\n")
+	write("go.sum", "example.com/x v1.0.0 h1:abc\n")
+	write("internal/handler/routes/routes.go", "package routes\n")
+	os.Remove(filepath.Join(dir, "internal", "types", "types.go"))
+
+	changes, err := before.Rollback()
+	if err != nil {
+		t.Fatalf("Rollback() failed: %v", err)
+	}
+	want := &snapshot.Changes{
+		Created:  []string{"go.sum", "internal/handler/routes/routes.go"},
+		Modified: []string{"go.mod"},
+		Deleted:  []string{"internal/types/types.go"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Rollback() = %+v, want %+v", changes, want)
+	}
+
+	after, err := snapshot.Take(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := before.Compare(after); !c.Empty() {
+		t.Errorf("tree differs after rollback: %+v", c)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "internal", "handler")); !os.IsNotExist(err) {
+		t.Errorf("created directory internal/handler was not removed")
+	}
+}
+
+func TestRollbackRemovesCreatedRoot(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "svc")
+	before, err := snapshot.Take(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.MkdirAll(filepath.Join(dir, "internal", "logic"), 0755)
+	os.WriteFile(filepath.Join(dir, "internal", "logic", "a.go"), []byte("package logic\n"), 0644)
+
+	if _, err := before.Rollback(); err != nil {
+		t.Fatalf("Rollback() failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("created root %s was not removed", dir)
+	}
+}
+
+func TestCopyTo(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "internal", "empty"), 0755)
+	os.MkdirAll(filepath.Join(dir, "etc"), 0755)
+	os.WriteFile(filepath.Join(dir, "etc", "app.yaml"), []byte("Port: 8888\n"), 0600)
+	os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\n"), 0755)
+
+	s, err := snapshot.Take(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copyDir := filepath.Join(t.TempDir(), "copy")
+	if err := s.CopyTo(copyDir); err != nil {
+		t.Fatalf("CopyTo() failed: %v", err)
+	}
+
+	copied, err := snapshot.Take(copyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := s.Compare(copied); !c.Empty() {
+		t.Errorf("copy differs: %+v", c)
+	}
+	if info, err := os.Stat(filepath.Join(copyDir, "run.sh")); err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("mode not kept: %v %v", info, err)
+	}
+	if _, err := os.Stat(filepath.Join(copyDir, "internal", "empty")); err != nil {
+		t.Errorf("empty directory not copied: %v", err)
+	}
+}
+
+func TestTakeFilesRollback(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module mono\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "other.go"), []byte("package mono\n"), 0644)
+
+	before, err := snapshot.TakeFiles(dir, "go.mod", "go.sum")
+	if err != nil {
+		t.Fatalf("TakeFiles() failed: %v", err)
+	}
+	if got := before.Files(); len(got) != 1 || got[0] != "go.mod" {
+		t.Errorf("Files() = %v", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module mono\n\nrequire x v1.0.0\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "go.sum"), []byte("x v1.0.0 h1:abc\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "other.go"), []byte("package mono\n\n// untracked\n"), 0644)
+
+	changes, err := before.Rollback()
+	if err != nil {
+		t.Fatalf("Rollback() failed: %v", err)
+	}
+	want := &snapshot.Changes{Created: []string{"go.sum"}, Modified: []string{"go.mod"}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Rollback() = %+v, want %+v", changes, want)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "other.go")); string(content) != "package mono\n\n// untracked\n" {
+		t.Errorf("Rollback() touched a file outside the snapshot")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.sum")); !os.IsNotExist(err) {
+		t.Errorf("go.sum was not removed")
+	}
+}
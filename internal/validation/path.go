@@ -1,19 +1,24 @@
 package validation
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 )
 
 // ValidatePath validates a file/directory path
-// Checks if path is absolute and writable
-func ValidatePath(path string) error {
+// Checks if path is absolute, inside the allowed roots and writable
+func ValidatePath(ctx context.Context, path string) error {
 	// Check if path is absolute
 	if !filepath.IsAbs(path) {
 		return fmt.Errorf("path must be absolute, got: %s", path)
 	}
 
+	if err := CheckAllowed(ctx, path); err != nil {
+		return err
+	}
+
 	// Check if parent directory exists and is writable
 	parentDir := filepath.Dir(path)
 	if err := checkWritable(parentDir); err != nil {
@@ -24,13 +29,17 @@ func ValidatePath(path string) error {
 }
 
 // ValidateOutputDir validates an output directory
-// Creates it if it doesn't exist
-func ValidateOutputDir(dir string) error {
+// Creates it if it doesn't exist, refusing directories outside the allowed roots
+func ValidateOutputDir(ctx context.Context, dir string) error {
 	// Check if absolute
 	if !filepath.IsAbs(dir) {
 		return fmt.Errorf("output directory must be absolute path, got: %s", dir)
 	}
 
+	if err := CheckAllowed(ctx, dir); err != nil {
+		return err
+	}
+
 	// Check if directory exists
 	info, err := os.Stat(dir)
 	if err != nil {
@@ -53,6 +62,35 @@ func ValidateOutputDir(dir string) error {
 	return checkWritable(dir)
 }
 
+// CheckOutputDir validates an output directory like ValidateOutputDir without touching
+// the filesystem, as for dry runs: a missing directory is accepted when its nearest
+// existing ancestor is a directory, and nothing is created or probed
+func CheckOutputDir(ctx context.Context, dir string) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("output directory must be absolute path, got: %s", dir)
+	}
+
+	if err := CheckAllowed(ctx, dir); err != nil {
+		return err
+	}
+
+	for path := dir; ; path = filepath.Dir(path) {
+		info, err := os.Stat(path)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("output path exists but is not a directory: %s", path)
+			}
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to check output directory: %w", err)
+		}
+		if filepath.Dir(path) == path {
+			return fmt.Errorf("failed to check output directory: %w", err)
+		}
+	}
+}
+
 // checkWritable checks if a directory is writable by trying to create a temp file
 func checkWritable(dir string) error {
 	// Try to create a temp file
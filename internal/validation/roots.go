@@ -0,0 +1,170 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/zeromicro/mcp-zero/internal/lock"
+)
+
+// Allowed roots limit the files tools read and write. Server roots come from flags and
+// the server configuration file and apply to every call; client roots come from the
+// roots list of the MCP client making the call and travel in its context.
+// With server roots set, client roots only narrow them; with neither, any path is allowed.
+// A client that lists roots is always held to them: when none of them can be used, no path is
+var (
+	rootsMu     sync.RWMutex
+	serverRoots []string
+)
+
+type clientRootsKey struct{}
+
+// clientRoots are the roots a client listed, split into the usable ones and the reasons
+// the others were rejected
+type clientRoots struct {
+	resolved []string
+	rejected []string
+}
+
+// SetAllowedRoots sets the server-wide roots; every root must be an existing directory
+// An empty list removes the restriction
+func SetAllowedRoots(roots []string) error {
+	var resolved []string
+	for _, root := range roots {
+		path, err := resolveRoot(root)
+		if err != nil {
+			return err
+		}
+		resolved = append(resolved, path)
+	}
+	rootsMu.Lock()
+	defer rootsMu.Unlock()
+	serverRoots = resolved
+	return nil
+}
+
+// WithClientRoots returns a context carrying the roots reported by the MCP client of a
+// call. Roots that are not absolute paths to existing directories are rejected rather than
+// dropped, so a client whose roots are all unusable may touch nothing. A client that listed
+// no roots passes an empty list and is not restricted
+func WithClientRoots(ctx context.Context, roots []string) context.Context {
+	if len(roots) == 0 {
+		return ctx
+	}
+	client := clientRoots{resolved: []string{}}
+	for _, root := range roots {
+		if !filepath.IsAbs(root) {
+			client.rejected = append(client.rejected, fmt.Sprintf("root %s is not an absolute path", root))
+			continue
+		}
+		path, err := resolveRoot(root)
+		if err != nil {
+			client.rejected = append(client.rejected, err.Error())
+			continue
+		}
+		client.resolved = append(client.resolved, path)
+	}
+	return context.WithValue(ctx, clientRootsKey{}, client)
+}
+
+// AllowedRoots returns the roots in effect for a call, or nil when any path is allowed
+// An empty, non-nil list allows no path
+func AllowedRoots(ctx context.Context) []string {
+	roots, _ := allowedRoots(ctx)
+	return roots
+}
+
+// allowedRoots returns the roots in effect for a call and the client roots rejected on the way
+func allowedRoots(ctx context.Context) (roots, rejected []string) {
+	client, listed := ctx.Value(clientRootsKey{}).(clientRoots)
+	rootsMu.RLock()
+	defer rootsMu.RUnlock()
+	if !listed {
+		return serverRoots, nil
+	}
+	if len(serverRoots) == 0 {
+		return client.resolved, client.rejected
+	}
+	// A client may narrow the server's roots but never widen them
+	narrowed := []string{}
+	rejected = client.rejected
+	for _, root := range client.resolved {
+		if withinAny(root, serverRoots) {
+			narrowed = append(narrowed, root)
+		} else {
+			rejected = append(rejected, fmt.Sprintf("root %s is outside the server roots %s", root, strings.Join(serverRoots, ", ")))
+		}
+	}
+	return narrowed, rejected
+}
+
+// OutsideRootsError reports a path outside every allowed root, with the client roots that
+// could not be used
+type OutsideRootsError struct {
+	Path     string
+	Resolved string
+	Roots    []string
+	Rejected []string
+}
+
+func (e *OutsideRootsError) Error() string {
+	path := e.Path
+	if e.Resolved != e.Path {
+		path = fmt.Sprintf("%s (resolved to %s)", e.Path, e.Resolved)
+	}
+	roots := strings.Join(e.Roots, ", ")
+	if len(e.Roots) == 0 {
+		roots = "none, since the client listed no usable roots"
+	}
+	msg := fmt.Sprintf("path %s is outside the allowed roots: %s", path, roots)
+	if len(e.Rejected) > 0 {
+		msg += fmt.Sprintf("; rejected client roots: %s", strings.Join(e.Rejected, "; "))
+	}
+	return msg
+}
+
+// CheckAllowed returns an *OutsideRootsError unless path is inside an allowed root
+// Symlinks and ".." are resolved first, so neither can lead out of a root. The operating
+// system applies ".." after a symlink to the link's target, while filepath.Join and Clean
+// drop the link itself; tools open paths both ways, so both must stay inside a root
+func CheckAllowed(ctx context.Context, path string) error {
+	roots, rejected := allowedRoots(ctx)
+	if roots == nil {
+		return nil
+	}
+	for _, candidate := range []string{path, filepath.Clean(path)} {
+		resolved, err := lock.Resolve(candidate)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+		if !withinAny(resolved, roots) {
+			return &OutsideRootsError{Path: path, Resolved: resolved, Roots: roots, Rejected: rejected}
+		}
+	}
+	return nil
+}
+
+// resolveRoot resolves a root, which must be an existing directory
+func resolveRoot(root string) (string, error) {
+	path, err := lock.Resolve(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid root %s: %w", root, err)
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("root %s is not a directory", root)
+	}
+	return path, nil
+}
+
+func withinAny(path string, roots []string) bool {
+	for _, root := range roots {
+		if lock.Within(path, root) {
+			return true
+		}
+	}
+	return false
+}
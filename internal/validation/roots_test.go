@@ -0,0 +1,186 @@
+package validation_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// sandbox restricts the allowed roots to a new directory for the rest of the test
+func sandbox(t *testing.T) (root, outside string) {
+	t.Helper()
+	base := t.TempDir()
+	root = filepath.Join(base, "workspace")
+	outside = filepath.Join(base, "outside")
+	for _, dir := range []string{filepath.Join(root, "svc"), outside} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := validation.SetAllowedRoots([]string{root}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { validation.SetAllowedRoots(nil) })
+	return root, outside
+}
+
+func TestCheckAllowed(t *testing.T) {
+	root, outside := sandbox(t)
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	// A link to a directory inside the root, used to climb out with ".." from its target
+	if err := os.Symlink(filepath.Join(root, "svc"), filepath.Join(root, "inner")); err != nil {
+		t.Fatal(err)
+	}
+	// A link to a nested directory: ".." from its target stays inside, while filepath.Clean
+	// drops the link and climbs out
+	if err := os.MkdirAll(filepath.Join(root, "svc", "a", "b"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "svc", "a", "b"), filepath.Join(root, "deep")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		path    string
+		allowed bool
+	}{
+		{"root itself", root, true},
+		{"existing directory", filepath.Join(root, "svc"), true},
+		{"file to be created", filepath.Join(root, "svc", "new", "demo.api"), true},
+		{"dot dot staying inside", root + "/svc/../svc/demo.api", true},
+		{"symlink staying inside", filepath.Join(root, "inner", "demo.api"), true},
+		{"dot dot traversal", root + "/svc/../../outside/demo.api", false},
+		{"dot dot past a missing directory", root + "/missing/../../outside", false},
+		{"absolute path elsewhere", "/etc/passwd", false},
+		{"symlink escape", filepath.Join(root, "escape", "demo.api"), false},
+		{"symlink escape to be created", filepath.Join(root, "escape", "new", "dir"), false},
+		{"dot dot after a symlink", root + "/inner/../../outside", false},
+		{"dot dot after a symlink, cleaned", root + "/deep/../../outside", false},
+		{"non-ASCII separator lookalike", root + "/svc/a\u012fb/../../../outside", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validation.CheckAllowed(context.Background(), tc.path)
+			if tc.allowed && err != nil {
+				t.Errorf("CheckAllowed(%q) = %v, want allowed", tc.path, err)
+			}
+			if !tc.allowed {
+				var outsideErr *validation.OutsideRootsError
+				if !errors.As(err, &outsideErr) {
+					t.Fatalf("CheckAllowed(%q) = %v, want an *OutsideRootsError", tc.path, err)
+				}
+				if !strings.Contains(err.Error(), "allowed roots: ") || !strings.Contains(err.Error(), filepath.Base(root)) {
+					t.Errorf("error does not name the allowed roots: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateOutputDirOutsideRoots(t *testing.T) {
+	root, outside := sandbox(t)
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	for _, dir := range []string{
+		filepath.Join(outside, "svc"),
+		root + "/../outside/svc",
+		filepath.Join(root, "escape", "svc"),
+	} {
+		if err := validation.ValidateOutputDir(context.Background(), dir); err == nil {
+			t.Errorf("ValidateOutputDir(%q) succeeded outside the allowed roots", dir)
+		}
+		if err := validation.ValidatePath(context.Background(), filepath.Join(dir, "demo.api")); err == nil {
+			t.Errorf("ValidatePath(%q) succeeded outside the allowed roots", dir)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "svc")); !os.IsNotExist(err) {
+		t.Error("ValidateOutputDir created a directory outside the allowed roots")
+	}
+	if err := validation.ValidateOutputDir(context.Background(), filepath.Join(root, "svc", "api")); err != nil {
+		t.Errorf("ValidateOutputDir inside the root failed: %v", err)
+	}
+}
+
+func TestClientRoots(t *testing.T) {
+	root, outside := sandbox(t)
+	nested := filepath.Join(root, "svc")
+
+	// Client roots narrow the server's roots but cannot widen them
+	ctx := validation.WithClientRoots(context.Background(), []string{"file-like but missing", nested, outside})
+	if err := validation.CheckAllowed(ctx, filepath.Join(root, "other")); err == nil {
+		t.Error("a path outside the client's root was allowed")
+	}
+	if err := validation.CheckAllowed(ctx, filepath.Join(nested, "x")); err != nil {
+		t.Errorf("a path inside the client's root was refused: %v", err)
+	}
+	if err := validation.CheckAllowed(ctx, filepath.Join(outside, "x")); err == nil {
+		t.Error("a client root outside the server's roots was honored")
+	}
+
+	// Client roots apply only to calls carrying them
+	if err := validation.CheckAllowed(context.Background(), filepath.Join(root, "other")); err != nil {
+		t.Errorf("a call without client roots was narrowed: %v", err)
+	}
+
+	// Without server roots, the client's roots apply on their own
+	validation.SetAllowedRoots(nil)
+	if err := validation.CheckAllowed(ctx, filepath.Join(outside, "x")); err != nil {
+		t.Errorf("client root refused without server roots: %v", err)
+	}
+	if err := validation.CheckAllowed(ctx, "/etc/passwd"); err == nil {
+		t.Error("a path outside the client's roots was allowed without server roots")
+	}
+	if err := validation.CheckAllowed(context.Background(), "/etc/passwd"); err != nil {
+		t.Errorf("any path should be allowed without roots, got %v", err)
+	}
+
+	if err := validation.SetAllowedRoots([]string{filepath.Join(outside, "missing")}); err == nil {
+		t.Error("SetAllowedRoots accepted a missing directory")
+	}
+}
+
+func TestClientRootsFailClosed(t *testing.T) {
+	root, outside := sandbox(t)
+
+	// With server roots, client roots all outside them allow nothing rather than every server root
+	ctx := validation.WithClientRoots(context.Background(), []string{outside})
+	err := validation.CheckAllowed(ctx, filepath.Join(root, "svc"))
+	var outsideErr *validation.OutsideRootsError
+	if !errors.As(err, &outsideErr) {
+		t.Fatalf("CheckAllowed = %v, want an *OutsideRootsError", err)
+	}
+	if roots := validation.AllowedRoots(ctx); roots == nil || len(roots) != 0 {
+		t.Errorf("AllowedRoots = %#v, want an empty, non-nil list", roots)
+	}
+	if !strings.Contains(err.Error(), outside) || !strings.Contains(err.Error(), "outside the server roots") {
+		t.Errorf("error does not name the rejected client root: %v", err)
+	}
+
+	// Without server roots, client roots that cannot be resolved allow nothing rather than everything
+	validation.SetAllowedRoots(nil)
+	missing := filepath.Join(outside, "missing")
+	ctx = validation.WithClientRoots(context.Background(), []string{missing, "https://example.com/repo"})
+	err = validation.CheckAllowed(ctx, filepath.Join(outside, "x"))
+	if !errors.As(err, &outsideErr) {
+		t.Fatalf("CheckAllowed = %v, want an *OutsideRootsError", err)
+	}
+	for _, rejected := range []string{missing, "https://example.com/repo"} {
+		if !strings.Contains(err.Error(), rejected) {
+			t.Errorf("error does not name the rejected client root %s: %v", rejected, err)
+		}
+	}
+
+	// A client that listed no roots is not restricted
+	if err := validation.CheckAllowed(validation.WithClientRoots(context.Background(), nil), filepath.Join(outside, "x")); err != nil {
+		t.Errorf("a client without roots was restricted: %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package validation_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -131,7 +132,7 @@ func TestValidatePath(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validation.ValidatePath(tt.path)
+			err := validation.ValidatePath(context.Background(), tt.path)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ValidatePath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
 			}
@@ -187,7 +188,7 @@ func TestValidateOutputDir(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			dir := tt.setup()
-			err := validation.ValidateOutputDir(dir)
+			err := validation.ValidateOutputDir(context.Background(), dir)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ValidateOutputDir(%q) error = %v, wantErr %v", dir, err, tt.wantErr)
 			}
@@ -202,6 +203,38 @@ func TestValidateOutputDir(t *testing.T) {
 	}
 }
 
+func TestCheckOutputDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "testfile")
+	if err := os.WriteFile(file, []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		wantErr bool
+	}{
+		{"existing directory", tmpDir, false},
+		{"missing directory", filepath.Join(tmpDir, "a", "b"), false},
+		{"relative path", "relative/path", true},
+		{"file instead of directory", file, true},
+		{"below a file", filepath.Join(file, "sub"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validation.CheckOutputDir(context.Background(), tt.dir); (err != nil) != tt.wantErr {
+				t.Errorf("CheckOutputDir(%q) error = %v, wantErr %v", tt.dir, err, tt.wantErr)
+			}
+		})
+	}
+
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 1 {
+		t.Errorf("CheckOutputDir wrote to %s: %v", tmpDir, entries)
+	}
+}
+
 func TestEnsureDirectoryExists(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "mcp-zero-test-*")
 	if err != nil {
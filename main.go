@@ -6,9 +6,16 @@ import (
 	"fmt"
 	"log"
 	"os"
-
-	"github.com/modelcontextprotocol/go-sdk/mcp"
-	"github.com/zeromicro/mcp-zero/tools"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+	"github.com/zeromicro/mcp-zero/internal/goctl"
+	"github.com/zeromicro/mcp-zero/internal/server"
+	"github.com/zeromicro/mcp-zero/internal/validation"
 )
 
 const (
@@ -19,82 +26,83 @@ const (
 func main() {
 	// Define command line flags
 	version := flag.Bool("version", false, "Print version information")
+	transport := flag.String("transport", server.TransportStdio, "Transport to serve MCP over: stdio, http (streamable HTTP) or sse (legacy SSE)")
+	addr := flag.String("addr", server.DefaultAddr, "Listen address for the http and sse transports")
+	authToken := flag.String("auth-token", "", "Bearer token required by the http and sse transports (default $MCP_ZERO_AUTH_TOKEN)")
+	commandTimeout := flag.String("command-timeout", os.Getenv("MCP_ZERO_COMMAND_TIMEOUT"), "Timeout for every goctl and go command, e.g. 10m; empty uses per-command defaults (default $MCP_ZERO_COMMAND_TIMEOUT)")
+	dependencyMode := flag.String("dependency-mode", os.Getenv("MCP_ZERO_DEPENDENCY_MODE"), "Where go mod tidy and go build resolve modules: online, offline (module cache only) or vendor (default $MCP_ZERO_DEPENDENCY_MODE, else online)")
+	moduleProxy := flag.String("module-proxy", os.Getenv("MCP_ZERO_MODULE_PROXY"), "Directory served as a file-based GOPROXY in offline mode; implies -dependency-mode offline (default $MCP_ZERO_MODULE_PROXY)")
+	verify := flag.String("verify", os.Getenv("MCP_ZERO_VERIFY"), "Comma-separated checks run after go build ./... on generated code: vet, test, gofmt or all (default $MCP_ZERO_VERIFY, else vet)")
+	roots := flag.String("roots", os.Getenv("MCP_ZERO_ROOTS"), "Directories tools may read and write, separated by "+string(filepath.ListSeparator)+"; empty allows any path unless the config file or the client sets roots (default $MCP_ZERO_ROOTS)")
+	configFile := flag.String("config", os.Getenv("MCP_ZERO_CONFIG"), "Server configuration file; its roots are added to -roots (default $MCP_ZERO_CONFIG)")
 	flag.Parse()
 
+	// Read after parsing so -h never prints the secret as the flag's default
+	if *authToken == "" {
+		*authToken = os.Getenv("MCP_ZERO_AUTH_TOKEN")
+	}
+
 	// Handle version flag
 	if *version {
 		fmt.Printf("%s version %s\n", appName, appVersion)
 		os.Exit(0)
 	}
 
-	// Create MCP server
-	server := mcp.NewServer(&mcp.Implementation{
-		Name:    appName,
-		Version: appVersion,
-	}, nil)
-
-	// Register create_api_service tool (T034 - User Story 1)
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "create_api_service",
-		Description: "Create a new go-zero API service with proper structure and configuration",
-	}, tools.CreateAPIService)
-
-	// Register generate_api_from_spec tool (T047 - User Story 2)
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "generate_api_from_spec",
-		Description: "Generate go-zero API code from API specification file",
-	}, tools.GenerateAPIFromSpec)
-
-	// Register create_rpc_service tool (T058 - User Story 3)
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "create_rpc_service",
-		Description: "Create a new go-zero RPC service with protobuf definition",
-	}, tools.CreateRPCService)
-
-	// Register generate_model tool (T071 - User Story 4)
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "generate_model",
-		Description: "Generate go-zero database model from table schema",
-	}, tools.GenerateModel)
+	if err := server.ValidateTransport(*transport); err != nil {
+		log.Fatalf("Invalid flag: %v", err)
+	}
 
-	// Register create_api_spec tool (T081 - User Story 5)
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "create_api_spec",
-		Description: "Create a sample API specification file for go-zero. IMPORTANT: Always define concrete types for request and response - do NOT use 'any' type in .api files as it's not supported by go-zero",
-	}, tools.CreateAPISpec)
+	if *commandTimeout != "" {
+		timeout, err := time.ParseDuration(*commandTimeout)
+		if err != nil || timeout <= 0 {
+			log.Fatalf("Invalid flag: -command-timeout must be a positive duration such as 10m, got %q", *commandTimeout)
+		}
+		goctl.SetDefaultTimeout(timeout)
+	}
 
-	// Register analyze_project tool (T097 - User Story 6)
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "analyze_project",
-		Description: "Analyze existing go-zero project structure and dependencies",
-	}, tools.AnalyzeProject)
+	deps, err := fixer.NewDependencies(*dependencyMode, *moduleProxy)
+	if err != nil {
+		log.Fatalf("Invalid flag: %v", err)
+	}
+	fixer.SetDefaultDependencies(deps)
+
+	if *verify != "" {
+		checks, err := fixer.ParseChecks(strings.Split(*verify, ","))
+		if err != nil {
+			log.Fatalf("Invalid flag: %v", err)
+		}
+		fixer.SetDefaultVerifyOptions(checks)
+	}
 
-	// Register validate_config tool (T109 - User Story 7)
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "validate_config",
-		Description: "Validate go-zero service configuration file",
-	}, tools.ValidateConfig)
+	allowedRoots := filepath.SplitList(*roots)
+	if *configFile != "" {
+		config, err := server.LoadConfig(*configFile)
+		if err != nil {
+			log.Fatalf("Invalid flag: %v", err)
+		}
+		allowedRoots = append(allowedRoots, config.Roots...)
+	}
+	if err := validation.SetAllowedRoots(allowedRoots); err != nil {
+		log.Fatalf("Invalid roots: %v", err)
+	}
 
-	// Register generate_config_template tool (T109 - User Story 7)
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "generate_config_template",
-		Description: "Generate configuration template for go-zero service",
-	}, tools.GenerateConfigTemplate)
+	// Create MCP server with all tools registered
+	mcpServer := server.NewServer(appName, appVersion)
 
-	// Register generate_template tool (T123 - User Story 8)
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "generate_template",
-		Description: "Generate common code templates (middleware, error handlers, deployment configs)",
-	}, tools.GenerateTemplate)
+	// Stop serving on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Register query_docs tool (T134 - User Story 9)
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "query_docs",
-		Description: "Query go-zero framework documentation and migration guides",
-	}, tools.QueryDocs)
+	if *transport != server.TransportStdio {
+		log.Printf("%s %s serving %s transport on %s", appName, appVersion, *transport, *addr)
+	}
 
-	// Run the server over stdin/stdout using the StdioTransport
-	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+	opts := server.TransportOptions{
+		Transport: *transport,
+		Addr:      *addr,
+		AuthToken: *authToken,
+	}
+	if err := server.Serve(ctx, mcpServer, opts); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
@@ -0,0 +1,227 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/analyzer"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+const endpointServiceAPI = `syntax = "v1"
+
+type User {
+	Id int64 ` + "`json:\"id\"`" + `
+}
+
+@server (
+	group:  user
+	prefix: /api/v1
+)
+service user-api {
+	// fetch one user
+	@handler GetUser
+	get /users/:id returns (User)
+}
+`
+
+const handWrittenLogic = "package logic\n\n// GetUser has hand-written code\nfunc GetUser() int { return 42 }\n"
+
+// fakeAPIGoctl simulates goctl api go: new handler/logic files, regenerated routes and
+// types, and an attempt to rewrite an existing logic file
+const fakeAPIGoctl = `mkdir -p "$dir/internal/handler" "$dir/internal/logic" "$dir/internal/types"
+printf 'package handler\n\nfunc CreateUserHandler() {}\n' > "$dir/internal/handler/createuserhandler.go"
+printf 'package handler\n\nfunc RegisterHandlers() { CreateUserHandler() }\n' > "$dir/internal/handler/routes.go"
+printf 'package logic\n\nfunc CreateUser() {}\n' > "$dir/internal/logic/createuserlogic.go"
+printf 'package logic\n\nfunc GetUser() int { return 0 }\n' > "$dir/internal/logic/getuserlogic.go"
+printf 'package types\n\ntype CreateUserReq struct{}\n' > "$dir/internal/types/types.go"`
+
+func setupEndpointService(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":                             "module example.com/user\n\ngo 1.23\n",
+		"user.go":                            "package main\n\nfunc main() {}\n",
+		"user.api":                           endpointServiceAPI,
+		"internal/handler/routes.go":         "package handler\n\nfunc RegisterHandlers() {}\n",
+		"internal/handler/getuserhandler.go": "package handler\n\nfunc GetUserHandler() {}\n",
+		"internal/logic/getuserlogic.go":     handWrittenLogic,
+		"internal/types/types.go":            "package types\n\ntype User struct{}\n",
+	})
+	return dir
+}
+
+func TestAddAPIEndpoint(t *testing.T) {
+	useFakeGoctl(t, fakeAPIGoctl)
+	dir := setupEndpointService(t)
+
+	result, data, err := tools.AddAPIEndpoint(context.Background(), nil, tools.AddAPIEndpointParams{
+		ServicePath: dir,
+		Group:       "user",
+		Prefix:      "/api/v1",
+		Method:      "post",
+		Path:        "/users/:orgId",
+		Handler:     "CreateUser",
+		Doc:         "Create a user",
+		RequestFields: []tools.FieldInput{
+			{Name: "OrgId", Type: "int64"},
+			{Name: "Name", Type: "string"},
+		},
+		ResponseType: "User",
+	})
+	if err != nil {
+		t.Fatalf("AddAPIEndpoint failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected successful result")
+	}
+
+	// The hand-written logic must survive regeneration
+	logic, err := os.ReadFile(filepath.Join(dir, "internal", "logic", "getuserlogic.go"))
+	if err != nil || string(logic) != handWrittenLogic {
+		t.Errorf("Existing logic file was overwritten: %s", logic)
+	}
+
+	fields := data.(map[string]any)
+	created := strings.Join(fields["created_files"].([]string), ",")
+	modified := strings.Join(fields["modified_files"].([]string), ",")
+	protected := strings.Join(fields["protected_files"].([]string), ",")
+
+	for _, want := range []string{"internal/handler/createuserhandler.go", "internal/logic/createuserlogic.go"} {
+		if !strings.Contains(created, want) {
+			t.Errorf("Expected %s in created files, got %s", want, created)
+		}
+	}
+	for _, want := range []string{"internal/handler/routes.go", "internal/types/types.go", "user.api"} {
+		if !strings.Contains(modified, want) {
+			t.Errorf("Expected %s in modified files, got %s", want, modified)
+		}
+	}
+	if strings.Contains(modified, "getuserlogic.go") || protected != "internal/logic/getuserlogic.go" {
+		t.Errorf("Expected getuserlogic.go to be protected, modified=%s protected=%s", modified, protected)
+	}
+
+	spec, err := analyzer.ParseAPISpecification(filepath.Join(dir, "user.api"), nil)
+	if err != nil {
+		t.Fatalf("Edited spec does not parse: %v", err)
+	}
+	if len(spec.Endpoints) != 2 || spec.Endpoints[1].Path != "/api/v1/users/:orgId" || spec.Endpoints[1].Request != "CreateUserReq" {
+		t.Errorf("Unexpected endpoints after edit: %+v", spec.Endpoints)
+	}
+
+	req := spec.AST.Type("CreateUserReq")
+	if req == nil {
+		t.Fatal("CreateUserReq type was not added")
+	}
+	if tag, _ := req.Field("OrgId").TagValue("path"); tag != "orgId" {
+		t.Errorf("Expected path tag for OrgId, got %q", req.Field("OrgId").Tag)
+	}
+	if tag, _ := req.Field("Name").TagValue("json"); tag != "name" {
+		t.Errorf("Expected json tag for Name, got %q", req.Field("Name").Tag)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "user.api"))
+	if !strings.Contains(string(content), "\t// fetch one user\n\t@handler GetUser\n") {
+		t.Error("Existing comments were not preserved")
+	}
+}
+
+func TestAddAPIEndpointRestoresSpecOnGoctlFailure(t *testing.T) {
+	useFakeGoctl(t, `echo "boom" >&2; exit 1`)
+	dir := setupEndpointService(t)
+
+	result, _, err := tools.AddAPIEndpoint(context.Background(), nil, tools.AddAPIEndpointParams{
+		ServicePath: filepath.Join(dir, "user.api"),
+		Group:       "user",
+		Method:      "delete",
+		Path:        "/users/:id",
+		Handler:     "DeleteUser",
+	})
+	if err == nil || !result.IsError {
+		t.Fatal("Expected an error result when goctl fails")
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "user.api"))
+	if string(content) != endpointServiceAPI {
+		t.Errorf("Expected the .api file to be restored, got:\n%s", content)
+	}
+}
+
+func TestAddAPIEndpointRollsBackOnVerifyFailure(t *testing.T) {
+	// goctl succeeds but leaves a handler that does not compile
+	useFakeGoctl(t, fakeAPIGoctl+`
+printf 'package handler\n\nfunc Broken() {\n' > "$dir/internal/handler/brokenhandler.go"`)
+	dir := setupEndpointService(t)
+
+	result, _, err := tools.AddAPIEndpoint(context.Background(), nil, tools.AddAPIEndpointParams{
+		ServicePath: filepath.Join(dir, "user.api"),
+		Group:       "user",
+		Method:      "post",
+		Path:        "/users",
+		Handler:     "CreateUser",
+	})
+	if !result.IsError {
+		t.Fatal("Expected an error result when the regenerated service does not build")
+	}
+	if text := failureText(result, err); !strings.Contains(text, "Rolled back") {
+		t.Errorf("Expected the failure to report the rollback, got:\n%s", text)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "user.api"))
+	if string(content) != endpointServiceAPI {
+		t.Errorf("Expected the .api file to be restored, got:\n%s", content)
+	}
+	for _, path := range []string{"internal/handler/createuserhandler.go", "internal/handler/brokenhandler.go", "internal/logic/createuserlogic.go"} {
+		if _, err := os.Stat(filepath.Join(dir, path)); !os.IsNotExist(err) {
+			t.Errorf("Expected generated file %s to be removed", path)
+		}
+	}
+	routes, _ := os.ReadFile(filepath.Join(dir, "internal/handler/routes.go"))
+	if string(routes) != "package handler\n\nfunc RegisterHandlers() {}\n" {
+		t.Errorf("Expected routes.go to be restored, got:\n%s", routes)
+	}
+}
+
+// fieldParams adds a route whose request has a single field
+func fieldParams(dir string, field tools.FieldInput) tools.AddAPIEndpointParams {
+	return tools.AddAPIEndpointParams{ServicePath: dir, Group: "user", Method: "post", Path: "/fields", Handler: "Fields", RequestFields: []tools.FieldInput{field}}
+}
+
+func TestAddAPIEndpointValidation(t *testing.T) {
+	dir := setupEndpointService(t)
+
+	tests := []struct {
+		name   string
+		params tools.AddAPIEndpointParams
+	}{
+		{"missing service path", tools.AddAPIEndpointParams{Method: "get", Path: "/a", Handler: "A"}},
+		{"bad handler", tools.AddAPIEndpointParams{ServicePath: dir, Method: "get", Path: "/a", Handler: "a-b"}},
+		{"bad method", tools.AddAPIEndpointParams{ServicePath: dir, Method: "fetch", Path: "/a", Handler: "A"}},
+		{"relative path", tools.AddAPIEndpointParams{ServicePath: dir, Method: "get", Path: "a", Handler: "A"}},
+		{"duplicate route", tools.AddAPIEndpointParams{ServicePath: dir, Group: "user", Method: "get", Path: "/users/:id", Prefix: "/api/v1", Handler: "Other"}},
+		{"missing directory", tools.AddAPIEndpointParams{ServicePath: filepath.Join(dir, "nope"), Method: "get", Path: "/a", Handler: "A"}},
+		{"type with a newline", fieldParams(dir, tools.FieldInput{Name: "Name", Type: "string\n}\n\ntype Injected {"})},
+		{"type with braces", fieldParams(dir, tools.FieldInput{Name: "Name", Type: "string } type Injected {"})},
+		{"type with a backtick", fieldParams(dir, tools.FieldInput{Name: "Name", Type: "string `json:\"x\"`"})},
+		{"inline struct type", fieldParams(dir, tools.FieldInput{Name: "Name", Type: "struct{}"})},
+		{"tag with a newline", fieldParams(dir, tools.FieldInput{Name: "Name", Type: "string", Tag: "json:\"name\"\n}\n\ntype Injected {"})},
+		{"tag with a backtick", fieldParams(dir, tools.FieldInput{Name: "Name", Type: "string", Tag: "json:\"name\"` }\ntype Injected {"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := tools.AddAPIEndpoint(context.Background(), nil, tt.params)
+			if err == nil || !result.IsError {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "user.api"))
+	if string(content) != endpointServiceAPI {
+		t.Error("Validation failures must not modify the .api file")
+	}
+}
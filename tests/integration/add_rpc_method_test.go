@@ -0,0 +1,209 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/analyzer"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+const rpcServiceProto = `syntax = "proto3";
+
+package user;
+option go_package = "./user";
+
+message GetUserReq {
+  int64 id = 1;
+}
+
+message GetUserResp {
+  string name = 1;
+}
+
+service User {
+  // fetch one user
+  rpc GetUser(GetUserReq) returns (GetUserResp);
+}
+`
+
+// fakeRPCGoctl simulates goctl rpc protoc: regenerated pb/grpc files, a new logic
+// stub, and an attempt to rewrite an existing logic file
+const fakeRPCGoctl = `mkdir -p "$dir/user" "$dir/internal/logic"
+printf 'package user\n\ntype ListUsersReq struct{}\n' > "$dir/user/user.pb.go"
+printf 'package user\n\ntype UserClient interface{}\n' > "$dir/user/user_grpc.pb.go"
+printf 'package logic\n\nfunc ListUsers() {}\n' > "$dir/internal/logic/listuserslogic.go"
+printf 'package logic\n\nfunc GetUser() int { return 0 }\n' > "$dir/internal/logic/getuserlogic.go"
+echo "$args" > "$dir/goctl-args.txt"`
+
+func setupRPCService(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":                         "module example.com/user\n\ngo 1.23\n",
+		"user.go":                        "package main\n\nfunc main() {}\n",
+		"user.proto":                     rpcServiceProto,
+		"user/user.pb.go":                "package user\n",
+		"user/user_grpc.pb.go":           "package user\n",
+		"internal/logic/getuserlogic.go": handWrittenLogic,
+	})
+	return dir
+}
+
+func TestAddRPCMethod(t *testing.T) {
+	useFakeGoctl(t, fakeRPCGoctl)
+	dir := setupRPCService(t)
+
+	result, data, err := tools.AddRPCMethod(context.Background(), nil, tools.AddRPCMethodParams{
+		ServiceDir: dir,
+		Method:     "ListUsers",
+		Doc:        "list users",
+		RequestFields: []tools.MessageFieldInput{
+			{Name: "page", Type: "int32"},
+			{Name: "size", Type: "int32", Number: 5},
+		},
+		ResponseFields: []tools.MessageFieldInput{
+			{Name: "users", Type: "repeated GetUserResp"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddRPCMethod failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected successful result")
+	}
+
+	logic, err := os.ReadFile(filepath.Join(dir, "internal", "logic", "getuserlogic.go"))
+	if err != nil || string(logic) != handWrittenLogic {
+		t.Errorf("Existing logic file was overwritten: %s", logic)
+	}
+
+	fields := data.(map[string]any)
+	if stubs := strings.Join(fields["logic_stubs"].([]string), ","); stubs != "internal/logic/listuserslogic.go" {
+		t.Errorf("Unexpected logic stubs: %s", stubs)
+	}
+	if pb := strings.Join(fields["pb_files"].([]string), ","); pb != "user/user.pb.go,user/user_grpc.pb.go" {
+		t.Errorf("Unexpected pb files: %s", pb)
+	}
+	if protected := strings.Join(fields["protected_files"].([]string), ","); protected != "internal/logic/getuserlogic.go" {
+		t.Errorf("Expected getuserlogic.go to be protected, got %s", protected)
+	}
+
+	args, _ := os.ReadFile(filepath.Join(dir, "goctl-args.txt"))
+	if !strings.HasPrefix(string(args), "rpc protoc user.proto --go_out=. --go-grpc_out=. --zrpc_out=.") {
+		t.Errorf("Unexpected goctl arguments: %s", args)
+	}
+
+	spec, err := analyzer.ParseProtoSpecification(filepath.Join(dir, "user.proto"), nil, nil)
+	if err != nil {
+		t.Fatalf("Edited proto does not parse: %v", err)
+	}
+	if len(spec.Methods) != 2 || spec.Methods[1].Name != "ListUsers" || spec.Methods[1].Request != "ListUsersReq" || spec.Methods[1].Response != "ListUsersResp" {
+		t.Errorf("Unexpected methods after edit: %+v", spec.Methods)
+	}
+	req := spec.AST.Message("ListUsersReq")
+	if req == nil || req.Field("page").Number != 1 || req.Field("size").Number != 5 {
+		t.Errorf("Unexpected request message: %+v", req)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "user.proto"))
+	if !strings.Contains(string(content), "  // fetch one user\n  rpc GetUser(GetUserReq) returns (GetUserResp);\n  // list users\n  rpc ListUsers(") {
+		t.Errorf("Unexpected proto content:\n%s", content)
+	}
+}
+
+func TestAddRPCMethodRestoresProtoOnGoctlFailure(t *testing.T) {
+	useFakeGoctl(t, `echo "boom" >&2; exit 1`)
+	dir := setupRPCService(t)
+
+	result, _, err := tools.AddRPCMethod(context.Background(), nil, tools.AddRPCMethodParams{
+		ServiceDir:   dir,
+		Method:       "DeleteUser",
+		RequestType:  "GetUserReq",
+		ResponseType: "GetUserResp",
+	})
+	if err == nil || !result.IsError {
+		t.Fatal("Expected an error result when goctl fails")
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "user.proto"))
+	if string(content) != rpcServiceProto {
+		t.Errorf("Expected the .proto file to be restored, got:\n%s", content)
+	}
+}
+
+func TestAddRPCMethodRollsBackSharedProto(t *testing.T) {
+	// goctl succeeds but leaves a pb file that does not compile
+	useFakeGoctl(t, fakeRPCGoctl+`
+printf 'package user\n\nfunc Broken() {\n' > "$dir/user/broken.pb.go"`)
+	base := t.TempDir()
+	dir := filepath.Join(base, "user")
+	shared := filepath.Join(base, "protos", "user.proto")
+	writeFiles(t, base, map[string]string{
+		"protos/user.proto":                   rpcServiceProto,
+		"user/go.mod":                         "module example.com/user\n\ngo 1.23\n",
+		"user/user.go":                        "package main\n\nfunc main() {}\n",
+		"user/user/user.pb.go":                "package user\n",
+		"user/internal/logic/getuserlogic.go": handWrittenLogic,
+	})
+
+	result, _, _ := tools.AddRPCMethod(context.Background(), nil, tools.AddRPCMethodParams{
+		ServiceDir:   dir,
+		Method:       "DeleteUser",
+		RequestType:  "GetUserReq",
+		ResponseType: "GetUserResp",
+		ProtoFile:    shared,
+	})
+	if !result.IsError {
+		t.Fatal("Expected an error result when the regenerated service does not build")
+	}
+	if text := failureText(result, nil); !strings.Contains(text, "Rolled back "+filepath.Dir(shared)) {
+		t.Errorf("Expected the failure to report the rollback of the shared proto, got:\n%s", text)
+	}
+
+	content, _ := os.ReadFile(shared)
+	if string(content) != rpcServiceProto {
+		t.Errorf("Expected the shared .proto file to be restored, got:\n%s", content)
+	}
+	for _, path := range []string{"user/broken.pb.go", "user/user_grpc.pb.go", "internal/logic/listuserslogic.go"} {
+		if _, err := os.Stat(filepath.Join(dir, path)); !os.IsNotExist(err) {
+			t.Errorf("Expected generated file %s to be removed", path)
+		}
+	}
+	if logic, _ := os.ReadFile(filepath.Join(dir, "internal/logic/getuserlogic.go")); string(logic) != handWrittenLogic {
+		t.Errorf("Expected hand-written logic to be kept, got:\n%s", logic)
+	}
+}
+
+func TestAddRPCMethodValidation(t *testing.T) {
+	dir := setupRPCService(t)
+
+	tests := []struct {
+		name   string
+		params tools.AddRPCMethodParams
+	}{
+		{"missing service dir", tools.AddRPCMethodParams{Method: "A"}},
+		{"bad method", tools.AddRPCMethodParams{ServiceDir: dir, Method: "a-b"}},
+		{"duplicate rpc", tools.AddRPCMethodParams{ServiceDir: dir, Method: "GetUser", RequestType: "GetUserReq", ResponseType: "GetUserResp"}},
+		{"undefined message", tools.AddRPCMethodParams{ServiceDir: dir, Method: "A", RequestType: "Missing", ResponseType: "GetUserResp"}},
+		{"duplicate field number", tools.AddRPCMethodParams{ServiceDir: dir, Method: "A", RequestFields: []tools.MessageFieldInput{{Name: "a", Type: "string", Number: 1}, {Name: "b", Type: "string", Number: 1}}}},
+		{"missing directory", tools.AddRPCMethodParams{ServiceDir: filepath.Join(dir, "nope"), Method: "A"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := tools.AddRPCMethod(context.Background(), nil, tt.params)
+			if err == nil || !result.IsError {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+
+	content, _ := os.ReadFile(filepath.Join(dir, "user.proto"))
+	if string(content) != rpcServiceProto {
+		t.Error("Validation failures must not modify the .proto file")
+	}
+}
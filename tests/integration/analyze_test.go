@@ -152,3 +152,41 @@ func TestAnalyzeProjectCache(t *testing.T) {
 		t.Errorf("Second call should be from cache")
 	}
 }
+
+func TestAnalyzeProjectCachesNamingStyles(t *testing.T) {
+	tools.ClearCache()
+	defer tools.ClearCache()
+	tmpDir := t.TempDir()
+	handlerDir := filepath.Join(tmpDir, "internal", "handler")
+	if err := os.MkdirAll(handlerDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(handlerDir, "get_user_handler.go"), []byte("package handler\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	styles := func() int {
+		t.Helper()
+		result, data, err := tools.AnalyzeProject(context.Background(), &mcp.CallToolRequest{}, tools.AnalyzeProjectParams{ProjectPath: tmpDir})
+		if err != nil || result.IsError {
+			t.Fatalf("analyze_project failed: %v", err)
+		}
+		return len(data.(map[string]any)["naming_styles"].(map[string][]string))
+	}
+
+	if got := styles(); got != 1 {
+		t.Fatalf("first analysis found %d styles, want 1", got)
+	}
+	// A file written behind the server's back is not seen until the cached entry goes,
+	// so the style report is served from the cache along with the analysis
+	if err := os.WriteFile(filepath.Join(handlerDir, "getuserhandler.go"), []byte("package handler\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := styles(); got != 1 {
+		t.Errorf("cached analysis found %d styles, want the cached 1", got)
+	}
+	tools.ClearCache()
+	if got := styles(); got != 2 {
+		t.Errorf("fresh analysis found %d styles, want 2", got)
+	}
+}
@@ -0,0 +1,54 @@
+package integration_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/goctl"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+func TestCreateAPIServiceCanceled(t *testing.T) {
+	useFakeGoctl(t, `sleep 30`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, _, err := tools.CreateAPIService(ctx, &mcp.CallToolRequest{}, tools.CreateAPIServiceParams{
+		ServiceName: "slowapi",
+		Port:        18931,
+		OutputDir:   t.TempDir(),
+	})
+	if err == nil || !result.IsError {
+		t.Fatal("expected the canceled call to fail")
+	}
+	if !strings.Contains(err.Error(), "canceled") {
+		t.Errorf("error = %v, want cancellation", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("canceled call took %s", elapsed)
+	}
+}
+
+func TestCreateAPIServiceTimeout(t *testing.T) {
+	useFakeGoctl(t, `sleep 30`)
+	goctl.SetDefaultTimeout(200 * time.Millisecond)
+	defer goctl.SetDefaultTimeout(0)
+
+	result, _, err := tools.CreateAPIService(context.Background(), &mcp.CallToolRequest{}, tools.CreateAPIServiceParams{
+		ServiceName: "slowapi",
+		Port:        18932,
+		OutputDir:   t.TempDir(),
+	})
+	if err == nil || !result.IsError {
+		t.Fatal("expected the call to time out")
+	}
+	if !strings.Contains(err.Error(), "timed out after 200ms") {
+		t.Errorf("error = %v, want timeout", err)
+	}
+}
@@ -0,0 +1,106 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+// exclusiveGoctl fails when another goctl call is running at the same time
+const exclusiveGoctl = `if ! mkdir "$FAKE_GOCTL_BUSY" 2>/dev/null; then
+  echo "another goctl call is running" >&2
+  exit 7
+fi
+sleep 0.3
+rmdir "$FAKE_GOCTL_BUSY"`
+
+// rendezvousGoctl waits until two goctl calls run at the same time, failing after 5 seconds
+const rendezvousGoctl = `touch "$FAKE_GOCTL_SYNC/$$"
+i=0
+while [ "$(ls "$FAKE_GOCTL_SYNC" | wc -l)" -lt 2 ]; do
+  i=$((i+1))
+  if [ $i -gt 50 ]; then
+    echo "the other goctl call never started" >&2
+    exit 7
+  fi
+  sleep 0.1
+done`
+
+// generateConcurrently runs generate_api_from_spec for every output directory at once
+func generateConcurrently(t *testing.T, apiFile string, outputDirs ...string) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for _, outputDir := range outputDirs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, _, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+				APIFile:   apiFile,
+				OutputDir: outputDir,
+				Style:     "go_zero",
+				Verify:    []string{"build"},
+			})
+			if err != nil || result.IsError {
+				t.Errorf("generation into %s failed: %s", outputDir, failureText(result, err))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConcurrentGenerationIntoSameDirectoryIsSerialized(t *testing.T) {
+	useFakeGoctl(t, exclusiveGoctl)
+	t.Setenv("FAKE_GOCTL_BUSY", filepath.Join(t.TempDir(), "busy"))
+	apiFile, outputDir := setupRollbackProject(t)
+
+	// The second call reaches the same tree through a symlink
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(filepath.Dir(outputDir), link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	generateConcurrently(t, apiFile, outputDir, filepath.Join(link, filepath.Base(outputDir)))
+}
+
+func TestConcurrentGenerationIntoUnrelatedDirectoriesRunsInParallel(t *testing.T) {
+	useFakeGoctl(t, rendezvousGoctl)
+	t.Setenv("FAKE_GOCTL_SYNC", t.TempDir())
+	apiFile, first := setupRollbackProject(t)
+	_, second := setupRollbackProject(t)
+
+	generateConcurrently(t, apiFile, first, second)
+}
+
+func TestWriteInvalidatesAnalysisCache(t *testing.T) {
+	useFakeGoctl(t, "")
+	tools.ClearCache()
+	defer tools.ClearCache()
+	apiFile, outputDir := setupRollbackProject(t)
+
+	analyze := func() bool {
+		t.Helper()
+		result, data, err := tools.AnalyzeProject(context.Background(), &mcp.CallToolRequest{}, tools.AnalyzeProjectParams{ProjectPath: outputDir})
+		if err != nil || result.IsError {
+			t.Fatalf("analyze_project failed: %s", failureText(result, err))
+		}
+		return data.(map[string]any)["from_cache"].(bool)
+	}
+
+	if analyze() {
+		t.Fatal("first analysis came from the cache")
+	}
+	if !analyze() {
+		t.Fatal("second analysis was not cached")
+	}
+
+	// Writing to a subdirectory of the analyzed project makes the analysis stale
+	generateConcurrently(t, apiFile, filepath.Join(outputDir, "internal", "admin"))
+	if analyze() {
+		t.Error("analysis was served from the cache after a write to the project")
+	}
+}
@@ -79,3 +79,32 @@ func TestGenerateConfigTemplate(t *testing.T) {
 		t.Errorf("Output file not created")
 	}
 }
+
+func TestUpdateConfig(t *testing.T) {
+	serviceDir := filepath.Join(t.TempDir(), "user")
+	os.MkdirAll(filepath.Join(serviceDir, "etc"), 0755)
+	config := "Name: user.rpc\nListenOn: 0.0.0.0:8080 # grpc\n\nEtcd:\n  Hosts:\n  - 127.0.0.1:2379\n  Key: user.rpc\n"
+	os.WriteFile(filepath.Join(serviceDir, "etc", "user.yaml"), []byte(config), 0644)
+	os.WriteFile(filepath.Join(serviceDir, "etc", "other.yaml"), []byte("Name: other\n"), 0644)
+
+	params := tools.UpdateConfigParams{
+		ServiceDir: serviceDir,
+		Edits:      []string{"Etcd.Key=user.v2.rpc", "Prometheus.Port=9101"},
+	}
+
+	result, _, err := tools.UpdateConfig(context.Background(), &mcp.CallToolRequest{}, params)
+	if err != nil || result.IsError {
+		t.Fatalf("update_config failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(serviceDir, "etc", "user.yaml"))
+	want := "Name: user.rpc\nListenOn: 0.0.0.0:8080 # grpc\n\nEtcd:\n  Hosts:\n  - 127.0.0.1:2379\n  Key: user.v2.rpc\nPrometheus:\n  Port: 9101\n"
+	if string(content) != want {
+		t.Errorf("config =\n%s\nwant\n%s", content, want)
+	}
+
+	params.Edits = []string{"ListenOn"}
+	if result, _, _ := tools.UpdateConfig(context.Background(), &mcp.CallToolRequest{}, params); !result.IsError {
+		t.Error("expected a validation error for an edit without '='")
+	}
+}
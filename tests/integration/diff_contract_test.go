@@ -0,0 +1,144 @@
+package integration_test
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/contract"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+const contractV1 = `syntax = "v1"
+
+type User {
+	Id   int64  ` + "`json:\"id\"`" + `
+	Name string ` + "`json:\"name\"`" + `
+}
+
+service users-api {
+	@handler GetUser
+	get /users/:id returns (User)
+
+	@handler DeleteUser
+	delete /users/:id
+}
+`
+
+const contractV2 = `syntax = "v1"
+
+type User {
+	Id   int64  ` + "`json:\"id\"`" + `
+	Name string ` + "`json:\"username\"`" + `
+}
+
+service users-api {
+	@handler GetUser
+	get /users/:id returns (User)
+}
+`
+
+func TestDiffContractFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"v1.api": contractV1, "v2.api": contractV2})
+
+	result, data, err := tools.DiffContract(context.Background(), nil, tools.DiffContractParams{
+		OldFile: filepath.Join(dir, "v1.api"),
+		NewFile: filepath.Join(dir, "v2.api"),
+	})
+	if err != nil {
+		t.Fatalf("DiffContract failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected a report without fail_on")
+	}
+
+	fields := data.(map[string]any)
+	if fields["breaking"] != 2 || fields["has_breaking"] != true {
+		t.Errorf("Expected 2 breaking changes, got %v", fields["breaking"])
+	}
+	kinds := map[string]bool{}
+	for _, c := range fields["changes"].([]contract.Change) {
+		kinds[c.Kind] = true
+	}
+	if !kinds[contract.RouteRemoved] || !kinds[contract.JSONTagChanged] {
+		t.Errorf("Unexpected changes: %v", fields["changes"])
+	}
+
+	// fail_on turns breaking changes into an error result
+	result, _, err = tools.DiffContract(context.Background(), nil, tools.DiffContractParams{
+		OldFile: filepath.Join(dir, "v1.api"),
+		NewFile: filepath.Join(dir, "v2.api"),
+		FailOn:  "breaking",
+	})
+	if err == nil || !result.IsError {
+		t.Fatal("Expected fail_on=breaking to fail")
+	}
+	if !strings.Contains(err.Error(), "route_removed") {
+		t.Errorf("Expected the report in the error, got %v", err)
+	}
+}
+
+func TestDiffContractRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	writeFiles(t, dir, map[string]string{"users.api": contractV1})
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "v1")
+	writeFiles(t, dir, map[string]string{"users.api": contractV2})
+
+	result, data, err := tools.DiffContract(context.Background(), nil, tools.DiffContractParams{
+		NewFile:  filepath.Join(dir, "users.api"),
+		Revision: "HEAD",
+	})
+	if err != nil {
+		t.Fatalf("DiffContract failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected successful result")
+	}
+	fields := data.(map[string]any)
+	if fields["old"] != "HEAD:users.api" || fields["breaking"] != 2 {
+		t.Errorf("Unexpected report: old=%v breaking=%v", fields["old"], fields["breaking"])
+	}
+}
+
+func TestDiffContractValidation(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"v1.api": contractV1, "v1.proto": "syntax = \"proto3\";\n"})
+
+	tests := []struct {
+		name   string
+		params tools.DiffContractParams
+	}{
+		{"missing new_file", tools.DiffContractParams{OldFile: filepath.Join(dir, "v1.api")}},
+		{"no old side", tools.DiffContractParams{NewFile: filepath.Join(dir, "v1.api")}},
+		{"both old sides", tools.DiffContractParams{NewFile: filepath.Join(dir, "v1.api"), OldFile: filepath.Join(dir, "v1.api"), Revision: "HEAD"}},
+		{"option revision", tools.DiffContractParams{NewFile: filepath.Join(dir, "v1.api"), Revision: "--output=x"}},
+		{"mixed types", tools.DiffContractParams{NewFile: filepath.Join(dir, "v1.api"), OldFile: filepath.Join(dir, "v1.proto")}},
+		{"bad fail_on", tools.DiffContractParams{NewFile: filepath.Join(dir, "v1.api"), OldFile: filepath.Join(dir, "v1.api"), FailOn: "fatal"}},
+		{"not a repository", tools.DiffContractParams{NewFile: filepath.Join(dir, "v1.api"), Revision: "HEAD"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := tools.DiffContract(context.Background(), nil, tt.params)
+			if err == nil || !result.IsError {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
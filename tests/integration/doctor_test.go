@@ -0,0 +1,159 @@
+package integration_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/doctor"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+func TestDoctorReportsGoctlVersion(t *testing.T) {
+	for _, tc := range []struct {
+		version, status string
+		multiple        bool
+		hint            string
+		upgrade         bool
+	}{
+		{"1.8.4", "ok", true, "", false},
+		{"1.4.2", "ok", false, "--multiple needs goctl 1.5.0 or newer", true},
+		{"1.2.0", "unsupported", false, "older than 1.3.0", true},
+		{"1.9.0", "untested", true, "newer than the releases", false},
+		{"devel", "unknown", true, "Could not detect the goctl version", false},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			useFakeGoctl(t, "exit 1")
+			t.Setenv("FAKE_GOCTL_VERSION", tc.version)
+
+			result, data, err := tools.Doctor(context.Background(), &mcp.CallToolRequest{}, tools.DoctorParams{})
+			if err != nil || result.IsError {
+				t.Fatalf("Doctor failed: %v", err)
+			}
+			if compat := data.(*doctor.Goctl); compat.Status != tc.status {
+				t.Errorf("status = %s, want %s", compat.Status, tc.status)
+			}
+			text := result.Content[0].(*mcp.TextContent).Text
+			if tc.hint != "" && !strings.Contains(text, tc.hint) {
+				t.Errorf("result missing hint %q:\n%s", tc.hint, text)
+			}
+			if upgrade := strings.Contains(text, "go install github.com/zeromicro/go-zero/tools/goctl@latest"); upgrade != tc.upgrade {
+				t.Errorf("upgrade command shown = %v, want %v:\n%s", upgrade, tc.upgrade, text)
+			}
+			if want := map[bool]string{true: "✓", false: "✗"}[tc.multiple] + " goctl rpc protoc --multiple"; !strings.Contains(text, want) {
+				t.Errorf("result missing %q:\n%s", want, text)
+			}
+		})
+	}
+}
+
+func TestEnvironmentDoctorReportsGoctlCompatibility(t *testing.T) {
+	for _, tc := range []struct {
+		version, status string
+		check           doctor.Status
+		multiple        bool
+		hint            string
+		upgrade         bool
+	}{
+		{"1.8.4", "ok", doctor.StatusPass, true, "", false},
+		{"1.4.2", "ok", doctor.StatusWarn, false, "--multiple needs goctl 1.5.0 or newer", true},
+		{"1.2.0", "unsupported", doctor.StatusFail, false, "older than 1.3.0", true},
+		{"1.9.0", "untested", doctor.StatusWarn, true, "newer than the releases", false},
+		{"devel", "unknown", doctor.StatusWarn, true, "Could not detect the goctl version", true},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			useFakeGoctl(t, "exit 1")
+			t.Setenv("FAKE_GOCTL_VERSION", tc.version)
+
+			result, data, err := tools.EnvironmentDoctor(context.Background(), &mcp.CallToolRequest{}, tools.EnvironmentDoctorParams{Workspace: t.TempDir()})
+			if err != nil || result.IsError {
+				t.Fatalf("EnvironmentDoctor failed: %v", err)
+			}
+			report := data.(map[string]any)
+			if compat := report["goctl"].(*doctor.Goctl); compat.Status != tc.status {
+				t.Errorf("goctl status = %s, want %s", compat.Status, tc.status)
+			}
+			for _, check := range report["checks"].([]doctor.Check) {
+				if check.Name == "goctl" && check.Status != tc.check {
+					t.Errorf("goctl check = %+v, want %s", check, tc.check)
+				}
+			}
+			text := result.Content[0].(*mcp.TextContent).Text
+			if tc.hint != "" && !strings.Contains(text, tc.hint) {
+				t.Errorf("result missing hint %q:\n%s", tc.hint, text)
+			}
+			if upgrade := strings.Contains(text, "go install github.com/zeromicro/go-zero/tools/goctl@latest"); upgrade != tc.upgrade {
+				t.Errorf("upgrade command shown = %v, want %v:\n%s", upgrade, tc.upgrade, text)
+			}
+			if want := map[bool]string{true: "✓", false: "✗"}[tc.multiple] + " goctl rpc protoc --multiple"; !strings.Contains(text, want) {
+				t.Errorf("result missing %q:\n%s", want, text)
+			}
+		})
+	}
+}
+
+func TestGenerationRefusesOldGoctl(t *testing.T) {
+	useFakeGoctl(t, brokenGoctl)
+	t.Setenv("FAKE_GOCTL_VERSION", "1.2.0")
+	apiFile, outputDir := setupRollbackProject(t)
+
+	_, _, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:   apiFile,
+		OutputDir: outputDir,
+	})
+	if err == nil || !strings.Contains(err.Error(), "older than 1.3.0") || !strings.Contains(err.Error(), "go install") {
+		t.Errorf("expected goctl 1.2.0 to be refused with an upgrade hint, got %v", err)
+	}
+}
+
+func TestGenerationGatesStyleFormatsByVersion(t *testing.T) {
+	for _, tc := range []struct {
+		version, style string
+		refused        bool
+	}{
+		{"1.3.0", "goZero", false},
+		{"1.3.0", "Go-Zero", true},
+		{"1.4.0", "Go-Zero", false},
+		{"1.8.4", "go#zero", false},
+	} {
+		t.Run(tc.version+" "+tc.style, func(t *testing.T) {
+			// A new script per version, as detected versions are cached per binary
+			useFakeGoctl(t, brokenGoctl)
+			t.Setenv("FAKE_GOCTL_VERSION", tc.version)
+			apiFile, outputDir := setupRollbackProject(t)
+			result, _, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+				APIFile:   apiFile,
+				OutputDir: outputDir,
+				Style:     tc.style,
+			})
+			// The broken fake goctl fails every generation that gets past the gate
+			text := failureText(result, err)
+			refused := strings.Contains(text, "custom formats requires goctl 1.4.0 or newer")
+			if refused != tc.refused {
+				t.Errorf("style %s on goctl %s refused = %v, want %v: %s", tc.style, tc.version, refused, tc.refused, text)
+			}
+		})
+	}
+}
+
+func TestAddRPCMethodMultipleServicesNeedGoctl15(t *testing.T) {
+	useFakeGoctl(t, fakeRPCGoctl)
+	t.Setenv("FAKE_GOCTL_VERSION", "1.4.2")
+	dir := setupRPCService(t)
+	writeFiles(t, dir, map[string]string{
+		"user.proto": rpcServiceProto + "\nservice Admin {\n  rpc Ban(GetUserReq) returns (GetUserResp);\n}\n",
+	})
+
+	_, _, err := tools.AddRPCMethod(context.Background(), nil, tools.AddRPCMethodParams{
+		ServiceDir:   dir,
+		Service:      "User",
+		Method:       "DeleteUser",
+		RequestType:  "GetUserReq",
+		ResponseType: "GetUserResp",
+	})
+	if err == nil || !strings.Contains(err.Error(), "goctl rpc protoc --multiple requires goctl 1.5.0 or newer") {
+		t.Errorf("expected --multiple to be refused on goctl 1.4.2, got %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+// dryRunFiles extracts the file changes from a dry run result
+func dryRunFiles(t *testing.T, data any, err error) map[string]tools.FileChange {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	fields, ok := data.(map[string]any)
+	if !ok || fields["dry_run"] != true {
+		t.Fatalf("result is not a dry run: %#v", data)
+	}
+	files := make(map[string]tools.FileChange)
+	for _, change := range fields["files"].([]tools.FileChange) {
+		files[change.Path] = change
+	}
+	return files
+}
+
+// assertUnchanged fails if dir differs from the snapshot taken before the dry run
+func assertUnchanged(t *testing.T, before *snapshot.Snapshot) {
+	t.Helper()
+	after, err := snapshot.Take(before.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := before.Compare(after); !changes.Empty() {
+		t.Errorf("dry run changed %s: %+v", before.Root, changes)
+	}
+}
+
+// fakeAPINewGoctl simulates goctl api new creating a service in the working directory
+const fakeAPINewGoctl = `set -- $args
+svc="$PWD/$3"
+mkdir -p "$svc/etc" "$svc/internal/config"
+printf 'syntax = "v1"\n' > "$svc/$3.api"
+printf 'package main\n\nfunc main() {}\n' > "$svc/$3.go"
+printf 'Name: %s\nHost: 0.0.0.0\nPort: 8888\n' "$3" > "$svc/etc/$3-api.yaml"
+printf 'package config\n' > "$svc/internal/config/config.go"`
+
+func TestCreateAPIServiceDryRun(t *testing.T) {
+	useFakeGoctl(t, fakeAPINewGoctl)
+	outputDir := t.TempDir()
+	before, _ := snapshot.Take(outputDir)
+
+	result, data, err := tools.CreateAPIService(context.Background(), &mcp.CallToolRequest{}, tools.CreateAPIServiceParams{
+		ServiceName: "previewapi",
+		Port:        18934,
+		OutputDir:   outputDir,
+		DryRun:      true,
+	})
+	files := dryRunFiles(t, data, err)
+	assertUnchanged(t, before)
+
+	serviceDir := filepath.Join(outputDir, "previewapi")
+	if _, err := os.Stat(serviceDir); !os.IsNotExist(err) {
+		t.Errorf("dry run created %s", serviceDir)
+	}
+	config, ok := files[filepath.Join(serviceDir, "etc", "previewapi-api.yaml")]
+	if !ok || config.Status != "created" || !strings.Contains(config.Diff, "+Port: 18934") {
+		t.Errorf("config change = %+v", config)
+	}
+	if _, ok := files[filepath.Join(serviceDir, "go.mod")]; !ok {
+		t.Errorf("go.mod missing from %v", files)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Nothing was written") {
+		t.Errorf("message = %s", text)
+	}
+}
+
+func TestDryRunLeavesMissingOutputDir(t *testing.T) {
+	useFakeGoctl(t, `mkdir -p "$dir/internal/handler"
+printf 'package handler\n' > "$dir/internal/handler/routes.go"`)
+	tmpDir := t.TempDir()
+	writeFiles(t, tmpDir, map[string]string{
+		"demo.api": "syntax = \"v1\"\n\nservice demo-api {\n\t@handler PingHandler\n\tget /ping\n}\n",
+	})
+	outputDir := filepath.Join(tmpDir, "missing", "demo")
+
+	_, data, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:   filepath.Join(tmpDir, "demo.api"),
+		OutputDir: outputDir,
+		Style:     "go_zero",
+		DryRun:    true,
+	})
+	files := dryRunFiles(t, data, err)
+	if _, ok := files[filepath.Join(outputDir, "internal", "handler", "routes.go")]; !ok {
+		t.Errorf("routes.go missing from %v", files)
+	}
+
+	useFakeGoctl(t, fakeAPINewGoctl)
+	_, data, err = tools.CreateAPIService(context.Background(), &mcp.CallToolRequest{}, tools.CreateAPIServiceParams{
+		ServiceName: "previewapi",
+		OutputDir:   outputDir,
+		DryRun:      true,
+	})
+	dryRunFiles(t, data, err)
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "missing")); !os.IsNotExist(err) {
+		t.Error("dry run created the missing output directory")
+	}
+}
+
+func TestGenerateAPIFromSpecDryRun(t *testing.T) {
+	useFakeGoctl(t, `mkdir -p "$dir/internal/handler"
+printf 'package handler\n' > "$dir/internal/handler/routes.go"
+printf 'Name: demo\nPort: 9999\n' > "$dir/etc/demo.yaml"
+rm -f "$dir/stale.go"`)
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "demo")
+	writeFiles(t, outputDir, map[string]string{
+		"go.mod":        "module demo\n\ngo 1.21\n",
+		"etc/demo.yaml": "Name: demo\nPort: 8888\n",
+		"demo.go":       "package main\n\nfunc main() {}\n",
+		"stale.go":      "package main\n",
+	})
+	writeFiles(t, tmpDir, map[string]string{
+		"demo.api": "syntax = \"v1\"\n\nservice demo-api {\n\t@handler PingHandler\n\tget /ping\n}\n",
+	})
+	before, _ := snapshot.Take(outputDir)
+
+	_, data, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:   filepath.Join(tmpDir, "demo.api"),
+		OutputDir: outputDir,
+		Style:     "go_zero",
+		DryRun:    true,
+	})
+	files := dryRunFiles(t, data, err)
+	assertUnchanged(t, before)
+
+	want := map[string]string{
+		"internal/handler/routes.go": "created",
+		"etc/demo.yaml":              "modified",
+		"stale.go":                   "deleted",
+	}
+	for rel, status := range want {
+		change, ok := files[filepath.Join(outputDir, filepath.FromSlash(rel))]
+		if !ok || change.Status != status {
+			t.Errorf("%s: got %+v, want %s", rel, change, status)
+		}
+	}
+	config := files[filepath.Join(outputDir, "etc", "demo.yaml")]
+	if !strings.Contains(config.Diff, "-Port: 8888") || !strings.Contains(config.Diff, "+Port: 9999") {
+		t.Errorf("config diff:\n%s", config.Diff)
+	}
+	if deleted := files[filepath.Join(outputDir, "stale.go")]; !strings.Contains(deleted.Diff, "+++ "+os.DevNull) {
+		t.Errorf("deleted diff:\n%s", deleted.Diff)
+	}
+}
+
+func TestSingleFileToolsDryRun(t *testing.T) {
+	dir := t.TempDir()
+	existingConfig := filepath.Join(dir, "etc", "demo.yaml")
+	writeFiles(t, dir, map[string]string{"etc/demo.yaml": "Name: demo\n"})
+	before, _ := snapshot.Take(dir)
+
+	_, data, err := tools.CreateAPISpec(context.Background(), &mcp.CallToolRequest{}, tools.CreateAPISpecParams{
+		ServiceName:   "demo",
+		EndpointsJSON: `[{"method":"get","path":"/ping","handler":"PingHandler"}]`,
+		OutputPath:    filepath.Join(dir, "demo.api"),
+		DryRun:        true,
+	})
+	files := dryRunFiles(t, data, err)
+	if spec := files[filepath.Join(dir, "demo.api")]; spec.Status != "created" || !strings.Contains(spec.Diff, "+\tget /ping") {
+		t.Errorf("spec change = %+v", spec)
+	}
+
+	_, data, err = tools.GenerateConfigTemplate(context.Background(), &mcp.CallToolRequest{}, tools.GenerateConfigParams{
+		ServiceName: "demo",
+		ServiceType: "api",
+		Environment: "development",
+		Port:        8080,
+		OutputPath:  existingConfig,
+		DryRun:      true,
+	})
+	files = dryRunFiles(t, data, err)
+	if config := files[existingConfig]; config.Status != "modified" || !strings.Contains(config.Diff, "+Port: 8080") {
+		t.Errorf("config change = %+v", config)
+	}
+
+	_, data, err = tools.GenerateTemplate(context.Background(), &mcp.CallToolRequest{}, tools.GenerateTemplateParams{
+		TemplateType: "middleware",
+		TemplateName: "auth",
+		OutputPath:   filepath.Join(dir, "internal", "middleware", "auth.go"),
+		DryRun:       true,
+	})
+	files = dryRunFiles(t, data, err)
+	if len(files) != 1 {
+		t.Errorf("template changes = %+v", files)
+	}
+
+	assertUnchanged(t, before)
+	if _, err := os.Stat(filepath.Join(dir, "internal")); !os.IsNotExist(err) {
+		t.Errorf("dry run created directories")
+	}
+}
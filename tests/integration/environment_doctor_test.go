@@ -0,0 +1,50 @@
+package integration_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/doctor"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+func TestEnvironmentDoctor(t *testing.T) {
+	useFakeGoctl(t, "exit 1")
+	t.Setenv("FAKE_GOCTL_VERSION", "1.6.3")
+
+	result, data, err := tools.EnvironmentDoctor(context.Background(), &mcp.CallToolRequest{}, tools.EnvironmentDoctorParams{
+		Workspace: t.TempDir(),
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("EnvironmentDoctor failed: %v", err)
+	}
+	report := data.(map[string]any)
+	checks := map[string]doctor.Check{}
+	for _, check := range report["checks"].([]doctor.Check) {
+		checks[check.Name] = check
+	}
+	for _, name := range []string{"go", "goctl", "protoc", "protoc-gen-go", "protoc-gen-go-grpc", "GOPATH/GOMODCACHE", "GOPROXY", "workspace", "go-zero module"} {
+		if _, ok := checks[name]; !ok {
+			t.Errorf("missing check %s", name)
+		}
+	}
+	if check := checks["goctl"]; check.Status != doctor.StatusPass || !strings.HasPrefix(check.Detail, "1.6.3 at ") {
+		t.Errorf("goctl check = %+v", check)
+	}
+	if check := checks["workspace"]; check.Status != doctor.StatusPass {
+		t.Errorf("workspace check = %+v", check)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "STATUS") {
+		t.Errorf("result has no table:\n%s", text)
+	}
+}
+
+func TestEnvironmentDoctorRejectsUnknownMode(t *testing.T) {
+	_, _, err := tools.EnvironmentDoctor(context.Background(), &mcp.CallToolRequest{}, tools.EnvironmentDoctorParams{DependencyMode: "airgapped"})
+	if err == nil || !strings.Contains(err.Error(), "dependency_mode") {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/openapi"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+func TestExportOpenAPI(t *testing.T) {
+	dir := setupEndpointService(t)
+	output := filepath.Join(dir, "docs", "openapi.yaml")
+
+	result, data, err := tools.ExportOpenAPI(context.Background(), nil, tools.ExportOpenAPIParams{
+		APIFile:    filepath.Join(dir, "user.api"),
+		OutputPath: output,
+		Version:    "3.1",
+	})
+	if err != nil {
+		t.Fatalf("ExportOpenAPI failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected successful result")
+	}
+	if fields := data.(map[string]any); fields["format"] != "yaml" || fields["operations"] != 1 {
+		t.Errorf("Unexpected result data: %v", fields)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Document was not written: %v", err)
+	}
+	doc, err := openapi.Unmarshal(content)
+	if err != nil {
+		t.Fatalf("Written document does not decode: %v", err)
+	}
+	item, ok := doc.Paths.Get("/api/v1/users/{id}")
+	if doc.OpenAPI != "3.1.0" || !ok || item.Get == nil || item.Get.OperationID != "GetUser" {
+		t.Errorf("Unexpected document:\n%s", content)
+	}
+}
+
+func TestExportOpenAPIInline(t *testing.T) {
+	dir := setupEndpointService(t)
+
+	result, data, err := tools.ExportOpenAPI(context.Background(), nil, tools.ExportOpenAPIParams{APIFile: filepath.Join(dir, "user.api")})
+	if err != nil || result.IsError {
+		t.Fatalf("ExportOpenAPI failed: %v", err)
+	}
+	content := data.(map[string]any)["content"].(string)
+	if !strings.HasPrefix(content, "{\n  \"openapi\": \"3.0.3\"") {
+		t.Errorf("Expected a JSON document, got:\n%s", content)
+	}
+}
+
+func TestExportOpenAPIValidation(t *testing.T) {
+	dir := setupEndpointService(t)
+
+	tests := []struct {
+		name   string
+		params tools.ExportOpenAPIParams
+	}{
+		{"missing api file", tools.ExportOpenAPIParams{}},
+		{"nonexistent api file", tools.ExportOpenAPIParams{APIFile: filepath.Join(dir, "nope.api")}},
+		{"bad format", tools.ExportOpenAPIParams{APIFile: filepath.Join(dir, "user.api"), Format: "xml"}},
+		{"bad version", tools.ExportOpenAPIParams{APIFile: filepath.Join(dir, "user.api"), Version: "2.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := tools.ExportOpenAPI(context.Background(), nil, tt.params)
+			if err == nil || !result.IsError {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package integration_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useFakeGoctl installs a shell script as goctl for the duration of the test
+// The script answers --version with $FAKE_GOCTL_VERSION, default 1.8.4; otherwise the
+// body runs after "dir" is set from the -dir flag, if any
+func useFakeGoctl(t *testing.T, body string) {
+	t.Helper()
+
+	script := `#!/bin/sh
+if [ "$1" = "--version" ]; then
+  echo "goctl version ${FAKE_GOCTL_VERSION:-1.8.4} linux/amd64"
+  exit 0
+fi
+dir="$PWD"
+args="$*"
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -dir|--dir) dir="$2"; shift ;;
+  esac
+  shift
+done
+` + body + "\n"
+
+	path := filepath.Join(t.TempDir(), "goctl")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOCTL_PATH", path)
+}
+
+// writeFiles creates files relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+const unformattedSpec = `syntax = "v1"
+
+type User struct {
+  Id int64 ` + "`json:\"id\"`" + `
+    Name string ` + "`json:\"name\"`" + ` // display name
+}
+
+service users-api {
+  @handler GetUser
+  GET /users/:id returns (User)
+}
+`
+
+func TestFormatAPISpecCheck(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"users.api": unformattedSpec})
+	apiFile := filepath.Join(dir, "users.api")
+
+	result, data, err := tools.FormatAPISpec(context.Background(), nil, tools.FormatAPISpecParams{APIFile: apiFile, Check: true})
+	if err != nil {
+		t.Fatalf("FormatAPISpec failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected successful result")
+	}
+	fields := data.(map[string]any)
+	if fields["changed"] != true {
+		t.Error("Expected the file to need formatting")
+	}
+	unified := fields["diff"].(string)
+	for _, want := range []string{"--- " + apiFile, "-type User struct {", "+type User {", "+\tName string `json:\"name\"` // display name", "+\tget /users/:id returns (User)"} {
+		if !strings.Contains(unified, want) {
+			t.Errorf("Expected diff to contain %q, got:\n%s", want, unified)
+		}
+	}
+
+	content, _ := os.ReadFile(apiFile)
+	if string(content) != unformattedSpec {
+		t.Error("Check mode must not write the file")
+	}
+}
+
+func TestFormatAPISpecWrite(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"users.api": unformattedSpec})
+	apiFile := filepath.Join(dir, "users.api")
+
+	if _, _, err := tools.FormatAPISpec(context.Background(), nil, tools.FormatAPISpecParams{APIFile: apiFile}); err != nil {
+		t.Fatalf("FormatAPISpec failed: %v", err)
+	}
+	content, _ := os.ReadFile(apiFile)
+	if !strings.Contains(string(content), "\tId   int64  `json:\"id\"`\n\tName string `json:\"name\"` // display name\n") {
+		t.Errorf("Expected aligned fields, got:\n%s", content)
+	}
+
+	// A formatted file is left alone
+	_, data, err := tools.FormatAPISpec(context.Background(), nil, tools.FormatAPISpecParams{APIFile: apiFile, Check: true})
+	if err != nil {
+		t.Fatalf("FormatAPISpec failed: %v", err)
+	}
+	if data.(map[string]any)["changed"] != false {
+		t.Error("Expected a formatted file to be unchanged")
+	}
+}
+
+func TestCreateAPISpecIsFormatted(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "shop.api")
+	_, _, err := tools.CreateAPISpec(context.Background(), nil, tools.CreateAPISpecParams{
+		ServiceName:   "shop",
+		EndpointsJSON: `[{"method":"post","path":"/orders","handler":"CreateOrder","request":{"item_id":1,"quantity":1,"note":"x"},"response":{"id":1}}]`,
+		OutputPath:    output,
+	})
+	if err != nil {
+		t.Fatalf("CreateAPISpec failed: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	formatted, err := apispec.FormatSource(output, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(formatted) != string(content) {
+		t.Errorf("create_api_spec output is not formatted:\n%s", content)
+	}
+}
+
+func TestFormatAPISpecValidation(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"broken.api": "type {"})
+
+	for _, params := range []tools.FormatAPISpecParams{
+		{},
+		{APIFile: filepath.Join(dir, "missing.api")},
+		{APIFile: filepath.Join(dir, "broken.api")},
+	} {
+		result, _, err := tools.FormatAPISpec(context.Background(), nil, params)
+		if err == nil || !result.IsError {
+			t.Errorf("Expected an error for %+v", params)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/goctl"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+// templateGoctl records its arguments and answers goctl template init with a set of
+// default templates; FAKE_TEMPLATE_REV stands in for the goctl release
+const templateGoctl = `echo "$args" >> "$FAKE_GOCTL_LOG"
+set -- $args
+if [ "$1 $2" = "template init" ]; then
+  rev="${FAKE_TEMPLATE_REV:-1}"
+  mkdir -p "$4/api"
+  printf 'handler %s\n' "$rev" > "$4/api/handler.tpl"
+  printf 'main %s\n' "$rev" > "$4/api/main.tpl"
+  if [ "$rev" -gt 1 ]; then
+    mkdir -p "$4/rpc"
+    printf 'logic %s\n' "$rev" > "$4/rpc/logic.tpl"
+  fi
+fi`
+
+// useTemplateGoctl installs templateGoctl and returns the file its arguments are logged to
+func useTemplateGoctl(t *testing.T) string {
+	t.Helper()
+	useFakeGoctl(t, templateGoctl)
+	log := filepath.Join(t.TempDir(), "goctl.log")
+	t.Setenv("FAKE_GOCTL_LOG", log)
+	return log
+}
+
+// goctlCalls returns the logged goctl command lines
+func goctlCalls(t *testing.T, log string) []string {
+	t.Helper()
+	content, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func TestGenerateAPIFromSpecUsesGoctlDirectory(t *testing.T) {
+	log := useTemplateGoctl(t)
+	apiFile, outputDir := setupRollbackProject(t)
+	home := filepath.Join(filepath.Dir(outputDir), ".goctl")
+	if err := os.Mkdir(home, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	result, data, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:   apiFile,
+		OutputDir: outputDir,
+		Style:     "go_zero",
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("generation failed: %s", failureText(result, err))
+	}
+	calls := goctlCalls(t, log)
+	if len(calls) != 1 || !strings.HasPrefix(calls[0], "api go ") || !strings.HasSuffix(calls[0], "--home "+home) {
+		t.Errorf("goctl calls = %q, want api go with --home %s", calls, home)
+	}
+	templates := data.(map[string]any)["templates"].(goctl.Templates)
+	if templates.Home != home || templates.Source != ".goctl directory" {
+		t.Errorf("templates = %+v", templates)
+	}
+}
+
+func TestGenerateAPIFromSpecUsesConfiguredRemote(t *testing.T) {
+	log := useTemplateGoctl(t)
+	apiFile, outputDir := setupRollbackProject(t)
+	root := filepath.Dir(outputDir)
+	writeFiles(t, root, map[string]string{
+		".goctl/api/handler.tpl": "ignored\n",
+		".mcp-zero.yaml":         "templates:\n  remote: https://example.com/templates.git\n  branch: v2\n",
+	})
+
+	result, _, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:   apiFile,
+		OutputDir: outputDir,
+		Style:     "go_zero",
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("generation failed: %s", failureText(result, err))
+	}
+	calls := goctlCalls(t, log)
+	if len(calls) != 1 || !strings.HasSuffix(calls[0], "--remote https://example.com/templates.git --branch v2") || strings.Contains(calls[0], "--home") {
+		t.Errorf("goctl calls = %q, want the configured remote only", calls)
+	}
+}
+
+func TestGenerateAPIFromSpecRejectsMissingTemplateHome(t *testing.T) {
+	useTemplateGoctl(t)
+	apiFile, outputDir := setupRollbackProject(t)
+
+	_, _, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:      apiFile,
+		OutputDir:    outputDir,
+		TemplateHome: filepath.Join(t.TempDir(), "missing"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "template_home") {
+		t.Errorf("expected a template_home validation error, got %v", err)
+	}
+
+	_, _, err = tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:        apiFile,
+		OutputDir:      outputDir,
+		TemplateBranch: "v2",
+	})
+	if err == nil || !strings.Contains(err.Error(), "template_remote") {
+		t.Errorf("expected template_branch without template_remote to be rejected, got %v", err)
+	}
+}
+
+// templateStatuses returns the reported templates as "path status" keys
+func templateStatuses(t *testing.T, data any) map[string]bool {
+	t.Helper()
+	encoded, err := json.Marshal(data.(map[string]any)["files"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []struct{ Path, Status string }
+	if err := json.Unmarshal(encoded, &files); err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[string]bool{}
+	for _, file := range files {
+		statuses[file.Path+" "+file.Status] = true
+	}
+	return statuses
+}
+
+func TestManageGoctlTemplates(t *testing.T) {
+	useTemplateGoctl(t)
+	projectDir := t.TempDir()
+	home := filepath.Join(projectDir, ".goctl")
+	manage := func(action string) (string, map[string]bool) {
+		t.Helper()
+		result, data, err := tools.ManageGoctlTemplates(context.Background(), &mcp.CallToolRequest{}, tools.ManageGoctlTemplatesParams{
+			Action:     action,
+			ProjectDir: projectDir,
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("%s failed: %s", action, failureText(result, err))
+		}
+		return result.Content[0].(*mcp.TextContent).Text, templateStatuses(t, data)
+	}
+	readHome := func(rel string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(home, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	if _, _, err := tools.ManageGoctlTemplates(context.Background(), &mcp.CallToolRequest{}, tools.ManageGoctlTemplatesParams{Action: "diff", ProjectDir: projectDir}); err == nil {
+		t.Error("expected diff without a template home to fail")
+	}
+
+	_, statuses := manage("init")
+	if len(statuses) != 2 || !statuses["api/handler.tpl created"] || !statuses["api/main.tpl created"] {
+		t.Errorf("init reported %v", statuses)
+	}
+	if got := readHome("api/handler.tpl"); got != "handler 1\n" {
+		t.Errorf("api/handler.tpl = %q", got)
+	}
+
+	if _, statuses = manage("diff"); len(statuses) != 0 {
+		t.Errorf("fresh home differs from the defaults: %v", statuses)
+	}
+
+	writeFiles(t, home, map[string]string{
+		"api/main.tpl": "main 1 with logging\n",
+		"api/old.tpl":  "no longer used\n",
+	})
+	text, statuses := manage("diff")
+	if len(statuses) != 2 || !statuses["api/main.tpl customized"] || !statuses["api/old.tpl unused"] {
+		t.Errorf("diff reported %v", statuses)
+	}
+	if !strings.Contains(text, "+main 1 with logging") {
+		t.Errorf("diff output lacks the customization:\n%s", text)
+	}
+
+	// A goctl upgrade changes every default and adds a template
+	t.Setenv("FAKE_TEMPLATE_REV", "2")
+	if _, statuses = manage("diff"); !statuses["api/handler.tpl outdated"] || !statuses["rpc/logic.tpl missing"] {
+		t.Errorf("diff after the upgrade reported %v", statuses)
+	}
+
+	text, statuses = manage("update")
+	if len(statuses) != 3 || !statuses["api/handler.tpl updated"] || !statuses["api/main.tpl conflict"] || !statuses["rpc/logic.tpl added"] {
+		t.Errorf("update reported %v", statuses)
+	}
+	if !strings.Contains(text, "-main 1") || !strings.Contains(text, "+main 2") {
+		t.Errorf("update output lacks the upstream change to the conflicting template:\n%s", text)
+	}
+	for rel, content := range map[string]string{
+		"api/handler.tpl": "handler 2\n",
+		"api/main.tpl":    "main 1 with logging\n",
+		"rpc/logic.tpl":   "logic 2\n",
+		"api/old.tpl":     "no longer used\n",
+	} {
+		if got := readHome(rel); got != content {
+			t.Errorf("%s = %q after update, want %q", rel, got, content)
+		}
+	}
+
+	// The baseline now records the new defaults, so the customization no longer conflicts
+	if _, statuses = manage("update"); len(statuses) != 1 || !statuses["api/main.tpl customized"] {
+		t.Errorf("second update reported %v", statuses)
+	}
+}
@@ -0,0 +1,152 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/analyzer"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+const legacyOpenAPI = `openapi: 3.0.3
+info:
+  title: Legacy Billing
+  version: "1.0"
+paths:
+  /invoices/{id}:
+    get:
+      tags: [invoice]
+      operationId: getInvoice
+      security:
+        - bearer: []
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+            format: int64
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Invoice"
+components:
+  securitySchemes:
+    bearer:
+      type: http
+      scheme: bearer
+  schemas:
+    Invoice:
+      type: object
+      required: [id]
+      properties:
+        id:
+          type: integer
+          format: int64
+        extra:
+          oneOf:
+            - type: string
+            - type: integer
+`
+
+func TestImportOpenAPI(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"openapi.yaml": legacyOpenAPI})
+	output := filepath.Join(dir, "api", "billing.api")
+
+	result, data, err := tools.ImportOpenAPI(context.Background(), nil, tools.ImportOpenAPIParams{
+		OpenAPIFile: filepath.Join(dir, "openapi.yaml"),
+		OutputPath:  output,
+	})
+	if err != nil {
+		t.Fatalf("ImportOpenAPI failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected successful result")
+	}
+
+	fields := data.(map[string]any)
+	if fields["service_name"] != "legacy-billing" {
+		t.Errorf("Expected service name from the title, got %v", fields["service_name"])
+	}
+	if warnings := strings.Join(fields["warnings"].([]string), "\n"); !strings.Contains(warnings, "Invoice.extra: oneOf/anyOf cannot be represented") {
+		t.Errorf("Expected a oneOf warning, got %q", warnings)
+	}
+
+	spec, err := analyzer.ParseAPISpecification(output, nil)
+	if err != nil {
+		t.Fatalf("Imported spec does not parse: %v", err)
+	}
+	if len(spec.Endpoints) != 1 {
+		t.Fatalf("Expected one endpoint, got %+v", spec.Endpoints)
+	}
+	ep := spec.Endpoints[0]
+	if ep.Method != "GET" || ep.Path != "/invoices/:id" || ep.Handler != "GetInvoice" || ep.Group != "invoice" || ep.JWT != "Bearer" || ep.Response != "Invoice" {
+		t.Errorf("Unexpected endpoint: %+v", ep)
+	}
+
+	// An existing spec is never overwritten
+	result, _, err = tools.ImportOpenAPI(context.Background(), nil, tools.ImportOpenAPIParams{
+		OpenAPIFile: filepath.Join(dir, "openapi.yaml"),
+		OutputPath:  output,
+	})
+	if err == nil || !result.IsError {
+		t.Error("Expected an error when the output file exists")
+	}
+}
+
+func TestImportOpenAPIGenerateFailure(t *testing.T) {
+	useFakeGoctl(t, `echo "boom" >&2; exit 1`)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"openapi.yaml": legacyOpenAPI})
+	output := filepath.Join(dir, "billing.api")
+
+	result, _, err := tools.ImportOpenAPI(context.Background(), nil, tools.ImportOpenAPIParams{
+		OpenAPIFile: filepath.Join(dir, "openapi.yaml"),
+		OutputPath:  output,
+		Generate:    true,
+		OutputDir:   dir,
+	})
+	if err == nil || !result.IsError {
+		t.Fatal("Expected an error when code generation fails")
+	}
+	if !strings.Contains(err.Error(), "spec written to") {
+		t.Errorf("Expected the error to mention the written spec, got %v", err)
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Error("The imported spec should be kept when generation fails")
+	}
+}
+
+func TestImportOpenAPIValidation(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"openapi.yaml": legacyOpenAPI,
+		"other.yaml":   "title: not openapi\n",
+	})
+
+	tests := []struct {
+		name   string
+		params tools.ImportOpenAPIParams
+	}{
+		{"missing file", tools.ImportOpenAPIParams{}},
+		{"nonexistent file", tools.ImportOpenAPIParams{OpenAPIFile: filepath.Join(dir, "nope.yaml")}},
+		{"not openapi", tools.ImportOpenAPIParams{OpenAPIFile: filepath.Join(dir, "other.yaml")}},
+		{"bad service name", tools.ImportOpenAPIParams{OpenAPIFile: filepath.Join(dir, "openapi.yaml"), ServiceName: "Bad Name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := tools.ImportOpenAPI(context.Background(), nil, tt.params)
+			if err == nil || !result.IsError {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package integration_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeromicro/mcp-zero/internal/lint"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+const lintSpec = `syntax = "v1"
+
+type GetUserReq {
+	Id int64 ` + "`path:\"id\"`" + `
+}
+
+type User {
+	Id   int64       ` + "`json:\"id\"`" + `
+	Meta interface{} ` + "`json:\"meta\"`" + `
+}
+
+service users-api {
+	@handler GetUser
+	get /users/:id (GetUserReq) returns (User)
+
+	@handler GetUser
+	get /users/:uid/profile returns (User)
+}
+`
+
+func TestLintAPISpec(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"api/users.api": lintSpec})
+
+	result, data, err := tools.LintAPISpec(context.Background(), nil, tools.LintAPISpecParams{
+		APIFile: filepath.Join(dir, "api", "users.api"),
+	})
+	if err != nil {
+		t.Fatalf("LintAPISpec failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("Expected a report without fail_on")
+	}
+
+	fields := data.(map[string]any)
+	rules := map[string]bool{}
+	for _, issue := range fields["issues"].([]lint.Issue) {
+		rules[issue.Rule] = true
+	}
+	for _, rule := range []string{"any-type", "duplicate-handler", "path-param-tag", "missing-doc"} {
+		if !rules[rule] {
+			t.Errorf("Expected a %s issue, got %v", rule, fields["issues"])
+		}
+	}
+}
+
+func TestLintAPISpecProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		".mcp-zero.yaml": "lint:\n  rules:\n    missing-doc: false\n    any-type: false\n",
+		"api/users.api":  lintSpec,
+	})
+	apiFile := filepath.Join(dir, "api", "users.api")
+
+	_, data, err := tools.LintAPISpec(context.Background(), nil, tools.LintAPISpecParams{APIFile: apiFile})
+	if err != nil {
+		t.Fatalf("LintAPISpec failed: %v", err)
+	}
+	fields := data.(map[string]any)
+	if fields["config"] != filepath.Join(dir, ".mcp-zero.yaml") {
+		t.Errorf("Expected the project config to be used, got %v", fields["config"])
+	}
+	for _, issue := range fields["issues"].([]lint.Issue) {
+		if issue.Rule == "missing-doc" || issue.Rule == "any-type" {
+			t.Errorf("Disabled rule reported: %v", issue)
+		}
+	}
+
+	// Per-call rules override the project config, and fail_on turns errors into a failure
+	result, _, err := tools.LintAPISpec(context.Background(), nil, tools.LintAPISpecParams{
+		APIFile: apiFile,
+		Rules:   map[string]bool{"any-type": true},
+		FailOn:  "error",
+	})
+	if err == nil || !result.IsError {
+		t.Fatal("Expected fail_on=error to fail")
+	}
+	if !strings.Contains(err.Error(), "users.api:9:2: error: field User.Meta uses interface{}") {
+		t.Errorf("Expected a file:line position for the any-type issue, got %v", err)
+	}
+}
+
+func TestLintAPISpecValidation(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"users.api": lintSpec, "broken.api": "service {"})
+
+	tests := []struct {
+		name   string
+		params tools.LintAPISpecParams
+	}{
+		{"missing file", tools.LintAPISpecParams{}},
+		{"nonexistent file", tools.LintAPISpecParams{APIFile: filepath.Join(dir, "nope.api")}},
+		{"syntax error", tools.LintAPISpecParams{APIFile: filepath.Join(dir, "broken.api")}},
+		{"unknown rule", tools.LintAPISpecParams{APIFile: filepath.Join(dir, "users.api"), Rules: map[string]bool{"nope": true}}},
+		{"bad fail_on", tools.LintAPISpecParams{APIFile: filepath.Join(dir, "users.api"), FailOn: "fatal"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := tools.LintAPISpec(context.Background(), nil, tt.params)
+			if err == nil || !result.IsError {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+// setupStyledService writes a buildable service whose generated files use the go_zero style
+func setupStyledService(t *testing.T, extra map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                                "module example.com/demo\n\ngo 1.21\n",
+		"demo.go":                               "package main\n\nimport _ \"example.com/demo/internal/handler\"\n\nfunc main() {}\n",
+		"internal/config/config.go":             "package config\n\ntype Config struct{}\n",
+		"internal/handler/routes.go":            "package handler\n",
+		"internal/handler/get_user_handler.go":  "package handler\n\nfunc GetUserHandler() {}\n",
+		"internal/logic/get_user_logic.go":      "package logic\n\ntype GetUserLogic struct{}\n",
+		"internal/logic/get_user_logic_test.go": "package logic\n",
+		"internal/svc/service_context.go":       "package svc\n\ntype ServiceContext struct{}\n",
+	}
+	for rel, content := range extra {
+		files[rel] = content
+	}
+	writeFiles(t, dir, files)
+	return dir
+}
+
+func TestMigrateStyleRenamesFiles(t *testing.T) {
+	dir := setupStyledService(t, nil)
+
+	result, data, err := tools.MigrateStyle(context.Background(), &mcp.CallToolRequest{}, tools.MigrateStyleParams{
+		ProjectDir: dir,
+		To:         "goZero",
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("migration failed: %v %v", err, data)
+	}
+
+	for _, rel := range []string{
+		"internal/handler/getUserHandler.go",
+		"internal/handler/routes.go",
+		"internal/logic/getUserLogic.go",
+		"internal/logic/getUserLogic_test.go",
+		"internal/svc/serviceContext.go",
+		"internal/config/config.go",
+	} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel))); err != nil {
+			t.Errorf("%s missing after migration", rel)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "internal", "svc", "service_context.go")); !os.IsNotExist(err) {
+		t.Error("service_context.go was not renamed")
+	}
+
+	fields := data.(map[string]any)
+	if fields["mixed"] != false {
+		t.Errorf("styles still mixed: %v", fields["styles"])
+	}
+}
+
+func TestMigrateStyleDryRun(t *testing.T) {
+	dir := setupStyledService(t, nil)
+	before, err := snapshot.Take(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, data, err := tools.MigrateStyle(context.Background(), &mcp.CallToolRequest{}, tools.MigrateStyleParams{
+		ProjectDir: dir,
+		To:         "gozero",
+		DryRun:     true,
+	})
+	files := dryRunFiles(t, data, err)
+
+	if files[filepath.Join(dir, "internal", "svc", "servicecontext.go")].Status != "created" ||
+		files[filepath.Join(dir, "internal", "svc", "service_context.go")].Status != "deleted" {
+		t.Errorf("unexpected dry run files: %v", files)
+	}
+	assertUnchanged(t, before)
+}
+
+func TestMigrateStyleRollsBackBrokenBuild(t *testing.T) {
+	dir := setupStyledService(t, map[string]string{
+		"internal/logic/broken_logic.go": "package logic\n\nvar _ = undefined\n",
+	})
+	before, err := snapshot.Take(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, _, err := tools.MigrateStyle(context.Background(), &mcp.CallToolRequest{}, tools.MigrateStyleParams{
+		ProjectDir: dir,
+		To:         "goZero",
+	})
+	if text := failureText(result, err); !strings.Contains(text, "Rolled back") {
+		t.Fatalf("expected the build failure to be rolled back, got %s", text)
+	}
+	assertUnchanged(t, before)
+}
+
+func TestMigrateStyleRejectsInvalidStyle(t *testing.T) {
+	_, _, err := tools.MigrateStyle(context.Background(), &mcp.CallToolRequest{}, tools.MigrateStyleParams{
+		ProjectDir: t.TempDir(),
+		To:         "snake_case",
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid style") {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
+
+func TestMigrateStyleCustomFormatNeedsGoctl14(t *testing.T) {
+	for version, want := range map[string]string{
+		"1.2.0": "older than 1.3.0",
+		"1.3.4": "goctl --style custom formats requires goctl 1.4.0 or newer",
+	} {
+		useFakeGoctl(t, "exit 0")
+		t.Setenv("FAKE_GOCTL_VERSION", version)
+		_, _, err := tools.MigrateStyle(context.Background(), &mcp.CallToolRequest{}, tools.MigrateStyleParams{
+			ProjectDir: setupStyledService(t, nil),
+			To:         "Go-Zero",
+		})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("goctl %s: expected %q, got %v", version, want, err)
+		}
+	}
+
+	// A classic style needs no goctl at all, a custom format does
+	empty := t.TempDir()
+	t.Setenv("GOCTL_PATH", "")
+	t.Setenv("HOME", empty)
+	t.Setenv("GOPATH", empty)
+	t.Setenv("PATH", empty)
+	_, _, err := tools.MigrateStyle(context.Background(), &mcp.CallToolRequest{}, tools.MigrateStyleParams{
+		ProjectDir: setupStyledService(t, nil),
+		To:         "Go-Zero",
+	})
+	if err == nil || !strings.Contains(err.Error(), "goctl not found") {
+		t.Errorf("expected a missing goctl to be reported, got %v", err)
+	}
+}
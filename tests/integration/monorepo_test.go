@@ -0,0 +1,175 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+// monorepoGoctl generates a main package that imports an internal package by absolute path,
+// the way goctl does before imports are fixed
+const monorepoGoctl = `mkdir -p "$dir/internal/handler"
+printf 'package handler\n\nfunc Register() {}\n' > "$dir/internal/handler/routes.go"
+printf 'package main\n\nimport "%s/internal/handler"\n\nfunc main() { handler.Register() }\n' "$dir" > "$dir/demo.go"`
+
+func writeDemoSpec(t *testing.T, dir string) string {
+	t.Helper()
+	writeFiles(t, dir, map[string]string{
+		"demo.api": "syntax = \"v1\"\n\nservice demo-api {\n\t@handler PingHandler\n\tget /ping\n}\n",
+	})
+	return filepath.Join(dir, "demo.api")
+}
+
+func TestGenerateAPIFromSpecInsideMonorepo(t *testing.T) {
+	useFakeGoctl(t, monorepoGoctl)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"go.mod": "module example.com/mono\n\ngo 1.21\n"})
+	outputDir := filepath.Join(root, "services", "demo")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	result, data, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:   writeDemoSpec(t, t.TempDir()),
+		OutputDir: outputDir,
+		Style:     "go_zero",
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("generation failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, "go.mod")); !os.IsNotExist(err) {
+		t.Error("a nested go.mod was created inside the monorepo")
+	}
+	content, _ := os.ReadFile(filepath.Join(outputDir, "demo.go"))
+	if !strings.Contains(string(content), `"example.com/mono/services/demo/internal/handler"`) {
+		t.Errorf("import path not derived from the monorepo module:\n%s", content)
+	}
+
+	fields := data.(map[string]any)
+	if fields["module_path"] != "example.com/mono/services/demo" || fields["new_module"] != false || fields["module_root"] != root {
+		t.Errorf("unexpected module data: %v", fields)
+	}
+}
+
+func TestGenerateAPIFromSpecAddToWorkspace(t *testing.T) {
+	useFakeGoctl(t, monorepoGoctl)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.work":       "go 1.21\n\nuse ./shared\n",
+		"shared/go.mod": "module example.com/shared\n\ngo 1.21\n",
+	})
+	outputDir := filepath.Join(root, "demo")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	result, data, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:        writeDemoSpec(t, t.TempDir()),
+		OutputDir:      outputDir,
+		Style:          "go_zero",
+		ModulePath:     "example.com/demo",
+		AddToWorkspace: true,
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("generation failed: %v", err)
+	}
+
+	goMod, _ := os.ReadFile(filepath.Join(outputDir, "go.mod"))
+	if !strings.Contains(string(goMod), "module example.com/demo") {
+		t.Errorf("go.mod does not declare module_path:\n%s", goMod)
+	}
+	goWork, _ := os.ReadFile(filepath.Join(root, "go.work"))
+	if !strings.Contains(string(goWork), "./demo") {
+		t.Errorf("module not added to go.work:\n%s", goWork)
+	}
+	if fields := data.(map[string]any); fields["added_to_workspace"] != true {
+		t.Errorf("added_to_workspace not reported: %v", fields)
+	}
+}
+
+func TestGenerateAPIFromSpecOutsideWorkspace(t *testing.T) {
+	useFakeGoctl(t, monorepoGoctl)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.work":       "go 1.21\n\nuse ./shared\n",
+		"shared/go.mod": "module example.com/shared\n\ngo 1.21\n",
+	})
+	outputDir := filepath.Join(root, "demo")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without add_to_workspace the new module builds on its own with GOWORK=off
+	result, _, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:   writeDemoSpec(t, t.TempDir()),
+		OutputDir: outputDir,
+		Style:     "go_zero",
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("generation failed: %v", err)
+	}
+	goWork, _ := os.ReadFile(filepath.Join(root, "go.work"))
+	if strings.Contains(string(goWork), "./demo") {
+		t.Errorf("go.work was changed without add_to_workspace:\n%s", goWork)
+	}
+}
+
+func TestGenerateAPIFromSpecInsideMonorepoDryRun(t *testing.T) {
+	useFakeGoctl(t, monorepoGoctl)
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"go.mod": "module example.com/mono\n\ngo 1.21\n"})
+	outputDir := filepath.Join(root, "services", "demo")
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, data, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:   writeDemoSpec(t, t.TempDir()),
+		OutputDir: outputDir,
+		Style:     "go_zero",
+		DryRun:    true,
+	})
+	files := dryRunFiles(t, data, err)
+	if _, ok := files[filepath.Join(outputDir, "go.mod")]; ok {
+		t.Errorf("dry run reports a nested go.mod: %v", files)
+	}
+	change, ok := files[filepath.Join(outputDir, "demo.go")]
+	if !ok || !strings.Contains(change.Diff, "example.com/mono/services/demo/internal/handler") {
+		t.Errorf("dry run import path not derived from the monorepo module: %+v", change)
+	}
+}
+
+func TestGenerateAPIFromSpecAddToWorkspaceDryRun(t *testing.T) {
+	useFakeGoctl(t, monorepoGoctl)
+	root := t.TempDir()
+	goWork := "go 1.21\n\nuse ./shared\n"
+	writeFiles(t, root, map[string]string{
+		"go.work":       goWork,
+		"shared/go.mod": "module example.com/shared\n\ngo 1.21\n",
+	})
+	outputDir := filepath.Join(root, "demo")
+
+	_, data, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:        writeDemoSpec(t, t.TempDir()),
+		OutputDir:      outputDir,
+		Style:          "go_zero",
+		ModulePath:     "example.com/demo",
+		AddToWorkspace: true,
+		DryRun:         true,
+	})
+	files := dryRunFiles(t, data, err)
+	change, ok := files[filepath.Join(root, "go.work")]
+	if !ok || change.Status != "modified" || !strings.Contains(change.Diff, "+\t./demo") {
+		t.Errorf("dry run does not preview the go.work change: %+v", change)
+	}
+	if content, _ := os.ReadFile(filepath.Join(root, "go.work")); string(content) != goWork {
+		t.Errorf("dry run changed go.work:\n%s", content)
+	}
+}
@@ -0,0 +1,64 @@
+package integration_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+func TestGenerateAPIFromSpecOffline(t *testing.T) {
+	useFakeGoctl(t, monorepoGoctl)
+	t.Setenv("GOMODCACHE", t.TempDir())
+	t.Setenv("GOFLAGS", "-modcacherw")
+
+	result, data, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:        writeDemoSpec(t, t.TempDir()),
+		OutputDir:      t.TempDir(),
+		Style:          "go_zero",
+		ModulePath:     "example.com/demo",
+		DependencyMode: fixer.DepsOffline,
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("offline generation failed: %v", err)
+	}
+	if mode := data.(map[string]any)["dependency_mode"]; mode != fixer.DepsOffline {
+		t.Errorf("dependency_mode = %v, want offline", mode)
+	}
+}
+
+func TestGenerateAPIFromSpecServerWideVendorMode(t *testing.T) {
+	useFakeGoctl(t, monorepoGoctl)
+	fixer.SetDefaultDependencies(fixer.Dependencies{Mode: fixer.DepsVendor})
+	t.Cleanup(func() { fixer.SetDefaultDependencies(fixer.Dependencies{Mode: fixer.DepsOnline}) })
+
+	outputDir := t.TempDir()
+	_, _, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:    writeDemoSpec(t, t.TempDir()),
+		OutputDir:  outputDir,
+		Style:      "go_zero",
+		ModulePath: "example.com/demo",
+	})
+	if err == nil || !strings.Contains(err.Error(), "vendor/modules.txt") {
+		t.Fatalf("expected the missing vendor directory to be reported, got %v", err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(outputDir, "*")); len(matches) != 0 {
+		t.Errorf("failed generation was not rolled back: %v", matches)
+	}
+}
+
+func TestDependencyModeValidation(t *testing.T) {
+	_, _, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:        writeDemoSpec(t, t.TempDir()),
+		OutputDir:      t.TempDir(),
+		DependencyMode: "airgapped",
+	})
+	if err == nil || !strings.Contains(err.Error(), "unknown dependency mode") {
+		t.Errorf("expected an invalid dependency mode error, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package integration_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/server"
+)
+
+func TestGenerateModelProgressNotifications(t *testing.T) {
+	useFakeGoctl(t, `mkdir -p "$dir" && printf 'package model\n\ntype Users struct{ Id int64 }\n' > "$dir/usersmodel.go"`)
+	outputDir := t.TempDir()
+
+	var mu sync.Mutex
+	var progress []*mcp.ProgressNotificationParams
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "test"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, req.Params)
+		},
+	})
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.NewServer("mcp-zero", "test").Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	params := &mcp.CallToolParams{
+		Meta: mcp.Meta{"progressToken": "model-1"},
+		Name: "generate_model",
+		Arguments: map[string]any{
+			"source_type": "mysql",
+			"source":      "user:pass@tcp(127.0.0.1:3306)/app",
+			"table":       "users",
+			"output_dir":  outputDir,
+		},
+	}
+	result, err := session.CallTool(ctx, params)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	if result.IsError {
+		t.Fatalf("generate_model failed: %s", text)
+	}
+	for _, want := range []string{"Steps (", "1. goctl model:", "5. go build:", "6. go vet:"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("result missing %q:\n%s", want, text)
+		}
+	}
+
+	// Notifications are delivered asynchronously; wait for the final one
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(progress)
+		mu.Unlock()
+		if n >= 7 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(progress) != 7 {
+		t.Fatalf("got %d progress notifications, want 7: %+v", len(progress), progress)
+	}
+	wantSteps := []string{"goctl model", "fix imports", "go mod init", "go mod tidy", "go build", "go vet", ""}
+	for i, p := range progress {
+		if p.ProgressToken != "model-1" || p.Total != 6 || p.Progress != float64(i) {
+			t.Errorf("notification %d = %+v", i, p)
+		}
+		if p.Meta["step"] != wantSteps[i] {
+			t.Errorf("notification %d step = %v, want %q", i, p.Meta["step"], wantSteps[i])
+		}
+	}
+	if !strings.HasPrefix(progress[0].Message, "[1/6] goctl model") || !strings.HasPrefix(progress[6].Message, "[6/6] done") {
+		t.Errorf("unexpected messages: %q ... %q", progress[0].Message, progress[6].Message)
+	}
+}
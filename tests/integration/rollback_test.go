@@ -0,0 +1,127 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+// brokenGoctl generates code that fails go build and rewrites the existing config
+const brokenGoctl = `mkdir -p "$dir/internal/handler"
+printf 'package handler\n' > "$dir/internal/handler/routes.go"
+printf 'package main\n\nfunc broken() { undefined() }\n' > "$dir/broken.go"
+printf 'Name: demo\nPort: 9999\n' > "$dir/etc/demo.yaml"`
+
+// failureText returns the failure a tool reported: the Go error, or the text of an error
+// result, which carries structured data such as verification diagnostics
+func failureText(result *mcp.CallToolResult, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if result != nil && result.IsError && len(result.Content) > 0 {
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+	return ""
+}
+
+func setupRollbackProject(t *testing.T) (apiFile, outputDir string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	outputDir = filepath.Join(tmpDir, "demo")
+	writeFiles(t, outputDir, map[string]string{
+		"go.mod":        "module demo\n\ngo 1.21\n",
+		"etc/demo.yaml": "Name: demo\nPort: 8888\n",
+		"demo.go":       "package main\n\nfunc main() {}\n",
+	})
+	apiFile = filepath.Join(tmpDir, "demo.api")
+	writeFiles(t, tmpDir, map[string]string{
+		"demo.api": "syntax = \"v1\"\n\nservice demo-api {\n\t@handler PingHandler\n\tget /ping\n}\n",
+	})
+	return apiFile, outputDir
+}
+
+func TestGenerateAPIFromSpecRollsBackOnFailure(t *testing.T) {
+	useFakeGoctl(t, brokenGoctl)
+	apiFile, outputDir := setupRollbackProject(t)
+
+	result, data, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:   apiFile,
+		OutputDir: outputDir,
+		Style:     "go_zero",
+	})
+	if !result.IsError {
+		t.Fatal("expected generation to fail at go build")
+	}
+	text := failureText(result, err)
+	for _, want := range []string{"failed to verify build", "Rolled back", "removed (2): broken.go, internal/handler/routes.go", "restored (1): etc/demo.yaml"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("error missing %q:\n%s", want, text)
+		}
+	}
+	diagnostics, _ := data.(map[string]any)["diagnostics"].([]fixer.Diagnostic)
+	if len(diagnostics) != 1 || diagnostics[0].File != "broken.go" || diagnostics[0].Line != 3 || !strings.Contains(diagnostics[0].Message, "undefined") {
+		t.Errorf("unexpected diagnostics: %+v", diagnostics)
+	}
+
+	content, _ := os.ReadFile(filepath.Join(outputDir, "etc", "demo.yaml"))
+	if string(content) != "Name: demo\nPort: 8888\n" {
+		t.Errorf("config not restored: %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "internal")); !os.IsNotExist(err) {
+		t.Errorf("generated directory internal/ was left behind")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "go.mod")); err != nil {
+		t.Errorf("existing go.mod was removed: %v", err)
+	}
+}
+
+func TestGenerateAPIFromSpecKeepOnFailure(t *testing.T) {
+	useFakeGoctl(t, brokenGoctl)
+	apiFile, outputDir := setupRollbackProject(t)
+
+	result, _, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:       apiFile,
+		OutputDir:     outputDir,
+		Style:         "go_zero",
+		KeepOnFailure: true,
+	})
+	text := failureText(result, err)
+	if text == "" {
+		t.Fatal("expected generation to fail at go build")
+	}
+	if !strings.Contains(text, "keep_on_failure is set") || !strings.Contains(text, "created (2): broken.go, internal/handler/routes.go") {
+		t.Errorf("error does not report the kept output:\n%s", text)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "broken.go")); err != nil {
+		t.Errorf("failed output was not kept: %v", err)
+	}
+}
+
+func TestCreateAPIServiceRollsBackNewServiceDir(t *testing.T) {
+	useFakeGoctl(t, `set -- $args
+mkdir -p "$PWD/$3/etc" && printf 'package main\n\nfunc main() { broken }\n' > "$PWD/$3/main.go"`)
+	outputDir := t.TempDir()
+
+	result, _, err := tools.CreateAPIService(context.Background(), &mcp.CallToolRequest{}, tools.CreateAPIServiceParams{
+		ServiceName: "brokenapi",
+		Port:        18933,
+		OutputDir:   outputDir,
+	})
+	text := failureText(result, err)
+	if text == "" {
+		t.Fatal("expected service creation to fail")
+	}
+	if !strings.Contains(text, "Rolled back") {
+		t.Errorf("error does not report the rollback:\n%s", text)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "brokenapi")); !os.IsNotExist(err) {
+		t.Errorf("service directory was left behind")
+	}
+}
@@ -0,0 +1,251 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/server"
+	"github.com/zeromicro/mcp-zero/internal/validation"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+const apiConfig = "Name: demo-api\nHost: 0.0.0.0\nPort: 8888\n"
+
+// setupRoots allows only a new workspace directory and returns it with a directory outside
+// it holding a config file, and a symlink inside the workspace pointing to that directory
+func setupRoots(t *testing.T) (workspace, outside, escape string) {
+	t.Helper()
+	base := t.TempDir()
+	workspace = filepath.Join(base, "workspace")
+	outside = filepath.Join(base, "outside")
+	writeFiles(t, workspace, map[string]string{"etc/demo-api.yaml": apiConfig})
+	writeFiles(t, outside, map[string]string{"etc/demo-api.yaml": apiConfig})
+	escape = filepath.Join(workspace, "escape")
+	if err := os.Symlink(outside, escape); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	if err := validation.SetAllowedRoots([]string{workspace}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { validation.SetAllowedRoots(nil) })
+	return workspace, outside, escape
+}
+
+// expectOutsideRoots fails unless the call was refused for leaving the allowed roots
+func expectOutsideRoots(t *testing.T, result *mcp.CallToolResult, err error, root string) {
+	t.Helper()
+	if result == nil || !result.IsError {
+		t.Fatalf("call outside the allowed roots succeeded: %v", err)
+	}
+	text := failureText(result, nil)
+	if !strings.Contains(text, "outside the allowed roots") || !strings.Contains(text, filepath.Base(root)) {
+		t.Errorf("error does not name the allowed roots:\n%s", text)
+	}
+}
+
+func TestValidateConfigOutsideRoots(t *testing.T) {
+	workspace, outside, escape := setupRoots(t)
+	ctx := context.Background()
+
+	for name, path := range map[string]string{
+		"absolute path": filepath.Join(outside, "etc", "demo-api.yaml"),
+		"dot dot":       workspace + "/etc/../../outside/etc/demo-api.yaml",
+		"symlink":       filepath.Join(escape, "etc", "demo-api.yaml"),
+		"system file":   "/etc/passwd",
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, _, err := tools.ValidateConfig(ctx, &mcp.CallToolRequest{}, tools.ValidateConfigParams{ConfigPath: path, ServiceType: "api"})
+			expectOutsideRoots(t, result, err, workspace)
+		})
+	}
+
+	result, _, err := tools.ValidateConfig(ctx, &mcp.CallToolRequest{}, tools.ValidateConfigParams{
+		ConfigPath:  filepath.Join(workspace, "etc", "demo-api.yaml"),
+		ServiceType: "api",
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("config inside the workspace was refused: %s", failureText(result, err))
+	}
+}
+
+func TestGenerateOutsideRootsWritesNothing(t *testing.T) {
+	workspace, outside, escape := setupRoots(t)
+	ctx := context.Background()
+
+	for name, dir := range map[string]string{
+		"absolute path": filepath.Join(outside, "generated"),
+		"dot dot":       workspace + "/../outside/generated",
+		"symlink":       filepath.Join(escape, "generated"),
+		"non-ASCII":     workspace + "/sub/a\u012fb/../../../outside/generated",
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, _, err := tools.GenerateTemplate(ctx, &mcp.CallToolRequest{}, tools.GenerateTemplateParams{
+				TemplateType: "middleware",
+				TemplateName: "logging",
+				OutputPath:   filepath.Join(dir, "logging.go"),
+			})
+			expectOutsideRoots(t, result, err, workspace)
+
+			result, _, err = tools.GenerateConfigTemplate(ctx, &mcp.CallToolRequest{}, tools.GenerateConfigParams{
+				ServiceName: "demo-api",
+				ServiceType: "api",
+				Environment: "development",
+				OutputPath:  filepath.Join(dir, "demo-api.yaml"),
+			})
+			expectOutsideRoots(t, result, err, workspace)
+
+			result, _, err = tools.UpdateConfig(ctx, &mcp.CallToolRequest{}, tools.UpdateConfigParams{
+				ConfigPath: filepath.Join(dir, "..", "etc", "demo-api.yaml"),
+				Edits:      []string{"Port=9999"},
+			})
+			expectOutsideRoots(t, result, err, workspace)
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(outside, "generated")); !os.IsNotExist(err) {
+		t.Error("a tool created files outside the allowed roots")
+	}
+	if data, _ := os.ReadFile(filepath.Join(outside, "etc", "demo-api.yaml")); string(data) != apiConfig {
+		t.Errorf("a tool rewrote a config outside the allowed roots:\n%s", data)
+	}
+
+	result, _, err := tools.GenerateTemplate(ctx, &mcp.CallToolRequest{}, tools.GenerateTemplateParams{
+		TemplateType: "middleware",
+		TemplateName: "logging",
+		OutputPath:   filepath.Join(workspace, "middleware", "logging.go"),
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("generation inside the workspace was refused: %s", failureText(result, err))
+	}
+}
+
+func TestAPIImportOutsideRoots(t *testing.T) {
+	workspace, _, _ := setupRoots(t)
+	writeFiles(t, filepath.Dir(workspace), map[string]string{"shared/types.api": "syntax = \"v1\"\n\ntype Secret {\n\tValue string `json:\"value\"`\n}\n"})
+	writeFiles(t, workspace, map[string]string{"demo.api": "syntax = \"v1\"\n\nimport \"../shared/types.api\"\n\nservice demo-api {\n\t@handler ping\n\tget /ping\n}\n"})
+
+	result, _, err := tools.LintAPISpec(context.Background(), &mcp.CallToolRequest{}, tools.LintAPISpecParams{APIFile: filepath.Join(workspace, "demo.api")})
+	expectOutsideRoots(t, result, err, workspace)
+}
+
+func TestDiffContractRevisionImportsInsideRoots(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	workspace, _, _ := setupRoots(t)
+	writeFiles(t, workspace, map[string]string{
+		"types.api": "syntax = \"v1\"\n\ntype Pong {\n\tValue string `json:\"value\"`\n}\n",
+		"demo.api":  "syntax = \"v1\"\n\nimport \"types.api\"\n\nservice demo-api {\n\t@handler ping\n\tget /ping returns (Pong)\n}\n",
+	})
+	for _, args := range [][]string{{"init", "-q"}, {"add", "-A"}, {"commit", "-q", "-m", "v1"}} {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = workspace
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	// The revision's imports are read from a checkout outside the roots, but come from the workspace
+	result, _, err := tools.DiffContract(context.Background(), nil, tools.DiffContractParams{
+		NewFile:  filepath.Join(workspace, "demo.api"),
+		Revision: "HEAD",
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("diff against a revision inside the roots was refused: %s", failureText(result, err))
+	}
+}
+
+// connectWithRoots connects a new client listing roots to srv
+func connectWithRoots(t *testing.T, srv *mcp.Server, roots ...string) (*mcp.Client, *mcp.ClientSession) {
+	t.Helper()
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := srv.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatal(err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "test"}, nil)
+	for _, root := range roots {
+		client.AddRoots(&mcp.Root{Name: filepath.Base(root), URI: "file://" + filepath.ToSlash(root)})
+	}
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return client, session
+}
+
+// validateConfigIn calls validate_config on the api config under dir
+func validateConfigIn(t *testing.T, session *mcp.ClientSession, dir string) *mcp.CallToolResult {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "validate_config",
+		Arguments: map[string]any{"config_path": filepath.Join(dir, "etc", "demo-api.yaml"), "service_type": "api"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestClientRootsNarrowAccess(t *testing.T) {
+	base := t.TempDir()
+	first := filepath.Join(base, "first")
+	second := filepath.Join(base, "second")
+	writeFiles(t, first, map[string]string{"etc/demo-api.yaml": apiConfig})
+	writeFiles(t, second, map[string]string{"etc/demo-api.yaml": apiConfig})
+
+	client, session := connectWithRoots(t, server.NewServer("mcp-zero", "test"), first)
+	if result := validateConfigIn(t, session, first); result.IsError {
+		t.Fatalf("config inside the client's root was refused: %s", failureText(result, nil))
+	}
+	expectOutsideRoots(t, validateConfigIn(t, session, second), nil, first)
+
+	// A changed roots list is picked up once the client's notification arrives
+	client.AddRoots(&mcp.Root{Name: "second", URI: "file://" + filepath.ToSlash(second)})
+	client.RemoveRoots("file://" + filepath.ToSlash(first))
+	deadline := time.Now().Add(5 * time.Second)
+	for validateConfigIn(t, session, second).IsError {
+		if time.Now().After(deadline) {
+			t.Fatal("the client's new root was never applied")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	expectOutsideRoots(t, validateConfigIn(t, session, first), nil, second)
+}
+
+func TestClientRootsPerSession(t *testing.T) {
+	base := t.TempDir()
+	first := filepath.Join(base, "first")
+	second := filepath.Join(base, "second")
+	writeFiles(t, first, map[string]string{"etc/demo-api.yaml": apiConfig})
+	writeFiles(t, second, map[string]string{"etc/demo-api.yaml": apiConfig})
+
+	// Sessions sharing one server, as over the http and sse transports
+	srv := server.NewServer("mcp-zero", "test")
+	_, firstSession := connectWithRoots(t, srv, first)
+	_, secondSession := connectWithRoots(t, srv, second)
+	_, unrestricted := connectWithRoots(t, srv)
+
+	for _, session := range []*mcp.ClientSession{firstSession, secondSession, unrestricted} {
+		validateConfigIn(t, session, first) // every session's roots are known before the checks
+	}
+	if result := validateConfigIn(t, firstSession, first); result.IsError {
+		t.Fatalf("config inside the first client's root was refused: %s", failureText(result, nil))
+	}
+	expectOutsideRoots(t, validateConfigIn(t, firstSession, second), nil, first)
+	if result := validateConfigIn(t, secondSession, second); result.IsError {
+		t.Fatalf("config inside the second client's root was refused: %s", failureText(result, nil))
+	}
+	expectOutsideRoots(t, validateConfigIn(t, secondSession, first), nil, second)
+	if result := validateConfigIn(t, unrestricted, second); result.IsError {
+		t.Fatalf("another client's roots narrowed a client without roots: %s", failureText(result, nil))
+	}
+}
@@ -0,0 +1,273 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/zeromicro/mcp-zero/internal/server"
+)
+
+var expectedTools = []string{
+	"add_api_endpoint",
+	"add_rpc_method",
+	"analyze_project",
+	"create_api_service",
+	"create_api_spec",
+	"create_rpc_service",
+	"diff_contract",
+	"doctor",
+	"environment_doctor",
+	"export_openapi",
+	"format_api_spec",
+	"generate_api_from_spec",
+	"generate_config_template",
+	"generate_model",
+	"generate_template",
+	"import_openapi",
+	"lint_api_spec",
+	"manage_goctl_templates",
+	"migrate_style",
+	"query_docs",
+	"update_config",
+	"validate_config",
+}
+
+// bearerTransport adds an Authorization header to every request
+type bearerTransport struct {
+	token string
+}
+
+func (b *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// connectClient connects an MCP client to a fresh mcp-zero server over the named transport
+func connectClient(t *testing.T, transport string, authToken string, clientToken string) (*mcp.ClientSession, error) {
+	t.Helper()
+
+	ctx := context.Background()
+	mcpServer := server.NewServer("mcp-zero", "test")
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "test"}, nil)
+
+	httpClient := &http.Client{}
+	if clientToken != "" {
+		httpClient.Transport = &bearerTransport{token: clientToken}
+	}
+
+	switch transport {
+	case server.TransportStdio:
+		// stdio framing is exercised through the equivalent in-memory transport
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		if _, err := mcpServer.Connect(ctx, serverTransport, nil); err != nil {
+			t.Fatalf("Failed to connect server: %v", err)
+		}
+		return client.Connect(ctx, clientTransport, nil)
+	case server.TransportHTTP:
+		handler, err := server.NewHTTPHandler(mcpServer, transport, authToken)
+		if err != nil {
+			t.Fatalf("NewHTTPHandler failed: %v", err)
+		}
+		httpServer := httptest.NewServer(handler)
+		t.Cleanup(httpServer.Close)
+		return client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: httpServer.URL, HTTPClient: httpClient, MaxRetries: -1}, nil)
+	case server.TransportSSE:
+		handler, err := server.NewHTTPHandler(mcpServer, transport, authToken)
+		if err != nil {
+			t.Fatalf("NewHTTPHandler failed: %v", err)
+		}
+		httpServer := httptest.NewServer(handler)
+		t.Cleanup(httpServer.Close)
+		return client.Connect(ctx, &mcp.SSEClientTransport{Endpoint: httpServer.URL, HTTPClient: httpClient}, nil)
+	}
+
+	t.Fatalf("unknown transport %s", transport)
+	return nil, nil
+}
+
+func resultText(result *mcp.CallToolResult) string {
+	text := ""
+	for _, content := range result.Content {
+		if tc, ok := content.(*mcp.TextContent); ok {
+			text += tc.Text
+		}
+	}
+	return text
+}
+
+func TestToolsOverEveryTransport(t *testing.T) {
+	transports := []string{server.TransportStdio, server.TransportHTTP, server.TransportSSE}
+
+	// A shared input file gives byte-for-byte comparable output across transports
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("Name: testapi\nHost: 0.0.0.0\nPort: 8888\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputs := make(map[string]string)
+
+	for _, transport := range transports {
+		t.Run(transport, func(t *testing.T) {
+			session, err := connectClient(t, transport, "", "")
+			if err != nil {
+				t.Fatalf("Failed to connect over %s: %v", transport, err)
+			}
+			defer session.Close()
+
+			ctx := context.Background()
+
+			listed, err := session.ListTools(ctx, nil)
+			if err != nil {
+				t.Fatalf("ListTools failed: %v", err)
+			}
+
+			var names []string
+			for _, tool := range listed.Tools {
+				names = append(names, tool.Name)
+			}
+			sort.Strings(names)
+
+			if len(names) != len(expectedTools) {
+				t.Fatalf("Expected %d tools, got %d: %v", len(expectedTools), len(names), names)
+			}
+			for i, name := range expectedTools {
+				if names[i] != name {
+					t.Errorf("Tool %d = %s, want %s", i, names[i], name)
+				}
+			}
+
+			result, err := session.CallTool(ctx, &mcp.CallToolParams{
+				Name:      "query_docs",
+				Arguments: map[string]any{"query": "middleware"},
+			})
+			if err != nil {
+				t.Fatalf("CallTool query_docs failed: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("query_docs returned error: %s", resultText(result))
+			}
+
+			result, err = session.CallTool(ctx, &mcp.CallToolParams{
+				Name:      "validate_config",
+				Arguments: map[string]any{"config_path": configPath, "service_type": "api"},
+			})
+			if err != nil {
+				t.Fatalf("CallTool validate_config failed: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("validate_config returned error: %s", resultText(result))
+			}
+			outputs[transport] = resultText(result)
+
+			// File-writing tools must work the same way too
+			tmpDir := t.TempDir()
+			outputPath := filepath.Join(tmpDir, "etc", "testapi.yaml")
+			result, err = session.CallTool(ctx, &mcp.CallToolParams{
+				Name: "generate_config_template",
+				Arguments: map[string]any{
+					"service_name": "testapi",
+					"service_type": "api",
+					"environment":  "development",
+					"output_path":  outputPath,
+				},
+			})
+			if err != nil {
+				t.Fatalf("CallTool generate_config_template failed: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("generate_config_template returned error: %s", resultText(result))
+			}
+			if _, err := os.Stat(outputPath); err != nil {
+				t.Errorf("Config file not created over %s: %v", transport, err)
+			}
+
+			// Tool errors are reported in-band, not as protocol errors
+			result, err = session.CallTool(ctx, &mcp.CallToolParams{
+				Name:      "validate_config",
+				Arguments: map[string]any{"config_path": filepath.Join(tmpDir, "missing.yaml")},
+			})
+			if err != nil {
+				t.Fatalf("CallTool validate_config failed: %v", err)
+			}
+			if !result.IsError {
+				t.Errorf("Expected validate_config to report an error over %s", transport)
+			}
+		})
+	}
+
+	for _, transport := range transports[1:] {
+		if outputs[transport] != outputs[server.TransportStdio] {
+			t.Errorf("validate_config output over %s differs from stdio", transport)
+		}
+	}
+}
+
+func TestHTTPTransportBearerAuth(t *testing.T) {
+	tests := []struct {
+		name        string
+		transport   string
+		clientToken string
+		expectError bool
+	}{
+		{"http with valid token", server.TransportHTTP, "secret", false},
+		{"http without token", server.TransportHTTP, "", true},
+		{"http with wrong token", server.TransportHTTP, "wrong", true},
+		{"sse with valid token", server.TransportSSE, "secret", false},
+		{"sse without token", server.TransportSSE, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := connectClient(t, tt.transport, "secret", tt.clientToken)
+			if tt.expectError {
+				if err == nil {
+					session.Close()
+					t.Fatal("Expected connection to be rejected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected connection to succeed, got: %v", err)
+			}
+			defer session.Close()
+
+			if _, err := session.ListTools(context.Background(), nil); err != nil {
+				t.Errorf("ListTools failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestServeShutsDownOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(ctx, server.NewServer("mcp-zero", "test"), server.TransportOptions{
+			Transport: server.TransportHTTP,
+			Addr:      "127.0.0.1:0",
+		})
+	}()
+
+	cancel()
+
+	if err := <-errCh; err != nil {
+		t.Errorf("Serve returned error after cancel: %v", err)
+	}
+}
+
+func TestServeRejectsUnknownTransport(t *testing.T) {
+	err := server.Serve(context.Background(), server.NewServer("mcp-zero", "test"), server.TransportOptions{
+		Transport: "websocket",
+	})
+	if err == nil {
+		t.Error("Expected error for unsupported transport")
+	}
+}
@@ -0,0 +1,67 @@
+package integration_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+	"github.com/zeromicro/mcp-zero/tools"
+)
+
+// vetGoctl generates a sub-package that builds but fails go vet, and a file gofmt would change
+const vetGoctl = `mkdir -p "$dir/internal/logic"
+printf 'package logic\n\nimport "fmt"\n\nfunc Ping() string { return fmt.Sprintf("%%d", "ping") }\n' > "$dir/internal/logic/ping_logic.go"
+printf 'package logic\n\nfunc  Pong() {}\n' > "$dir/internal/logic/pong_logic.go"`
+
+func TestGenerateAPIFromSpecReportsDiagnostics(t *testing.T) {
+	useFakeGoctl(t, vetGoctl)
+	apiFile, outputDir := setupRollbackProject(t)
+
+	result, data, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:   apiFile,
+		OutputDir: outputDir,
+		Style:     "go_zero",
+		Verify:    []string{"vet", "gofmt"},
+	})
+	if err != nil || !result.IsError {
+		t.Fatalf("expected go vet to fail with an error result, got %v", err)
+	}
+	text := failureText(result, err)
+	if !strings.Contains(text, "failed to verify vet") || !strings.Contains(text, "Rolled back") {
+		t.Errorf("unexpected error:\n%s", text)
+	}
+
+	report := data.(map[string]any)
+	if checks := report["failed_checks"].([]string); len(checks) != 1 || checks[0] != fixer.CheckVet {
+		t.Errorf("failed_checks = %v, want [vet]", checks)
+	}
+	diagnostics := report["diagnostics"].([]fixer.Diagnostic)
+	if len(diagnostics) != 1 {
+		t.Fatalf("diagnostics = %+v, want one", diagnostics)
+	}
+	d := diagnostics[0]
+	if d.Check != fixer.CheckVet || d.File != "internal/logic/ping_logic.go" || d.Line != 5 || !strings.Contains(d.Message, "wrong type") {
+		t.Errorf("unexpected diagnostic: %+v", d)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "internal")); !os.IsNotExist(err) {
+		t.Errorf("generated directory internal/ was left behind")
+	}
+}
+
+func TestGenerateAPIFromSpecRejectsUnknownCheck(t *testing.T) {
+	apiFile, outputDir := setupRollbackProject(t)
+
+	_, _, err := tools.GenerateAPIFromSpec(context.Background(), &mcp.CallToolRequest{}, tools.GenerateAPIFromSpecParams{
+		APIFile:   apiFile,
+		OutputDir: outputDir,
+		Verify:    []string{"staticcheck"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "verify") {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+}
@@ -0,0 +1,402 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/analyzer"
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+	"github.com/zeromicro/mcp-zero/internal/goctl"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// AddAPIEndpointParams defines the parameters for add_api_endpoint tool
+type AddAPIEndpointParams struct {
+	ServicePath    string       `json:"service_path"`
+	Group          string       `json:"group,omitempty"`
+	Prefix         string       `json:"prefix,omitempty"`
+	Method         string       `json:"method"`
+	Path           string       `json:"path"`
+	Handler        string       `json:"handler"`
+	Doc            string       `json:"doc,omitempty"`
+	RequestType    string       `json:"request_type,omitempty"`
+	RequestFields  []FieldInput `json:"request_fields,omitempty"`
+	ResponseType   string       `json:"response_type,omitempty"`
+	ResponseFields []FieldInput `json:"response_fields,omitempty"`
+	Style          string       `json:"style,omitempty"`
+	KeepOnFailure  bool         `json:"keep_on_failure,omitempty"`
+}
+
+// fieldsHint is the suggestion shown for invalid request or response fields
+const fieldsHint = "Give every field a name and a type such as string, []*User or map[string]int; tags are one line without backticks"
+
+// FieldInput defines a field of a request or response type
+type FieldInput struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+// AddAPIEndpoint adds a route to an existing .api file and regenerates the service incrementally
+func AddAPIEndpoint(ctx context.Context, req *mcp.CallToolRequest, params AddAPIEndpointParams) (*mcp.CallToolResult, any, error) {
+	if params.ServicePath == "" {
+		return responses.FormatValidationError("service_path", "", "service_path is required", "Provide the service directory or its .api file")
+	}
+	if !isIdentifier(params.Handler) {
+		return responses.FormatValidationError("handler", params.Handler, "handler must be a Go identifier", "Use a name such as GetUser")
+	}
+	if !strings.HasPrefix(params.Path, "/") {
+		return responses.FormatValidationError("path", params.Path, "path must start with /", "Use a path such as /users/:id")
+	}
+	method := strings.ToLower(params.Method)
+	switch method {
+	case "get", "head", "post", "put", "patch", "delete", "options":
+	default:
+		return responses.FormatValidationError("method", params.Method, "unsupported HTTP method", "Use get, post, put, patch, delete, head or options")
+	}
+
+	serviceDir, apiFile, err := resolveServiceAPIFile(ctx, params.ServicePath)
+	if err != nil {
+		return responses.FormatValidationError("service_path", params.ServicePath, err.Error(), pathHint(err, "Provide the service directory containing exactly one root .api file, or the .api file itself"))
+	}
+
+	// Hold the lock from reading the .api files until the service is regenerated; the route
+	// may go into an imported file outside the service directory
+	lockTargets := []string{serviceDir, apiFile}
+	if loaded, err := apispec.Load(apiFile, importCheck(ctx)); err == nil {
+		for _, file := range loaded.Files {
+			lockTargets = append(lockTargets, file.Path)
+		}
+	}
+	held, err := lockPaths(ctx, false, lockTargets...)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
+
+	spec, err := analyzer.ParseAPISpecification(apiFile, importCheck(ctx))
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to parse API specification: %v", err))
+	}
+
+	route := apispec.NewRoute{
+		Method:   method,
+		Path:     params.Path,
+		Handler:  params.Handler,
+		Doc:      params.Doc,
+		Request:  params.RequestType,
+		Response: params.ResponseType,
+	}
+	baseName := strings.TrimSuffix(params.Handler, "Handler")
+	pathParams := (&apispec.Route{Path: params.Path}).PathParams()
+
+	var types []apispec.NewType
+	if len(params.RequestFields) > 0 {
+		if route.Request == "" {
+			route.Request = baseName + "Req"
+		}
+		typ, err := buildNewType(route.Request, params.RequestFields, method, pathParams)
+		if err != nil {
+			return responses.FormatValidationError("request_fields", route.Request, err.Error(), fieldsHint)
+		}
+		types = append(types, typ)
+	}
+	if len(params.ResponseFields) > 0 {
+		if route.Response == "" {
+			route.Response = baseName + "Resp"
+		}
+		typ, err := buildNewType(route.Response, params.ResponseFields, "", nil)
+		if err != nil {
+			return responses.FormatValidationError("response_fields", route.Response, err.Error(), fieldsHint)
+		}
+		types = append(types, typ)
+	}
+
+	edit, err := apispec.AddRoute(spec.AST, params.Group, params.Prefix, route, types)
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to add endpoint: %v", err))
+	}
+
+	style := params.Style
+	if style == "" {
+		style = fixer.SuggestStyleBasedOnExisting(serviceDir, "go_zero")
+	}
+
+	// Regenerate with the templates the service was generated with
+	templates, err := resolveTemplates(ctx, serviceDir, "", "", "")
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+
+	executor, err := goctl.NewExecutor()
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to create executor: %v", err))
+	}
+	executor.Templates = templates
+	if err := executor.RequireStyle(style); err != nil {
+		return responses.FormatValidationError("style", style, err.Error(), styleUpgradeHint)
+	}
+
+	// Any failure restores the edited spec and the service tree
+	snapshots, err := editSnapshots(serviceDir, edit.Path)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	before := snapshots[0]
+
+	var importChanges []fixer.ImportChange
+	var changes *snapshot.Changes
+	var protected []string
+	steps := newPipeline(req).
+		Add("write .api file", func(ctx context.Context) error {
+			if err := os.WriteFile(edit.Path, edit.Source, 0644); err != nil {
+				return fmt.Errorf("failed to write API file: %w", err)
+			}
+			return nil
+		}).
+		Add("goctl api go", func(ctx context.Context) error {
+			result := executor.ExecuteContext(ctx, "api", "go", "-api", apiFile, "-dir", serviceDir, "-style", style)
+			if result.Error != nil {
+				return fmt.Errorf("failed to regenerate API code: %v\nStderr: %s", result.Error, result.Stderr)
+			}
+			return nil
+		}).
+		Add("fix imports", func(ctx context.Context) error {
+			module, err := fixer.DetectModule(serviceDir)
+			if err != nil || module.Root == "" {
+				return nil
+			}
+			if importChanges, err = fixer.FixImports(serviceDir, module.ImportPath); err != nil {
+				return fmt.Errorf("failed to fix imports: %w", err)
+			}
+			return nil
+		}).
+		// goctl leaves existing logic files alone, but never let hand-written code be replaced
+		Add("keep logic files", func(ctx context.Context) error {
+			after, err := before.Retake()
+			if err != nil {
+				return err
+			}
+			changes = before.Compare(after)
+			var modified []string
+			for _, path := range changes.Modified {
+				if strings.HasPrefix(path, "internal/logic/") {
+					if err := before.Restore(path); err != nil {
+						return fmt.Errorf("failed to restore logic file %s: %w", path, err)
+					}
+					protected = append(protected, path)
+					continue
+				}
+				modified = append(modified, path)
+			}
+			changes.Modified = modified
+			return nil
+		}).
+		Add("check style conflicts", func(ctx context.Context) error {
+			if err := fixer.ValidateNoStyleConflicts(serviceDir); err != nil {
+				return fmt.Errorf("style conflicts detected after generation: %w", err)
+			}
+			return nil
+		})
+	addVerifySteps(steps, serviceDir, fixer.DefaultVerifyOptions())
+
+	timings, err := runTransaction(ctx, steps, params.KeepOnFailure, snapshots...)
+	if err != nil {
+		return failure(err)
+	}
+
+	fullPath := params.Path
+	if prefix := strings.Trim(params.Prefix, "/"); prefix != "" {
+		fullPath = "/" + prefix + params.Path
+	}
+
+	message := fmt.Sprintf("Successfully added endpoint %s %s → %s\n\nAPI file: %s\n", strings.ToUpper(method), fullPath, params.Handler, edit.Path)
+	if edit.NewBlock {
+		message += "Created a new service block for this group/prefix\n"
+	}
+	for _, typ := range types {
+		message += fmt.Sprintf("Added type: %s\n", typ.Name)
+	}
+
+	categories := []struct {
+		title string
+		dir   string
+	}{
+		{"Handler files", "internal/handler"},
+		{"Logic files", "internal/logic"},
+		{"Type files", "internal/types"},
+	}
+	for _, c := range categories {
+		created := snapshot.Under(changes.Created, c.dir)
+		changed := snapshot.Under(changes.Modified, c.dir)
+		if len(created) == 0 && len(changed) == 0 {
+			continue
+		}
+		message += fmt.Sprintf("\n%s:\n", c.title)
+		for _, path := range created {
+			message += fmt.Sprintf("  created: %s\n", path)
+		}
+		for _, path := range changed {
+			message += fmt.Sprintf("  changed: %s\n", path)
+		}
+	}
+	if len(protected) > 0 {
+		message += "\nExisting logic files kept unchanged:\n"
+		for _, path := range protected {
+			message += fmt.Sprintf("  %s\n", path)
+		}
+	}
+	message += templatesMessage(templates)
+	message += "\n" + timings.String()
+	message += "\nNext steps:\n"
+	message += fmt.Sprintf("  1. Implement the new logic in %s\n", strings.Join(nonEmpty(snapshot.Under(changes.Created, "internal/logic"), "internal/logic"), ", "))
+	message += "  2. go build ./...\n"
+
+	data := map[string]any{
+		"api_file":        edit.Path,
+		"service_dir":     serviceDir,
+		"method":          strings.ToUpper(method),
+		"path":            fullPath,
+		"handler":         params.Handler,
+		"new_block":       edit.NewBlock,
+		"created_files":   changes.Created,
+		"modified_files":  changes.Modified,
+		"protected_files": protected,
+		"import_changes":  importChanges,
+		"style":           style,
+		"templates":       templates,
+		"steps":           timings.Steps,
+		"elapsed_ms":      timings.ElapsedMS,
+	}
+
+	return responses.FormatSuccessWithData(message, data)
+}
+
+// resolveServiceAPIFile returns the service directory and the root .api file for a path
+func resolveServiceAPIFile(ctx context.Context, servicePath string) (string, string, error) {
+	if !filepath.IsAbs(servicePath) {
+		cwd, _ := os.Getwd()
+		servicePath = filepath.Join(cwd, servicePath)
+	}
+	if err := validation.CheckAllowed(ctx, servicePath); err != nil {
+		return "", "", err
+	}
+
+	info, err := os.Stat(servicePath)
+	if err != nil {
+		return "", "", fmt.Errorf("service path does not exist")
+	}
+	if !info.IsDir() {
+		if !strings.HasSuffix(servicePath, ".api") {
+			return "", "", fmt.Errorf("service path is not a directory or .api file")
+		}
+		return filepath.Dir(servicePath), servicePath, nil
+	}
+
+	apiFiles, err := filepath.Glob(filepath.Join(servicePath, "*.api"))
+	if err != nil || len(apiFiles) == 0 {
+		return "", "", fmt.Errorf("no .api file found in %s", servicePath)
+	}
+
+	// Files imported by another spec are not roots
+	imported := make(map[string]bool)
+	for _, apiFile := range apiFiles {
+		if spec, err := apispec.Load(apiFile, importCheck(ctx)); err == nil {
+			for _, f := range spec.Files[1:] {
+				imported[f.Path] = true
+			}
+		}
+	}
+	var roots []string
+	for _, apiFile := range apiFiles {
+		if !imported[apiFile] {
+			roots = append(roots, apiFile)
+		}
+	}
+	if len(roots) != 1 {
+		return "", "", fmt.Errorf("found %d root .api files in %s", len(roots), servicePath)
+	}
+	return servicePath, roots[0], nil
+}
+
+// buildNewType converts field inputs to a type, choosing tags the way goctl expects
+// Request fields named after a path parameter get a path tag, and GET/HEAD/DELETE
+// request fields are read from the query string with a form tag
+func buildNewType(name string, fields []FieldInput, method string, pathParams []string) (apispec.NewType, error) {
+	typ := apispec.NewType{Name: name}
+	for _, f := range fields {
+		if !isIdentifier(f.Name) || f.Type == "" {
+			return typ, fmt.Errorf("invalid field %q of type %q", f.Name, f.Type)
+		}
+		// Both go into the .api text as is, so neither may end the field early
+		if _, err := apispec.ParseTypeExpr(f.Type); err != nil {
+			return typ, fmt.Errorf("invalid type %q of field %s: %v", f.Type, f.Name, err)
+		}
+		tag := strings.Trim(f.Tag, "`")
+		if strings.ContainsAny(tag, "`\r\n") {
+			return typ, fmt.Errorf("invalid tag %q of field %s: tags cannot hold backticks or line breaks", f.Tag, f.Name)
+		}
+		if tag == "" {
+			key := lowerFirst(f.Name)
+			tagKey := "json"
+			for _, param := range pathParams {
+				if strings.EqualFold(param, f.Name) {
+					key, tagKey = param, "path"
+				}
+			}
+			if tagKey == "json" && (method == "get" || method == "head" || method == "delete") {
+				tagKey = "form"
+			}
+			tag = fmt.Sprintf(`%s:"%s"`, tagKey, key)
+		}
+		typ.Fields = append(typ.Fields, apispec.NewField{Name: f.Name, Type: f.Type, Tag: tag})
+	}
+	return typ, nil
+}
+
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	// Keep initialisms readable: ID -> id, UserID -> userID
+	upper := 0
+	for upper < len(s) && unicode.IsUpper(rune(s[upper])) {
+		upper++
+	}
+	switch {
+	case upper == len(s):
+		return strings.ToLower(s)
+	case upper > 1:
+		return strings.ToLower(s[:upper-1]) + s[upper-1:]
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+func nonEmpty(items []string, fallback string) []string {
+	if len(items) == 0 {
+		return []string{fallback}
+	}
+	return items
+}
@@ -0,0 +1,378 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/analyzer"
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+	"github.com/zeromicro/mcp-zero/internal/goctl"
+	"github.com/zeromicro/mcp-zero/internal/protospec"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// AddRPCMethodParams defines the parameters for add_rpc_method tool
+type AddRPCMethodParams struct {
+	ServiceDir      string              `json:"service_dir"`
+	ProtoFile       string              `json:"proto_file,omitempty"`
+	Service         string              `json:"service,omitempty"`
+	Method          string              `json:"method"`
+	Doc             string              `json:"doc,omitempty"`
+	RequestType     string              `json:"request_type,omitempty"`
+	RequestFields   []MessageFieldInput `json:"request_fields,omitempty"`
+	ResponseType    string              `json:"response_type,omitempty"`
+	ResponseFields  []MessageFieldInput `json:"response_fields,omitempty"`
+	ClientStreaming bool                `json:"client_streaming,omitempty"`
+	ServerStreaming bool                `json:"server_streaming,omitempty"`
+	ProtoPath       []string            `json:"proto_path,omitempty"`
+	Style           string              `json:"style,omitempty"`
+	KeepOnFailure   bool                `json:"keep_on_failure,omitempty"`
+}
+
+// MessageFieldInput defines a field of a request or response message
+type MessageFieldInput struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Number int    `json:"number,omitempty"`
+}
+
+// AddRPCMethod adds an rpc to an existing .proto file and regenerates the service
+func AddRPCMethod(ctx context.Context, req *mcp.CallToolRequest, params AddRPCMethodParams) (*mcp.CallToolResult, any, error) {
+	if params.ServiceDir == "" {
+		return responses.FormatValidationError("service_dir", "", "service_dir is required", "Provide the directory of a service created by create_rpc_service")
+	}
+	if !isIdentifier(params.Method) {
+		return responses.FormatValidationError("method", params.Method, "method must be an identifier", "Use a name such as GetUser")
+	}
+
+	serviceDir := params.ServiceDir
+	if !filepath.IsAbs(serviceDir) {
+		cwd, _ := os.Getwd()
+		serviceDir = filepath.Join(cwd, serviceDir)
+	}
+	if err := validation.CheckAllowed(ctx, serviceDir); err != nil {
+		return responses.FormatValidationError("service_dir", serviceDir, err.Error(), rootsHint)
+	}
+	if info, err := os.Stat(serviceDir); err != nil || !info.IsDir() {
+		return responses.FormatValidationError("service_dir", params.ServiceDir, "service directory does not exist", "Provide the directory of a service created by create_rpc_service")
+	}
+
+	includePaths := []string{serviceDir}
+	for _, dir := range params.ProtoPath {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(serviceDir, dir)
+		}
+		if err := validation.CheckAllowed(ctx, dir); err != nil {
+			return responses.FormatValidationError("proto_path", dir, err.Error(), rootsHint)
+		}
+		includePaths = append(includePaths, dir)
+	}
+
+	protoFile, err := resolveServiceProtoFile(ctx, serviceDir, params.ProtoFile, includePaths)
+	if err != nil {
+		return responses.FormatValidationError("proto_file", params.ProtoFile, err.Error(), pathHint(err, "Set proto_file to the service's .proto file"))
+	}
+
+	// Hold the lock from reading the .proto file until the service is regenerated; the
+	// file may live outside the service directory
+	held, err := lockPaths(ctx, false, serviceDir, protoFile)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
+
+	spec, err := analyzer.ParseProtoSpecification(protoFile, includePaths, importCheck(ctx))
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to parse proto specification: %v", err))
+	}
+
+	method := protospec.NewMethod{
+		Name:            params.Method,
+		Request:         params.RequestType,
+		Response:        params.ResponseType,
+		ClientStreaming: params.ClientStreaming,
+		ServerStreaming: params.ServerStreaming,
+		Doc:             params.Doc,
+	}
+
+	var messages []protospec.NewMessage
+	if len(params.RequestFields) > 0 || method.Request == "" {
+		if method.Request == "" {
+			method.Request = params.Method + "Req"
+		}
+		msg, err := buildNewMessage(method.Request, params.RequestFields)
+		if err != nil {
+			return responses.FormatValidationError("request_fields", method.Request, err.Error(), "Give every field a name, a type and a unique number")
+		}
+		messages = append(messages, msg)
+	}
+	if len(params.ResponseFields) > 0 || method.Response == "" {
+		if method.Response == "" {
+			method.Response = params.Method + "Resp"
+		}
+		msg, err := buildNewMessage(method.Response, params.ResponseFields)
+		if err != nil {
+			return responses.FormatValidationError("response_fields", method.Response, err.Error(), "Give every field a name, a type and a unique number")
+		}
+		messages = append(messages, msg)
+	}
+
+	edit, err := protospec.AddMethod(spec.AST, params.Service, method, messages)
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to add rpc method: %v", err))
+	}
+
+	style := params.Style
+	if style == "" {
+		style = fixer.SuggestStyleBasedOnExisting(serviceDir, "go_zero")
+	}
+
+	// Regenerate with the templates the service was generated with
+	templates, err := resolveTemplates(ctx, serviceDir, "", "", "")
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+
+	executor, err := goctl.NewExecutor()
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to create executor: %v", err))
+	}
+	executor.Templates = templates
+	if err := executor.RequireStyle(style); err != nil {
+		return responses.FormatValidationError("style", style, err.Error(), styleUpgradeHint)
+	}
+	multiple := len(spec.Services) > 1
+	if multiple {
+		if err := executor.Require(goctl.FeatureRPCMultiple); err != nil {
+			return responses.FormatError(err.Error())
+		}
+	}
+
+	// Any failure restores the edited proto and the service tree
+	snapshots, err := editSnapshots(serviceDir, edit.Path)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	before := snapshots[0]
+
+	relProto, err := filepath.Rel(serviceDir, protoFile)
+	if err != nil {
+		relProto = protoFile
+	}
+	args := rpcProtocArgs(relProto, style, includePaths[1:], multiple)
+
+	var importChanges []fixer.ImportChange
+	var changes *snapshot.Changes
+	var protected []string
+	steps := newPipeline(req).
+		Add("write .proto file", func(ctx context.Context) error {
+			if err := os.WriteFile(edit.Path, edit.Source, 0644); err != nil {
+				return fmt.Errorf("failed to write proto file: %w", err)
+			}
+			return nil
+		}).
+		Add("goctl rpc protoc", func(ctx context.Context) error {
+			result := executor.ExecuteInDirContext(ctx, serviceDir, args...)
+			if result.Error != nil {
+				return fmt.Errorf("failed to regenerate RPC code: %v\nStderr: %s%s", result.Error, result.Stderr, protocHint(result.Stderr))
+			}
+			return nil
+		}).
+		Add("fix imports", func(ctx context.Context) error {
+			module, err := fixer.DetectModule(serviceDir)
+			if err != nil || module.Root == "" {
+				return nil
+			}
+			if importChanges, err = fixer.FixImports(serviceDir, module.ImportPath); err != nil {
+				return fmt.Errorf("failed to fix imports: %w", err)
+			}
+			return nil
+		}).
+		// goctl leaves existing logic files alone, but never let hand-written code be replaced
+		Add("keep logic files", func(ctx context.Context) error {
+			after, err := before.Retake()
+			if err != nil {
+				return err
+			}
+			changes = before.Compare(after)
+			var modified []string
+			for _, path := range changes.Modified {
+				if strings.HasPrefix(path, "internal/logic/") {
+					if err := before.Restore(path); err != nil {
+						return fmt.Errorf("failed to restore logic file %s: %w", path, err)
+					}
+					protected = append(protected, path)
+					continue
+				}
+				modified = append(modified, path)
+			}
+			changes.Modified = modified
+			return nil
+		}).
+		Add("check style conflicts", func(ctx context.Context) error {
+			if err := fixer.ValidateNoStyleConflicts(serviceDir); err != nil {
+				return fmt.Errorf("style conflicts detected after generation: %w", err)
+			}
+			return nil
+		})
+	addVerifySteps(steps, serviceDir, fixer.DefaultVerifyOptions())
+
+	timings, err := runTransaction(ctx, steps, params.KeepOnFailure, snapshots...)
+	if err != nil {
+		return failure(err)
+	}
+
+	logicStubs := snapshot.Under(changes.Created, "internal/logic")
+	var pbFiles []string
+	var otherFiles []string
+	for _, path := range append(append([]string{}, changes.Created...), changes.Modified...) {
+		switch {
+		case strings.HasPrefix(path, "internal/logic/"):
+		case strings.HasSuffix(path, ".pb.go"):
+			pbFiles = append(pbFiles, path)
+		case strings.HasSuffix(path, ".go"):
+			otherFiles = append(otherFiles, path)
+		}
+	}
+
+	signature := fmt.Sprintf("rpc %s(%s) returns (%s)", method.Name, streamPrefix(method.ClientStreaming)+method.Request, streamPrefix(method.ServerStreaming)+method.Response)
+	message := fmt.Sprintf("Successfully added %s to service %s\n\nProto file: %s\n", signature, edit.Service, edit.Path)
+	for _, msg := range messages {
+		message += fmt.Sprintf("Added message: %s (%d fields)\n", msg.Name, len(msg.Fields))
+	}
+	if len(logicStubs) > 0 {
+		message += "\nNew logic stubs:\n"
+		for _, path := range logicStubs {
+			message += fmt.Sprintf("  %s\n", path)
+		}
+	}
+	if len(pbFiles) > 0 {
+		message += "\nUpdated pb/grpc files:\n"
+		for _, path := range pbFiles {
+			message += fmt.Sprintf("  %s\n", path)
+		}
+	}
+	if len(otherFiles) > 0 {
+		message += "\nOther regenerated files:\n"
+		for _, path := range otherFiles {
+			message += fmt.Sprintf("  %s\n", path)
+		}
+	}
+	if len(protected) > 0 {
+		message += "\nExisting logic files kept unchanged:\n"
+		for _, path := range protected {
+			message += fmt.Sprintf("  %s\n", path)
+		}
+	}
+	message += "\nBuild verified successfully\n"
+	message += templatesMessage(templates)
+	message += "\n" + timings.String()
+	message += "\nNext steps:\n"
+	message += fmt.Sprintf("  1. Implement the new logic in %s\n", strings.Join(nonEmpty(logicStubs, "internal/logic"), ", "))
+	message += "  2. go build ./...\n"
+
+	data := map[string]any{
+		"proto_file":      edit.Path,
+		"service_dir":     serviceDir,
+		"service":         edit.Service,
+		"method":          method.Name,
+		"logic_stubs":     logicStubs,
+		"pb_files":        pbFiles,
+		"created_files":   changes.Created,
+		"modified_files":  changes.Modified,
+		"protected_files": protected,
+		"import_changes":  importChanges,
+		"style":           style,
+		"templates":       templates,
+		"steps":           timings.Steps,
+		"elapsed_ms":      timings.ElapsedMS,
+	}
+
+	return responses.FormatSuccessWithData(message, data)
+}
+
+// resolveServiceProtoFile returns the root .proto file of a service directory
+func resolveServiceProtoFile(ctx context.Context, serviceDir, protoFile string, includePaths []string) (string, error) {
+	if protoFile != "" {
+		if !filepath.IsAbs(protoFile) {
+			protoFile = filepath.Join(serviceDir, protoFile)
+		}
+		if err := validation.CheckAllowed(ctx, protoFile); err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(protoFile); err != nil {
+			return "", fmt.Errorf("proto file does not exist")
+		}
+		return protoFile, nil
+	}
+
+	protoFiles, err := filepath.Glob(filepath.Join(serviceDir, "*.proto"))
+	if err != nil || len(protoFiles) == 0 {
+		return "", fmt.Errorf("no .proto file found in %s", serviceDir)
+	}
+
+	// Files imported by another proto are not roots
+	imported := make(map[string]bool)
+	for _, file := range protoFiles {
+		if spec, err := protospec.Load(file, includePaths, importCheck(ctx)); err == nil {
+			for _, f := range spec.Files[1:] {
+				imported[f.Path] = true
+			}
+		}
+	}
+	var roots []string
+	for _, file := range protoFiles {
+		if !imported[file] {
+			roots = append(roots, file)
+		}
+	}
+	if len(roots) != 1 {
+		return "", fmt.Errorf("found %d root .proto files in %s", len(roots), serviceDir)
+	}
+	return roots[0], nil
+}
+
+// buildNewMessage converts field inputs to a message, numbering unnumbered fields in order
+func buildNewMessage(name string, fields []MessageFieldInput) (protospec.NewMessage, error) {
+	msg := protospec.NewMessage{Name: name}
+	used := make(map[int]bool)
+	for _, f := range fields {
+		if f.Number > 0 {
+			if used[f.Number] {
+				return msg, fmt.Errorf("field number %d is used twice", f.Number)
+			}
+			used[f.Number] = true
+		}
+	}
+
+	next := 1
+	for _, f := range fields {
+		if !isIdentifier(f.Name) || strings.TrimSpace(f.Type) == "" {
+			return msg, fmt.Errorf("invalid field %q of type %q", f.Name, f.Type)
+		}
+		number := f.Number
+		if number == 0 {
+			for used[next] {
+				next++
+			}
+			number = next
+			used[number] = true
+		}
+		msg.Fields = append(msg.Fields, protospec.NewField{Name: f.Name, Type: strings.TrimSpace(f.Type), Number: number})
+	}
+	return msg, nil
+}
+
+func streamPrefix(stream bool) string {
+	if stream {
+		return "stream "
+	}
+	return ""
+}
@@ -12,7 +12,10 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/zeromicro/mcp-zero/internal/analyzer"
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+	"github.com/zeromicro/mcp-zero/internal/lock"
 	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/validation"
 )
 
 type AnalyzeProjectParams struct {
@@ -20,15 +23,19 @@ type AnalyzeProjectParams struct {
 }
 
 // Cache for project analysis results with automatic cleanup
+// Entries are keyed by the project path and the roots the scan ran under, since imports
+// outside a call's roots are refused and must not be served from another session's scan
 type analysisCache struct {
 	mu      sync.RWMutex
 	entries map[string]*cacheEntry
 }
 
 type cacheEntry struct {
-	analysis  *analyzer.ProjectAnalysis
-	timestamp time.Time
-	hits      int // Track cache hits for optimization metrics
+	projectPath string
+	analysis    *analyzer.ProjectAnalysis
+	styles      *fixer.StyleReport // nil when the naming styles could not be read
+	timestamp   time.Time
+	hits        int // Track cache hits for optimization metrics
 }
 
 var cache = &analysisCache{
@@ -67,29 +74,50 @@ func AnalyzeProject(ctx context.Context, req *mcp.CallToolRequest, params Analyz
 		}
 		projectPath = absPath
 	}
+	if err := validation.CheckAllowed(ctx, projectPath); err != nil {
+		return responses.FormatValidationError("project_path", projectPath, err.Error(), rootsHint)
+	}
+
+	// Hold off writes to the tree while it is scanned
+	held, err := lockPaths(ctx, true, projectPath)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
 
 	// Check cache
-	if cachedAnalysis := getCachedAnalysis(projectPath); cachedAnalysis != nil {
-		return formatAnalysisResult(cachedAnalysis, true)
+	key := cacheKey(projectPath, validation.AllowedRoots(ctx))
+	if cached := getCachedAnalysis(key); cached != nil {
+		return formatAnalysisResult(cached.analysis, cached.styles, true)
 	}
 
 	// Perform analysis
-	analysis, err := analyzer.ScanProject(projectPath)
+	analysis, err := analyzer.ScanProject(projectPath, importCheck(ctx))
 	if err != nil {
 		return responses.FormatError(fmt.Sprintf("failed to analyze project: %v", err))
 	}
 
+	styles, err := fixer.AnalyzeStyles(analysis.ProjectPath)
+	if err != nil {
+		styles = nil
+	}
+
 	// Cache the result
-	cacheAnalysis(projectPath, analysis)
+	cacheAnalysis(key, projectPath, analysis, styles)
 
-	return formatAnalysisResult(analysis, false)
+	return formatAnalysisResult(analysis, styles, false)
 }
 
-func getCachedAnalysis(projectPath string) *analyzer.ProjectAnalysis {
+// cacheKey identifies the analysis of projectPath under roots
+func cacheKey(projectPath string, roots []string) string {
+	return projectPath + "\x00" + strings.Join(roots, string(filepath.ListSeparator))
+}
+
+func getCachedAnalysis(key string) *cacheEntry {
 	cache.mu.Lock() // Use write lock to update hits counter
 	defer cache.mu.Unlock()
 
-	entry, exists := cache.entries[projectPath]
+	entry, exists := cache.entries[key]
 	if !exists {
 		return nil
 	}
@@ -97,17 +125,17 @@ func getCachedAnalysis(projectPath string) *analyzer.ProjectAnalysis {
 	// Check if cache is still valid
 	if time.Since(entry.timestamp) > cacheTTL {
 		// Remove expired entry immediately
-		delete(cache.entries, projectPath)
+		delete(cache.entries, key)
 		return nil
 	}
 
 	// Increment hit counter for metrics
 	entry.hits++
 
-	return entry.analysis
+	return entry
 }
 
-func cacheAnalysis(projectPath string, analysis *analyzer.ProjectAnalysis) {
+func cacheAnalysis(key, projectPath string, analysis *analyzer.ProjectAnalysis, styles *fixer.StyleReport) {
 	cache.mu.Lock()
 	defer cache.mu.Unlock()
 
@@ -116,10 +144,31 @@ func cacheAnalysis(projectPath string, analysis *analyzer.ProjectAnalysis) {
 		evictOldestEntry()
 	}
 
-	cache.entries[projectPath] = &cacheEntry{
-		analysis:  analysis,
-		timestamp: time.Now(),
-		hits:      0,
+	cache.entries[key] = &cacheEntry{
+		projectPath: projectPath,
+		analysis:    analysis,
+		styles:      styles,
+		timestamp:   time.Now(),
+		hits:        0,
+	}
+}
+
+// invalidateAnalyses drops cached analyses of trees overlapping the written paths
+func invalidateAnalyses(paths []string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	for key, entry := range cache.entries {
+		resolved, err := lock.Resolve(entry.projectPath)
+		if err != nil {
+			resolved = entry.projectPath
+		}
+		for _, path := range paths {
+			if lock.Overlaps(resolved, path) {
+				delete(cache.entries, key)
+				break
+			}
+		}
 	}
 }
 
@@ -192,7 +241,7 @@ func ClearCache() {
 	cache.entries = make(map[string]*cacheEntry)
 }
 
-func formatAnalysisResult(analysis *analyzer.ProjectAnalysis, fromCache bool) (*mcp.CallToolResult, any, error) {
+func formatAnalysisResult(analysis *analyzer.ProjectAnalysis, styles *fixer.StyleReport, fromCache bool) (*mcp.CallToolResult, any, error) {
 	var message strings.Builder
 
 	message.WriteString(fmt.Sprintf("Project Analysis: %s\n\n", analysis.ProjectPath))
@@ -273,6 +322,19 @@ func formatAnalysisResult(analysis *analyzer.ProjectAnalysis, fromCache bool) (*
 		message.WriteString("\n")
 	}
 
+	// Naming styles section
+	if styles != nil && len(styles.Styles) > 0 {
+		message.WriteString("=== Naming Styles ===\n")
+		message.WriteString(fmt.Sprintf("  %s\n", formatStyleCounts(styles)))
+		if styles.Mixed() {
+			message.WriteString(fmt.Sprintf("  Mixed styles: use migrate_style to rename everything to %s\n", styles.Dominant()))
+		}
+		for _, conflict := range styles.Conflicts {
+			message.WriteString(fmt.Sprintf("  Conflict in %s: %s\n", conflict.Dir, strings.Join(conflict.Files, ", ")))
+		}
+		message.WriteString("\n")
+	}
+
 	// Next steps
 	message.WriteString("=== Next Steps ===\n")
 	message.WriteString("  - Use generate_api_from_spec to update API services\n")
@@ -290,6 +352,11 @@ func formatAnalysisResult(analysis *analyzer.ProjectAnalysis, fromCache bool) (*
 		"go_zero_version":   analysis.Summary.GoZeroVersion,
 		"from_cache":        fromCache,
 	}
+	if styles != nil {
+		data["naming_styles"] = styles.Styles
+		data["mixed_styles"] = styles.Mixed()
+		data["style_conflicts"] = styles.Conflicts
+	}
 
 	return responses.FormatSuccessWithData(message.String(), data)
 }
@@ -3,22 +3,35 @@ package tools
 import (
 	"context"
 	"fmt"
+	"maps"
 	"path/filepath"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/zeromicro/mcp-zero/internal/fixer"
 	"github.com/zeromicro/mcp-zero/internal/goctl"
 	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
 	"github.com/zeromicro/mcp-zero/internal/validation"
 )
 
 // CreateAPIServiceParams defines the parameters for creating an API service
 type CreateAPIServiceParams struct {
-	ServiceName string `json:"service_name"`
-	Port        int    `json:"port,omitempty"`
-	OutputDir   string `json:"output_dir,omitempty"`
-	Style       string `json:"style,omitempty"`
+	ServiceName    string   `json:"service_name"`
+	Port           int      `json:"port,omitempty"`
+	OutputDir      string   `json:"output_dir,omitempty"`
+	Style          string   `json:"style,omitempty"`
+	KeepOnFailure  bool     `json:"keep_on_failure,omitempty"`
+	DryRun         bool     `json:"dry_run,omitempty"`
+	ModulePath     string   `json:"module_path,omitempty"`
+	AddToWorkspace bool     `json:"add_to_workspace,omitempty"`
+	DependencyMode string   `json:"dependency_mode,omitempty"`
+	ModuleProxy    string   `json:"module_proxy,omitempty"`
+	Verify         []string `json:"verify,omitempty"`
+	TemplateHome   string   `json:"template_home,omitempty"`
+	TemplateRemote string   `json:"template_remote,omitempty"`
+	TemplateBranch string   `json:"template_branch,omitempty"`
 }
 
 // CreateAPIService creates a new go-zero API service
@@ -48,75 +61,148 @@ func CreateAPIService(ctx context.Context, req *mcp.CallToolRequest, params Crea
 	if outputDir == "" {
 		outputDir = "."
 	}
-	if err := validation.ValidateOutputDir(outputDir); err != nil {
-		return responses.FormatValidationError("output_dir", outputDir, err.Error(), "Provide an absolute path to an existing writable directory")
+	if err := validateOutputDir(ctx, outputDir, params.DryRun); err != nil {
+		return responses.FormatValidationError("output_dir", outputDir, err.Error(), pathHint(err, "Provide an absolute path to an existing writable directory"))
 	}
 
 	// Prepare service directory
 	serviceDir := filepath.Join(outputDir, params.ServiceName)
 
-	// Execute goctl api new command
-	executor, err := goctl.NewExecutor()
+	checks, err := fixer.ResolveVerifyOptions(params.Verify)
 	if err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to create executor: %v", err))
+		return responses.FormatValidationError("verify", strings.Join(params.Verify, ","), err.Error(), "Use any of build, vet, test, gofmt or all")
 	}
 
-	// goctl api new creates service in current directory, so we execute in outputDir
-	args := []string{
-		"api",
-		"new",
-		params.ServiceName,
-		"--style", style,
-	}
-
-	result := executor.ExecuteInDir(outputDir, args...)
-	if result.Error != nil {
-		return responses.FormatError(fmt.Sprintf("failed to create API service: %v\nStderr: %s", result.Error, result.Stderr))
-	}
-
-	// Use a proper module path format (avoid module names starting with numbers)
-	moduleName := "github.com/example/" + params.ServiceName
-
-	// Fix imports
-	if err := fixer.FixImports(serviceDir, moduleName); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to fix imports: %v", err))
+	// Templates come from the parameters, the project configuration or a .goctl directory
+	templates, err := resolveTemplates(ctx, serviceDir, params.TemplateHome, params.TemplateRemote, params.TemplateBranch)
+	if err != nil {
+		return responses.FormatValidationError("template_home", params.TemplateHome, err.Error(), pathHint(err, templateHint))
 	}
 
-	// Initialize Go module
-	if err := fixer.InitializeGoModule(serviceDir, moduleName); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to initialize Go module: %v", err))
+	// Follow the enclosing module, such as a monorepo root, instead of creating a nested one
+	module, err := resolveGenerationModule(ctx, serviceDir, "github.com/example/"+params.ServiceName, moduleOptions{
+		ModulePath:     params.ModulePath,
+		AddToWorkspace: params.AddToWorkspace,
+		DependencyMode: params.DependencyMode,
+		ModuleProxy:    params.ModuleProxy,
+	})
+	if err != nil {
+		return responses.FormatError(err.Error())
 	}
 
-	// Tidy module
-	if err := fixer.TidyGoModule(serviceDir); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to tidy Go module: %v", err))
+	// Serialize with other tool calls writing to the same trees
+	held, err := lockPaths(ctx, params.DryRun, module.lockPaths()...)
+	if err != nil {
+		return responses.FormatError(err.Error())
 	}
+	defer held.Unlock()
 
-	// Update config file with port
-	if err := fixer.UpdateConfigFile(serviceDir, params.ServiceName, port); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to update config file: %v", err))
+	// A dry run generates into a staged copy of the service directory
+	var preview *dryRun
+	if params.DryRun {
+		if preview, err = newDryRun(serviceDir); err != nil {
+			return responses.FormatError(err.Error())
+		}
+		defer preview.Close()
+		outputDir, serviceDir = preview.Parent(), preview.Path
+		preview.Ignore(module.stagedOnly()...)
 	}
 
-	// Validate no style conflicts
-	if err := fixer.ValidateNoStyleConflicts(serviceDir); err != nil {
-		return responses.FormatError(fmt.Sprintf("style conflicts detected: %v", err))
+	// Execute goctl api new command
+	executor, err := goctl.NewExecutor()
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to create executor: %v", err))
 	}
-
-	// Verify build
-	if err := fixer.VerifyBuild(serviceDir); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to verify build: %v", err))
+	executor.Templates = templates
+	if err := executor.RequireStyle(style); err != nil {
+		return responses.FormatValidationError("style", style, err.Error(), styleUpgradeHint)
 	}
 
-	// Validate project structure
-	validator := goctl.NewValidator()
-	if err := validator.ValidateServiceProject(serviceDir, "api"); err != nil {
-		return responses.FormatError(fmt.Sprintf("project structure validation failed: %v", err))
+	// goctl api new creates service in current directory, so we execute in outputDir
+	args := []string{
+		"api",
+		"new",
+		params.ServiceName,
+		"--style", style,
 	}
 
-	// Return success response
-	additionalInfo := map[string]string{
-		"port":  fmt.Sprintf("%d", port),
-		"style": style,
-	}
-	return responses.FormatServiceCreated("api", params.ServiceName, serviceDir, additionalInfo)
+	moduleName := module.ImportPath
+	var importChanges []fixer.ImportChange
+	steps := newPipeline(req).
+		Add("goctl api new", func(ctx context.Context) error {
+			result := executor.ExecuteInDirContext(ctx, outputDir, args...)
+			if result.Error != nil {
+				return fmt.Errorf("failed to create API service: %v\nStderr: %s", result.Error, result.Stderr)
+			}
+			return nil
+		}).
+		Add("fix imports", func(ctx context.Context) error {
+			var err error
+			if importChanges, err = fixer.FixImports(serviceDir, moduleName); err != nil {
+				return fmt.Errorf("failed to fix imports: %w", err)
+			}
+			return nil
+		})
+	module.addSteps(steps, serviceDir, preview)
+	steps.
+		Add("update config", func(ctx context.Context) error {
+			if err := fixer.UpdateConfigFile(serviceDir, params.ServiceName, port); err != nil {
+				return fmt.Errorf("failed to update config file: %w", err)
+			}
+			return nil
+		}).
+		Add("check style conflicts", func(ctx context.Context) error {
+			if err := fixer.ValidateNoStyleConflicts(serviceDir); err != nil {
+				return fmt.Errorf("style conflicts detected: %w", err)
+			}
+			return nil
+		})
+	addVerifySteps(steps, serviceDir, checks)
+	steps.Add("validate structure", func(ctx context.Context) error {
+		if err := goctl.NewValidator().ValidateServiceProject(serviceDir, "api"); err != nil {
+			return fmt.Errorf("project structure validation failed: %w", err)
+		}
+		return nil
+	})
+
+	// Roll back everything goctl, go mod and the config update wrote if any step fails
+	var snapshots []*snapshot.Snapshot
+	if preview == nil {
+		if snapshots, err = module.snapshots(); err != nil {
+			return responses.FormatError(err.Error())
+		}
+	}
+	timings, err := runTransaction(module.context(ctx), steps, params.KeepOnFailure, snapshots...)
+	if err != nil {
+		return failure(err)
+	}
+	if preview != nil {
+		return preview.Result("create_api_service", timings)
+	}
+
+	message := fmt.Sprintf("Successfully created api service '%s'\n\nOutput directory: %s\n", params.ServiceName, serviceDir)
+	message += fmt.Sprintf("\nPort: %d\nStyle: %s\n", port, style)
+	message += templatesMessage(templates)
+	message += "\n" + timings.String()
+	message += "\nNext steps:\n"
+	message += fmt.Sprintf("  1. cd %s\n", serviceDir)
+	message += "  2. go mod tidy\n"
+	message += "  3. go run .\n"
+
+	data := map[string]any{
+		"service_type": "api",
+		"service_name": params.ServiceName,
+		"output_dir":   serviceDir,
+		"additional_info": map[string]string{
+			"port":  fmt.Sprintf("%d", port),
+			"style": style,
+		},
+		"import_changes": importChanges,
+		"checks":         checks.Checks(),
+		"templates":      templates,
+		"steps":          timings.Steps,
+		"elapsed_ms":     timings.ElapsedMS,
+	}
+	maps.Copy(data, module.data())
+	return responses.FormatSuccessWithData(message, data)
 }
@@ -7,12 +7,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/zeromicro/mcp-zero/internal/analyzer"
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+	"github.com/zeromicro/mcp-zero/internal/lint"
+	"github.com/zeromicro/mcp-zero/internal/project"
 	"github.com/zeromicro/mcp-zero/internal/responses"
 	"github.com/zeromicro/mcp-zero/internal/templates"
 	"github.com/zeromicro/mcp-zero/internal/validation"
@@ -22,6 +26,7 @@ type CreateAPISpecParams struct {
 	ServiceName   string `json:"service_name"`
 	EndpointsJSON string `json:"endpoints_json"`
 	OutputPath    string `json:"output_path,omitempty"`
+	DryRun        bool   `json:"dry_run,omitempty"`
 }
 
 type EndpointInput struct {
@@ -58,6 +63,9 @@ func CreateAPISpec(ctx context.Context, req *mcp.CallToolRequest, params CreateA
 		cwd, _ := os.Getwd()
 		outputPath = filepath.Join(cwd, outputPath)
 	}
+	if err := validation.CheckAllowed(ctx, outputPath); err != nil {
+		return responses.FormatValidationError("output_path", outputPath, err.Error(), rootsHint)
+	}
 
 	spec := templates.APISpec{
 		ServiceName: params.ServiceName,
@@ -102,6 +110,7 @@ func CreateAPISpec(ctx context.Context, req *mcp.CallToolRequest, params CreateA
 	for _, typeDef := range typeMap {
 		spec.Types = append(spec.Types, typeDef)
 	}
+	sort.Slice(spec.Types, func(i, j int) bool { return spec.Types[i].Name < spec.Types[j].Name })
 
 	tmpl, err := template.New("api").Parse(templates.APISpecTemplate)
 	if err != nil {
@@ -113,18 +122,63 @@ func CreateAPISpec(ctx context.Context, req *mcp.CallToolRequest, params CreateA
 		return responses.FormatError(fmt.Sprintf("failed to generate spec: %v", err))
 	}
 
-	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+	formatted, err := apispec.FormatSource(outputPath, buf.Bytes())
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("generated spec is invalid: %v", err))
+	}
+
+	// Serialize with other tool calls writing the spec
+	held, err := lockPaths(ctx, params.DryRun, outputPath)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
+
+	// A dry run writes the spec to a staged copy of the output file
+	writePath := outputPath
+	var preview *dryRun
+	if params.DryRun {
+		if preview, err = newDryRunFile(outputPath); err != nil {
+			return responses.FormatError(err.Error())
+		}
+		defer preview.Close()
+		writePath = preview.Path
+	}
+
+	if err := os.WriteFile(writePath, formatted, 0644); err != nil {
 		return responses.FormatError(fmt.Sprintf("failed to write spec file: %v", err))
 	}
 
-	if _, err := analyzer.ParseAPISpecification(outputPath); err != nil {
-		os.Remove(outputPath)
+	parsed, err := analyzer.ParseAPISpecification(writePath, importCheck(ctx))
+	if err != nil {
+		os.Remove(writePath)
 		return responses.FormatError(fmt.Sprintf("generated spec is invalid: %v", err))
 	}
+	if preview != nil {
+		return preview.Result("create_api_spec", nil)
+	}
+
+	// Surface lint problems in the generated spec, such as fields typed any
+	var lintIssues []lint.Issue
+	if config, err := project.LoadConfig(ctx, filepath.Dir(outputPath)); err == nil {
+		if result, err := lint.Lint(parsed.AST, config.Lint.Rules); err == nil {
+			for _, issue := range result.Issues {
+				if issue.Severity != lint.SeverityInfo {
+					lintIssues = append(lintIssues, issue)
+				}
+			}
+		}
+	}
 
 	message := fmt.Sprintf("Successfully created API specification: %s\n\nOutput file: %s\n", params.ServiceName, outputPath)
 	message += fmt.Sprintf("\nEndpoints: %d\n", len(spec.Endpoints))
 	message += fmt.Sprintf("Types: %d\n", len(spec.Types))
+	if len(lintIssues) > 0 {
+		message += fmt.Sprintf("\nLint issues (%d):\n", len(lintIssues))
+		for _, issue := range lintIssues {
+			message += fmt.Sprintf("  %s\n", issue)
+		}
+	}
 	message += "\nNext steps:\n"
 	message += "  1. Review the generated specification\n"
 	message += "  2. Use generate_api_from_spec to generate code\n"
@@ -135,6 +189,7 @@ func CreateAPISpec(ctx context.Context, req *mcp.CallToolRequest, params CreateA
 		"output_path":    outputPath,
 		"endpoint_count": len(spec.Endpoints),
 		"type_count":     len(spec.Types),
+		"lint_issues":    lintIssues,
 	}
 
 	return responses.FormatSuccessWithData(message, data)
@@ -146,7 +201,14 @@ func createTypeFromMap(name string, fields map[string]interface{}) templates.Typ
 		Fields: []templates.FieldDef{},
 	}
 
-	for fieldName, fieldValue := range fields {
+	names := make([]string, 0, len(fields))
+	for fieldName := range fields {
+		names = append(names, fieldName)
+	}
+	sort.Strings(names)
+
+	for _, fieldName := range names {
+		fieldValue := fields[fieldName]
 		goType := "string"
 		switch fieldValue.(type) {
 		case float64:
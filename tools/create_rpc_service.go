@@ -3,8 +3,10 @@ package tools
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -12,14 +14,27 @@ import (
 	"github.com/zeromicro/mcp-zero/internal/fixer"
 	"github.com/zeromicro/mcp-zero/internal/goctl"
 	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
 	"github.com/zeromicro/mcp-zero/internal/validation"
 )
 
 type CreateRPCServiceParams struct {
-	ServiceName  string `json:"service_name"`
-	ProtoContent string `json:"proto_content"`
-	OutputDir    string `json:"output_dir,omitempty"`
-	Style        string `json:"style,omitempty"`
+	ServiceName    string   `json:"service_name"`
+	ProtoContent   string   `json:"proto_content"`
+	OutputDir      string   `json:"output_dir,omitempty"`
+	Style          string   `json:"style,omitempty"`
+	ProtoPath      []string `json:"proto_path,omitempty"`
+	Multiple       bool     `json:"multiple,omitempty"`
+	KeepOnFailure  bool     `json:"keep_on_failure,omitempty"`
+	DryRun         bool     `json:"dry_run,omitempty"`
+	ModulePath     string   `json:"module_path,omitempty"`
+	AddToWorkspace bool     `json:"add_to_workspace,omitempty"`
+	DependencyMode string   `json:"dependency_mode,omitempty"`
+	ModuleProxy    string   `json:"module_proxy,omitempty"`
+	Verify         []string `json:"verify,omitempty"`
+	TemplateHome   string   `json:"template_home,omitempty"`
+	TemplateRemote string   `json:"template_remote,omitempty"`
+	TemplateBranch string   `json:"template_branch,omitempty"`
 }
 
 func CreateRPCService(ctx context.Context, req *mcp.CallToolRequest, params CreateRPCServiceParams) (*mcp.CallToolResult, any, error) {
@@ -36,93 +51,231 @@ func CreateRPCService(ctx context.Context, req *mcp.CallToolRequest, params Crea
 	if outputDir == "" {
 		outputDir = "."
 	}
-	if err := validation.ValidateOutputDir(outputDir); err != nil {
-		return responses.FormatValidationError("output_dir", outputDir, err.Error(), "Provide an absolute path to an existing writable directory")
+	if err := validateOutputDir(ctx, outputDir, params.DryRun); err != nil {
+		return responses.FormatValidationError("output_dir", outputDir, err.Error(), pathHint(err, "Provide an absolute path to an existing writable directory"))
 	}
 
 	serviceDir := filepath.Join(outputDir, params.ServiceName)
+	for _, dir := range params.ProtoPath {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(outputDir, dir)
+		}
+		if err := validation.CheckAllowed(ctx, dir); err != nil {
+			return responses.FormatValidationError("proto_path", dir, err.Error(), rootsHint)
+		}
+	}
 
-	// Write proto file to output directory (not temp) so protoc can find it
-	protoFile := filepath.Join(outputDir, params.ServiceName+".proto")
-	if err := os.WriteFile(protoFile, []byte(params.ProtoContent), 0644); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to write proto file: %v", err))
+	checks, err := fixer.ResolveVerifyOptions(params.Verify)
+	if err != nil {
+		return responses.FormatValidationError("verify", strings.Join(params.Verify, ","), err.Error(), "Use any of build, vet, test, gofmt or all")
 	}
-	defer os.Remove(protoFile)
 
-	spec, err := analyzer.ParseProtoSpecification(protoFile)
+	// Templates come from the parameters, the project configuration or a .goctl directory
+	templates, err := resolveTemplates(ctx, serviceDir, params.TemplateHome, params.TemplateRemote, params.TemplateBranch)
 	if err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to parse proto specification: %v", err))
+		return responses.FormatValidationError("template_home", params.TemplateHome, err.Error(), pathHint(err, templateHint))
 	}
 
-	executor, err := goctl.NewExecutor()
+	// Follow the enclosing module, such as a monorepo root, instead of creating a nested one
+	module, err := resolveGenerationModule(ctx, serviceDir, "github.com/example/"+params.ServiceName, moduleOptions{
+		ModulePath:     params.ModulePath,
+		AddToWorkspace: params.AddToWorkspace,
+		DependencyMode: params.DependencyMode,
+		ModuleProxy:    params.ModuleProxy,
+	})
 	if err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to create executor: %v", err))
+		return responses.FormatError(err.Error())
 	}
 
-	// Use relative path for proto file and execute in outputDir
-	args := []string{
-		"rpc",
-		"protoc",
-		params.ServiceName + ".proto",
-		"--go_out=.",
-		"--go-grpc_out=.",
-		"--zrpc_out=.",
-		"--style", style,
+	// Serialize with other tool calls writing to the same trees
+	held, err := lockPaths(ctx, params.DryRun, module.lockPaths()...)
+	if err != nil {
+		return responses.FormatError(err.Error())
 	}
+	defer held.Unlock()
 
-	result := executor.ExecuteInDir(outputDir, args...)
-	if result.Error != nil {
-		return responses.FormatError(fmt.Sprintf("failed to create RPC service: %v\nStderr: %s", result.Error, result.Stderr))
+	// Taken before the service directory and proto file are written so a failed
+	// generation leaves no trace; a dry run writes into a staged copy instead
+	var snapshots []*snapshot.Snapshot
+	var preview *dryRun
+	if params.DryRun {
+		if preview, err = newDryRun(serviceDir); err != nil {
+			return responses.FormatError(err.Error())
+		}
+		defer preview.Close()
+		serviceDir = preview.Path
+		preview.Ignore(module.stagedOnly()...)
+	} else if snapshots, err = module.snapshots(); err != nil {
+		return responses.FormatError(err.Error())
 	}
 
-	// Use a proper module path format (avoid module names starting with numbers)
-	moduleName := "github.com/example/" + params.ServiceName
+	if err := validation.EnsureDirectoryExists(serviceDir); err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to create service directory: %v", err))
+	}
 
-	if err := fixer.FixImports(serviceDir, moduleName); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to fix imports: %v", err))
+	// The proto file stays in the service directory, like goctl rpc new, so the
+	// service can be extended later with add_rpc_method
+	protoFile := filepath.Join(serviceDir, params.ServiceName+".proto")
+	if err := os.WriteFile(protoFile, []byte(params.ProtoContent), 0644); err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to write proto file: %v", err))
 	}
 
-	if err := fixer.InitializeGoModule(serviceDir, moduleName); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to initialize Go module: %v", err))
+	// proto_path entries are relative to outputDir
+	includePaths := []string{serviceDir}
+	for _, dir := range params.ProtoPath {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(outputDir, dir)
+		}
+		includePaths = append(includePaths, dir)
 	}
 
-	if err := fixer.TidyGoModule(serviceDir); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to tidy Go module: %v", err))
+	spec, err := analyzer.ParseProtoSpecification(protoFile, includePaths, importCheck(ctx))
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to parse proto specification: %v", err))
 	}
 
-	if err := fixer.VerifyBuild(serviceDir); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to verify build: %v", err))
+	if spec.GoPackage == "" {
+		return responses.FormatValidationError("proto_content", params.ServiceName+".proto", "missing option go_package", fmt.Sprintf("Add: option go_package = \"./%s\";", strings.ReplaceAll(params.ServiceName, "-", "")))
+	}
+	if len(spec.Services) > 1 && !params.Multiple {
+		return responses.FormatValidationError("proto_content", strings.Join(spec.Services, ", "), "proto declares more than one service", "Set multiple to true to generate one client per service")
 	}
 
-	validator := goctl.NewValidator()
-	if err := validator.ValidateServiceProject(serviceDir, "rpc"); err != nil {
-		return responses.FormatError(fmt.Sprintf("project structure validation failed: %v", err))
+	executor, err := goctl.NewExecutor()
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to create executor: %v", err))
+	}
+	executor.Templates = templates
+	if err := executor.RequireStyle(style); err != nil {
+		return responses.FormatValidationError("style", style, err.Error(), styleUpgradeHint)
+	}
+
+	// goctl before 1.5 has no --multiple, which a proto with one service does not need
+	multiple := params.Multiple
+	if multiple && !executor.Supports(goctl.FeatureRPCMultiple) {
+		if len(spec.Services) > 1 {
+			return responses.FormatError(executor.Require(goctl.FeatureRPCMultiple).Error())
+		}
+		multiple = false
+	}
+
+	// Use relative path for proto file and execute in serviceDir
+	args := rpcProtocArgs(params.ServiceName+".proto", style, includePaths[1:], multiple)
+
+	moduleName := module.ImportPath
+	var importChanges []fixer.ImportChange
+	steps := newPipeline(req).
+		Add("goctl rpc protoc", func(ctx context.Context) error {
+			result := executor.ExecuteInDirContext(ctx, serviceDir, args...)
+			if result.Error != nil {
+				return fmt.Errorf("failed to create RPC service: %v\nStderr: %s%s", result.Error, result.Stderr, protocHint(result.Stderr))
+			}
+			return nil
+		}).
+		Add("fix imports", func(ctx context.Context) error {
+			var err error
+			if importChanges, err = fixer.FixImports(serviceDir, moduleName); err != nil {
+				return fmt.Errorf("failed to fix imports: %w", err)
+			}
+			return nil
+		})
+	module.addSteps(steps, serviceDir, preview)
+	addVerifySteps(steps, serviceDir, checks)
+	steps.Add("validate structure", func(ctx context.Context) error {
+		if err := goctl.NewValidator().ValidateServiceProject(serviceDir, "rpc"); err != nil {
+			return fmt.Errorf("project structure validation failed: %w", err)
+		}
+		return nil
+	})
+
+	timings, err := runTransaction(module.context(ctx), steps, params.KeepOnFailure, snapshots...)
+	if err != nil {
+		return failure(err)
+	}
+	if preview != nil {
+		return preview.Result("create_rpc_service", timings)
 	}
 
 	message := fmt.Sprintf("Successfully created RPC service '%s'\n\nOutput directory: %s\n", params.ServiceName, serviceDir)
-	message += fmt.Sprintf("\nService: %s\n", spec.ServiceName)
+	if spec.Package != "" {
+		message += fmt.Sprintf("\nPackage: %s\n", spec.Package)
+	}
+	message += fmt.Sprintf("Go package: %s\n", spec.GoPackage)
+	if len(spec.Imports) > 0 {
+		message += fmt.Sprintf("Imports: %s\n", strings.Join(spec.Imports, ", "))
+	}
+	message += fmt.Sprintf("\nServices: %s\n", strings.Join(spec.Services, ", "))
 	message += "\nMethods:\n"
 	for _, method := range spec.Methods {
 		streamInfo := ""
 		if method.Stream != "" {
 			streamInfo = fmt.Sprintf(" [%s stream]", method.Stream)
 		}
-		message += fmt.Sprintf("  %s(%s) returns (%s)%s\n", method.Name, method.Request, method.Response, streamInfo)
+		message += fmt.Sprintf("  %s.%s(%s) returns (%s)%s\n", method.Service, method.Name, method.Request, method.Response, streamInfo)
 	}
 	message += fmt.Sprintf("\nMessages: %d\n", len(spec.Messages))
+	for _, msg := range spec.Messages {
+		message += fmt.Sprintf("  %s (%d fields)\n", msg.Name, len(msg.Fields))
+	}
+	if len(spec.Enums) > 0 {
+		message += fmt.Sprintf("Enums: %s\n", strings.Join(spec.Enums, ", "))
+	}
+	message += templatesMessage(templates)
+	message += "\n" + timings.String()
 	message += "\nNext steps:\n"
 	message += fmt.Sprintf("  1. cd %s\n", serviceDir)
 	message += "  2. go mod tidy\n"
 	message += "  3. go run .\n"
 
 	data := map[string]any{
-		"service_type":  "rpc",
-		"service_name":  params.ServiceName,
-		"output_dir":    serviceDir,
-		"style":         style,
-		"method_count":  len(spec.Methods),
-		"message_count": len(spec.Messages),
+		"service_type":   "rpc",
+		"service_name":   params.ServiceName,
+		"output_dir":     serviceDir,
+		"style":          style,
+		"method_count":   len(spec.Methods),
+		"message_count":  len(spec.Messages),
+		"enum_count":     len(spec.Enums),
+		"services":       spec.Services,
+		"package":        spec.Package,
+		"go_package":     spec.GoPackage,
+		"import_changes": importChanges,
+		"checks":         checks.Checks(),
+		"templates":      templates,
+		"steps":          timings.Steps,
+		"elapsed_ms":     timings.ElapsedMS,
 	}
+	maps.Copy(data, module.data())
 
 	return responses.FormatSuccessWithData(message, data)
 }
+
+// protocHint points at environment_doctor when goctl rpc protoc failed to find protoc or a plugin
+func protocHint(stderr string) string {
+	if strings.Contains(stderr, "protoc") && (strings.Contains(stderr, "not found") || strings.Contains(stderr, "executable file not found")) {
+		return "\nRun environment_doctor to check protoc, protoc-gen-go and protoc-gen-go-grpc"
+	}
+	return ""
+}
+
+// rpcProtocArgs builds the goctl rpc protoc arguments for a proto file in the working directory
+func rpcProtocArgs(protoFile, style string, includePaths []string, multiple bool) []string {
+	args := []string{
+		"rpc",
+		"protoc",
+		protoFile,
+		"--go_out=.",
+		"--go-grpc_out=.",
+		"--zrpc_out=.",
+		"--style", style,
+	}
+	if len(includePaths) > 0 {
+		args = append(args, "-I", ".")
+		for _, dir := range includePaths {
+			args = append(args, "-I", dir)
+		}
+	}
+	if multiple {
+		args = append(args, "--multiple")
+	}
+	return args
+}
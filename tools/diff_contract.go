@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+	"github.com/zeromicro/mcp-zero/internal/contract"
+	"github.com/zeromicro/mcp-zero/internal/protospec"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// DiffContractParams defines the parameters for diff_contract tool
+type DiffContractParams struct {
+	NewFile   string   `json:"new_file"`
+	OldFile   string   `json:"old_file,omitempty"`
+	Revision  string   `json:"revision,omitempty"`
+	ProtoPath []string `json:"proto_path,omitempty"`
+	FailOn    string   `json:"fail_on,omitempty"`
+}
+
+// DiffContract reports breaking changes between two versions of an .api or .proto file
+func DiffContract(ctx context.Context, req *mcp.CallToolRequest, params DiffContractParams) (*mcp.CallToolResult, any, error) {
+	if params.NewFile == "" {
+		return responses.FormatValidationError("new_file", "", "new_file is required", "Provide the path of the current .api or .proto file")
+	}
+	if (params.OldFile == "") == (params.Revision == "") {
+		return responses.FormatValidationError("old_file", params.OldFile, "exactly one of old_file or revision is required", "Set old_file to compare two files, or revision (such as HEAD or main) to compare against git")
+	}
+	if strings.HasPrefix(params.Revision, "-") {
+		return responses.FormatValidationError("revision", params.Revision, "revision must not start with '-'", "Use a branch, tag or commit such as main or HEAD~1")
+	}
+
+	var threshold contract.Severity
+	if params.FailOn != "" && params.FailOn != "never" {
+		severity, err := contract.ParseSeverity(params.FailOn)
+		if err != nil {
+			return responses.FormatValidationError("fail_on", params.FailOn, err.Error(), "Use breaking, warning, info or never")
+		}
+		threshold = severity
+	}
+
+	newFile := absPath(params.NewFile)
+	if err := validation.CheckAllowed(ctx, newFile); err != nil {
+		return responses.FormatValidationError("new_file", newFile, err.Error(), rootsHint)
+	}
+	if _, err := os.Stat(newFile); os.IsNotExist(err) {
+		return responses.FormatError(fmt.Sprintf("file not found: %s", newFile))
+	}
+	ext := strings.ToLower(filepath.Ext(newFile))
+	if ext != ".api" && ext != ".proto" {
+		return responses.FormatValidationError("new_file", params.NewFile, "unsupported file type", "Compare .api or .proto files")
+	}
+
+	var includePaths []string
+	for _, dir := range params.ProtoPath {
+		dir = absPath(dir)
+		if err := validation.CheckAllowed(ctx, dir); err != nil {
+			return responses.FormatValidationError("proto_path", dir, err.Error(), rootsHint)
+		}
+		includePaths = append(includePaths, dir)
+	}
+
+	oldFile := ""
+	oldIncludes := includePaths
+	checkImport := importCheck(ctx)
+	oldCheck := checkImport
+	var rev *contract.Revision
+	if params.Revision != "" {
+		var err error
+		rev, err = contract.Checkout(ctx, newFile, params.Revision, includePaths)
+		if err != nil {
+			return responses.FormatError(fmt.Sprintf("failed to read %s at %s: %v", params.NewFile, params.Revision, err))
+		}
+		defer rev.Close()
+		oldFile = rev.Path
+		oldIncludes = rev.IncludePaths
+		// Imports in the checkout are checked as the repository files they were read from
+		oldCheck = func(path string) error {
+			if rel, err := filepath.Rel(rev.Dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = filepath.Join(rev.Root, rel)
+			}
+			return checkImport(path)
+		}
+	} else {
+		oldFile = absPath(params.OldFile)
+		if err := validation.CheckAllowed(ctx, oldFile); err != nil {
+			return responses.FormatValidationError("old_file", oldFile, err.Error(), rootsHint)
+		}
+		if _, err := os.Stat(oldFile); os.IsNotExist(err) {
+			return responses.FormatError(fmt.Sprintf("file not found: %s", oldFile))
+		}
+		if !strings.EqualFold(filepath.Ext(oldFile), ext) {
+			return responses.FormatValidationError("old_file", params.OldFile, "old_file and new_file must have the same file type", "Compare two .api files or two .proto files")
+		}
+	}
+
+	var report *contract.Report
+	if ext == ".api" {
+		old, err := apispec.Load(oldFile, oldCheck)
+		if err != nil {
+			return responses.FormatError(fmt.Sprintf("failed to parse old API specification: %v", err))
+		}
+		current, err := apispec.Load(newFile, checkImport)
+		if err != nil {
+			return responses.FormatError(fmt.Sprintf("failed to parse new API specification: %v", err))
+		}
+		report = contract.DiffAPI(old, current)
+	} else {
+		old, err := protospec.Load(oldFile, oldIncludes, oldCheck)
+		if err != nil {
+			return responses.FormatError(fmt.Sprintf("failed to parse old proto file: %v", err))
+		}
+		current, err := protospec.Load(newFile, includePaths, checkImport)
+		if err != nil {
+			return responses.FormatError(fmt.Sprintf("failed to parse new proto file: %v", err))
+		}
+		report = contract.DiffProto(old, current)
+	}
+	if rev != nil {
+		rev.Relabel(report)
+	}
+
+	if threshold != "" && report.Exceeds(threshold) {
+		return responses.FormatError(fmt.Sprintf("contract check failed: changes at or above %s severity\n\n%s", threshold, report))
+	}
+
+	data := map[string]any{
+		"format":       report.Format,
+		"old":          report.Old,
+		"new":          report.New,
+		"breaking":     report.Breaking,
+		"warnings":     report.Warnings,
+		"info":         report.Info,
+		"has_breaking": report.Breaking > 0,
+		"changes":      report.Changes,
+	}
+	return responses.FormatSuccessWithData(report.String(), data)
+}
+
+// absPath resolves a path against the working directory
+func absPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	cwd, _ := os.Getwd()
+	return filepath.Join(cwd, path)
+}
@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/doctor"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+)
+
+// DoctorParams defines the parameters for doctor tool
+type DoctorParams struct{}
+
+// Doctor reports the goctl binary mcp-zero uses, its version and which features it supports;
+// environment_doctor includes the same report in its goctl section
+func Doctor(ctx context.Context, req *mcp.CallToolRequest, params DoctorParams) (*mcp.CallToolResult, any, error) {
+	compat, check := doctor.CheckGoctl()
+	if compat == nil {
+		return responses.FormatError(fmt.Sprintf("%s. Install it with: %s", check.Detail, check.Fix))
+	}
+
+	message := fmt.Sprintf("goctl: %s\nVersion: %s\nStatus: %s\n", compat.Path, compat.Version, compat.Status)
+	message += "\nCompatibility:\n" + compat.String()
+	return responses.FormatSuccessWithData(message, compat)
+}
@@ -0,0 +1,272 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/diff"
+	"github.com/zeromicro/mcp-zero/internal/pipeline"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// File change status values reported by a dry run
+const (
+	changeCreated  = "created"
+	changeModified = "modified"
+	changeDeleted  = "deleted"
+)
+
+// FileChange is a file a dry run would create, modify or delete, with its unified diff
+type FileChange struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// dryRun stages a copy of a tool's target in a temporary directory so the tool
+// can generate into it and report the difference without touching the target
+type dryRun struct {
+	Target string // real directory or file the tool would write
+	Path   string // staged counterpart of Target to generate into
+
+	root   string
+	before *snapshot.Snapshot // directory targets only
+	ignore map[string]bool    // staged paths, relative to Path, left out of the comparison
+	files  map[string]string  // files outside Target staged by StageFile, real path → staged path
+}
+
+// validateOutputDir validates an output directory, creating it unless this is a dry run,
+// which must leave the filesystem untouched
+func validateOutputDir(ctx context.Context, dir string, dryRun bool) error {
+	if dryRun {
+		return validation.CheckOutputDir(ctx, dir)
+	}
+	return validation.ValidateOutputDir(ctx, dir)
+}
+
+// newDryRun stages a copy of the target directory, which may not exist yet
+func newDryRun(target string) (*dryRun, error) {
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return nil, err
+	}
+	before, err := snapshot.Take(target)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := stage(target)
+	if err != nil {
+		return nil, err
+	}
+	d.before = before
+	if len(before.Files()) > 0 {
+		if err := before.CopyTo(d.Path); err != nil {
+			d.Close()
+			return nil, fmt.Errorf("failed to stage %s: %w", target, err)
+		}
+	}
+	return d, nil
+}
+
+// newDryRunFile stages a single target file; the staged copy starts with the file's current content
+func newDryRunFile(target string) (*dryRun, error) {
+	target, err := filepath.Abs(target)
+	if err != nil {
+		return nil, err
+	}
+	d, err := stage(target)
+	if err != nil {
+		return nil, err
+	}
+	if content, err := os.ReadFile(target); err == nil {
+		if err := os.WriteFile(d.Path, content, 0644); err != nil {
+			d.Close()
+			return nil, fmt.Errorf("failed to stage %s: %w", target, err)
+		}
+	}
+	return d, nil
+}
+
+func stage(target string) (*dryRun, error) {
+	root, err := os.MkdirTemp("", "mcp-zero-dry-run-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dry run directory: %w", err)
+	}
+	return &dryRun{
+		Target: target,
+		Path:   filepath.Join(root, filepath.Base(target)),
+		root:   root,
+	}, nil
+}
+
+// Parent returns the staged counterpart of the target's parent directory
+func (d *dryRun) Parent() string {
+	return d.root
+}
+
+// Ignore leaves staged files out of the comparison, such as a go.mod that exists only
+// because the staged copy is outside the target's enclosing module
+func (d *dryRun) Ignore(rels ...string) {
+	if d.ignore == nil {
+		d.ignore = make(map[string]bool)
+	}
+	for _, rel := range rels {
+		d.ignore[filepath.ToSlash(rel)] = true
+	}
+}
+
+// StageFile stages a copy of a file outside the target that the tool also writes, such as
+// the enclosing go.mod, and returns the path to write instead; its changes are reported too
+func (d *dryRun) StageFile(path string) (string, error) {
+	if staged, ok := d.files[path]; ok {
+		return staged, nil
+	}
+	if d.files == nil {
+		d.files = make(map[string]string)
+	}
+	dir := filepath.Join(d.root, fmt.Sprintf(".staged-%d", len(d.files)))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	staged := filepath.Join(dir, filepath.Base(path))
+	if content, err := os.ReadFile(path); err == nil {
+		if err := os.WriteFile(staged, content, 0644); err != nil {
+			return "", fmt.Errorf("failed to stage %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+	d.files[path] = staged
+	return staged, nil
+}
+
+// Close removes the staged copy
+func (d *dryRun) Close() error {
+	return os.RemoveAll(d.root)
+}
+
+// Changes compares the staged output with the real target, followed by the files staged
+// with StageFile
+func (d *dryRun) Changes() ([]FileChange, error) {
+	changes, err := d.targetChanges()
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(d.files))
+	for path := range d.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		before, errBefore := os.ReadFile(path)
+		after, errAfter := os.ReadFile(d.files[path])
+		if change, ok := fileChange(path, before, errBefore == nil, after, errAfter == nil); ok {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+func (d *dryRun) targetChanges() ([]FileChange, error) {
+	if d.before == nil {
+		before, errBefore := os.ReadFile(d.Target)
+		after, errAfter := os.ReadFile(d.Path)
+		change, ok := fileChange(d.Target, before, errBefore == nil, after, errAfter == nil)
+		if !ok {
+			return nil, nil
+		}
+		return []FileChange{change}, nil
+	}
+
+	after, err := snapshot.Take(d.Path)
+	if err != nil {
+		return nil, err
+	}
+	compared := d.before.Compare(after)
+	var changes []FileChange
+	for _, group := range [][]string{compared.Created, compared.Modified, compared.Deleted} {
+		for _, rel := range group {
+			if d.ignore[rel] {
+				continue
+			}
+			before, inBefore := d.before.Content(rel)
+			content, inAfter := after.Content(rel)
+			if change, ok := fileChange(filepath.Join(d.Target, filepath.FromSlash(rel)), before, inBefore, content, inAfter); ok {
+				changes = append(changes, change)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// fileChange classifies one path and renders its diff; ok is false when nothing changed
+func fileChange(path string, before []byte, existed bool, after []byte, exists bool) (FileChange, bool) {
+	change := FileChange{Path: path}
+	from, to := path, path
+	switch {
+	case !existed && !exists:
+		return change, false
+	case !existed:
+		change.Status = changeCreated
+		from = os.DevNull
+	case !exists:
+		change.Status = changeDeleted
+		to = os.DevNull
+	case string(before) == string(after):
+		return change, false
+	default:
+		change.Status = changeModified
+	}
+	change.Diff = diff.Unified(from, to, before, after, 3)
+	return change, true
+}
+
+// Result reports what the tool would have written; timings may be nil
+func (d *dryRun) Result(tool string, timings *pipeline.Result) (*mcp.CallToolResult, any, error) {
+	changes, err := d.Changes()
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to compare dry run output: %v", err))
+	}
+
+	counts := map[string]int{}
+	for _, change := range changes {
+		counts[change.Status]++
+	}
+
+	message := fmt.Sprintf("Dry run: %s would create %d, modify %d and delete %d files in %s\nNothing was written.\n",
+		tool, counts[changeCreated], counts[changeModified], counts[changeDeleted], d.Target)
+	if len(changes) > 0 {
+		message += "\nFiles:\n"
+		for _, change := range changes {
+			message += fmt.Sprintf("  %-8s %s\n", change.Status, change.Path)
+		}
+	}
+	if timings != nil {
+		message += "\n" + timings.String()
+	}
+
+	if changes == nil {
+		changes = []FileChange{}
+	}
+	data := map[string]any{
+		"dry_run":  true,
+		"target":   d.Target,
+		"files":    changes,
+		"created":  counts[changeCreated],
+		"modified": counts[changeModified],
+		"deleted":  counts[changeDeleted],
+	}
+	if timings != nil {
+		data["steps"] = timings.Steps
+		data["elapsed_ms"] = timings.ElapsedMS
+	}
+	return responses.FormatSuccessWithData(message, data)
+}
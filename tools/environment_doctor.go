@@ -0,0 +1,50 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/doctor"
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// EnvironmentDoctorParams defines the parameters for environment_doctor tool
+type EnvironmentDoctorParams struct {
+	Workspace      string `json:"workspace,omitempty"`       // directory code is generated into; default current directory
+	DependencyMode string `json:"dependency_mode,omitempty"` // check for this mode instead of the server-wide one
+	ModuleProxy    string `json:"module_proxy,omitempty"`
+}
+
+// EnvironmentDoctor checks the go toolchain, goctl, protoc and its plugins, the module cache,
+// GOPROXY and the workspace, returning a pass/warn/fail row with a fix for each
+func EnvironmentDoctor(ctx context.Context, req *mcp.CallToolRequest, params EnvironmentDoctorParams) (*mcp.CallToolResult, any, error) {
+	workspace := params.Workspace
+	if workspace == "" {
+		workspace = "."
+	}
+	workspace = absPath(workspace)
+	if err := validation.CheckAllowed(ctx, workspace); err != nil {
+		return responses.FormatValidationError("workspace", workspace, err.Error(), rootsHint)
+	}
+	if err := checkRoots(ctx, params.ModuleProxy); err != nil {
+		return responses.FormatValidationError("module_proxy", params.ModuleProxy, err.Error(), rootsHint)
+	}
+	deps, err := fixer.ResolveDependencies(params.DependencyMode, params.ModuleProxy)
+	if err != nil {
+		return responses.FormatValidationError("dependency_mode", params.DependencyMode, err.Error(), "Use online, offline or vendor; module_proxy must be an existing directory")
+	}
+
+	report := doctor.Run(ctx, doctor.Options{Workspace: workspace, Deps: deps})
+	return responses.FormatSuccessWithData(report.String(), map[string]any{
+		"status":          report.Status,
+		"checks":          report.Checks,
+		"passed":          report.Passed,
+		"warnings":        report.Warnings,
+		"failures":        report.Failures,
+		"goctl":           report.Goctl,
+		"dependency_mode": deps.Mode,
+	})
+}
@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/analyzer"
+	"github.com/zeromicro/mcp-zero/internal/openapi"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// ExportOpenAPIParams defines the parameters for export_openapi tool
+type ExportOpenAPIParams struct {
+	APIFile    string `json:"api_file"`
+	OutputPath string `json:"output_path,omitempty"`
+	Format     string `json:"format,omitempty"`
+	Version    string `json:"version,omitempty"`
+	ServerURL  string `json:"server_url,omitempty"`
+}
+
+// ExportOpenAPI converts an .api specification into an OpenAPI 3 document
+func ExportOpenAPI(ctx context.Context, req *mcp.CallToolRequest, params ExportOpenAPIParams) (*mcp.CallToolResult, any, error) {
+	if params.APIFile == "" {
+		return responses.FormatValidationError("api_file", "", "api_file is required", "Provide the path of the .api file to export")
+	}
+	apiFile := params.APIFile
+	if !filepath.IsAbs(apiFile) {
+		cwd, _ := os.Getwd()
+		apiFile = filepath.Join(cwd, apiFile)
+	}
+	if err := validation.CheckAllowed(ctx, apiFile); err != nil {
+		return responses.FormatValidationError("api_file", apiFile, err.Error(), rootsHint)
+	}
+	if _, err := os.Stat(apiFile); os.IsNotExist(err) {
+		return responses.FormatError(fmt.Sprintf("API file not found: %s", apiFile))
+	}
+
+	outputPath := params.OutputPath
+	if outputPath != "" && !filepath.IsAbs(outputPath) {
+		cwd, _ := os.Getwd()
+		outputPath = filepath.Join(cwd, outputPath)
+	}
+	if err := checkRoots(ctx, outputPath); err != nil {
+		return responses.FormatValidationError("output_path", outputPath, err.Error(), rootsHint)
+	}
+
+	// Default the format from the output file extension
+	format := strings.ToLower(params.Format)
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(outputPath)) {
+		case ".yaml", ".yml":
+			format = "yaml"
+		default:
+			format = "json"
+		}
+	}
+	if format != "json" && format != "yaml" {
+		return responses.FormatValidationError("format", params.Format, "unsupported format", "Use json or yaml")
+	}
+
+	spec, err := analyzer.ParseAPISpecification(apiFile, importCheck(ctx))
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to parse API specification: %v", err))
+	}
+
+	doc, err := openapi.Export(spec.AST, openapi.ExportOptions{Version: params.Version, ServerURL: params.ServerURL})
+	if err != nil {
+		if strings.Contains(err.Error(), "unsupported OpenAPI version") {
+			return responses.FormatValidationError("version", params.Version, err.Error(), "Use 3.0 or 3.1")
+		}
+		return responses.FormatError(fmt.Sprintf("failed to export OpenAPI document: %v", err))
+	}
+
+	content, err := openapi.Marshal(doc, format)
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to encode OpenAPI document: %v", err))
+	}
+
+	operations := 0
+	for _, p := range doc.Paths.Keys() {
+		item, _ := doc.Paths.Get(p)
+		for _, method := range openapi.Methods {
+			if item.Operation(method) != nil {
+				operations++
+			}
+		}
+	}
+	schemas := 0
+	if doc.Components != nil {
+		schemas = doc.Components.Schemas.Len()
+	}
+
+	message := fmt.Sprintf("Exported %s to OpenAPI %s (%s)\n\n", apiFile, doc.OpenAPI, format)
+	message += fmt.Sprintf("Paths: %d\nOperations: %d\nSchemas: %d\n", doc.Paths.Len(), operations, schemas)
+
+	data := map[string]any{
+		"api_file":   apiFile,
+		"openapi":    doc.OpenAPI,
+		"format":     format,
+		"paths":      doc.Paths.Len(),
+		"operations": operations,
+		"schemas":    schemas,
+	}
+
+	if outputPath == "" {
+		message += "\n" + string(content)
+		data["content"] = string(content)
+		return responses.FormatSuccessWithData(message, data)
+	}
+
+	// Serialize with other tool calls writing the document
+	held, err := lockPaths(ctx, false, outputPath)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
+
+	if err := validation.EnsureDirectoryExists(filepath.Dir(outputPath)); err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to create output directory: %v", err))
+	}
+	if err := os.WriteFile(outputPath, content, 0644); err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to write OpenAPI document: %v", err))
+	}
+	message += fmt.Sprintf("\nWritten to: %s\n", outputPath)
+	data["output_path"] = outputPath
+
+	return responses.FormatSuccessWithData(message, data)
+}
@@ -0,0 +1,78 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+	"github.com/zeromicro/mcp-zero/internal/diff"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// FormatAPISpecParams defines the parameters for format_api_spec tool
+type FormatAPISpecParams struct {
+	APIFile string `json:"api_file"`
+	Check   bool   `json:"check,omitempty"`
+}
+
+// FormatAPISpec rewrites an .api file in canonical format, or reports the changes in check mode
+func FormatAPISpec(ctx context.Context, req *mcp.CallToolRequest, params FormatAPISpecParams) (*mcp.CallToolResult, any, error) {
+	if params.APIFile == "" {
+		return responses.FormatValidationError("api_file", "", "api_file is required", "Provide the path of the .api file to format")
+	}
+	apiFile := absPath(params.APIFile)
+	if err := validation.CheckAllowed(ctx, apiFile); err != nil {
+		return responses.FormatValidationError("api_file", apiFile, err.Error(), rootsHint)
+	}
+
+	// Hold the lock from reading the file until it is rewritten
+	held, err := lockPaths(ctx, params.Check, apiFile)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
+
+	src, err := os.ReadFile(apiFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return responses.FormatError(fmt.Sprintf("API file not found: %s", apiFile))
+		}
+		return responses.FormatError(fmt.Sprintf("failed to read API file: %v", err))
+	}
+
+	formatted, err := apispec.FormatSource(apiFile, src)
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to parse API specification: %v", err))
+	}
+
+	changed := string(formatted) != string(src)
+	data := map[string]any{
+		"api_file": apiFile,
+		"changed":  changed,
+	}
+
+	if params.Check {
+		if !changed {
+			return responses.FormatSuccessWithData(fmt.Sprintf("%s is already formatted\n", apiFile), data)
+		}
+		unified := diff.Unified(apiFile, apiFile+" (formatted)", src, formatted, 3)
+		data["diff"] = unified
+		return responses.FormatSuccessWithData(fmt.Sprintf("%s is not formatted\n\n%s", apiFile, unified), data)
+	}
+
+	if !changed {
+		return responses.FormatSuccessWithData(fmt.Sprintf("%s is already formatted\n", apiFile), data)
+	}
+	info, err := os.Stat(apiFile)
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to stat API file: %v", err))
+	}
+	if err := os.WriteFile(apiFile, formatted, info.Mode().Perm()); err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to write API file: %v", err))
+	}
+	return responses.FormatSuccessWithData(fmt.Sprintf("Formatted %s\n", apiFile), data)
+}
@@ -3,8 +3,10 @@ package tools
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -12,14 +14,25 @@ import (
 	"github.com/zeromicro/mcp-zero/internal/fixer"
 	"github.com/zeromicro/mcp-zero/internal/goctl"
 	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
 	"github.com/zeromicro/mcp-zero/internal/validation"
 )
 
 // GenerateAPIFromSpecParams defines the parameters for generate_api_from_spec tool (T040-T043)
 type GenerateAPIFromSpecParams struct {
-	APIFile   string `json:"api_file"`
-	OutputDir string `json:"output_dir,omitempty"`
-	Style     string `json:"style,omitempty"`
+	APIFile        string   `json:"api_file"`
+	OutputDir      string   `json:"output_dir,omitempty"`
+	Style          string   `json:"style,omitempty"`
+	KeepOnFailure  bool     `json:"keep_on_failure,omitempty"`
+	DryRun         bool     `json:"dry_run,omitempty"`
+	ModulePath     string   `json:"module_path,omitempty"`
+	AddToWorkspace bool     `json:"add_to_workspace,omitempty"`
+	DependencyMode string   `json:"dependency_mode,omitempty"`
+	ModuleProxy    string   `json:"module_proxy,omitempty"`
+	Verify         []string `json:"verify,omitempty"`
+	TemplateHome   string   `json:"template_home,omitempty"`
+	TemplateRemote string   `json:"template_remote,omitempty"`
+	TemplateBranch string   `json:"template_branch,omitempty"`
 }
 
 // GenerateAPIFromSpec generates go-zero API code from API specification file (T044-T046)
@@ -30,13 +43,16 @@ func GenerateAPIFromSpec(ctx context.Context, req *mcp.CallToolRequest, params G
 		cwd, _ := os.Getwd()
 		apiFile = filepath.Join(cwd, apiFile)
 	}
+	if err := validation.CheckAllowed(ctx, apiFile); err != nil {
+		return responses.FormatValidationError("api_file", apiFile, err.Error(), rootsHint)
+	}
 
 	if _, err := os.Stat(apiFile); os.IsNotExist(err) {
 		return responses.FormatError(fmt.Sprintf("API file not found: %s", apiFile))
 	}
 
 	// T041: Parse API specification to extract metadata
-	spec, err := analyzer.ParseAPISpecification(apiFile)
+	spec, err := analyzer.ParseAPISpecification(apiFile, importCheck(ctx))
 	if err != nil {
 		return responses.FormatError(fmt.Sprintf("failed to parse API specification: %v", err))
 	}
@@ -51,8 +67,8 @@ func GenerateAPIFromSpec(ctx context.Context, req *mcp.CallToolRequest, params G
 		outputDir = filepath.Join(cwd, outputDir)
 	}
 
-	if err := validation.ValidateOutputDir(outputDir); err != nil {
-		return responses.FormatValidationError("output_dir", outputDir, err.Error(), "Provide an absolute path to an existing writable directory")
+	if err := validateOutputDir(ctx, outputDir, params.DryRun); err != nil {
+		return responses.FormatValidationError("output_dir", outputDir, err.Error(), pathHint(err, "Provide an absolute path to an existing writable directory"))
 	}
 
 	// T043: Set code style (default: detect existing or use go_zero)
@@ -61,58 +77,118 @@ func GenerateAPIFromSpec(ctx context.Context, req *mcp.CallToolRequest, params G
 		// Try to detect existing style to avoid conflicts
 		style = fixer.SuggestStyleBasedOnExisting(outputDir, "go_zero")
 	}
-	if style != "go_zero" && style != "gozero" {
-		return responses.FormatValidationError("style", style, "invalid style", "Use 'go_zero' or 'gozero'")
+	if _, err := fixer.ParseStyle(style); err != nil {
+		return responses.FormatValidationError("style", style, err.Error(), "Use a goctl style such as 'go_zero', 'gozero', 'goZero' or a custom format like 'Go-Zero'")
 	}
 
-	// Clean up any existing style conflicts before generating
-	if err := fixer.CleanupStyleConflicts(outputDir, style); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to cleanup style conflicts: %v", err))
+	checks, err := fixer.ResolveVerifyOptions(params.Verify)
+	if err != nil {
+		return responses.FormatValidationError("verify", strings.Join(params.Verify, ","), err.Error(), "Use any of build, vet, test, gofmt or all")
 	}
 
-	// T044: Execute goctl api go command
-	executor, err := goctl.NewExecutor()
+	// Templates come from the parameters, the project configuration or a .goctl directory
+	templates, err := resolveTemplates(ctx, outputDir, params.TemplateHome, params.TemplateRemote, params.TemplateBranch)
 	if err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to create executor: %v", err))
+		return responses.FormatValidationError("template_home", params.TemplateHome, err.Error(), pathHint(err, templateHint))
 	}
 
-	args := []string{
-		"api",
-		"go",
-		"-api", apiFile,
-		"-dir", outputDir,
-		"-style", style,
+	// Follow the enclosing module, such as a monorepo root, instead of creating a nested one
+	module, err := resolveGenerationModule(ctx, outputDir, spec.ServiceName, moduleOptions{
+		ModulePath:     params.ModulePath,
+		AddToWorkspace: params.AddToWorkspace,
+		DependencyMode: params.DependencyMode,
+		ModuleProxy:    params.ModuleProxy,
+	})
+	if err != nil {
+		return responses.FormatError(err.Error())
 	}
 
-	result := executor.Execute(args...)
-	if result.Error != nil {
-		return responses.FormatError(fmt.Sprintf("failed to generate API code: %v\nStderr: %s", result.Error, result.Stderr))
+	// Serialize with other tool calls writing to the same trees
+	held, err := lockPaths(ctx, params.DryRun, module.lockPaths()...)
+	if err != nil {
+		return responses.FormatError(err.Error())
 	}
+	defer held.Unlock()
 
-	// Get module name from service name
-	moduleName := spec.ServiceName
-
-	// T045: Fix imports and initialize modules
-	if err := fixer.FixImports(outputDir, moduleName); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to fix imports: %v", err))
+	// A dry run generates into a staged copy of the output directory
+	var preview *dryRun
+	if params.DryRun {
+		if preview, err = newDryRun(outputDir); err != nil {
+			return responses.FormatError(err.Error())
+		}
+		defer preview.Close()
+		outputDir = preview.Path
+		preview.Ignore(module.stagedOnly()...)
 	}
 
-	if err := fixer.InitializeGoModule(outputDir, moduleName); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to initialize Go module: %v", err))
+	executor, err := goctl.NewExecutor()
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to create executor: %v", err))
 	}
-
-	if err := fixer.TidyGoModule(outputDir); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to tidy Go module: %v", err))
+	executor.Templates = templates
+	if err := executor.RequireStyle(style); err != nil {
+		return responses.FormatValidationError("style", style, err.Error(), styleUpgradeHint)
 	}
 
-	// Validate no style conflicts after generation
-	if err := fixer.ValidateNoStyleConflicts(outputDir); err != nil {
-		return responses.FormatError(fmt.Sprintf("style conflicts detected after generation: %v", err))
+	args := []string{
+		"api",
+		"go",
+		"-api", apiFile,
+		"-dir", outputDir,
+		"-style", style,
 	}
 
-	// T046: Verify build success
-	if err := fixer.VerifyBuild(outputDir); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to verify build: %v", err))
+	moduleName := module.ImportPath
+	var importChanges []fixer.ImportChange
+	steps := newPipeline(req).
+		// Clean up any existing style conflicts before generating
+		Add("cleanup style conflicts", func(ctx context.Context) error {
+			if err := fixer.CleanupStyleConflicts(outputDir, style); err != nil {
+				return fmt.Errorf("failed to cleanup style conflicts: %w", err)
+			}
+			return nil
+		}).
+		// T044: Execute goctl api go command
+		Add("goctl api go", func(ctx context.Context) error {
+			result := executor.ExecuteContext(ctx, args...)
+			if result.Error != nil {
+				return fmt.Errorf("failed to generate API code: %v\nStderr: %s", result.Error, result.Stderr)
+			}
+			return nil
+		}).
+		// T045: Fix imports and initialize modules
+		Add("fix imports", func(ctx context.Context) error {
+			var err error
+			if importChanges, err = fixer.FixImports(outputDir, moduleName); err != nil {
+				return fmt.Errorf("failed to fix imports: %w", err)
+			}
+			return nil
+		})
+	module.addSteps(steps, outputDir, preview)
+	steps.
+		// Validate no style conflicts after generation
+		Add("check style conflicts", func(ctx context.Context) error {
+			if err := fixer.ValidateNoStyleConflicts(outputDir); err != nil {
+				return fmt.Errorf("style conflicts detected after generation: %w", err)
+			}
+			return nil
+		})
+	// T046: Verify build success, plus the configured checks
+	addVerifySteps(steps, outputDir, checks)
+
+	// Style cleanup deletes files, so the snapshot is taken before any step runs
+	var snapshots []*snapshot.Snapshot
+	if preview == nil {
+		if snapshots, err = module.snapshots(); err != nil {
+			return responses.FormatError(err.Error())
+		}
+	}
+	timings, err := runTransaction(module.context(ctx), steps, params.KeepOnFailure, snapshots...)
+	if err != nil {
+		return failure(err)
+	}
+	if preview != nil {
+		return preview.Result("generate_api_from_spec", timings)
 	}
 
 	// Format success message with endpoint list
@@ -122,6 +198,8 @@ func GenerateAPIFromSpec(ctx context.Context, req *mcp.CallToolRequest, params G
 		message += fmt.Sprintf("  %s %s → %s\n", ep.Method, ep.Path, ep.Handler)
 	}
 	message += fmt.Sprintf("\nTotal types: %d\n", len(spec.Types))
+	message += templatesMessage(templates)
+	message += "\n" + timings.String()
 	message += "\nNext steps:\n"
 	message += fmt.Sprintf("  1. cd %s\n", outputDir)
 	message += "  2. go mod tidy\n"
@@ -134,7 +212,13 @@ func GenerateAPIFromSpec(ctx context.Context, req *mcp.CallToolRequest, params G
 		"style":          style,
 		"endpoint_count": len(spec.Endpoints),
 		"type_count":     len(spec.Types),
+		"import_changes": importChanges,
+		"checks":         checks.Checks(),
+		"templates":      templates,
+		"steps":          timings.Steps,
+		"elapsed_ms":     timings.ElapsedMS,
 	}
+	maps.Copy(data, module.data())
 
 	return responses.FormatSuccessWithData(message, data)
 }
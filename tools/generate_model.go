@@ -3,7 +3,9 @@ package tools
 import (
 	"context"
 	"fmt"
+	"maps"
 	"path/filepath"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -11,14 +13,26 @@ import (
 	"github.com/zeromicro/mcp-zero/internal/goctl"
 	"github.com/zeromicro/mcp-zero/internal/responses"
 	"github.com/zeromicro/mcp-zero/internal/security"
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
+	"github.com/zeromicro/mcp-zero/internal/validation"
 )
 
 type GenerateModelParams struct {
-	SourceType string `json:"source_type"`
-	Source     string `json:"source"`
-	Table      string `json:"table"`
-	OutputDir  string `json:"output_dir,omitempty"`
-	Style      string `json:"style,omitempty"`
+	SourceType     string   `json:"source_type"`
+	Source         string   `json:"source"`
+	Table          string   `json:"table"`
+	OutputDir      string   `json:"output_dir,omitempty"`
+	Style          string   `json:"style,omitempty"`
+	KeepOnFailure  bool     `json:"keep_on_failure,omitempty"`
+	DryRun         bool     `json:"dry_run,omitempty"`
+	ModulePath     string   `json:"module_path,omitempty"`
+	AddToWorkspace bool     `json:"add_to_workspace,omitempty"`
+	DependencyMode string   `json:"dependency_mode,omitempty"`
+	ModuleProxy    string   `json:"module_proxy,omitempty"`
+	Verify         []string `json:"verify,omitempty"`
+	TemplateHome   string   `json:"template_home,omitempty"`
+	TemplateRemote string   `json:"template_remote,omitempty"`
+	TemplateBranch string   `json:"template_branch,omitempty"`
 }
 
 func GenerateModel(ctx context.Context, req *mcp.CallToolRequest, params GenerateModelParams) (*mcp.CallToolResult, any, error) {
@@ -38,6 +52,9 @@ func GenerateModel(ctx context.Context, req *mcp.CallToolRequest, params Generat
 	if outputDir == "" {
 		outputDir = "./model"
 	}
+	if err := validation.CheckAllowed(ctx, outputDir); err != nil {
+		return responses.FormatValidationError("output_dir", outputDir, err.Error(), rootsHint)
+	}
 
 	style := params.Style
 	if style == "" {
@@ -53,10 +70,54 @@ func GenerateModel(ctx context.Context, req *mcp.CallToolRequest, params Generat
 	}
 	defer connInfo.Clear()
 
+	checks, err := fixer.ResolveVerifyOptions(params.Verify)
+	if err != nil {
+		return responses.FormatValidationError("verify", strings.Join(params.Verify, ","), err.Error(), "Use any of build, vet, test, gofmt or all")
+	}
+
+	// Templates come from the parameters, the project configuration or a .goctl directory
+	templates, err := resolveTemplates(ctx, outputDir, params.TemplateHome, params.TemplateRemote, params.TemplateBranch)
+	if err != nil {
+		return responses.FormatValidationError("template_home", params.TemplateHome, err.Error(), pathHint(err, templateHint))
+	}
+
+	// Follow the enclosing module instead of creating a nested one
+	module, err := resolveGenerationModule(ctx, outputDir, "model", moduleOptions{
+		ModulePath:     params.ModulePath,
+		AddToWorkspace: params.AddToWorkspace,
+		DependencyMode: params.DependencyMode,
+		ModuleProxy:    params.ModuleProxy,
+	})
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+
+	// Serialize with other tool calls writing to the same trees
+	held, err := lockPaths(ctx, params.DryRun, module.lockPaths()...)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
+
+	// A dry run generates into a staged copy of the output directory
+	var preview *dryRun
+	if params.DryRun {
+		if preview, err = newDryRun(outputDir); err != nil {
+			return responses.FormatError(err.Error())
+		}
+		defer preview.Close()
+		outputDir = preview.Path
+		preview.Ignore(module.stagedOnly()...)
+	}
+
 	executor, err := goctl.NewExecutor()
 	if err != nil {
 		return responses.FormatError(fmt.Sprintf("failed to create executor: %v", err))
 	}
+	executor.Templates = templates
+	if err := executor.RequireStyle(style); err != nil {
+		return responses.FormatValidationError("style", style, err.Error(), styleUpgradeHint)
+	}
 
 	args := []string{
 		"model",
@@ -68,34 +129,46 @@ func GenerateModel(ctx context.Context, req *mcp.CallToolRequest, params Generat
 		"-style", style,
 	}
 
-	result := executor.Execute(args...)
-	if result.Error != nil {
-		connInfo.Clear()
-		return responses.FormatError(fmt.Sprintf("failed to generate model: %v\nStderr: %s", result.Error, result.Stderr))
-	}
-
-	connInfo.Clear()
-
-	moduleName := "model"
-	if err := fixer.FixImports(outputDir, moduleName); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to fix imports: %v", err))
+	moduleName := module.ImportPath
+	var importChanges []fixer.ImportChange
+	steps := newPipeline(req).
+		Add("goctl model", func(ctx context.Context) error {
+			result := executor.ExecuteContext(ctx, args...)
+			connInfo.Clear()
+			if result.Error != nil {
+				return fmt.Errorf("failed to generate model: %v\nStderr: %s", result.Error, result.Stderr)
+			}
+			return nil
+		}).
+		Add("fix imports", func(ctx context.Context) error {
+			var err error
+			if importChanges, err = fixer.FixImports(outputDir, moduleName); err != nil {
+				return fmt.Errorf("failed to fix imports: %w", err)
+			}
+			return nil
+		})
+	module.addSteps(steps, outputDir, preview)
+	addVerifySteps(steps, outputDir, checks)
+
+	var snapshots []*snapshot.Snapshot
+	if preview == nil {
+		if snapshots, err = module.snapshots(); err != nil {
+			return responses.FormatError(err.Error())
+		}
 	}
-
-	if err := fixer.InitializeGoModule(outputDir, moduleName); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to initialize Go module: %v", err))
-	}
-
-	if err := fixer.TidyGoModule(outputDir); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to tidy Go module: %v", err))
+	timings, err := runTransaction(module.context(ctx), steps, params.KeepOnFailure, snapshots...)
+	if err != nil {
+		return failure(err)
 	}
-
-	if err := fixer.VerifyBuild(outputDir); err != nil {
-		return responses.FormatError(fmt.Sprintf("failed to verify build: %v", err))
+	if preview != nil {
+		return preview.Result("generate_model", timings)
 	}
 
 	message := fmt.Sprintf("Successfully generated database model for table '%s'\n\nOutput directory: %s\n", params.Table, outputDir)
 	message += fmt.Sprintf("\nSource Type: %s\n", params.SourceType)
 	message += fmt.Sprintf("Table: %s\n", params.Table)
+	message += templatesMessage(templates)
+	message += "\n" + timings.String()
 	message += "\nNext steps:\n"
 	message += fmt.Sprintf("  1. cd %s\n", outputDir)
 	message += "  2. Review generated model code\n"
@@ -103,11 +176,17 @@ func GenerateModel(ctx context.Context, req *mcp.CallToolRequest, params Generat
 
 	absPath, _ := filepath.Abs(outputDir)
 	data := map[string]any{
-		"source_type": params.SourceType,
-		"table":       params.Table,
-		"output_dir":  absPath,
-		"style":       style,
+		"source_type":    params.SourceType,
+		"table":          params.Table,
+		"output_dir":     absPath,
+		"style":          style,
+		"import_changes": importChanges,
+		"checks":         checks.Checks(),
+		"templates":      templates,
+		"steps":          timings.Steps,
+		"elapsed_ms":     timings.ElapsedMS,
 	}
+	maps.Copy(data, module.data())
 
 	return responses.FormatSuccessWithData(message, data)
 }
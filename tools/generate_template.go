@@ -5,14 +5,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/zeromicro/mcp-zero/internal/goctl"
 	"github.com/zeromicro/mcp-zero/internal/responses"
 	"github.com/zeromicro/mcp-zero/internal/templates"
+	"github.com/zeromicro/mcp-zero/internal/validation"
 )
 
 type GenerateTemplateParams struct {
@@ -20,6 +21,7 @@ type GenerateTemplateParams struct {
 	TemplateName string `json:"template_name"`        // specific template like "auth", "logging", etc.
 	Parameters   string `json:"parameters,omitempty"` // JSON string of parameters
 	OutputPath   string `json:"output_path,omitempty"`
+	DryRun       bool   `json:"dry_run,omitempty"`
 }
 
 // GenerateTemplate generates code templates for common patterns
@@ -85,6 +87,32 @@ func GenerateTemplate(ctx context.Context, req *mcp.CallToolRequest, params Gene
 		cwd, _ := os.Getwd()
 		outputPath = filepath.Join(cwd, outputPath)
 	}
+	if err := validation.CheckAllowed(ctx, outputPath); err != nil {
+		return responses.FormatValidationError("output_path", outputPath, err.Error(), rootsHint)
+	}
+
+	// Serialize with other tool calls writing the file
+	held, err := lockPaths(ctx, params.DryRun, outputPath)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
+
+	// A dry run writes and formats a staged copy, then compares it with the existing file
+	if params.DryRun {
+		preview, err := newDryRunFile(outputPath)
+		if err != nil {
+			return responses.FormatError(err.Error())
+		}
+		defer preview.Close()
+		if err := os.WriteFile(preview.Path, []byte(code), 0644); err != nil {
+			return responses.FormatError(fmt.Sprintf("failed to write file: %v", err))
+		}
+		if strings.HasSuffix(preview.Path, ".go") {
+			verifyGoFile(ctx, preview.Path)
+		}
+		return preview.Result("generate_template", nil)
+	}
 
 	// Create directory if needed
 	dir := filepath.Dir(outputPath)
@@ -100,7 +128,7 @@ func GenerateTemplate(ctx context.Context, req *mcp.CallToolRequest, params Gene
 	// Try to verify the generated code compiles (best effort)
 	compileCheck := ""
 	if strings.HasSuffix(outputPath, ".go") {
-		if err := verifyGoFile(outputPath); err != nil {
+		if err := verifyGoFile(ctx, outputPath); err != nil {
 			compileCheck = fmt.Sprintf("\n⚠️  Warning: Generated code may have compilation issues: %v\n", err)
 		} else {
 			compileCheck = "\n✅ Generated code verified successfully\n"
@@ -251,11 +279,10 @@ Example usage:
 	return ""
 }
 
-func verifyGoFile(filePath string) error {
+func verifyGoFile(ctx context.Context, filePath string) error {
 	// Use go fmt to check syntax
-	cmd := exec.Command("go", "fmt", filePath)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("syntax check failed: %w", err)
+	if result := goctl.Run(ctx, "", 0, "go", "fmt", filePath); result.Error != nil {
+		return fmt.Errorf("syntax check failed: %w", result.Error)
 	}
 	return nil
 }
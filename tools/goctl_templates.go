@@ -0,0 +1,389 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/diff"
+	"github.com/zeromicro/mcp-zero/internal/goctl"
+	"github.com/zeromicro/mcp-zero/internal/project"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// templateBaselineDir keeps, inside a template home, the goctl defaults the home was
+// last initialized or updated from, so update can tell customized templates apart
+const templateBaselineDir = ".mcp-zero-defaults"
+
+// templateHint is the suggestion shown for invalid template parameters
+const templateHint = "Point template_home at a directory created by manage_goctl_templates, or set template_remote to a git repository"
+
+// resolveTemplates picks the goctl templates for generating into dir: the call's
+// parameters first, then the project configuration file, then a .goctl directory
+func resolveTemplates(ctx context.Context, dir, home, remote, branch string) (goctl.Templates, error) {
+	if branch != "" && remote == "" {
+		return goctl.Templates{}, fmt.Errorf("template_branch requires template_remote")
+	}
+	if home != "" && remote != "" {
+		return goctl.Templates{}, fmt.Errorf("set template_home or template_remote, not both")
+	}
+	if remote != "" {
+		return goctl.Templates{Remote: remote, Branch: branch, Source: "parameters"}, nil
+	}
+	if home != "" {
+		home, err := filepath.Abs(home)
+		if err != nil {
+			return goctl.Templates{}, err
+		}
+		return checkTemplateHome(ctx, goctl.Templates{Home: home, Source: "parameters"})
+	}
+
+	config, err := project.LoadConfig(ctx, dir)
+	if err != nil {
+		return goctl.Templates{}, err
+	}
+	if config.Templates.Remote != "" {
+		return goctl.Templates{Remote: config.Templates.Remote, Branch: config.Templates.Branch, Source: config.Path}, nil
+	}
+	if config.Templates.Branch != "" {
+		return goctl.Templates{}, fmt.Errorf("templates.branch in %s requires templates.remote", config.Path)
+	}
+	if home := config.TemplateHome(); home != "" {
+		return checkTemplateHome(ctx, goctl.Templates{Home: home, Source: config.Path})
+	}
+
+	if home := project.FindTemplateHome(ctx, dir); home != "" {
+		return goctl.Templates{Home: home, Source: project.TemplateDirName + " directory"}, nil
+	}
+	return goctl.Templates{}, nil
+}
+
+// checkTemplateHome rejects a template home that is not a directory or is outside the allowed roots
+func checkTemplateHome(ctx context.Context, templates goctl.Templates) (goctl.Templates, error) {
+	if err := checkRoots(ctx, templates.Home); err != nil {
+		return goctl.Templates{}, fmt.Errorf("template home from %s: %w", templates.Source, err)
+	}
+	if info, err := os.Stat(templates.Home); err != nil || !info.IsDir() {
+		return goctl.Templates{}, fmt.Errorf("template home %s (from %s) is not a directory", templates.Home, templates.Source)
+	}
+	return templates, nil
+}
+
+// templatesMessage describes the templates in a tool result, empty for goctl's defaults
+func templatesMessage(templates goctl.Templates) string {
+	if templates.Empty() {
+		return ""
+	}
+	return fmt.Sprintf("Templates: %s (from %s)\n", templates, templates.Source)
+}
+
+// ManageGoctlTemplatesParams defines the parameters for manage_goctl_templates tool
+type ManageGoctlTemplatesParams struct {
+	Action     string `json:"action"`
+	ProjectDir string `json:"project_dir,omitempty"`
+	Home       string `json:"home,omitempty"`
+}
+
+// templateFile is a template the action reported on
+type templateFile struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// ManageGoctlTemplates initializes a project template home from goctl's defaults,
+// compares it with them, or updates it after a goctl upgrade
+func ManageGoctlTemplates(ctx context.Context, req *mcp.CallToolRequest, params ManageGoctlTemplatesParams) (*mcp.CallToolResult, any, error) {
+	switch params.Action {
+	case "init", "diff", "update":
+	default:
+		return responses.FormatValidationError("action", params.Action, "unknown action", "Use init, diff or update")
+	}
+
+	projectDir := params.ProjectDir
+	if projectDir == "" {
+		projectDir = "."
+	}
+	projectDir, err := filepath.Abs(projectDir)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	if err := validation.CheckAllowed(ctx, projectDir); err != nil {
+		return responses.FormatValidationError("project_dir", projectDir, err.Error(), rootsHint)
+	}
+
+	home := params.Home
+	if home != "" {
+		if home, err = filepath.Abs(home); err != nil {
+			return responses.FormatError(err.Error())
+		}
+	} else {
+		templates, err := resolveTemplates(ctx, projectDir, "", "", "")
+		if err != nil {
+			return responses.FormatValidationError("project_dir", projectDir, err.Error(), templateHint)
+		}
+		if templates.Remote != "" {
+			return responses.FormatValidationError("project_dir", projectDir, fmt.Sprintf("templates come from the git repository %s", templates), "Manage remote templates in their repository, or pass home to work on a local copy")
+		}
+		home = templates.Home
+	}
+	if home == "" {
+		if params.Action != "init" {
+			return responses.FormatValidationError("home", "", "no template home found", "Run the init action first, or pass home")
+		}
+		home = filepath.Join(projectDir, project.TemplateDirName)
+	}
+	if err := validation.CheckAllowed(ctx, home); err != nil {
+		return responses.FormatValidationError("home", home, err.Error(), rootsHint)
+	}
+	if params.Action != "init" {
+		if info, err := os.Stat(home); err != nil || !info.IsDir() {
+			return responses.FormatValidationError("home", home, "template home does not exist", "Run the init action first")
+		}
+	}
+
+	// Serialize with other tool calls using the template home
+	held, err := lockPaths(ctx, params.Action == "diff", home)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
+
+	executor, err := goctl.NewExecutor()
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to create executor: %v", err))
+	}
+	defaults, err := goctlDefaultTemplates(ctx, executor)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	current, err := readTemplates(home)
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to read template home: %v", err))
+	}
+	baseline, err := readTemplates(filepath.Join(home, templateBaselineDir))
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to read template baseline: %v", err))
+	}
+
+	var files []templateFile
+	switch params.Action {
+	case "init":
+		files, err = initTemplates(home, defaults, current)
+	case "diff":
+		files = diffTemplates(defaults, current, baseline)
+	case "update":
+		files, err = updateTemplates(home, defaults, current, baseline)
+	}
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	if params.Action != "diff" {
+		if err := writeTemplates(filepath.Join(home, templateBaselineDir), defaults, true); err != nil {
+			return responses.FormatError(fmt.Sprintf("failed to record template baseline: %v", err))
+		}
+	}
+
+	counts := map[string]int{}
+	for _, file := range files {
+		counts[file.Status]++
+	}
+	message := fmt.Sprintf("Template home: %s\ngoctl: %s (%d default templates)\n", home, executor.Version(), len(defaults))
+	if len(files) == 0 {
+		message += "\nAll templates match goctl's defaults\n"
+	} else {
+		message += "\n"
+		for _, file := range files {
+			message += fmt.Sprintf("  %-10s %s\n", file.Status, file.Path)
+		}
+		for _, file := range files {
+			if file.Diff != "" {
+				message += "\n" + file.Diff
+			}
+		}
+	}
+	if counts["conflict"] > 0 {
+		message += "\nConflicts keep your version; merge the shown changes to goctl's default by hand\n"
+	}
+	if templates, _ := resolveTemplates(ctx, projectDir, "", "", ""); params.Action == "init" && templates.Home != home {
+		message += fmt.Sprintf("\nAdd templates.home: %s to %s so generation tools use it\n", home, project.ConfigNames[0])
+	}
+
+	if files == nil {
+		files = []templateFile{}
+	}
+	return responses.FormatSuccessWithData(message, map[string]any{
+		"action":        params.Action,
+		"home":          home,
+		"goctl_version": executor.Version().String(),
+		"files":         files,
+		"counts":        counts,
+	})
+}
+
+// goctlDefaultTemplates returns the templates of the installed goctl by initializing
+// them into a scratch directory
+func goctlDefaultTemplates(ctx context.Context, executor *goctl.Executor) (map[string][]byte, error) {
+	dir, err := os.MkdirTemp("", "goctl-templates-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	result := executor.ExecuteContext(ctx, "template", "init", "--home", dir)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to initialize goctl templates: %v\nStderr: %s", result.Error, result.Stderr)
+	}
+	templates, err := readTemplates(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("goctl template init wrote no templates")
+	}
+	return templates, nil
+}
+
+// readTemplates reads every file under dir by slash-separated relative path,
+// skipping hidden directories such as the baseline and .git
+func readTemplates(dir string) (map[string][]byte, error) {
+	templates := map[string][]byte{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		templates[filepath.ToSlash(rel)] = content
+		return nil
+	})
+	return templates, err
+}
+
+// writeTemplates writes templates under dir, first removing what is there when replace is set
+func writeTemplates(dir string, templates map[string][]byte, replace bool) error {
+	if replace {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+	for _, rel := range sortedTemplates(templates) {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, templates[rel], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// initTemplates writes the defaults missing from home, keeping existing templates
+func initTemplates(home string, defaults, current map[string][]byte) ([]templateFile, error) {
+	var files []templateFile
+	missing := map[string][]byte{}
+	for _, rel := range sortedTemplates(defaults) {
+		if _, ok := current[rel]; ok {
+			files = append(files, templateFile{Path: rel, Status: "kept"})
+			continue
+		}
+		missing[rel] = defaults[rel]
+		files = append(files, templateFile{Path: rel, Status: "created"})
+	}
+	if err := writeTemplates(home, missing, false); err != nil {
+		return nil, fmt.Errorf("failed to write templates: %w", err)
+	}
+	return files, nil
+}
+
+// diffTemplates compares home with goctl's defaults
+// customized: differs from the default; missing: goctl falls back to its built-in
+// template; unused: not a template of this goctl release; outdated: goctl's default
+// changed since the home was initialized or updated
+func diffTemplates(defaults, current, baseline map[string][]byte) []templateFile {
+	var files []templateFile
+	for _, rel := range sortedTemplates(defaults) {
+		content, ok := current[rel]
+		switch {
+		case !ok:
+			files = append(files, templateFile{Path: rel, Status: "missing"})
+		case !bytes.Equal(content, defaults[rel]):
+			files = append(files, templateFile{Path: rel, Status: "customized", Diff: diff.Unified("default/"+rel, "home/"+rel, defaults[rel], content, 3)})
+		}
+		if base, ok := baseline[rel]; ok && !bytes.Equal(base, defaults[rel]) {
+			files = append(files, templateFile{Path: rel, Status: "outdated", Diff: diff.Unified("previous/"+rel, "default/"+rel, base, defaults[rel], 3)})
+		}
+	}
+	for _, rel := range sortedTemplates(current) {
+		if _, ok := defaults[rel]; !ok {
+			files = append(files, templateFile{Path: rel, Status: "unused"})
+		}
+	}
+	return files
+}
+
+// updateTemplates brings home up to goctl's defaults without losing customizations
+// Templates still equal to the baseline take the new default; customized ones are
+// kept, and reported as conflicts when goctl's default changed underneath them
+func updateTemplates(home string, defaults, current, baseline map[string][]byte) ([]templateFile, error) {
+	var files []templateFile
+	changed := map[string][]byte{}
+	for _, rel := range sortedTemplates(defaults) {
+		content, ok := current[rel]
+		base, hasBase := baseline[rel]
+		switch {
+		case !ok:
+			changed[rel] = defaults[rel]
+			files = append(files, templateFile{Path: rel, Status: "added"})
+		case bytes.Equal(content, defaults[rel]):
+		case hasBase && bytes.Equal(content, base):
+			changed[rel] = defaults[rel]
+			files = append(files, templateFile{Path: rel, Status: "updated"})
+		case hasBase && !bytes.Equal(base, defaults[rel]):
+			files = append(files, templateFile{Path: rel, Status: "conflict", Diff: diff.Unified("previous/"+rel, "default/"+rel, base, defaults[rel], 3)})
+		default:
+			files = append(files, templateFile{Path: rel, Status: "customized"})
+		}
+	}
+	if err := writeTemplates(home, changed, false); err != nil {
+		return nil, fmt.Errorf("failed to write templates: %w", err)
+	}
+	return files, nil
+}
+
+// sortedTemplates returns the paths of templates in order
+func sortedTemplates(templates map[string][]byte) []string {
+	paths := make([]string, 0, len(templates))
+	for rel := range templates {
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/analyzer"
+	"github.com/zeromicro/mcp-zero/internal/openapi"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// ImportOpenAPIParams defines the parameters for import_openapi tool
+type ImportOpenAPIParams struct {
+	OpenAPIFile string `json:"openapi_file"`
+	ServiceName string `json:"service_name,omitempty"`
+	OutputPath  string `json:"output_path,omitempty"`
+	Generate    bool   `json:"generate,omitempty"`
+	OutputDir   string `json:"output_dir,omitempty"`
+	Style       string `json:"style,omitempty"`
+}
+
+// ImportOpenAPI converts an OpenAPI 2 or 3 document into a go-zero .api specification
+func ImportOpenAPI(ctx context.Context, req *mcp.CallToolRequest, params ImportOpenAPIParams) (*mcp.CallToolResult, any, error) {
+	if params.OpenAPIFile == "" {
+		return responses.FormatValidationError("openapi_file", "", "openapi_file is required", "Provide the path of an OpenAPI 3 or Swagger 2 document in JSON or YAML")
+	}
+	if params.ServiceName != "" {
+		if err := validation.ValidateServiceName(params.ServiceName); err != nil {
+			return responses.FormatValidationError("service_name", params.ServiceName, err.Error(), "Use lowercase letters, numbers, and hyphens only")
+		}
+	}
+
+	openAPIFile := params.OpenAPIFile
+	if !filepath.IsAbs(openAPIFile) {
+		cwd, _ := os.Getwd()
+		openAPIFile = filepath.Join(cwd, openAPIFile)
+	}
+	if err := validation.CheckAllowed(ctx, openAPIFile); err != nil {
+		return responses.FormatValidationError("openapi_file", openAPIFile, err.Error(), rootsHint)
+	}
+	raw, err := os.ReadFile(openAPIFile)
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to read OpenAPI document: %v", err))
+	}
+
+	doc, err := openapi.Parse(raw)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	result, err := openapi.Import(doc, openapi.ImportOptions{ServiceName: params.ServiceName})
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to convert OpenAPI document: %v", err))
+	}
+
+	outputPath := params.OutputPath
+	if outputPath == "" {
+		outputPath = result.ServiceName + ".api"
+	}
+	if !filepath.IsAbs(outputPath) {
+		cwd, _ := os.Getwd()
+		outputPath = filepath.Join(cwd, outputPath)
+	}
+	if err := validation.CheckAllowed(ctx, outputPath); err != nil {
+		return responses.FormatValidationError("output_path", outputPath, err.Error(), rootsHint)
+	}
+	// Generation checks output_dir too, but refusing it now keeps the spec from being written
+	if params.Generate {
+		if err := validation.CheckAllowed(ctx, absPath(params.OutputDir)); err != nil {
+			return responses.FormatValidationError("output_dir", params.OutputDir, err.Error(), rootsHint)
+		}
+	}
+	// Check for an existing file and write it under one lock
+	held, err := lockPaths(ctx, false, outputPath)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
+
+	if _, err := os.Stat(outputPath); err == nil {
+		return responses.FormatValidationError("output_path", outputPath, "file already exists", "Choose another output_path or remove the existing file")
+	}
+	if err := validation.EnsureDirectoryExists(filepath.Dir(outputPath)); err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to create output directory: %v", err))
+	}
+	if err := os.WriteFile(outputPath, result.Source, 0644); err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to write spec file: %v", err))
+	}
+
+	spec, err := analyzer.ParseAPISpecification(outputPath, importCheck(ctx))
+	if err != nil {
+		os.Remove(outputPath)
+		return responses.FormatError(fmt.Sprintf("generated spec is invalid: %v", err))
+	}
+
+	message := fmt.Sprintf("Successfully imported %s (OpenAPI %s)\n\nOutput file: %s\n", openAPIFile, doc.OpenAPI, outputPath)
+	message += fmt.Sprintf("Service: %s-api\n", result.ServiceName)
+	message += fmt.Sprintf("Endpoints: %d\n", len(spec.Endpoints))
+	message += fmt.Sprintf("Types: %d\n", len(spec.Types))
+	if len(result.Groups) > 0 {
+		message += fmt.Sprintf("Groups: %s\n", strings.Join(result.Groups, ", "))
+	}
+	if len(result.Warnings) > 0 {
+		message += fmt.Sprintf("\nConstructs that could not be represented (%d):\n", len(result.Warnings))
+		for _, warning := range result.Warnings {
+			message += fmt.Sprintf("  - %s\n", warning)
+		}
+	}
+
+	data := map[string]any{
+		"openapi_file":   openAPIFile,
+		"output_path":    outputPath,
+		"service_name":   result.ServiceName,
+		"endpoint_count": len(spec.Endpoints),
+		"type_count":     len(spec.Types),
+		"groups":         result.Groups,
+		"warnings":       result.Warnings,
+	}
+
+	if !params.Generate {
+		message += "\nNext steps:\n"
+		message += "  1. Review the generated specification and the warnings above\n"
+		message += "  2. Use generate_api_from_spec to generate code\n"
+		return responses.FormatSuccessWithData(message, data)
+	}
+
+	// generate_api_from_spec takes its own lock, which may cover the spec file
+	held.Unlock()
+	genResult, _, err := GenerateAPIFromSpec(ctx, req, GenerateAPIFromSpecParams{
+		APIFile:   outputPath,
+		OutputDir: params.OutputDir,
+		Style:     params.Style,
+	})
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("spec written to %s, but code generation failed: %v", outputPath, err))
+	}
+	for _, content := range genResult.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			message += "\n" + text.Text
+		}
+	}
+	data["generated"] = true
+
+	return responses.FormatSuccessWithData(message, data)
+}
@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/apispec"
+	"github.com/zeromicro/mcp-zero/internal/lint"
+	"github.com/zeromicro/mcp-zero/internal/project"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// LintAPISpecParams defines the parameters for lint_api_spec tool
+type LintAPISpecParams struct {
+	APIFile    string          `json:"api_file"`
+	ConfigFile string          `json:"config_file,omitempty"`
+	Rules      map[string]bool `json:"rules,omitempty"`
+	FailOn     string          `json:"fail_on,omitempty"`
+}
+
+// LintAPISpec checks an .api specification against go-zero conventions
+func LintAPISpec(ctx context.Context, req *mcp.CallToolRequest, params LintAPISpecParams) (*mcp.CallToolResult, any, error) {
+	if params.APIFile == "" {
+		return responses.FormatValidationError("api_file", "", "api_file is required", "Provide the path of the .api file to lint")
+	}
+	failOn := strings.ToLower(params.FailOn)
+	switch failOn {
+	case "", "never", "error", "warning", "info":
+	default:
+		return responses.FormatValidationError("fail_on", params.FailOn, "unsupported severity", "Use error, warning, info or never")
+	}
+
+	apiFile := absPath(params.APIFile)
+	if err := validation.CheckAllowed(ctx, apiFile); err != nil {
+		return responses.FormatValidationError("api_file", apiFile, err.Error(), rootsHint)
+	}
+	if _, err := os.Stat(apiFile); os.IsNotExist(err) {
+		return responses.FormatError(fmt.Sprintf("API file not found: %s", apiFile))
+	}
+
+	var config *project.Config
+	var err error
+	if params.ConfigFile != "" {
+		configFile := absPath(params.ConfigFile)
+		if err := validation.CheckAllowed(ctx, configFile); err != nil {
+			return responses.FormatValidationError("config_file", configFile, err.Error(), rootsHint)
+		}
+		config, err = project.ReadConfig(configFile)
+	} else {
+		config, err = project.LoadConfig(ctx, filepath.Dir(apiFile))
+	}
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+
+	overrides := make(map[string]bool)
+	for name, enabled := range config.Lint.Rules {
+		overrides[name] = enabled
+	}
+	for name, enabled := range params.Rules {
+		overrides[name] = enabled
+	}
+
+	spec, err := apispec.Load(apiFile, importCheck(ctx))
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to parse API specification: %v", err))
+	}
+	result, err := lint.Lint(spec, overrides)
+	if err != nil {
+		var names []string
+		for _, r := range lint.Rules() {
+			names = append(names, r.Name)
+		}
+		return responses.FormatValidationError("rules", err.Error(), err.Error(), "Known rules: "+strings.Join(names, ", "))
+	}
+
+	message := formatLintResult(apiFile, result)
+	if config.Path != "" {
+		message += fmt.Sprintf("\nConfig: %s\n", config.Path)
+	}
+
+	if lintFailed(result, failOn) {
+		return responses.FormatError(fmt.Sprintf("lint failed at %s severity\n\n%s", failOn, message))
+	}
+
+	data := map[string]any{
+		"api_file": apiFile,
+		"config":   config.Path,
+		"rules":    result.Rules,
+		"errors":   result.Errors,
+		"warnings": result.Warnings,
+		"info":     result.Info,
+		"issues":   result.Issues,
+	}
+	return responses.FormatSuccessWithData(message, data)
+}
+
+// formatLintResult renders lint issues one per line as file:line:column
+func formatLintResult(apiFile string, result *lint.Result) string {
+	message := fmt.Sprintf("Lint results for %s\n\nErrors: %d, Warnings: %d, Info: %d\n", apiFile, result.Errors, result.Warnings, result.Info)
+	if len(result.Issues) == 0 {
+		return message + "\nNo issues found\n"
+	}
+	message += "\n"
+	for _, issue := range result.Issues {
+		message += issue.String() + "\n"
+	}
+	return message
+}
+
+// lintFailed reports whether the result has an issue at or above the fail_on severity
+func lintFailed(result *lint.Result, failOn string) bool {
+	switch failOn {
+	case "error":
+		return result.Errors > 0
+	case "warning":
+		return result.Errors+result.Warnings > 0
+	case "info":
+		return len(result.Issues) > 0
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/lock"
+)
+
+// locks serializes tool calls writing to overlapping trees, while calls on unrelated
+// directories run in parallel
+var locks = lock.NewManager()
+
+func init() {
+	// An analysis is stale once a write to its tree finishes
+	locks.OnRelease(invalidateAnalyses)
+}
+
+// lockPaths takes the write lock on paths, or a shared read lock when readOnly, as for
+// dry runs, which only copy them
+func lockPaths(ctx context.Context, readOnly bool, paths ...string) (*lock.Lock, error) {
+	var held *lock.Lock
+	var err error
+	if readOnly {
+		held, err = locks.RLock(ctx, paths...)
+	} else {
+		held, err = locks.Lock(ctx, paths...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gave up waiting for another tool call using %s: %w", strings.Join(paths, ", "), err)
+	}
+	return held, nil
+}
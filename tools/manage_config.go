@@ -13,6 +13,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"gopkg.in/yaml.v3"
 
+	"github.com/zeromicro/mcp-zero/internal/fixer"
 	"github.com/zeromicro/mcp-zero/internal/responses"
 	"github.com/zeromicro/mcp-zero/internal/templates"
 	"github.com/zeromicro/mcp-zero/internal/validation"
@@ -29,6 +30,15 @@ type GenerateConfigParams struct {
 	Environment string `json:"environment"`  // "development", "production", "test"
 	Port        int    `json:"port,omitempty"`
 	OutputPath  string `json:"output_path,omitempty"`
+	DryRun      bool   `json:"dry_run,omitempty"`
+}
+
+type UpdateConfigParams struct {
+	ConfigPath  string   `json:"config_path,omitempty"`
+	ServiceDir  string   `json:"service_dir,omitempty"`  // used with service_name to pick etc/*.yaml
+	ServiceName string   `json:"service_name,omitempty"` // defaults to the service directory name
+	Edits       []string `json:"edits"`                  // path=value, e.g. Prometheus.Port=9101
+	DryRun      bool     `json:"dry_run,omitempty"`
 }
 
 // ValidateConfig validates a go-zero configuration file
@@ -44,6 +54,9 @@ func ValidateConfig(ctx context.Context, req *mcp.CallToolRequest, params Valida
 		}
 		params.ConfigPath = absPath
 	}
+	if err := validation.CheckAllowed(ctx, params.ConfigPath); err != nil {
+		return responses.FormatValidationError("config_path", params.ConfigPath, err.Error(), rootsHint)
+	}
 
 	content, err := os.ReadFile(params.ConfigPath)
 	if err != nil {
@@ -214,6 +227,29 @@ func GenerateConfigTemplate(ctx context.Context, req *mcp.CallToolRequest, param
 		cwd, _ := os.Getwd()
 		outputPath = filepath.Join(cwd, outputPath)
 	}
+	if err := validation.CheckAllowed(ctx, outputPath); err != nil {
+		return responses.FormatValidationError("output_path", outputPath, err.Error(), rootsHint)
+	}
+
+	// Serialize with other tool calls writing the config
+	held, err := lockPaths(ctx, params.DryRun, outputPath)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
+
+	// A dry run only compares the config with the existing file
+	if params.DryRun {
+		preview, err := newDryRunFile(outputPath)
+		if err != nil {
+			return responses.FormatError(err.Error())
+		}
+		defer preview.Close()
+		if err := os.WriteFile(preview.Path, []byte(configContent), 0644); err != nil {
+			return responses.FormatError(fmt.Sprintf("failed to write config file: %v", err))
+		}
+		return preview.Result("generate_config_template", nil)
+	}
 
 	// Create directory if needed
 	dir := filepath.Dir(outputPath)
@@ -262,3 +298,99 @@ func GenerateConfigTemplate(ctx context.Context, req *mcp.CallToolRequest, param
 
 	return responses.FormatSuccessWithData(message, resultData)
 }
+
+// UpdateConfig edits keys of a go-zero YAML config by path, keeping comments and layout
+func UpdateConfig(ctx context.Context, req *mcp.CallToolRequest, params UpdateConfigParams) (*mcp.CallToolResult, any, error) {
+	if len(params.Edits) == 0 {
+		return responses.FormatValidationError("edits", "", "edits is required", "Provide path=value edits such as Port=8080 or Prometheus.Port=9101")
+	}
+	edits := make([]fixer.ConfigEdit, 0, len(params.Edits))
+	for _, raw := range params.Edits {
+		edit, err := fixer.ParseConfigEdit(raw)
+		if err != nil {
+			return responses.FormatValidationError("edits", raw, err.Error(), "Use path=value, with dots between nested keys, e.g. Etcd.Key=user.rpc")
+		}
+		edits = append(edits, edit)
+	}
+
+	// Resolve the config file: an explicit path, or the service's etc/ file
+	configPath := params.ConfigPath
+	if configPath == "" {
+		if params.ServiceDir == "" {
+			return responses.FormatValidationError("config_path", "", "config_path or service_dir is required", "Provide the config file or the service directory holding etc/")
+		}
+		serviceDir, err := filepath.Abs(params.ServiceDir)
+		if err != nil {
+			return responses.FormatError(fmt.Sprintf("failed to resolve service directory: %v", err))
+		}
+		if err := validation.CheckAllowed(ctx, serviceDir); err != nil {
+			return responses.FormatValidationError("service_dir", serviceDir, err.Error(), rootsHint)
+		}
+		serviceName := params.ServiceName
+		if serviceName == "" {
+			serviceName = filepath.Base(serviceDir)
+		}
+		if configPath, err = fixer.FindConfigFile(serviceDir, serviceName); err != nil {
+			return responses.FormatValidationError("service_name", serviceName, err.Error(), "Set service_name or pass config_path")
+		}
+		if configPath == "" {
+			return responses.FormatError(fmt.Sprintf("no config file found in %s", filepath.Join(serviceDir, "etc")))
+		}
+	}
+	configPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to resolve config path: %v", err))
+	}
+	if err := validation.CheckAllowed(ctx, configPath); err != nil {
+		return responses.FormatValidationError("config_path", configPath, err.Error(), rootsHint)
+	}
+
+	// Hold the lock from reading the config until it is rewritten
+	held, err := lockPaths(ctx, params.DryRun, configPath)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
+
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to read config file: %v", err))
+	}
+	updated, changes, err := fixer.EditConfig(content, edits)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+
+	// A dry run only compares the edited config with the existing file
+	if params.DryRun {
+		preview, err := newDryRunFile(configPath)
+		if err != nil {
+			return responses.FormatError(err.Error())
+		}
+		defer preview.Close()
+		if err := os.WriteFile(preview.Path, updated, 0644); err != nil {
+			return responses.FormatError(fmt.Sprintf("failed to write config file: %v", err))
+		}
+		return preview.Result("update_config", nil)
+	}
+
+	if err := os.WriteFile(configPath, updated, 0644); err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to write config file: %v", err))
+	}
+
+	message := fmt.Sprintf("Updated %s\n\nChanges:\n", configPath)
+	for _, change := range changes {
+		if change.Added {
+			message += fmt.Sprintf("  %s: added %s\n", change.Path, change.New)
+		} else {
+			message += fmt.Sprintf("  %s: %s → %s\n", change.Path, change.Old, change.New)
+		}
+	}
+
+	data := map[string]any{
+		"config_path": configPath,
+		"changes":     changes,
+	}
+
+	return responses.FormatSuccessWithData(message, data)
+}
@@ -0,0 +1,180 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+	"github.com/zeromicro/mcp-zero/internal/goctl"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// styleUpgradeHint is the suggestion shown for a --style format the installed goctl lacks
+const styleUpgradeHint = "Use go_zero, gozero or goZero, or upgrade goctl to use custom style formats"
+
+// MigrateStyleParams defines the parameters for migrate_style tool
+type MigrateStyleParams struct {
+	ProjectDir    string   `json:"project_dir"`
+	To            string   `json:"to"`             // target goctl style, e.g. goZero
+	From          string   `json:"from,omitempty"` // only rename files in this style; default all
+	DryRun        bool     `json:"dry_run,omitempty"`
+	KeepOnFailure bool     `json:"keep_on_failure,omitempty"`
+	Verify        []string `json:"verify,omitempty"` // checks after go build: vet, test, gofmt
+}
+
+// MigrateStyle renames generated files from one goctl naming style to another and
+// verifies the project still builds, rolling back the renames if it does not
+func MigrateStyle(ctx context.Context, req *mcp.CallToolRequest, params MigrateStyleParams) (*mcp.CallToolResult, any, error) {
+	if params.ProjectDir == "" {
+		return responses.FormatValidationError("project_dir", "", "project_dir is required", "Provide the service or project directory")
+	}
+	projectDir := absPath(params.ProjectDir)
+	if err := validation.CheckAllowed(ctx, projectDir); err != nil {
+		return responses.FormatValidationError("project_dir", projectDir, err.Error(), rootsHint)
+	}
+	if info, err := os.Stat(projectDir); err != nil || !info.IsDir() {
+		return responses.FormatValidationError("project_dir", projectDir, "directory not found", "Provide an existing service or project directory")
+	}
+	if _, err := fixer.ParseStyle(params.To); err != nil {
+		return responses.FormatValidationError("to", params.To, err.Error(), "Use a goctl style such as 'go_zero', 'gozero', 'goZero' or a custom format like 'Go-Zero'")
+	}
+	if params.From != "" {
+		if _, err := fixer.ParseStyle(params.From); err != nil {
+			return responses.FormatValidationError("from", params.From, err.Error(), "Use a goctl style such as 'go_zero', 'gozero' or 'goZero', or leave it empty")
+		}
+	}
+
+	// Files are renamed without goctl, but the installed goctl must be able to regenerate
+	// in a custom style format
+	if !slices.Contains(goctl.ClassicStyles, params.To) {
+		executor, err := goctl.NewExecutor()
+		if err != nil {
+			return responses.FormatValidationError("to", params.To, err.Error(), "Install goctl, or migrate to go_zero, gozero or goZero")
+		}
+		if err := executor.RequireStyle(params.To); err != nil {
+			return responses.FormatValidationError("to", params.To, err.Error(), styleUpgradeHint)
+		}
+	}
+
+	checks, err := fixer.ResolveVerifyOptions(params.Verify)
+	if err != nil {
+		return responses.FormatValidationError("verify", strings.Join(params.Verify, ","), err.Error(), "Use any of build, vet, test, gofmt or all")
+	}
+
+	// Plan and rename while no other tool call writes to the project
+	held, err := lockPaths(ctx, params.DryRun, projectDir)
+	if err != nil {
+		return responses.FormatError(err.Error())
+	}
+	defer held.Unlock()
+
+	before, err := fixer.AnalyzeStyles(projectDir)
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to analyze naming styles: %v", err))
+	}
+	renames, err := fixer.PlanStyleMigration(projectDir, params.To, params.From)
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("cannot migrate to %s: %v", params.To, err))
+	}
+
+	// A dry run renames inside a staged copy of the project
+	workDir := projectDir
+	var preview *dryRun
+	if params.DryRun {
+		if preview, err = newDryRun(projectDir); err != nil {
+			return responses.FormatError(err.Error())
+		}
+		defer preview.Close()
+		workDir = preview.Path
+	}
+
+	steps := newPipeline(req).
+		Add("rename files", func(ctx context.Context) error {
+			return fixer.ApplyStyleMigration(workDir, renames)
+		})
+	// The staged copy may sit outside the project's module, so only real runs are verified
+	if preview == nil && len(renames) > 0 {
+		addVerifySteps(steps, workDir, checks)
+	}
+
+	var snapshots []*snapshot.Snapshot
+	if preview == nil && len(renames) > 0 {
+		snap, err := snapshot.Take(projectDir)
+		if err != nil {
+			return responses.FormatError(err.Error())
+		}
+		snapshots = append(snapshots, snap)
+	}
+	timings, err := runTransaction(ctx, steps, params.KeepOnFailure, snapshots...)
+	if err != nil {
+		return failure(err)
+	}
+	if preview != nil {
+		return preview.Result("migrate_style", timings)
+	}
+
+	after, err := fixer.AnalyzeStyles(projectDir)
+	if err != nil {
+		return responses.FormatError(fmt.Sprintf("failed to analyze naming styles: %v", err))
+	}
+
+	message := fmt.Sprintf("Migrated %s to style %s\n", projectDir, params.To)
+	message += fmt.Sprintf("\nStyles before: %s\n", formatStyleCounts(before))
+	if len(renames) == 0 {
+		message += "\nAll generated files already use this style; nothing was renamed\n"
+	} else {
+		message += fmt.Sprintf("\nRenamed %d files:\n", len(renames))
+		for _, rename := range renames {
+			message += fmt.Sprintf("  %s → %s\n", rename.From, rename.To)
+		}
+	}
+	if after.Mixed() {
+		message += fmt.Sprintf("\nStyles still in use: %s\n", formatStyleCounts(after))
+	}
+	message += "\n" + timings.String()
+	message += "\nPass the same style to goctl from now on, e.g. --style " + params.To + "\n"
+
+	if renames == nil {
+		renames = []fixer.StyleRename{}
+	}
+	return responses.FormatSuccessWithData(message, map[string]any{
+		"project_dir": projectDir,
+		"style":       params.To,
+		"renames":     renames,
+		"styles":      after.Styles,
+		"mixed":       after.Mixed(),
+		"conflicts":   after.Conflicts,
+		"checks":      checks.Checks(),
+		"steps":       timings.Steps,
+		"elapsed_ms":  timings.ElapsedMS,
+	})
+}
+
+// formatStyleCounts lists styles with their file counts, such as "go_zero (12), goZero (3)"
+func formatStyleCounts(report *fixer.StyleReport) string {
+	if len(report.Styles) == 0 {
+		return "none detected"
+	}
+	styles := make([]string, 0, len(report.Styles))
+	for style := range report.Styles {
+		styles = append(styles, style)
+	}
+	sort.Strings(styles)
+
+	text := ""
+	for i, style := range styles {
+		if i > 0 {
+			text += ", "
+		}
+		text += fmt.Sprintf("%s (%d)", style, len(report.Styles[style]))
+	}
+	return text
+}
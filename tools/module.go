@@ -0,0 +1,182 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+	"github.com/zeromicro/mcp-zero/internal/pipeline"
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
+)
+
+// moduleOptions are the module parameters shared by the generation tools
+type moduleOptions struct {
+	ModulePath     string // explicit import path, empty to detect
+	AddToWorkspace bool   // add a newly created module to the enclosing go.work
+	DependencyMode string // online, offline or vendor; empty uses the server-wide mode
+	ModuleProxy    string // file-based module proxy directory for offline mode
+}
+
+// generationModule is the module layout chosen for code generated into Dir
+type generationModule struct {
+	*fixer.ModuleResolution
+	Dir  string
+	Deps fixer.Dependencies
+	opts moduleOptions
+}
+
+// resolveGenerationModule detects the module covering dir, which may not exist yet
+// fallback is the module path used when dir is outside any module and no module_path is given
+func resolveGenerationModule(ctx context.Context, dir, fallback string, opts moduleOptions) (*generationModule, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	deps, err := fixer.ResolveDependencies(opts.DependencyMode, opts.ModuleProxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dependency options: %w", err)
+	}
+	resolution, err := fixer.ResolveModule(abs, opts.ModulePath, fallback)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect Go module: %w", err)
+	}
+	module := &generationModule{ModuleResolution: resolution, Dir: abs, Deps: deps, opts: opts}
+	// go mod tidy and go work use write above dir, so those files must be allowed too
+	if err := checkRoots(ctx, append(module.lockPaths(), opts.ModuleProxy)...); err != nil {
+		return nil, err
+	}
+	return module, nil
+}
+
+// context makes the go commands of a pipeline run under ctx resolve modules in the chosen mode
+func (m *generationModule) context(ctx context.Context) context.Context {
+	return fixer.WithDependencies(ctx, m.Deps)
+}
+
+// addToWorkspace reports whether a go work use step runs after go mod init
+func (m *generationModule) addToWorkspace() bool {
+	return m.opts.AddToWorkspace && m.NewModule && m.Enclosing.WorkFile != ""
+}
+
+// addSteps appends go mod init, go work use when requested, and go mod tidy for workDir,
+// the directory generation writes to; it differs from Dir in a dry run, where preview
+// stages copies of the enclosing go.mod, go.sum and go.work for the steps to change instead
+func (m *generationModule) addSteps(steps *pipeline.Pipeline, workDir string, preview *dryRun) {
+	steps.Add("go mod init", func(ctx context.Context) error {
+		if err := fixer.InitializeGoModule(ctx, workDir, m.ImportPath); err != nil {
+			return fmt.Errorf("failed to initialize Go module: %w", err)
+		}
+		return nil
+	})
+	if m.addToWorkspace() {
+		steps.Add("go work use", func(ctx context.Context) error {
+			var err error
+			if preview == nil {
+				err = fixer.AddToWorkspace(ctx, m.Enclosing.WorkFile, m.Dir)
+			} else {
+				var staged string
+				if staged, err = preview.StageFile(m.Enclosing.WorkFile); err == nil {
+					err = fixer.AddToWorkspaceCopy(ctx, staged, m.Enclosing.WorkFile, m.Dir)
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("failed to add module to go.work: %w", err)
+			}
+			return nil
+		})
+	}
+	steps.Add("go mod tidy", func(ctx context.Context) error {
+		if err := fixer.TidyGoModule(ctx, workDir); err != nil {
+			return fmt.Errorf("failed to tidy Go module: %w", err)
+		}
+		return nil
+	})
+	if preview != nil && len(m.stagedOnly()) > 0 {
+		// The staged copy got a go.mod of its own; carry what it needs over to copies of
+		// the enclosing go.mod and go.sum, which the real run's go mod tidy updates
+		steps.Add("preview enclosing go.mod", func(ctx context.Context) error {
+			modFile, err := preview.StageFile(filepath.Join(m.Enclosing.Root, "go.mod"))
+			if err != nil {
+				return err
+			}
+			sumFile, err := preview.StageFile(filepath.Join(m.Enclosing.Root, "go.sum"))
+			if err != nil {
+				return err
+			}
+			if err := fixer.MergeRequirements(ctx, modFile, sumFile, workDir); err != nil {
+				return fmt.Errorf("failed to preview the enclosing go.mod: %w", err)
+			}
+			return nil
+		})
+	}
+}
+
+// stagedOnly lists files a dry run creates only because its staged copy is outside the
+// enclosing module; they are left out of the preview, which shows the enclosing go.mod
+// and go.sum changing instead
+func (m *generationModule) stagedOnly() []string {
+	if m.NewModule || m.Enclosing.Root == m.Dir {
+		return nil
+	}
+	return []string{"go.mod", "go.sum"}
+}
+
+// snapshots records everything generation may change: Dir itself, the enclosing go.mod
+// and go.sum that go mod tidy updates when no new module is created, and go.work when extended
+func (m *generationModule) snapshots() ([]*snapshot.Snapshot, error) {
+	dirSnapshot, err := snapshot.Take(m.Dir)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := []*snapshot.Snapshot{dirSnapshot}
+
+	if !m.NewModule && m.Enclosing.Root != m.Dir {
+		files, err := snapshot.TakeFiles(m.Enclosing.Root, "go.mod", "go.sum")
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, files)
+	}
+	if m.addToWorkspace() {
+		files, err := snapshot.TakeFiles(filepath.Dir(m.Enclosing.WorkFile), "go.work", "go.work.sum")
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, files)
+	}
+	return snapshots, nil
+}
+
+// lockPaths lists what snapshots records, for locking against other tool calls
+func (m *generationModule) lockPaths() []string {
+	paths := []string{m.Dir}
+	if !m.NewModule && m.Enclosing.Root != m.Dir {
+		paths = append(paths, filepath.Join(m.Enclosing.Root, "go.mod"), filepath.Join(m.Enclosing.Root, "go.sum"))
+	}
+	if m.addToWorkspace() {
+		dir := filepath.Dir(m.Enclosing.WorkFile)
+		paths = append(paths, filepath.Join(dir, "go.work"), filepath.Join(dir, "go.work.sum"))
+	}
+	return paths
+}
+
+// data describes the module layout in tool results
+func (m *generationModule) data() map[string]any {
+	data := map[string]any{
+		"module_path":     m.ImportPath,
+		"new_module":      m.NewModule,
+		"dependency_mode": m.Deps.Mode,
+	}
+	if m.Deps.ProxyDir != "" {
+		data["module_proxy"] = m.Deps.ProxyDir
+	}
+	if m.Enclosing.Root != "" {
+		data["module_root"] = m.Enclosing.Root
+	}
+	if m.Enclosing.WorkFile != "" {
+		data["go_work"] = m.Enclosing.WorkFile
+		data["added_to_workspace"] = m.addToWorkspace()
+	}
+	return data
+}
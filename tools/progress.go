@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/pipeline"
+)
+
+// newPipeline creates a pipeline that reports MCP progress for the tool call
+func newPipeline(req *mcp.CallToolRequest) *pipeline.Pipeline {
+	return pipeline.New(progressReporter(req))
+}
+
+// progressReporter sends notifications/progress when the client supplied a progress token
+// Returns nil otherwise; notifications are best effort and never fail the call
+func progressReporter(req *mcp.CallToolRequest) pipeline.Reporter {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return nil
+	}
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, p pipeline.Progress) {
+		message := fmt.Sprintf("[%d/%d] %s (%s elapsed)", p.Index, p.Total, p.Step, p.Elapsed.Round(time.Millisecond))
+		if p.Step == "" {
+			message = fmt.Sprintf("[%d/%d] done (%s elapsed)", p.Index, p.Total, p.Elapsed.Round(time.Millisecond))
+		}
+		_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Message:       message,
+			Progress:      float64(p.Completed),
+			Total:         float64(p.Total),
+			Meta: mcp.Meta{
+				"step":       p.Step,
+				"index":      p.Index,
+				"total":      p.Total,
+				"elapsed_ms": p.Elapsed.Milliseconds(),
+			},
+		})
+	}
+}
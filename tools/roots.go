@@ -0,0 +1,42 @@
+package tools
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zeromicro/mcp-zero/internal/validation"
+)
+
+// rootsHint is the suggestion shown for paths outside the allowed workspace roots
+const rootsHint = "Use a path inside the allowed roots; the server operator sets them with -roots or the roots list of the server config file"
+
+// pathHint returns rootsHint when err is about the allowed roots, and hint otherwise
+func pathHint(err error, hint string) string {
+	var outside *validation.OutsideRootsError
+	if errors.As(err, &outside) {
+		return rootsHint
+	}
+	return hint
+}
+
+// importCheck returns the check the spec parsers run before reading each import, which
+// refuses files outside the call's allowed roots whether or not the importing file is inside
+func importCheck(ctx context.Context) func(path string) error {
+	return func(path string) error {
+		return validation.CheckAllowed(ctx, path)
+	}
+}
+
+// checkRoots returns the error for the first path outside the call's allowed roots,
+// skipping empty paths
+func checkRoots(ctx context.Context, paths ...string) error {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := validation.CheckAllowed(ctx, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
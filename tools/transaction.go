@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeromicro/mcp-zero/internal/pipeline"
+	"github.com/zeromicro/mcp-zero/internal/snapshot"
+)
+
+// runTransaction runs steps and, if one fails, restores every snapshotted directory or file set
+// exactly as it was unless keep is set. The error reports what was rolled back or kept and
+// unwraps to the failed step's error. Without snapshots, as in a dry run, nothing is rolled back
+func runTransaction(ctx context.Context, steps *pipeline.Pipeline, keep bool, snapshots ...*snapshot.Snapshot) (*pipeline.Result, error) {
+	timings, runErr := steps.Run(ctx)
+	if runErr == nil {
+		return timings, nil
+	}
+
+	message := runErr.Error() + "\n\n" + timings.String()
+	if len(snapshots) == 0 {
+		return timings, &transactionError{message: message, err: runErr}
+	}
+
+	changed := false
+	for _, before := range snapshots {
+		dir := before.Root
+		if keep {
+			after, err := before.Retake()
+			if err != nil {
+				message += fmt.Sprintf("\nkeep_on_failure is set; the failed output in %s was kept\n", dir)
+				continue
+			}
+			if changes := before.Compare(after); !changes.Empty() {
+				changed = true
+				message += fmt.Sprintf("\nkeep_on_failure is set; the failed output in %s was kept:\n%s", dir, formatChanges(changes, "created", "modified", "deleted"))
+			}
+			continue
+		}
+
+		changes, err := before.Rollback()
+		switch {
+		case err != nil:
+			changed = true
+			message += fmt.Sprintf("\nRollback of %s was incomplete: %v\n", dir, err)
+			if changes != nil {
+				message += formatChanges(changes, "removed", "restored", "restored")
+			}
+		case !changes.Empty():
+			changed = true
+			message += fmt.Sprintf("\nRolled back %s to its previous state (set keep_on_failure to keep the failed output):\n%s", dir, formatChanges(changes, "removed", "restored", "restored"))
+		}
+	}
+	if !changed {
+		message += "\nNo files were changed\n"
+	}
+	return timings, &transactionError{message: message, err: runErr}
+}
+
+// editSnapshots snapshots a service directory, and each edited file outside it on its own,
+// so a failed incremental generation restores both. The service snapshot comes first
+func editSnapshots(serviceDir string, edited ...string) ([]*snapshot.Snapshot, error) {
+	service, err := snapshot.Take(serviceDir)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := []*snapshot.Snapshot{service}
+	for _, path := range edited {
+		if rel, err := filepath.Rel(serviceDir, path); err == nil && filepath.IsLocal(rel) {
+			continue
+		}
+		files, err := snapshot.TakeFiles(filepath.Dir(path), filepath.Base(path))
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, files)
+	}
+	return snapshots, nil
+}
+
+// transactionError is the report of a failed transaction
+type transactionError struct {
+	message string
+	err     error
+}
+
+func (e *transactionError) Error() string { return e.message }
+
+func (e *transactionError) Unwrap() error { return e.err }
+
+// formatChanges lists changed files under the given verbs for created, modified and deleted files
+func formatChanges(changes *snapshot.Changes, created, modified, deleted string) string {
+	lines := map[string][]string{}
+	var verbs []string
+	add := func(verb string, files []string) {
+		if len(files) == 0 {
+			return
+		}
+		if _, ok := lines[verb]; !ok {
+			verbs = append(verbs, verb)
+		}
+		lines[verb] = append(lines[verb], files...)
+	}
+	add(created, changes.Created)
+	add(modified, changes.Modified)
+	add(deleted, changes.Deleted)
+
+	var b strings.Builder
+	for _, verb := range verbs {
+		fmt.Fprintf(&b, "  %s (%d): %s\n", verb, len(lines[verb]), strings.Join(lines[verb], ", "))
+	}
+	return b.String()
+}
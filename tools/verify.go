@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/zeromicro/mcp-zero/internal/fixer"
+	"github.com/zeromicro/mcp-zero/internal/pipeline"
+	"github.com/zeromicro/mcp-zero/internal/responses"
+)
+
+// addVerifySteps adds a pipeline step for each verification check run on dir
+func addVerifySteps(steps *pipeline.Pipeline, dir string, opts fixer.VerifyOptions) {
+	for _, check := range opts.Checks() {
+		steps.Add(fixer.CheckStep(check), func(ctx context.Context) error {
+			if err := fixer.RunCheck(ctx, dir, check); err != nil {
+				return fmt.Errorf("failed to verify %s: %w", check, err)
+			}
+			return nil
+		})
+	}
+}
+
+// failure formats a failed tool run; when a verification check failed, its diagnostics
+// are returned as data so the caller can fix them without re-running the check
+func failure(err error) (*mcp.CallToolResult, any, error) {
+	var verr *fixer.VerifyError
+	if !errors.As(err, &verr) {
+		return responses.FormatError(err.Error())
+	}
+	return responses.FormatErrorWithData(err.Error(), map[string]any{
+		"failed_checks": verr.Checks,
+		"diagnostics":   verr.Diagnostics,
+	})
+}